@@ -0,0 +1,67 @@
+package gqs
+
+import "time"
+
+// DefaultAdaptiveMultiplier is the factor used by adaptive polling when
+// AdaptivePullConfig.Multiplier is not greater than one.
+const DefaultAdaptiveMultiplier = 2.0
+
+// AdaptivePullConfig enables adaptive polling of a Worker.
+//
+// While pulls return full batches, the interval until the next pull is
+// divided by Multiplier, down to MinInterval, so that a busy worker
+// drains its backlog quickly. While pulls return no jobs, the interval
+// is multiplied by Multiplier, up to MaxInterval, reducing the query
+// load of idle workers. A partially filled batch resets the interval to
+// WorkerConfig.PullInterval.
+//
+// A zero MinInterval or MaxInterval defaults to PullInterval, disabling
+// the corresponding adjustment. A Notifier still wakes the worker up
+// immediately regardless of the current interval.
+type AdaptivePullConfig struct {
+	MinInterval time.Duration
+	MaxInterval time.Duration
+	Multiplier  float64
+}
+
+type adaptivePull struct {
+	base       time.Duration
+	min        time.Duration
+	max        time.Duration
+	multiplier float64
+	current    time.Duration
+}
+
+func newAdaptivePull(config *AdaptivePullConfig, base time.Duration) *adaptivePull {
+	if config == nil {
+		return nil
+	}
+	ret := &adaptivePull{
+		base:       base,
+		min:        min(config.MinInterval, base),
+		max:        max(config.MaxInterval, base),
+		multiplier: config.Multiplier,
+		current:    base,
+	}
+	if config.MinInterval <= 0 {
+		ret.min = base
+	}
+	if ret.multiplier <= 1 {
+		ret.multiplier = DefaultAdaptiveMultiplier
+	}
+	return ret
+}
+
+// next returns the interval until the next pull after a pull that
+// returned pulled jobs out of batch.
+func (ap *adaptivePull) next(pulled, batch int) time.Duration {
+	switch {
+	case pulled >= batch:
+		ap.current = max(time.Duration(float64(ap.current)/ap.multiplier), ap.min)
+	case pulled == 0:
+		ap.current = min(time.Duration(float64(ap.current)*ap.multiplier), ap.max)
+	default:
+		ap.current = ap.base
+	}
+	return ap.current
+}
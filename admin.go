@@ -0,0 +1,62 @@
+package gqs
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Admin provides bulk administrative operations on sets of jobs.
+//
+// Admin is intended for operational use, such as requeueing jobs
+// killed by an incident or postponing a flood of jobs. It is not used
+// by Worker.
+//
+// Operations taking a filter select jobs with the filtering fields of
+// ListOptions (Ids, Statuses, Queues, Tenants, LockedBy, time ranges
+// and Metadata); Order, Limit, Cursor and Offset are ignored. Each
+// operation only accepts the statuses it may transition; if
+// filter.Statuses lists any other status, ErrBadStatus is returned. An
+// empty Statuses list selects all accepted statuses.
+//
+// All operations return the number of affected jobs.
+type Admin interface {
+
+	// KillByStatus transitions matching Pending, Scheduled and
+	// Processing jobs to Dead.
+	//
+	// Workers holding killed Processing jobs lose their leases.
+	KillByStatus(ctx context.Context, filter *ListOptions) (int64, error)
+
+	// RequeueByStatus transitions matching terminal jobs back to
+	// Pending, resets their attempts and makes them eligible immediately.
+	RequeueByStatus(ctx context.Context, filter *ListOptions) (int64, error)
+
+	// DeleteByIds permanently removes the jobs with the given ids.
+	//
+	// Processing jobs are never deleted; unknown ids are ignored.
+	DeleteByIds(ctx context.Context, ids []uuid.UUID) (int64, error)
+
+	// UpdateNextRun reschedules matching Pending and Scheduled jobs to
+	// become eligible at the given time, making them Scheduled if it is
+	// in the future and Pending otherwise.
+	UpdateNextRun(ctx context.Context, filter *ListOptions, at time.Time) (int64, error)
+}
+
+// Canceler is an optional extension of Admin aborting single jobs,
+// including jobs whose handler is running.
+type Canceler interface {
+
+	// Cancel aborts the job with the given id.
+	//
+	// A Pending or Scheduled job is transitioned to Cancelled
+	// immediately. For a
+	// Processing job, cancellation is requested: its next lease
+	// extension fails with ErrCancelRequested, which makes Worker cancel
+	// the handler context and transition the job to Cancelled.
+	// Cancellation is therefore observed within half of the lock timeout.
+	//
+	// Cancel returns an error wrapping ErrNotFound if the job does not
+	// exist and ErrBadStatus if it is terminal.
+	Cancel(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,80 @@
+package gqs
+
+import (
+	"context"
+	"github.com/romanqed/gqs/job"
+	"time"
+)
+
+// AlertKind identifies which limit of an AlertThreshold was exceeded.
+type AlertKind uint8
+
+const (
+	// AlertCount indicates that the number of jobs exceeded MaxCount.
+	AlertCount AlertKind = iota + 1
+
+	// AlertAge indicates that the oldest job exceeded MaxAge.
+	AlertAge
+
+	// AlertRate indicates that the number of jobs that entered the status
+	// during the last hour exceeded MaxPerHour.
+	AlertRate
+)
+
+// AlertThreshold defines alerting limits for jobs of a single queue
+// in a single status.
+//
+// Queue and Status identify the threshold. If Status is job.Unknown
+// (zero value), jobs in any status are taken into account.
+//
+// MaxCount limits the number of jobs (for example, maximum Pending
+// backlog).
+//
+// MaxAge limits the age of the oldest job, measured from CreatedAt.
+//
+// MaxPerHour limits the number of jobs whose last transition into
+// the status happened within the last hour (for example, maximum
+// Dead jobs per hour).
+//
+// A zero limit disables the corresponding check.
+type AlertThreshold struct {
+	Queue      string
+	Status     job.Status
+	MaxCount   int64
+	MaxAge     time.Duration
+	MaxPerHour int64
+}
+
+// Alert describes a single exceeded limit.
+//
+// Count holds the measured number of jobs for AlertCount and AlertRate.
+// Age holds the measured age of the oldest job for AlertAge.
+type Alert struct {
+	Kind      AlertKind
+	Threshold AlertThreshold
+	Count     int64
+	Age       time.Duration
+}
+
+// Alerter manages alert thresholds stored next to the queue data and
+// evaluates them against the current storage state.
+//
+// Keeping thresholds in storage lets dashboards, alerting and
+// administrative tools share a single source of truth.
+type Alerter interface {
+
+	// SetThreshold creates or replaces the threshold identified by
+	// its Queue and Status.
+	SetThreshold(ctx context.Context, threshold *AlertThreshold) error
+
+	// DeleteThreshold removes the threshold identified by queue and status.
+	// Deleting a missing threshold is not an error.
+	DeleteThreshold(ctx context.Context, queue string, status job.Status) error
+
+	// Thresholds returns all stored thresholds.
+	Thresholds(ctx context.Context) ([]*AlertThreshold, error)
+
+	// Evaluate checks every stored threshold and returns the exceeded
+	// limits. An empty result means that no limit is exceeded.
+	Evaluate(ctx context.Context) ([]*Alert, error)
+}
@@ -0,0 +1,95 @@
+package gqs
+
+import (
+	"context"
+	"github.com/romanqed/gqs/internal"
+	"time"
+)
+
+// AlertHandler is invoked by AlertWorker for every exceeded limit.
+type AlertHandler func(ctx context.Context, alert *Alert)
+
+// AlertConfig defines the behavior of an AlertWorker.
+//
+// Interval defines how often thresholds are evaluated.
+//
+// Handler is invoked for every exceeded limit, after it is logged.
+// Handler is optional.
+type AlertConfig struct {
+	Interval time.Duration
+	Handler  AlertHandler
+}
+
+// AlertWorker periodically evaluates thresholds of an Alerter and
+// reports exceeded limits.
+//
+// AlertWorker has a strict lifecycle:
+//   - Start may only be called once.
+//   - Stop must be called to terminate the worker.
+//   - Stop waits for the internal task to finish or until the timeout
+//     expires.
+type AlertWorker struct {
+	lcBase
+	alerter  Alerter
+	task     internal.TimerTask
+	log      Logger
+	interval time.Duration
+	handler  AlertHandler
+}
+
+// NewAlertWorker creates a new AlertWorker using the provided
+// Alerter implementation and configuration.
+//
+// The worker is not started automatically. Call Start to begin
+// periodic evaluation.
+func NewAlertWorker(alerter Alerter, config *AlertConfig, log Logger) *AlertWorker {
+	return &AlertWorker{
+		alerter:  alerter,
+		log:      loggerOf(log),
+		interval: config.Interval,
+		handler:  config.Handler,
+	}
+}
+
+func (aw *AlertWorker) evaluate(ctx context.Context) {
+	alerts, err := aw.alerter.Evaluate(ctx)
+	if err != nil {
+		aw.log.Error("error while evaluating alerts", "error", err)
+		return
+	}
+	for _, alert := range alerts {
+		aw.log.Warn("alert threshold exceeded",
+			"kind", alert.Kind,
+			"queue", alert.Threshold.Queue,
+			"status", alert.Threshold.Status,
+			"count", alert.Count,
+			"age", alert.Age)
+		if aw.handler != nil {
+			aw.handler(ctx, alert)
+		}
+	}
+}
+
+// Start begins periodic evaluation of alert thresholds.
+//
+// Start returns ErrDoubleStarted if the worker has already been started.
+//
+// The provided context controls cancellation of the background task.
+func (aw *AlertWorker) Start(ctx context.Context) error {
+	if err := aw.tryStart(); err != nil {
+		return err
+	}
+	aw.task.Start(ctx, aw.evaluate, aw.interval)
+	return nil
+}
+
+// Stop terminates the background evaluation task.
+//
+// Stop waits until the task finishes or the specified timeout expires.
+// If shutdown does not complete within the timeout, ErrStopTimeout
+// is returned.
+//
+// Stop returns ErrDoubleStopped if the worker is not running.
+func (aw *AlertWorker) Stop(timeout time.Duration) error {
+	return aw.tryStop(timeout, aw.task.Stop)
+}
@@ -0,0 +1,59 @@
+package gqs_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+)
+
+type mockAlerter struct{}
+
+func (m *mockAlerter) SetThreshold(ctx context.Context, threshold *gqs.AlertThreshold) error {
+	return nil
+}
+
+func (m *mockAlerter) DeleteThreshold(ctx context.Context, queue string, status job.Status) error {
+	return nil
+}
+
+func (m *mockAlerter) Thresholds(ctx context.Context) ([]*gqs.AlertThreshold, error) {
+	return nil, nil
+}
+
+func (m *mockAlerter) Evaluate(ctx context.Context) ([]*gqs.Alert, error) {
+	return []*gqs.Alert{{Kind: gqs.AlertCount, Count: 10}}, nil
+}
+
+func TestAlertWorkerBasic(t *testing.T) {
+	alerts := make(chan *gqs.Alert, 10)
+
+	cfg := &gqs.AlertConfig{
+		Interval: 50 * time.Millisecond,
+		Handler: func(ctx context.Context, alert *gqs.Alert) {
+			alerts <- alert
+		},
+	}
+
+	w := gqs.NewAlertWorker(&mockAlerter{}, cfg, slog.Default())
+
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case alert := <-alerts:
+		if alert.Kind != gqs.AlertCount {
+			t.Fatalf("expected count alert, got %v", alert.Kind)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("alert handler not called")
+	}
+
+	if err := w.Stop(time.Second); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,122 @@
+package gqs
+
+import (
+	"context"
+	"github.com/romanqed/gqs/job"
+	"sync"
+	"time"
+)
+
+type attemptKey struct{}
+
+// attempt holds the state a handler accumulates during a single
+// processing attempt of a job.
+type attempt struct {
+	mu        sync.Mutex
+	job       *job.Job
+	number    uint32
+	started   time.Time
+	scheduled time.Time
+	lastError string
+	result    []byte
+	hasResult bool
+	logs      []job.LogLine
+	next      []Continuation
+}
+
+// AttemptInfo describes the processing attempt of the job being handled.
+//
+// Number is the attempt number, starting at 1. Started is the time the
+// handler was invoked and Scheduled the time the job became eligible
+// for this attempt, so that Started.Sub(Scheduled) is the queueing
+// delay; for retried jobs, Scheduled is the end of the retry backoff.
+//
+// PreviousError is the error of the most recent failed attempt, as
+// stored in job.Job.LastError. It is empty if no attempt has failed.
+type AttemptInfo struct {
+	Number        uint32
+	Started       time.Time
+	Scheduled     time.Time
+	PreviousError string
+}
+
+// Attempt returns information about the processing attempt of the job
+// being handled, so that retried handlers can adapt their behavior,
+// for example by switching to a fallback endpoint.
+//
+// ctx must be the context passed to a MessageHandler by Worker.
+// Attempt returns false if ctx does not belong to a handler.
+func Attempt(ctx context.Context) (AttemptInfo, bool) {
+	at, ok := attemptFrom(ctx)
+	if !ok {
+		return AttemptInfo{}, false
+	}
+	return AttemptInfo{
+		Number:        at.number,
+		Started:       at.started,
+		Scheduled:     at.scheduled,
+		PreviousError: at.lastError,
+	}, true
+}
+
+// PreviousError returns the error of the most recent failed attempt of
+// the job being handled.
+//
+// ctx must be the context passed to a MessageHandler by Worker.
+// PreviousError returns false if ctx does not belong to a handler or
+// no attempt of the job has failed yet.
+func PreviousError(ctx context.Context) (string, bool) {
+	at, ok := attemptFrom(ctx)
+	if !ok || at.lastError == "" {
+		return "", false
+	}
+	return at.lastError, true
+}
+
+func withAttempt(ctx context.Context, at *attempt) context.Context {
+	return context.WithValue(ctx, attemptKey{}, at)
+}
+
+func attemptFrom(ctx context.Context) (*attempt, bool) {
+	ret, ok := ctx.Value(attemptKey{}).(*attempt)
+	return ret, ok
+}
+
+func (at *attempt) setResult(result []byte) {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+	at.result = result
+	at.hasResult = true
+}
+
+func (at *attempt) getResult() ([]byte, bool) {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+	return at.result, at.hasResult
+}
+
+func (at *attempt) addLog(line job.LogLine) {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+	at.logs = append(at.logs, line)
+}
+
+func (at *attempt) takeLogs() []job.LogLine {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+	ret := at.logs
+	at.logs = nil
+	return ret
+}
+
+func (at *attempt) addNext(next Continuation) {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+	at.next = append(at.next, next)
+}
+
+func (at *attempt) getNext() []Continuation {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+	return at.next
+}
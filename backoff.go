@@ -1,25 +1,65 @@
 package gqs
 
 import (
+	"github.com/romanqed/gqs/job"
 	"math"
 	"math/rand/v2"
 	"time"
 )
 
+// BackoffConfig defines the retry policy applied by Worker when
+// a handler returns an error. It is the default RetryPolicy.
+//
+// MaxRetries limits the number of attempts; zero means unlimited.
+// It may be overridden per message (see message.Message.MaxRetries).
+//
+// InitialInterval, MaxInterval, Multiplier and RandomizationFactor
+// control the exponential delay between attempts.
+//
+// PriorityStep is subtracted from the job priority each time the job
+// is rescheduled, so repeatedly failing jobs gradually yield to fresh
+// work. A zero PriorityStep leaves priority unchanged.
+//
+// MinPriority is the lower bound for demotion. Jobs already below
+// MinPriority are not demoted further.
 type BackoffConfig struct {
 	MaxRetries          uint32
 	InitialInterval     time.Duration
 	MaxInterval         time.Duration
 	Multiplier          float64
 	RandomizationFactor float64
+	PriorityStep        int
+	MinPriority         int
 }
 
-type backoffCounter struct {
-	BackoffConfig
+// RetryPolicy decides whether and when a failed job is retried.
+//
+// NextDelay is called with the number of the attempt that failed,
+// starting at 1, and the error returned by the handler. It returns the
+// delay before the next attempt, or false to make the job Dead.
+//
+// Implementations may inspect err to retry different kinds of failures
+// differently, for example to give up on validation errors at once or
+// to back off longer on rate limiting responses. NextDelay may be
+// called concurrently and must be safe for concurrent use.
+//
+// If a message carries its own MaxRetries, Worker makes the job Dead
+// once it is exceeded, before consulting the policy.
+type RetryPolicy interface {
+	NextDelay(attempt uint32, err error) (time.Duration, bool)
 }
 
-func (bc *backoffCounter) next(attempt uint32) (time.Duration, bool) {
-	if bc.MaxRetries > 0 && attempt > bc.MaxRetries {
+// NextDelay implements RetryPolicy with an exponential backoff,
+// regardless of err.
+func (bc BackoffConfig) NextDelay(attempt uint32, err error) (time.Duration, bool) {
+	return bc.next(attempt, 0)
+}
+
+func (bc BackoffConfig) next(attempt uint32, maxRetries uint32) (time.Duration, bool) {
+	if maxRetries == 0 {
+		maxRetries = bc.MaxRetries
+	}
+	if maxRetries > 0 && attempt > maxRetries {
 		return 0, false
 	}
 	exp := float64(bc.InitialInterval) * math.Pow(bc.Multiplier, float64(attempt-1))
@@ -34,3 +74,44 @@ func (bc *backoffCounter) next(attempt uint32) (time.Duration, bool) {
 	}
 	return time.Duration(exp), true
 }
+
+func (bc BackoffConfig) demote(priority int) int {
+	if bc.PriorityStep == 0 || priority <= bc.MinPriority {
+		return priority
+	}
+	return max(priority-bc.PriorityStep, bc.MinPriority)
+}
+
+// retryPolicy is the retry policy of a class of errors, paired with the
+// backoff governing priority demotion.
+type retryPolicy struct {
+	policy  RetryPolicy
+	backoff BackoffConfig
+}
+
+func newRetryPolicy(policy RetryPolicy, backoff BackoffConfig) retryPolicy {
+	if policy == nil {
+		policy = backoff
+	}
+	return retryPolicy{policy: policy, backoff: backoff}
+}
+
+// exceeded reports whether jb reached the retry limit of its message or,
+// if unset, of the backoff.
+func (rp retryPolicy) exceeded(jb *job.Job) bool {
+	limit := jb.MaxRetries
+	if limit == 0 {
+		limit = rp.backoff.MaxRetries
+	}
+	return limit > 0 && jb.Attempts > limit
+}
+
+func (rp retryPolicy) next(jb *job.Job, err error) (time.Duration, bool) {
+	if bc, ok := rp.policy.(BackoffConfig); ok {
+		return bc.next(jb.Attempts, jb.MaxRetries)
+	}
+	if jb.MaxRetries > 0 && jb.Attempts > jb.MaxRetries {
+		return 0, false
+	}
+	return rp.policy.NextDelay(jb.Attempts, err)
+}
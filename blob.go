@@ -0,0 +1,217 @@
+package gqs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/romanqed/gqs/message"
+	"maps"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// MetaPayloadRef is the metadata key under which OffloadPusher stores
+// the blob key of an offloaded payload.
+const MetaPayloadRef = "gqs_payload_ref"
+
+var (
+	// ErrPayloadTooLarge is returned by OffloadPusher for messages whose
+	// payload exceeds OffloadConfig.MaxSize.
+	ErrPayloadTooLarge = errors.New("payload too large")
+
+	// ErrBlobNotFound is returned by BlobStore.Get for missing blobs.
+	ErrBlobNotFound = errors.New("blob not found")
+)
+
+// BlobStore stores payloads too large to be kept with jobs, such as
+// an object storage bucket or a shared filesystem.
+//
+// Keys are generated by OffloadPusher from message ids and consist of
+// characters safe in file names and object keys.
+type BlobStore interface {
+
+	// Put stores data under key, replacing any previous blob.
+	Put(ctx context.Context, key string, data []byte) error
+
+	// Get returns the blob stored under key, or an error wrapping
+	// ErrBlobNotFound if there is none.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Delete removes the blob stored under key. Deleting a missing blob
+	// is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// OffloadConfig defines how OffloadPusher treats large payloads.
+//
+// Payloads longer than Threshold bytes are written to Store and the
+// message keeps only a reference in its metadata (see MetaPayloadRef).
+// If Store is nil or Threshold is zero, payloads are never offloaded.
+//
+// MaxSize, if positive, is the maximum payload size accepted; larger
+// payloads are rejected with ErrPayloadTooLarge before anything is
+// written.
+type OffloadConfig struct {
+	Store     BlobStore
+	Threshold int
+	MaxSize   int
+}
+
+// OffloadPusher is a Pusher enforcing a payload size limit and moving
+// large payloads to a BlobStore before delegating to the underlying
+// Pusher, so that pulling jobs does not transfer multi-megabyte rows.
+//
+// Offloaded payloads are resolved by Worker if WorkerConfig.Blobs is
+// set, or by the ResolvePayloads middleware.
+type OffloadPusher struct {
+	pusher Pusher
+	config OffloadConfig
+}
+
+// NewOffloadPusher creates an OffloadPusher delegating to pusher.
+func NewOffloadPusher(pusher Pusher, config *OffloadConfig) *OffloadPusher {
+	return &OffloadPusher{
+		pusher: pusher,
+		config: *config,
+	}
+}
+
+func (op *OffloadPusher) offload(ctx context.Context, msg *message.Message) (*message.Message, error) {
+	size := len(msg.Payload)
+	if op.config.MaxSize > 0 && size > op.config.MaxSize {
+		return nil, fmt.Errorf("%w: %d bytes exceed %d", ErrPayloadTooLarge, size, op.config.MaxSize)
+	}
+	if op.config.Store == nil || op.config.Threshold <= 0 || size <= op.config.Threshold {
+		return msg, nil
+	}
+	key := msg.Id.String()
+	if err := op.config.Store.Put(ctx, key, msg.Payload); err != nil {
+		return nil, err
+	}
+	// the caller's message is left untouched
+	ret := *msg
+	ret.Metadata = maps.Clone(msg.Metadata)
+	ret.Set(MetaPayloadRef, key)
+	ret.Payload = nil
+	return &ret, nil
+}
+
+// Push enqueues msg, offloading its payload if it exceeds the
+// threshold. It returns ErrPayloadTooLarge if the payload exceeds the
+// size limit.
+//
+// If the underlying Pusher fails, the written blob is deleted.
+func (op *OffloadPusher) Push(ctx context.Context, msg *message.Message, delay time.Duration) error {
+	return op.push(ctx, msg, func(msg *message.Message) error {
+		return op.pusher.Push(ctx, msg, delay)
+	})
+}
+
+// PushAt enqueues msg to run at at, offloading its payload as Push. If
+// the underlying Pusher does not implement SchedulePusher, the time
+// remaining until at is used as the push delay.
+func (op *OffloadPusher) PushAt(ctx context.Context, msg *message.Message, at time.Time) error {
+	return op.push(ctx, msg, func(msg *message.Message) error {
+		if scheduler, ok := feature[SchedulePusher](op.pusher, CapSchedulePush); ok {
+			return scheduler.PushAt(ctx, msg, at)
+		}
+		return op.pusher.Push(ctx, msg, max(time.Until(at), 0))
+	})
+}
+
+func (op *OffloadPusher) push(ctx context.Context, msg *message.Message, fn func(msg *message.Message) error) error {
+	stored, err := op.offload(ctx, msg)
+	if err != nil {
+		return err
+	}
+	err = fn(stored)
+	if err != nil && stored != msg && !errors.Is(err, ErrDuplicateID) {
+		// the blob of a duplicate belongs to the existing job
+		_ = op.config.Store.Delete(context.WithoutCancel(ctx), msg.Id.String())
+	}
+	return err
+}
+
+// ResolvePayload loads the offloaded payload of msg from store, if msg
+// refers to one. The reference is kept in the metadata, so that the
+// blob can be deleted once the job is done.
+func ResolvePayload(ctx context.Context, store BlobStore, msg *message.Message) error {
+	key, ok := message.Get[string](msg, MetaPayloadRef)
+	if !ok {
+		return nil
+	}
+	data, err := store.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("cannot resolve payload %s: %w", key, err)
+	}
+	msg.Payload = data
+	return nil
+}
+
+// ResolvePayloads returns a Middleware loading offloaded payloads from
+// store before invoking the handler (see ResolvePayload). A payload
+// that cannot be loaded fails the attempt with the error of store.
+func ResolvePayloads(store BlobStore) Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, msg *message.Message) error {
+			if err := ResolvePayload(ctx, store, msg); err != nil {
+				return err
+			}
+			return next(ctx, msg)
+		}
+	}
+}
+
+// DirBlobStore is a BlobStore keeping every blob in a file of a
+// directory, for example a network filesystem shared by producers and
+// workers.
+type DirBlobStore struct {
+	dir string
+}
+
+// NewDirBlobStore creates a DirBlobStore storing blobs in dir, which
+// must exist.
+func NewDirBlobStore(dir string) *DirBlobStore {
+	return &DirBlobStore{dir: dir}
+}
+
+func (ds *DirBlobStore) path(key string) string {
+	return filepath.Join(ds.dir, filepath.Base(key))
+}
+
+// Put writes data to the file of key. The file is written under a
+// temporary name and renamed, so readers never observe partial blobs.
+func (ds *DirBlobStore) Put(_ context.Context, key string, data []byte) error {
+	tmp, err := os.CreateTemp(ds.dir, ".blob-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), ds.path(key))
+}
+
+// Get reads the file of key.
+func (ds *DirBlobStore) Get(_ context.Context, key string) ([]byte, error) {
+	ret, err := os.ReadFile(ds.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrBlobNotFound, key)
+	}
+	return ret, err
+}
+
+// Delete removes the file of key.
+func (ds *DirBlobStore) Delete(_ context.Context, key string) error {
+	err := os.Remove(ds.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
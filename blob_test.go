@@ -0,0 +1,93 @@
+package gqs_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestOffloadPayload(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	store := gqs.NewDirBlobStore(t.TempDir())
+	observer := gsql.NewObserver(db)
+	pusher := gqs.NewOffloadPusher(gsql.NewPusher(db), &gqs.OffloadConfig{
+		Store:     store,
+		Threshold: 16,
+		MaxSize:   1024,
+	})
+
+	large := message.NewMessage()
+	large.Payload = bytes.Repeat([]byte("x"), 512)
+	if err := pusher.Push(ctx, large, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(large.Payload) != 512 || large.Get(gqs.MetaPayloadRef) != nil {
+		t.Fatal("expected the pushed message to be left untouched")
+	}
+
+	small := message.NewMessage()
+	small.Payload = []byte("small")
+	if err := pusher.Push(ctx, small, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	huge := message.NewMessage()
+	huge.Payload = bytes.Repeat([]byte("x"), 2048)
+	if err := pusher.Push(ctx, huge, 0); !errors.Is(err, gqs.ErrPayloadTooLarge) {
+		t.Fatalf("expected ErrPayloadTooLarge, got %v", err)
+	}
+
+	stored, _ := observer.Get(ctx, large.Id)
+	if stored == nil || len(stored.Payload) != 0 || stored.Get(gqs.MetaPayloadRef) == nil {
+		t.Fatalf("expected the payload to be offloaded, got %+v", stored)
+	}
+
+	got := make(chan []byte, 2)
+	handler := func(ctx context.Context, msg *message.Message) error {
+		got <- msg.Payload
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    10,
+		PullInterval: 50 * time.Millisecond,
+		LockTimeout:  200 * time.Millisecond,
+		Blobs:        store,
+	}
+
+	worker := gqs.NewWorker(gsql.NewPuller(db), handler, cfg, slog.Default())
+	_ = worker.Start(ctx)
+
+	time.Sleep(200 * time.Millisecond)
+
+	_ = worker.Stop(time.Second)
+
+	close(got)
+	var sizes []int
+	for payload := range got {
+		sizes = append(sizes, len(payload))
+	}
+	if len(sizes) != 2 || sizes[0]+sizes[1] != 512+5 {
+		t.Fatalf("unexpected resolved payload sizes %v", sizes)
+	}
+
+	stored, _ = observer.Get(ctx, large.Id)
+	if stored == nil || stored.Status != job.Done {
+		t.Fatalf("expected done job, got %+v", stored)
+	}
+	if _, err := store.Get(ctx, large.Id.String()); !errors.Is(err, gqs.ErrBlobNotFound) {
+		t.Fatalf("expected the blob to be deleted, got %v", err)
+	}
+}
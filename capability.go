@@ -0,0 +1,180 @@
+package gqs
+
+// Capability is a set of optional features supported by a storage
+// implementation.
+type Capability uint32
+
+const (
+	// CapBatchPush indicates support for BatchPusher.
+	CapBatchPush Capability = 1 << iota
+
+	// CapSnapshotPush indicates support for SnapshotPusher.
+	CapSnapshotPush
+
+	// CapBatchExtend indicates support for BatchLockExtender.
+	CapBatchExtend
+
+	// CapRelease indicates support for Releaser.
+	CapRelease
+
+	// CapResult indicates support for ResultCompleter.
+	CapResult
+
+	// CapLogs indicates support for LogSaver.
+	CapLogs
+
+	// CapQuery indicates support for QueryObserver.
+	CapQuery
+
+	// CapInstances indicates support for InstanceObserver.
+	CapInstances
+
+	// CapStream indicates support for StreamPuller.
+	CapStream
+
+	// CapLockLoss indicates support for LockLossRecorder.
+	CapLockLoss
+
+	// CapBatchComplete indicates support for BatchCompleter.
+	CapBatchComplete
+
+	// CapSchedulePush indicates support for SchedulePusher.
+	CapSchedulePush
+
+	// CapExport indicates support for Exporter.
+	CapExport
+
+	// CapDiagnostics indicates support for DiagnosticsSaver.
+	CapDiagnostics
+
+	// CapHistory indicates support for HistoryObserver.
+	CapHistory
+
+	// CapReport indicates support for Reporter.
+	CapReport
+
+	// CapChain indicates support for ChainCompleter.
+	CapChain
+
+	// CapMetrics indicates support for MetricsObserver.
+	CapMetrics
+
+	// CapPause indicates support for PauseObserver.
+	CapPause
+
+	// CapFilter indicates support for FilterPuller.
+	CapFilter
+
+	// CapOverview indicates support for OverviewObserver.
+	CapOverview
+
+	// CapOwner indicates support for OwnerPuller.
+	CapOwner
+
+	// CapArchive indicates support for Archiver.
+	CapArchive
+
+	// CapStats indicates support for StatsObserver.
+	CapStats
+
+	// CapCancel indicates support for Canceler.
+	CapCancel
+
+	// CapBatchClean indicates support for BatchCleaner.
+	CapBatchClean
+
+	// CapGroupPush indicates support for GroupPusher.
+	CapGroupPush
+
+	// CapGroups indicates support for GroupObserver.
+	CapGroups
+
+	// CapRetentionClean indicates support for RetentionCleaner.
+	CapRetentionClean
+
+	// CapDeadLetters indicates support for DeadLetterObserver.
+	CapDeadLetters
+)
+
+// Has reports whether all capabilities of other are present in c.
+func (c Capability) Has(other Capability) bool {
+	return c&other == other
+}
+
+// Capable is an optional interface of storage implementations
+// advertising the optional features they support.
+//
+// Implementing Capable is required only when the set of supported
+// features is not fixed by the type, for example for wrappers
+// forwarding every method to an underlying implementation that may
+// lack some of them. Types that do not implement Capable are assumed
+// to support every optional interface they implement.
+type Capable interface {
+
+	// Capabilities returns the supported optional features.
+	//
+	// The result must not change during the lifetime of the value.
+	Capabilities() Capability
+}
+
+func implements[T any](impl any) bool {
+	_, ok := impl.(T)
+	return ok
+}
+
+var capabilityChecks = map[Capability]func(any) bool{
+	CapBatchPush:      implements[BatchPusher],
+	CapSnapshotPush:   implements[SnapshotPusher],
+	CapBatchExtend:    implements[BatchLockExtender],
+	CapRelease:        implements[Releaser],
+	CapResult:         implements[ResultCompleter],
+	CapLogs:           implements[LogSaver],
+	CapQuery:          implements[QueryObserver],
+	CapInstances:      implements[InstanceObserver],
+	CapStream:         implements[StreamPuller],
+	CapLockLoss:       implements[LockLossRecorder],
+	CapBatchComplete:  implements[BatchCompleter],
+	CapSchedulePush:   implements[SchedulePusher],
+	CapExport:         implements[Exporter],
+	CapDiagnostics:    implements[DiagnosticsSaver],
+	CapHistory:        implements[HistoryObserver],
+	CapReport:         implements[Reporter],
+	CapChain:          implements[ChainCompleter],
+	CapMetrics:        implements[MetricsObserver],
+	CapPause:          implements[PauseObserver],
+	CapFilter:         implements[FilterPuller],
+	CapOverview:       implements[OverviewObserver],
+	CapOwner:          implements[OwnerPuller],
+	CapArchive:        implements[Archiver],
+	CapStats:          implements[StatsObserver],
+	CapCancel:         implements[Canceler],
+	CapBatchClean:     implements[BatchCleaner],
+	CapGroupPush:      implements[GroupPusher],
+	CapGroups:         implements[GroupObserver],
+	CapRetentionClean: implements[RetentionCleaner],
+	CapDeadLetters:    implements[DeadLetterObserver],
+}
+
+// Supports reports whether impl supports every capability of c.
+//
+// A capability is supported if impl implements the corresponding
+// optional interface and, if impl implements Capable, advertises it.
+//
+// Worker uses Supports to detect optional features of its Puller and
+// falls back to the core interface when a feature is missing.
+func Supports(impl any, c Capability) bool {
+	if capable, ok := impl.(Capable); ok && !capable.Capabilities().Has(c) {
+		return false
+	}
+	for bit, check := range capabilityChecks {
+		if c.Has(bit) && !check(impl) {
+			return false
+		}
+	}
+	return true
+}
+
+func feature[T any](impl any, c Capability) (T, bool) {
+	ret, ok := impl.(T)
+	return ret, ok && Supports(impl, c)
+}
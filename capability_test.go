@@ -0,0 +1,87 @@
+package gqs_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+type hidingPuller struct {
+	*gsql.Puller
+	caps gqs.Capability
+}
+
+func (hp *hidingPuller) Capabilities() gqs.Capability {
+	return hp.caps
+}
+
+func TestSupports(t *testing.T) {
+	db := newTestDB(t)
+	puller := gsql.NewPuller(db)
+
+	if !gqs.Supports(puller, gqs.CapRelease|gqs.CapResult) {
+		t.Fatal("expected puller to support release and results")
+	}
+	if gqs.Supports(puller, gqs.CapQuery) {
+		t.Fatal("expected puller not to support queries")
+	}
+
+	wrapped := &hidingPuller{Puller: puller, caps: gqs.CapRelease}
+	if !gqs.Supports(wrapped, gqs.CapRelease) {
+		t.Fatal("expected advertised capability to be supported")
+	}
+	if gqs.Supports(wrapped, gqs.CapResult) {
+		t.Fatal("expected hidden capability not to be supported")
+	}
+}
+
+func TestWorkerDegradesWithoutCapability(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	observer := gsql.NewObserver(db)
+	puller := &hidingPuller{Puller: gsql.NewPuller(db)}
+
+	logger := slog.Default()
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		gqs.SetResult(ctx, []byte("result"))
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    1,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  200 * time.Millisecond,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_ = worker.Start(ctx)
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+
+	time.Sleep(200 * time.Millisecond)
+
+	j, _ := observer.Get(ctx, msg.Id)
+	if j.Status != job.Done {
+		t.Fatalf("expected Done, got %v", j.Status)
+	}
+	if j.Result != nil {
+		t.Fatalf("expected result to be discarded, got %q", j.Result)
+	}
+
+	_ = worker.Stop(time.Second)
+}
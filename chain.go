@@ -0,0 +1,54 @@
+package gqs
+
+import (
+	"context"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	"time"
+)
+
+// Continuation is a follow-up message enqueued together with the
+// completion of a job.
+//
+// Delay is applied to the follow-up as by Pusher.Push.
+type Continuation struct {
+	Message *message.Message
+	Delay   time.Duration
+}
+
+// ChainCompleter is an optional extension of Puller that enqueues
+// follow-up messages atomically with the Done transition of a job.
+//
+// Worker uses it when a handler schedules follow-ups with PushNext, so
+// that a crash between completing a job and pushing its continuation
+// cannot lose the continuation.
+type ChainCompleter interface {
+
+	// CompleteAndPush behaves like Puller.Complete and additionally
+	// enqueues every message of next, within a single atomic operation:
+	// either the job is completed and all messages are enqueued, or
+	// nothing changes.
+	//
+	// If job.Result is not nil, it is stored as by
+	// ResultCompleter.CompleteWithResult.
+	CompleteAndPush(ctx context.Context, job *job.Job, next []Continuation) error
+}
+
+// PushNext schedules msg to be enqueued after delay once the job being
+// handled completes successfully.
+//
+// ctx must be the context passed to a MessageHandler by Worker.
+// Follow-ups are pushed atomically with the completion of the job if
+// the Puller implements ChainCompleter; otherwise they are discarded
+// and an error is logged. Follow-ups are discarded if the handler
+// returns an error, so a retried attempt must schedule them again.
+//
+// PushNext returns false if ctx does not belong to a handler.
+func PushNext(ctx context.Context, msg *message.Message, delay time.Duration) bool {
+	at, ok := attemptFrom(ctx)
+	if !ok {
+		return false
+	}
+	at.addNext(Continuation{Message: msg, Delay: delay})
+	return true
+}
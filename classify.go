@@ -0,0 +1,44 @@
+package gqs
+
+// ErrorClass names a category of handler errors, such as "network"
+// or "validation", that share a retry policy.
+//
+// The empty class denotes errors without a specific policy.
+type ErrorClass string
+
+// Classifier maps a handler error to its ErrorClass.
+//
+// Classifier is only invoked for errors that follow the retry path,
+// that is not for ErrKill or shutdown cancellations.
+type Classifier func(err error) ErrorClass
+
+// ClassPolicy defines how Worker treats errors of a single ErrorClass.
+//
+// Kill makes the job Dead immediately, without retries.
+// Otherwise, the job is retried according to Retry, if set, or Backoff,
+// which replace WorkerConfig.RetryPolicy and WorkerConfig.Backoff for
+// errors of the class. Priority demotion always follows Backoff.
+type ClassPolicy struct {
+	Kill    bool
+	Backoff BackoffConfig
+	Retry   RetryPolicy
+}
+
+type classPolicy struct {
+	kill  bool
+	retry retryPolicy
+}
+
+func newClassPolicies(policies map[ErrorClass]ClassPolicy) map[ErrorClass]classPolicy {
+	if len(policies) == 0 {
+		return nil
+	}
+	ret := make(map[ErrorClass]classPolicy, len(policies))
+	for class, policy := range policies {
+		ret[class] = classPolicy{
+			kill:  policy.Kill,
+			retry: newRetryPolicy(policy.Retry, policy.Backoff),
+		}
+	}
+	return ret
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"github.com/romanqed/gqs/internal"
 	"github.com/romanqed/gqs/job"
-	"log/slog"
 	"time"
 )
 
@@ -12,7 +11,7 @@ import (
 // for a CleanWorker.
 //
 // Status specifies which job state should be targeted for deletion.
-// Only terminal states (such as job.Done or job.Dead) are valid.
+// Only terminal states (see job.Status.Terminal) are valid.
 //
 // Interval defines how often the cleaner runs.
 //
@@ -20,11 +19,38 @@ import (
 // timestamp is older than now - Delta.
 //
 // Delta defines the age threshold applied when Before is enabled.
+//
+// Retention, if set, replaces Status, Before and Delta: on every cycle
+// the worker loads the current policies from it and cleans each status
+// according to its policy. Policies may thus be changed at runtime,
+// without restarting the worker.
+//
+// If the Cleaner implements RetentionCleaner, every cycle additionally
+// removes jobs whose own retention elapsed (see RetentionConfig), of
+// every terminal status, honoring Archive and BatchSize. Such jobs are
+// not affected by the global policy.
+//
+// Archive makes the worker move matching jobs into the archive instead
+// of deleting them. The Cleaner must implement Archiver; otherwise every
+// cycle fails with ErrArchiveUnsupported and no job is deleted.
+//
+// BatchSize, if positive, makes the worker delete jobs in chunks of at
+// most BatchSize jobs, until a chunk deletes fewer, instead of with a
+// single Clean call. The Cleaner must implement BatchCleaner or
+// RetentionCleaner; otherwise BatchSize is ignored. BatchSize has no effect on archiving.
+//
+// Events, if set, receives an OnCleanup event after every successful
+// Clean or Archive call (see EventListener). With BatchSize, a single
+// event reports the jobs deleted by all chunks of a status.
 type CleanConfig struct {
-	Status   job.Status
-	Interval time.Duration
-	Before   bool
-	Delta    time.Duration
+	Status    job.Status
+	Interval  time.Duration
+	Before    bool
+	Delta     time.Duration
+	Retention RetentionStore
+	Archive   bool
+	BatchSize int
+	Events    EventListener
 }
 
 // CleanWorker periodically invokes a Cleaner implementation
@@ -46,11 +72,17 @@ type CleanWorker struct {
 	lcBase
 	cleaner  Cleaner
 	task     internal.TimerTask
-	log      *slog.Logger
+	log      Logger
 	status   job.Status
 	interval time.Duration
 	before   bool
 	delta    time.Duration
+	store    RetentionStore
+	archive  bool
+	batch    int
+	purge    func(ctx context.Context, status job.Status, before *time.Time) (int64, error)
+	retained func(ctx context.Context, status job.Status) (int64, error)
+	events   EventListener
 }
 
 // NewCleanWorker creates a new CleanWorker using the provided
@@ -58,14 +90,84 @@ type CleanWorker struct {
 //
 // The worker is not started automatically. Call Start to begin
 // periodic cleaning.
-func NewCleanWorker(cleaner Cleaner, config *CleanConfig, log *slog.Logger) *CleanWorker {
+func NewCleanWorker(cleaner Cleaner, config *CleanConfig, log Logger) *CleanWorker {
 	return &CleanWorker{
 		cleaner:  cleaner,
-		log:      log,
+		log:      loggerOf(log),
 		status:   config.Status,
 		interval: config.Interval,
 		before:   config.Before,
 		delta:    config.Delta,
+		store:    config.Retention,
+		archive:  config.Archive,
+		batch:    config.BatchSize,
+		purge:    purgeOf(cleaner, config.Archive, config.BatchSize),
+		retained: retainedOf(cleaner, config.Archive, config.BatchSize),
+		events:   listenerOf(config.Events),
+	}
+}
+
+func purgeOf(cleaner Cleaner, archive bool, batch int) func(context.Context, job.Status, *time.Time) (int64, error) {
+	if retention, ok := feature[RetentionCleaner](cleaner, CapRetentionClean); ok {
+		if archive {
+			return retention.ArchiveUnretained
+		}
+		return func(ctx context.Context, status job.Status, before *time.Time) (int64, error) {
+			return cleanBatches(ctx, batch, func(ctx context.Context, limit int) (int64, error) {
+				return retention.CleanUnretained(ctx, status, before, limit)
+			})
+		}
+	}
+	if !archive {
+		if batcher, ok := feature[BatchCleaner](cleaner, CapBatchClean); ok && batch > 0 {
+			return func(ctx context.Context, status job.Status, before *time.Time) (int64, error) {
+				return cleanBatches(ctx, batch, func(ctx context.Context, limit int) (int64, error) {
+					return batcher.CleanBatch(ctx, status, before, limit)
+				})
+			}
+		}
+		return cleaner.Clean
+	}
+	if archiver, ok := feature[Archiver](cleaner, CapArchive); ok {
+		return archiver.Archive
+	}
+	return func(context.Context, job.Status, *time.Time) (int64, error) {
+		return 0, ErrArchiveUnsupported
+	}
+}
+
+func retainedOf(cleaner Cleaner, archive bool, batch int) func(context.Context, job.Status) (int64, error) {
+	retention, ok := feature[RetentionCleaner](cleaner, CapRetentionClean)
+	if !ok {
+		return nil
+	}
+	if archive {
+		return retention.ArchiveRetained
+	}
+	return func(ctx context.Context, status job.Status) (int64, error) {
+		return cleanBatches(ctx, batch, func(ctx context.Context, limit int) (int64, error) {
+			return retention.CleanRetained(ctx, status, limit)
+		})
+	}
+}
+
+// cleanBatches calls clean until a chunk deletes fewer than limit jobs,
+// and returns the total number of deleted jobs. If limit is not
+// positive, clean is called once to delete all jobs.
+func cleanBatches(ctx context.Context, limit int, clean func(ctx context.Context, limit int) (int64, error)) (int64, error) {
+	if limit <= 0 {
+		return clean(ctx, 0)
+	}
+	var ret int64
+	for {
+		count, err := clean(ctx, limit)
+		ret += count
+		if err != nil || count < int64(limit) {
+			return ret, err
+		}
+		if err := ctx.Err(); err != nil {
+			return ret, err
+		}
 	}
 }
 
@@ -80,13 +182,56 @@ func (cw *CleanWorker) beforeStamp() *time.Time {
 	return &ret
 }
 
+func (cw *CleanWorker) cleanRetention(ctx context.Context) {
+	policies, err := cw.store.Retentions(ctx)
+	if err != nil {
+		cw.log.Error("cannot load retention policies", "error", err)
+		return
+	}
+	for _, policy := range policies {
+		before := time.Now().Add(-policy.MaxAge)
+		count, err := cw.purge(ctx, policy.Status, &before)
+		if err != nil {
+			cw.log.Error("error while cleaning", "status", policy.Status, "error", err)
+			continue
+		}
+		cw.log.Info("cleaned jobs", "status", policy.Status, "count", count, "archive", cw.archive)
+		cw.events.OnCleanup(policy.Status, count)
+	}
+}
+
+// cleanRetained removes jobs whose own retention elapsed.
+func (cw *CleanWorker) cleanRetained(ctx context.Context) {
+	for _, status := range job.TerminalStatuses {
+		count, err := cw.retained(ctx, status)
+		if err != nil {
+			cw.log.Error("error while cleaning retained jobs", "status", status, "error", err)
+			continue
+		}
+		if count == 0 {
+			continue
+		}
+		cw.log.Info("cleaned retained jobs", "status", status, "count", count, "archive", cw.archive)
+		cw.events.OnCleanup(status, count)
+	}
+}
+
 func (cw *CleanWorker) clean(ctx context.Context) {
+	if cw.retained != nil {
+		cw.cleanRetained(ctx)
+	}
+	if cw.store != nil {
+		cw.cleanRetention(ctx)
+		return
+	}
 	before := cw.beforeStamp()
-	count, err := cw.cleaner.Clean(ctx, cw.status, before)
+	count, err := cw.purge(ctx, cw.status, before)
 	if err != nil {
 		cw.log.Error("error while cleaning", "error", err)
+		return
 	}
-	cw.log.Info("cleaned jobs", "count", count)
+	cw.log.Info("cleaned jobs", "count", count, "archive", cw.archive)
+	cw.events.OnCleanup(cw.status, count)
 }
 
 // Start begins periodic execution of the cleaning task.
@@ -2,6 +2,8 @@ package gqs_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"sync/atomic"
 	"testing"
@@ -9,6 +11,8 @@ import (
 
 	"github.com/romanqed/gqs"
 	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
 )
 
 type mockCleaner struct {
@@ -79,3 +83,192 @@ func TestCleanWorkerLifecycleErrors(t *testing.T) {
 		t.Fatal("expected ErrDoubleStopped")
 	}
 }
+
+func TestCleanWorkerRetention(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	admin := gsql.NewAdmin(db)
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+	jobs, _ := puller.Pull(ctx, 1, time.Minute)
+	_ = puller.Complete(ctx, jobs[0])
+
+	if err := admin.SetRetention(ctx, &gqs.RetentionPolicy{Status: job.Pending}); !errors.Is(err, gqs.ErrBadStatus) {
+		t.Fatalf("expected ErrBadStatus, got %v", err)
+	}
+	if err := admin.SetRetention(ctx, &gqs.RetentionPolicy{Status: job.Done, MaxAge: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &gqs.CleanConfig{
+		Interval:  20 * time.Millisecond,
+		Retention: admin,
+	}
+	w := gqs.NewCleanWorker(gsql.NewCleaner(db), cfg, slog.Default())
+	_ = w.Start(ctx)
+	defer w.Stop(time.Second)
+
+	time.Sleep(60 * time.Millisecond)
+	if j, _ := observer.Get(ctx, msg.Id); j == nil {
+		t.Fatal("expected job younger than retention to be kept")
+	}
+
+	// shorten retention at runtime
+	if err := admin.SetRetention(ctx, &gqs.RetentionPolicy{Status: job.Done}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if j, _ := observer.Get(ctx, msg.Id); j != nil {
+		t.Fatal("expected job to be cleaned after retention change")
+	}
+}
+
+type mockArchiver struct {
+	mockCleaner
+	archived atomic.Int64
+}
+
+func (m *mockArchiver) Archive(ctx context.Context, status job.Status, before *time.Time) (int64, error) {
+	m.archived.Add(1)
+	return 1, nil
+}
+
+func TestCleanWorkerArchive(t *testing.T) {
+	archiver := &mockArchiver{}
+	cfg := &gqs.CleanConfig{
+		Status:   job.Done,
+		Interval: 20 * time.Millisecond,
+		Archive:  true,
+	}
+
+	w := gqs.NewCleanWorker(archiver, cfg, slog.Default())
+	_ = w.Start(context.Background())
+	time.Sleep(60 * time.Millisecond)
+	_ = w.Stop(time.Second)
+
+	if archiver.archived.Load() == 0 || archiver.count.Load() != 0 {
+		t.Fatalf("expected jobs to be archived only, got %d archive and %d clean calls",
+			archiver.archived.Load(), archiver.count.Load())
+	}
+	if !w.Describe().Archive {
+		t.Fatal("expected the description to report archiving")
+	}
+
+	// a cleaner without archive support must not delete jobs
+	cleaner := &mockCleaner{}
+	w = gqs.NewCleanWorker(cleaner, cfg, slog.Default())
+	_ = w.Start(context.Background())
+	time.Sleep(60 * time.Millisecond)
+	_ = w.Stop(time.Second)
+
+	if cleaner.count.Load() != 0 {
+		t.Fatal("expected no clean calls without archive support")
+	}
+}
+
+type mockBatchCleaner struct {
+	mockCleaner
+	left    atomic.Int64
+	batches atomic.Int64
+}
+
+func (m *mockBatchCleaner) CleanBatch(ctx context.Context, status job.Status, before *time.Time, limit int) (int64, error) {
+	m.batches.Add(1)
+	count := min(m.left.Load(), int64(limit))
+	m.left.Add(-count)
+	return count, nil
+}
+
+func TestCleanWorkerBatch(t *testing.T) {
+	cleaner := &mockBatchCleaner{}
+	cleaner.left.Store(5)
+	cfg := &gqs.CleanConfig{
+		Status:    job.Done,
+		Interval:  time.Hour,
+		BatchSize: 2,
+	}
+
+	w := gqs.NewCleanWorker(cleaner, cfg, slog.Default())
+	_ = w.Start(context.Background())
+	time.Sleep(50 * time.Millisecond)
+	_ = w.Stop(time.Second)
+
+	if cleaner.left.Load() != 0 {
+		t.Fatalf("expected every job to be cleaned in one cycle, %d left", cleaner.left.Load())
+	}
+	if cleaner.batches.Load() != 3 || cleaner.count.Load() != 0 {
+		t.Fatalf("expected 3 batches and no clean calls, got %d and %d",
+			cleaner.batches.Load(), cleaner.count.Load())
+	}
+}
+
+type retentionCleaner struct {
+	mockCleaner
+	statuses   chan job.Status
+	unretained atomic.Int64
+}
+
+func (rc *retentionCleaner) CleanRetained(ctx context.Context, status job.Status, limit int) (int64, error) {
+	if limit != 2 {
+		return 0, fmt.Errorf("expected batches of 2, got %d", limit)
+	}
+	rc.statuses <- status
+	return 0, nil
+}
+
+func (rc *retentionCleaner) ArchiveRetained(ctx context.Context, status job.Status) (int64, error) {
+	return 0, gqs.ErrArchiveUnsupported
+}
+
+func (rc *retentionCleaner) CleanUnretained(ctx context.Context, status job.Status, before *time.Time, limit int) (int64, error) {
+	rc.unretained.Add(1)
+	return 0, nil
+}
+
+func (rc *retentionCleaner) ArchiveUnretained(ctx context.Context, status job.Status, before *time.Time) (int64, error) {
+	return 0, gqs.ErrArchiveUnsupported
+}
+
+func TestCleanWorkerRetained(t *testing.T) {
+	cleaner := &retentionCleaner{statuses: make(chan job.Status, 10)}
+
+	cfg := &gqs.CleanConfig{
+		Status:    job.Done,
+		Interval:  time.Hour,
+		BatchSize: 2,
+	}
+
+	w := gqs.NewCleanWorker(cleaner, cfg, slog.Default())
+
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop(time.Second)
+
+	// the first cycle runs at once
+	for _, expected := range job.TerminalStatuses {
+		select {
+		case status := <-cleaner.statuses:
+			if status != expected {
+				t.Fatalf("expected retained jobs of %v to be cleaned, got %v", expected, status)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected retained jobs to be cleaned")
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for cleaner.unretained.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the global policy to skip retained jobs")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if cleaner.count.Load() != 0 {
+		t.Fatal("expected Clean not to be called")
+	}
+}
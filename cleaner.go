@@ -11,9 +11,13 @@ var (
 	// ErrBadStatus indicates that an invalid job status was supplied to Cleaner.
 	//
 	// Cleaner implementations are expected to restrict deletion to terminal
-	// states (for example, Done or Dead). Supplying a non-terminal status
+	// states (Done, Dead or Cancelled). Supplying a non-terminal status
 	// such as Pending or Processing should result in ErrBadStatus.
 	ErrBadStatus = errors.New("bad job status")
+
+	// ErrArchiveUnsupported is logged by CleanWorker configured with
+	// CleanConfig.Archive if its Cleaner does not implement Archiver.
+	ErrArchiveUnsupported = errors.New("cleaner does not support archiving")
 )
 
 // Cleaner provides a mechanism for permanently removing jobs from storage.
@@ -27,7 +31,7 @@ var (
 //   - removing completed jobs older than a certain time
 //   - purging dead jobs after inspection
 //
-// Clean must only delete jobs in terminal states (such as Done or Dead).
+// Clean must only delete jobs in terminal states (see job.Status.Terminal).
 // Implementations must reject attempts to delete Pending or Processing jobs.
 type Cleaner interface {
 
@@ -35,7 +39,7 @@ type Cleaner interface {
 	//
 	// The status parameter specifies which job state to target.
 	// If status is job.Unknown (zero value), implementations may interpret
-	// this as a request to delete all terminal jobs (for example, Done and Dead).
+	// this as a request to delete all terminal jobs.
 	//
 	// The before parameter restricts deletion to jobs whose UpdatedAt
 	// timestamp is less than or equal to the provided time.
@@ -50,3 +54,36 @@ type Cleaner interface {
 	// with visibility timeouts.
 	Clean(ctx context.Context, status job.Status, before *time.Time) (int64, error)
 }
+
+// Archiver is an optional extension of Cleaner moving terminal jobs into
+// an archive instead of deleting them.
+//
+// Archiving keeps the live job storage small, which matters for Pull
+// performance, while retaining records of completed jobs, for example
+// for compliance. Archived jobs are no longer visible to the Observer
+// of live jobs.
+type Archiver interface {
+
+	// Archive moves jobs matching the given status and time condition
+	// into the archive and returns the number of archived jobs.
+	//
+	// Status and before are interpreted as by Cleaner.Clean, including
+	// ErrBadStatus for non-terminal states.
+	Archive(ctx context.Context, status job.Status, before *time.Time) (int64, error)
+}
+
+// BatchCleaner is an optional extension of Cleaner deleting jobs in
+// chunks of bounded size.
+//
+// Deleting millions of rows with a single statement may lock the
+// storage for minutes. CleanWorker configured with CleanConfig.BatchSize
+// instead calls CleanBatch repeatedly, so that every statement holds
+// its locks only briefly.
+type BatchCleaner interface {
+
+	// CleanBatch deletes at most limit jobs matching the given status
+	// and time condition, interpreted as by Cleaner.Clean, and returns
+	// the number of deleted jobs. Fewer than limit deleted jobs mean
+	// that no matching job is left.
+	CleanBatch(ctx context.Context, status job.Status, before *time.Time, limit int) (int64, error)
+}
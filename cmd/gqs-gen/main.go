@@ -0,0 +1,107 @@
+// Command gqs-gen generates scaffolding for gqs storage backends.
+//
+// Usage:
+//
+//	go run github.com/romanqed/gqs/cmd/gqs-gen backend <name> [-out dir]
+//
+// The backend command creates a package named <name> (in ./<name> unless
+// -out is given) containing stub implementations of gqs.Pusher,
+// gqs.Puller, gqs.Observer and gqs.Cleaner, and a test file asserting
+// at compile time that the stubs satisfy these interfaces. Every stub
+// method returns ErrNotImplemented.
+//
+// Optional extensions (gqs.BatchPusher, gqs.Releaser and others) are
+// not generated; implement them as the backend matures.
+package main
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+var errUsage = errors.New("usage: gqs-gen backend <name> [-out dir]")
+
+type backend struct {
+	Package string
+}
+
+func render(name string) (map[string][]byte, error) {
+	tmpl, err := template.ParseFS(templates, "templates/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	data := &backend{Package: name}
+	ret := make(map[string][]byte)
+	for _, t := range tmpl.Templates() {
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		src, err := format.Source(buf.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", t.Name(), err)
+		}
+		ret[strings.TrimSuffix(t.Name(), ".tmpl")] = src
+	}
+	return ret, nil
+}
+
+func generate(name, out string) error {
+	files, err := render(name)
+	if err != nil {
+		return err
+	}
+	// refuse to touch an existing backend before writing anything
+	for file := range files {
+		path := filepath.Join(out, file)
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists", path)
+		}
+	}
+	if err := os.MkdirAll(out, 0o755); err != nil {
+		return err
+	}
+	for file, src := range files {
+		path := filepath.Join(out, file)
+		if err := os.WriteFile(path, src, 0o644); err != nil {
+			return err
+		}
+		fmt.Println("created", path)
+	}
+	return nil
+}
+
+func run(args []string) error {
+	if len(args) < 2 || args[0] != "backend" {
+		return errUsage
+	}
+	name := args[1]
+	if !token.IsIdentifier(name) || strings.ToLower(name) != name {
+		return fmt.Errorf("bad package name %q", name)
+	}
+	flags := flag.NewFlagSet("backend", flag.ContinueOnError)
+	out := flags.String("out", name, "output directory")
+	if err := flags.Parse(args[2:]); err != nil {
+		return err
+	}
+	return generate(name, *out)
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateBackend(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "mybackend")
+	if err := run([]string{"backend", "mybackend", "-out", out}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"doc.go", "pusher.go", "puller.go", "observer.go", "cleaner.go", "backend_test.go"} {
+		if _, err := os.Stat(filepath.Join(out, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := run([]string{"backend", "mybackend", "-out", out}); err == nil {
+		t.Fatal("expected existing files not to be overwritten")
+	}
+	if err := run([]string{"backend", "My-Backend"}); err == nil {
+		t.Fatal("expected bad package name to be rejected")
+	}
+}
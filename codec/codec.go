@@ -0,0 +1,114 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"google.golang.org/protobuf/proto"
+	"reflect"
+)
+
+var (
+	// ErrNotProto indicates that a value passed to the Protobuf codec
+	// is not a protocol buffer message.
+	ErrNotProto = errors.New("value is not a proto message")
+)
+
+// Codec converts values to and from message payloads.
+//
+// Implementations must be safe for concurrent use.
+type Codec interface {
+
+	// Name returns a short identifier of the encoding, such as "json".
+	Name() string
+
+	// Marshal encodes v into a payload.
+	Marshal(v any) ([]byte, error)
+
+	// Unmarshal decodes data into the value pointed to by v.
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	// JSON encodes payloads with encoding/json.
+	JSON Codec = jsonCodec{}
+
+	// Gob encodes payloads with encoding/gob.
+	Gob Codec = gobCodec{}
+
+	// Protobuf encodes payloads with the protocol buffers binary format.
+	//
+	// Marshal accepts proto.Message values. Unmarshal accepts either
+	// a proto.Message or a pointer to a proto.Message pointer; in the
+	// latter case a new message is allocated.
+	Protobuf Codec = protoCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+type gobCodec struct{}
+
+func (gobCodec) Name() string {
+	return "gob"
+}
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+type protoCodec struct{}
+
+func (protoCodec) Name() string {
+	return "protobuf"
+}
+
+func (protoCodec) Marshal(v any) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, ErrNotProto
+	}
+	return proto.Marshal(msg)
+}
+
+func (protoCodec) Unmarshal(data []byte, v any) error {
+	if msg, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, msg)
+	}
+	// a pointer to a nil message pointer, as produced by decoding
+	// into a zero value of a generic message type
+	ptr := reflect.ValueOf(v)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() || ptr.Elem().Kind() != reflect.Pointer {
+		return ErrNotProto
+	}
+	elem := reflect.New(ptr.Elem().Type().Elem())
+	msg, ok := elem.Interface().(proto.Message)
+	if !ok {
+		return ErrNotProto
+	}
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return err
+	}
+	ptr.Elem().Set(elem)
+	return nil
+}
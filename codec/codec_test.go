@@ -0,0 +1,49 @@
+package codec_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/romanqed/gqs/codec"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type payload struct {
+	Name  string
+	Count int
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	for _, c := range []codec.Codec{codec.JSON, codec.Gob} {
+		data, err := c.Marshal(payload{Name: "a", Count: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded payload
+		if err := c.Unmarshal(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Name != "a" || decoded.Count != 2 {
+			t.Fatalf("%s: unexpected value %+v", c.Name(), decoded)
+		}
+	}
+}
+
+func TestProtobufCodec(t *testing.T) {
+	data, err := codec.Protobuf.Marshal(wrapperspb.String("value"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var msg *wrapperspb.StringValue
+	if err := codec.Protobuf.Unmarshal(data, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.GetValue() != "value" {
+		t.Fatalf("unexpected value %q", msg.GetValue())
+	}
+
+	if _, err := codec.Protobuf.Marshal(payload{}); !errors.Is(err, codec.ErrNotProto) {
+		t.Fatalf("expected ErrNotProto, got %v", err)
+	}
+}
@@ -0,0 +1,9 @@
+// Package codec provides payload encodings for gqs messages.
+//
+// A Codec converts application values to and from message.Message
+// payloads. JSON, Gob and Protobuf implementations are provided;
+// custom encodings may implement Codec directly.
+//
+// Codecs are typically used through gqs.PushTyped and gqs.TypedHandler,
+// which remove marshaling boilerplate from producers and handlers.
+package codec
@@ -0,0 +1,71 @@
+package gqs
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+// DefaultDeadPreview is the number of payload bytes returned in
+// DeadLetter.Preview if DeadListOptions.PreviewSize is not set.
+const DefaultDeadPreview = 256
+
+// DeadLetter describes a Dead job for investigation.
+//
+// Preview holds the first bytes of the payload and PayloadSize the
+// size of the whole payload, so that listings stay small for jobs
+// with large payloads.
+type DeadLetter struct {
+	Id          uuid.UUID `json:"id"`
+	Queue       string    `json:"queue"`
+	TenantId    string    `json:"tenant_id"`
+	Type        string    `json:"type"`
+	Attempts    uint32    `json:"attempts"`
+	MaxRetries  uint32    `json:"max_retries"`
+	LastError   string    `json:"last_error"`
+	Preview     []byte    `json:"preview"`
+	PayloadSize int64     `json:"payload_size"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// DeadListOptions defines filtering and pagination for
+// DeadLetterObserver.ListDead.
+//
+// Queues restricts results to jobs of any of the listed queues. An
+// empty slice applies no restriction.
+//
+// Limit defines the maximum page size; zero or negative means no limit.
+//
+// Cursor continues listing after the last job of a previous page
+// (see DeadPage.Next).
+//
+// PreviewSize is the maximum length of DeadLetter.Preview. If zero,
+// DefaultDeadPreview is used; if negative, no preview is returned.
+type DeadListOptions struct {
+	Queues      []string
+	Limit       int
+	Cursor      string
+	PreviewSize int
+}
+
+// DeadPage is a single page of dead letters returned by
+// DeadLetterObserver.ListDead.
+//
+// Next is an opaque cursor pointing after the last returned letter.
+// It is empty if the page is the last one.
+type DeadPage struct {
+	Letters []*DeadLetter
+	Next    string
+}
+
+// DeadLetterObserver is an optional extension of Observer listing Dead
+// jobs, the primary query when investigating failures.
+type DeadLetterObserver interface {
+
+	// ListDead returns a page of Dead jobs matching opts, most recently
+	// killed first, that is ordered by UpdatedAt descending with ties
+	// broken by job id. A malformed cursor is reported with
+	// ErrBadCursor.
+	ListDead(ctx context.Context, opts *DeadListOptions) (*DeadPage, error)
+}
@@ -0,0 +1,167 @@
+package gqs
+
+import "time"
+
+// Service kinds reported in Description.Kind.
+const (
+	KindWorker      = "worker"
+	KindClean       = "clean"
+	KindAlert       = "alert"
+	KindReap        = "reap"
+	KindMaintenance = "maintenance"
+)
+
+// Description is a machine-readable description of the configuration
+// of a Service, suitable for documentation generation and detection
+// of configuration drift between deployments.
+//
+// Kind identifies the type of the service; fields not applicable to
+// the kind are left zero and omitted from JSON. Durations are encoded
+// in nanoseconds.
+//
+// For a Worker, Queues lists the queues its Puller is restricted to
+// (see QueueLister), or is empty if the Puller consumes all queues or
+// does not report them. Capabilities lists the optional features of
+// the Puller used by the worker.
+//
+// Interval is the pull interval of a Worker and the run interval of
+// lifecycle workers.
+//
+// For a CleanWorker, Retention describes the static retention policy.
+// If the policies are loaded from a RetentionStore, Retention is empty
+// and DynamicRetention is set, as the policies may change at runtime.
+// Archive reports whether jobs are archived rather than deleted, and
+// BatchSize the size of deletion chunks, if jobs are deleted in chunks.
+type Description struct {
+	Kind         string     `json:"kind"`
+	Instance     string     `json:"instance,omitempty"`
+	Queues       []string   `json:"queues,omitempty"`
+	Capabilities Capability `json:"capabilities,omitempty"`
+
+	Concurrency         int            `json:"concurrency,omitempty"`
+	ReservedConcurrency int            `json:"reserved_concurrency,omitempty"`
+	ReservedPriority    int            `json:"reserved_priority,omitempty"`
+	BatchSize           int            `json:"batch_size,omitempty"`
+	Interval            time.Duration  `json:"interval,omitempty"`
+	LockTimeout         time.Duration  `json:"lock_timeout,omitempty"`
+	HandlerTimeout      time.Duration  `json:"handler_timeout,omitempty"`
+	Backoff             *BackoffConfig `json:"backoff,omitempty"`
+
+	Retention        []RetentionPolicy `json:"retention,omitempty"`
+	DynamicRetention bool              `json:"dynamic_retention,omitempty"`
+	Archive          bool              `json:"archive,omitempty"`
+	DeadAfter        time.Duration     `json:"dead_after,omitempty"`
+}
+
+// Describer is an optional extension of Service reporting its
+// configuration.
+//
+// Worker, CleanWorker, AlertWorker, ReapWorker and MaintenanceWorker
+// implement Describer.
+type Describer interface {
+
+	// Describe returns the description of the service configuration.
+	Describe() Description
+}
+
+// QueueLister is an optional interface of Pullers restricted to
+// a fixed set of queues, reported in Worker descriptions.
+type QueueLister interface {
+
+	// Queues returns the queues the Puller consumes, or nil if it
+	// consumes all queues.
+	Queues() []string
+}
+
+// Describe returns the descriptions of services implementing Describer,
+// in the given order, describing the topology of a deployment run with
+// Run. Services not implementing Describer are skipped.
+func Describe(services ...Service) []Description {
+	var ret []Description
+	for _, service := range services {
+		if describer, ok := service.(Describer); ok {
+			ret = append(ret, describer.Describe())
+		}
+	}
+	return ret
+}
+
+func capabilitiesOf(impl any) Capability {
+	var ret Capability
+	for c := range capabilityChecks {
+		if Supports(impl, c) {
+			ret |= c
+		}
+	}
+	return ret
+}
+
+// Describe implements Describer.
+func (w *Worker) Describe() Description {
+	var queues []string
+	if lister, ok := w.puller.(QueueLister); ok {
+		queues = lister.Queues()
+	}
+	backoff := w.retry.backoff
+	return Description{
+		Kind:                KindWorker,
+		Instance:            w.instance.Id,
+		Queues:              queues,
+		Capabilities:        capabilitiesOf(w.puller),
+		Concurrency:         w.concurrency,
+		ReservedConcurrency: w.reservedN,
+		ReservedPriority:    w.highPrio,
+		BatchSize:           w.batchSize,
+		Interval:            w.interval,
+		LockTimeout:         w.lock,
+		HandlerTimeout:      w.timeout,
+		Backoff:             &backoff,
+	}
+}
+
+// Describe implements Describer.
+func (cw *CleanWorker) Describe() Description {
+	ret := Description{
+		Kind:     KindClean,
+		Interval: cw.interval,
+		Archive:  cw.archive,
+	}
+	if !cw.archive && Supports(cw.cleaner, CapBatchClean) {
+		ret.BatchSize = cw.batch
+	}
+	if cw.store != nil {
+		ret.DynamicRetention = true
+		return ret
+	}
+	policy := RetentionPolicy{Status: cw.status}
+	if cw.before {
+		policy.MaxAge = cw.delta
+	}
+	ret.Retention = []RetentionPolicy{policy}
+	return ret
+}
+
+// Describe implements Describer.
+func (aw *AlertWorker) Describe() Description {
+	return Description{
+		Kind:     KindAlert,
+		Interval: aw.interval,
+	}
+}
+
+// Describe implements Describer.
+func (rw *ReapWorker) Describe() Description {
+	return Description{
+		Kind:      KindReap,
+		Interval:  rw.interval,
+		DeadAfter: rw.deadAfter,
+	}
+}
+
+// Describe implements Describer.
+func (mw *MaintenanceWorker) Describe() Description {
+	return Description{
+		Kind:     KindMaintenance,
+		Interval: mw.interval,
+	}
+}
@@ -0,0 +1,66 @@
+package gqs_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestDescribe(t *testing.T) {
+	db := newTestDB(t)
+
+	puller := gsql.NewPullerWithOptions(db, &gsql.PullerOptions{Queues: []string{"mail"}})
+	handler := func(ctx context.Context, msg *message.Message) error {
+		return nil
+	}
+	worker := gqs.NewWorker(puller, handler, &gqs.WorkerConfig{
+		Concurrency:  4,
+		Queue:        10,
+		BatchSize:    2,
+		PullInterval: time.Second,
+		LockTimeout:  time.Minute,
+		Backoff:      gqs.BackoffConfig{MaxRetries: 5},
+		Instance:     "worker-1",
+	}, slog.Default())
+	cleaner := gqs.NewCleanWorker(gsql.NewCleaner(db), &gqs.CleanConfig{
+		Status:   job.Done,
+		Interval: time.Hour,
+		Before:   true,
+		Delta:    24 * time.Hour,
+	}, slog.Default())
+
+	descs := gqs.Describe(worker, cleaner)
+	if len(descs) != 2 {
+		t.Fatalf("expected 2 descriptions, got %d", len(descs))
+	}
+
+	w := descs[0]
+	if w.Kind != gqs.KindWorker || w.Instance != "worker-1" || w.Concurrency != 4 || w.BatchSize != 2 {
+		t.Fatalf("unexpected worker description %+v", w)
+	}
+	if len(w.Queues) != 1 || w.Queues[0] != "mail" {
+		t.Fatalf("expected queue mail, got %v", w.Queues)
+	}
+	if !w.Capabilities.Has(gqs.CapRelease) || w.Capabilities.Has(gqs.CapBatchPush) {
+		t.Fatalf("unexpected capabilities %b", w.Capabilities)
+	}
+	if w.Backoff == nil || w.Backoff.MaxRetries != 5 {
+		t.Fatal("expected backoff to be described")
+	}
+
+	c := descs[1]
+	if c.Kind != gqs.KindClean || len(c.Retention) != 1 || c.Retention[0].MaxAge != 24*time.Hour {
+		t.Fatalf("unexpected clean description %+v", c)
+	}
+
+	if _, err := json.Marshal(descs); err != nil {
+		t.Fatal(err)
+	}
+}
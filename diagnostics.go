@@ -0,0 +1,77 @@
+package gqs
+
+import (
+	"context"
+	"github.com/romanqed/gqs/job"
+	"maps"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"time"
+)
+
+// MaxDiagnosticsStackSize limits the size of goroutine stack traces
+// captured into job.Diagnostics.
+const MaxDiagnosticsStackSize = 64 * 1024
+
+// DiagnosticsConfig defines sampling of extended failure diagnostics.
+//
+// Rate is the fraction of failed attempts, between 0 and 1, for which
+// diagnostics are captured. Capturing includes a dump of all goroutine
+// stacks, which is expensive, so Rate should stay low in production.
+//
+// Capture, if set, is called for every sampled failure and returns
+// additional attributes stored in job.Diagnostics.Attrs. It must not
+// block for long, as the job lease is not extended meanwhile.
+type DiagnosticsConfig struct {
+	Rate    float64
+	Capture func(ctx context.Context, job *job.Job, err error) map[string]any
+}
+
+// DiagnosticsSaver is an optional extension of Puller that persists
+// failure diagnostics of a job.
+//
+// Worker uses it for sampled failures when WorkerConfig.Diagnostics
+// is set.
+type DiagnosticsSaver interface {
+
+	// SaveDiagnostics replaces the stored diagnostics of job with
+	// job.Diagnostics.
+	//
+	// SaveDiagnostics must only succeed if the job is currently
+	// Processing.
+	SaveDiagnostics(ctx context.Context, job *job.Job) error
+}
+
+func captureStack() string {
+	buf := make([]byte, MaxDiagnosticsStackSize)
+	return string(buf[:runtime.Stack(buf, true)])
+}
+
+func (w *Worker) diagnose(ctx context.Context, jb *job.Job, err error, wait, took time.Duration) {
+	if w.diag == nil || rand.Float64() >= w.diag.Rate {
+		return
+	}
+	saver, ok := feature[DiagnosticsSaver](w.puller, CapDiagnostics)
+	if !ok {
+		w.log.Warn("job diagnostics discarded, puller does not support diagnostics", "id", jb.Id)
+		return
+	}
+	diag := &job.Diagnostics{
+		Time:      time.Now(),
+		Attempt:   jb.Attempts,
+		Error:     err.Error(),
+		Payload:   slices.Clone(jb.Payload),
+		Metadata:  maps.Clone(jb.Metadata),
+		QueueWait: wait,
+		Duration:  took,
+		Stack:     captureStack(),
+	}
+	if w.diag.Capture != nil {
+		diag.Attrs = w.diag.Capture(ctx, jb, err)
+	}
+	jb.Diagnostics = diag
+	if err := saver.SaveDiagnostics(ctx, jb); err != nil {
+		w.log.Error("cannot save job diagnostics", "id", jb.Id, "err", err)
+	}
+}
@@ -0,0 +1,124 @@
+package gqs
+
+import (
+	"github.com/romanqed/gqs/job"
+	"math"
+	"sync"
+	"time"
+)
+
+// DispatchMode controls how a Worker feeds pulled batches to its
+// handlers.
+type DispatchMode uint8
+
+const (
+	// DispatchSequential pulls full batches and hands their jobs to
+	// the handler pool in pull order, blocking while the pool is
+	// saturated. Jobs of a batch larger than Concurrency plus Queue
+	// wait for earlier jobs while their leases tick.
+	DispatchSequential DispatchMode = iota
+
+	// DispatchFair pulls no more jobs than the pool can start or
+	// buffer at the moment, skipping a pull entirely while it is
+	// saturated, and interleaves the jobs of a batch round-robin by
+	// queue. Pulled jobs thus never wait behind a long batch, keeping
+	// the latency of small jobs low at the cost of more frequent pulls.
+	DispatchFair
+)
+
+// AdaptiveBatchConfig enables batch sizing of a Worker based on the
+// observed handler duration.
+//
+// The worker keeps a moving average of handler durations and pulls
+// about as many jobs as its Concurrency handlers can complete within
+// Target, between MinSize and WorkerConfig.BatchSize. Until the first
+// handler completes, full batches are pulled.
+//
+// A zero Target defaults to WorkerConfig.PullInterval; a MinSize below
+// one defaults to one.
+type AdaptiveBatchConfig struct {
+	MinSize int
+	Target  time.Duration
+}
+
+// batchSmoothing is the weight of the latest sample in the moving
+// average of handler durations.
+const batchSmoothing = 0.2
+
+type batchSizer struct {
+	mutex       sync.Mutex
+	avg         float64
+	min         int
+	max         int
+	target      float64
+	concurrency int
+}
+
+func newBatchSizer(config *AdaptiveBatchConfig, concurrency, batch int, interval time.Duration) *batchSizer {
+	if config == nil {
+		return nil
+	}
+	ret := &batchSizer{
+		min:         max(config.MinSize, 1),
+		max:         batch,
+		target:      float64(config.Target),
+		concurrency: max(concurrency, 1),
+	}
+	if config.Target <= 0 {
+		ret.target = float64(interval)
+	}
+	ret.min = min(ret.min, ret.max)
+	return ret
+}
+
+// observe records the duration of a handler invocation.
+func (bs *batchSizer) observe(took time.Duration) {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+	if bs.avg == 0 {
+		bs.avg = float64(took)
+		return
+	}
+	bs.avg += batchSmoothing * (float64(took) - bs.avg)
+}
+
+// size returns the number of jobs to pull next.
+func (bs *batchSizer) size() int {
+	bs.mutex.Lock()
+	avg := bs.avg
+	bs.mutex.Unlock()
+	if avg <= 0 {
+		return bs.max
+	}
+	ret := math.Ceil(float64(bs.concurrency) * bs.target / avg)
+	if ret >= float64(bs.max) {
+		return bs.max
+	}
+	return max(int(ret), bs.min)
+}
+
+// interleave reorders jobs round-robin by queue, keeping the relative
+// order of jobs of the same queue.
+func interleave(jobs []*job.Job) []*job.Job {
+	var queues []string
+	groups := make(map[string][]*job.Job)
+	for _, jb := range jobs {
+		if _, ok := groups[jb.Queue]; !ok {
+			queues = append(queues, jb.Queue)
+		}
+		groups[jb.Queue] = append(groups[jb.Queue], jb)
+	}
+	if len(queues) < 2 {
+		return jobs
+	}
+	ret := make([]*job.Job, 0, len(jobs))
+	for len(ret) < len(jobs) {
+		for _, queue := range queues {
+			if group := groups[queue]; len(group) != 0 {
+				ret = append(ret, group[0])
+				groups[queue] = group[1:]
+			}
+		}
+	}
+	return ret
+}
@@ -37,16 +37,29 @@
 //
 // Jobs follow this lifecycle:
 //
+//	Scheduled  -> Pending   (once NextRunAt passes)
 //	Pending    -> Processing
 //	Processing -> Done
 //	Processing -> Pending   (via Return)
+//	Processing -> Scheduled (via Return with a backoff)
 //	Processing -> Dead
+//	Waiting, Processing -> Cancelled   (via Canceler)
 //
-// Terminal states (Done, Dead) are not retried unless explicitly requeued.
+// Delayed pushes and retries wait in Scheduled, so that the backlog
+// ready to run is distinguishable from deferred jobs; due Scheduled jobs
+// are pulled like Pending ones.
+//
+// Terminal states (Done, Dead, Cancelled) are not retried unless
+// explicitly requeued. Cancelled distinguishes intentionally aborted
+// jobs from jobs that failed.
 //
 // # Retry Policy
 //
-// Retry behavior is controlled by BackoffConfig.
+// Retry behavior is controlled by a RetryPolicy, BackoffConfig by
+// default. Custom policies may inspect the handler error, for example
+// to give up on validation errors or back off longer on rate limiting.
+// Handlers that know when to retry, for example from a Retry-After
+// header, may return RetryAfter or RetryAt to bypass the policy.
 //
 // When a handler returns an error:
 //
@@ -54,6 +67,10 @@
 //     the job is rescheduled with a computed backoff delay.
 //   - Otherwise, the job transitions to Dead.
 //
+// Rescheduled jobs may additionally be demoted in priority
+// (see BackoffConfig.PriorityStep), so that repeatedly failing jobs
+// do not compete with fresh work forever.
+//
 // Attempts are incremented each time a job is successfully pulled.
 //
 // Worker
@@ -74,27 +91,170 @@
 //
 // gqs defines the following primary interfaces:
 //
-//	Pusher   — enqueue messages
-//	Puller   — manage job lifecycle transitions
-//	Observer — inspect job state
-//	Cleaner  — remove terminal jobs
+//	Pusher        — enqueue messages
+//	BatchPusher   — enqueue messages in bulk with per-message results
+//	Puller        — manage job lifecycle transitions
+//	Observer      — inspect job state
+//	QueryObserver — filter, paginate and count jobs
+//	Cleaner       — remove terminal jobs
+//	Archiver      — move terminal jobs into an archive
+//	Alerter       — manage and evaluate alert thresholds
+//	Registry      — track liveness of worker instances
+//	Admin         — bulk kill, requeue, delete and reschedule jobs
+//	QueueController — pause and resume queues
 //
 // These interfaces allow storage implementations to be plugged in
 // without coupling the queue logic to a specific database.
 //
+// Optional features are exposed as separate interfaces (BatchPusher,
+// Releaser, ResultCompleter and others). Implementations may advertise
+// the features they support via Capable; Supports combines both checks,
+// and Worker degrades gracefully when a feature is missing.
+//
+// Observer.Get returns (nil, nil) for missing jobs unless the backend is
+// configured to return ErrNotFound; GetExisting returns ErrNotFound in
+// both cases.
+//
 // # Concurrency Model
 //
 // Worker uses a bounded internal queue and a fixed-size worker pool.
 // Pulling and processing are decoupled to smooth load.
 //
+// With DispatchFair, the worker pulls only as many jobs as its handlers
+// can accept, so large batches do not queue jobs behind each other;
+// AdaptiveBatchConfig additionally sizes batches by handler duration.
+//
 // Shutdown is graceful: in-flight handlers are allowed to finish,
-// subject to a configurable timeout.
+// subject to a configurable timeout, and jobs pulled but not started
+// yet are given back to storage at once.
+//
+// Worker.Pause and Worker.Resume suspend and resume pulling of a
+// running worker, for example during maintenance windows, without the
+// Stop/Start cycle; Worker.PauseDispatch also holds pulled jobs.
+//
+// Worker.Status reports the lifecycle state, in-flight and queued jobs,
+// the outcome of the last pull and processing counters; Worker.Healthy
+// condenses it into a readiness check.
 //
 // # Immediate termination
 //
 // A handler may return ErrKill to permanently mark a job as Dead
 // without applying retry or backoff logic.
 //
+// Operators may abort a job from the outside with Canceler.Cancel: a
+// running handler is canceled at the next lease extension, with
+// ErrCancelRequested as the cause, and the job is marked Cancelled.
+//
+// # Chained Jobs
+//
+// A handler may schedule follow-up messages with PushNext. They are
+// enqueued atomically with the completion of the job by Pullers
+// implementing ChainCompleter, so a crash cannot complete a job while
+// losing its continuation.
+//
+// Pipeline adapts a PipelineHandler returning an Output, so that
+// multi-stage pipelines route follow-ups to the queues of the next
+// stages without pushing them manually.
+//
+// Jobs fanning in instead list the jobs they wait for in
+// message.Message.DependsOn. They are delivered once all of them are
+// Done, and killed with ErrDependencyFailed if any of them dies.
+// Backends implementing GroupPusher push a Group of messages with a
+// callback delivered once every member ended, whatever its outcome;
+// GroupObserver reports the progress of the group.
+//
+// Package workflow builds sagas on top of chained jobs: named steps
+// with their own retry policies, persisted workflow state and
+// compensation of completed steps once a step fails.
+//
+// # Middleware
+//
+// Cross-cutting concerns (logging, metrics, tracing, panic recovery)
+// may be layered around the MessageHandler with Worker.Use.
+// Recover is a built-in Middleware converting panics into ErrPanic.
+// Request-scoped dependencies may be attached to the handler context
+// with WorkerConfig.DecorateContext instead of global variables.
+// Handlers needing delivery metadata, such as the attempt number, may be
+// written as a JobHandler (see HandleJob and NewJobWorker).
+//
+// # Lifecycle Events
+//
+// An EventListener set in WorkerConfig.Events or CleanConfig.Events
+// receives pulls, completions, retries, kills, lease losses and
+// cleanups, for custom metrics, alerting or webhooks.
+//
+// Workers write operational messages to a Logger, which *slog.Logger
+// implements; other logging libraries plug in through a small adapter,
+// and a nil Logger discards messages.
+//
+// # Typed Payloads
+//
+// PushTyped and TypedHandler encode and decode payloads with a
+// codec.Codec (JSON, Gob or Protobuf), so producers and handlers work
+// with typed values instead of raw bytes. Payloads that cannot be
+// decoded fail with ErrDecode, which wraps ErrKill.
+//
+// # Push-time Routing
+//
+// RulesPusher wraps a Pusher and applies Rules before each push,
+// setting the queue, priority, delay or retry limit of messages that
+// match on type, queue or metadata. Rules may be defined in code or
+// decoded from JSON configuration with ParseRules.
+//
+// On the consuming side, MultiPuller lets one Worker consume several
+// Pullers, such as the shards of a ShardedPusher, interleaving pulls
+// by weighted round-robin.
+//
+// # Reports
+//
+// WriteReport streams jobs matching ListOptions to a ReportWriter,
+// such as CSVWriter for spreadsheets or NDJSONWriter for data pipelines.
+// Observers implementing Reporter stream rows directly from the storage
+// cursor; others are read page by page with Query.
+//
+// # Autoscaling
+//
+// Observers implementing MetricsObserver report per-queue backlog.
+// NewScalerHandler exposes it over HTTP as JSON, for external
+// autoscalers such as the KEDA metrics-api scaler.
+//
+// Observers implementing OverviewObserver summarize every queue by
+// status in a single consistent query, for dashboards (see package ui).
+// Observers implementing StatsObserver compute counts, the age of the
+// oldest Pending job, average attempts and recent throughput with
+// aggregate queries, for health checks.
+// Observers implementing DeadLetterObserver list Dead jobs, most
+// recently killed first, with their last error, attempts and a
+// preview of the payload.
+//
+// # Multi-Tenancy
+//
+// Messages may name the tenant owning them (message.Message.TenantId).
+// TenantPusher and TenantObserver confine code acting on behalf of a
+// tenant to its own jobs; PullFilter.Tenants dedicates workers to some
+// tenants. Storage may additionally schedule tenants fairly, so that a
+// noisy tenant cannot starve the others (see sql.PullerOptions).
+//
+// # Replay
+//
+// Replay executes a handler locally on the message of a terminal job, with the attempt context of its last attempt, to reproduce
+// failures while debugging. Results, logs and follow-up messages are
+// captured instead of being persisted, and the job is left untouched.
+//
+// # Transition Export
+//
+// Backends implementing Outbox record every job state transition
+// atomically with the change. RelayWorker publishes them to a
+// TransitionSink, such as WebhookSink or the Kafka sink of package
+// github.com/romanqed/gqs/kafka, with at-least-once delivery.
+//
+// # Large Payloads
+//
+// OffloadPusher rejects payloads above a size limit and moves payloads
+// above a threshold to a BlobStore, keeping only a reference on the
+// job. Workers configured with WorkerConfig.Blobs resolve the reference
+// before invoking the handler and delete the blob once the job is Done.
+//
 // # Storage Expectations
 //
 // Implementations of Puller must ensure atomic state transitions,
@@ -103,6 +263,10 @@
 // gqs assumes that storage provides reliable write semantics.
 // Behavior under concurrent writers depends on the chosen backend.
 //
+// Storage failures may be reported as *StorageError. Worker retries
+// job transitions failing with transient ones for as long as the lease
+// of the job lasts (see WorkerConfig.StorageBackoff).
+//
 // # Summary
 //
 // gqs provides a minimal yet structured foundation for building
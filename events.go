@@ -0,0 +1,69 @@
+package gqs
+
+import (
+	"github.com/romanqed/gqs/job"
+	"time"
+)
+
+// EventListener receives structured lifecycle events of Worker and
+// CleanWorker, enabling custom metrics, alerting or webhooks without
+// wrapping the handler.
+//
+// Methods are called synchronously from the goroutine performing the
+// transition, possibly concurrently, so implementations must be safe
+// for concurrent use and should not block. Events are emitted only
+// after the corresponding transition succeeded in storage.
+//
+// Embed NopListener to implement only some of the methods.
+type EventListener interface {
+
+	// OnPulled is called when a pulled job is dispatched for handling.
+	OnPulled(jb *job.Job)
+
+	// OnCompleted is called when a job is completed; took is the
+	// duration of the handler.
+	OnCompleted(jb *job.Job, took time.Duration)
+
+	// OnRetried is called when a failed job is rescheduled after backoff.
+	OnRetried(jb *job.Job, err error, backoff time.Duration)
+
+	// OnKilled is called when a failed job is moved to Dead.
+	OnKilled(jb *job.Job, err error)
+
+	// OnLeaseLost is called when a worker loses the lease of a job
+	// while handling it.
+	OnLeaseLost(jb *job.Job)
+
+	// OnCleanup is called after a CleanWorker deleted count jobs
+	// of status. For CleanConfig without Retention, status is the
+	// configured CleanConfig.Status.
+	OnCleanup(status job.Status, count int64)
+}
+
+// NopListener is an EventListener ignoring every event.
+type NopListener struct{}
+
+// OnPulled implements EventListener.
+func (NopListener) OnPulled(*job.Job) {}
+
+// OnCompleted implements EventListener.
+func (NopListener) OnCompleted(*job.Job, time.Duration) {}
+
+// OnRetried implements EventListener.
+func (NopListener) OnRetried(*job.Job, error, time.Duration) {}
+
+// OnKilled implements EventListener.
+func (NopListener) OnKilled(*job.Job, error) {}
+
+// OnLeaseLost implements EventListener.
+func (NopListener) OnLeaseLost(*job.Job) {}
+
+// OnCleanup implements EventListener.
+func (NopListener) OnCleanup(job.Status, int64) {}
+
+func listenerOf(listener EventListener) EventListener {
+	if listener == nil {
+		return NopListener{}
+	}
+	return listener
+}
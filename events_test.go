@@ -0,0 +1,150 @@
+package gqs_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+type recordingListener struct {
+	gqs.NopListener
+	mu     sync.Mutex
+	events map[string]int
+}
+
+func (rl *recordingListener) add(event string) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.events == nil {
+		rl.events = make(map[string]int)
+	}
+	rl.events[event]++
+}
+
+func (rl *recordingListener) count(event string) int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.events[event]
+}
+
+func (rl *recordingListener) OnPulled(*job.Job) {
+	rl.add("pulled")
+}
+
+func (rl *recordingListener) OnCompleted(*job.Job, time.Duration) {
+	rl.add("completed")
+}
+
+func (rl *recordingListener) OnRetried(*job.Job, error, time.Duration) {
+	rl.add("retried")
+}
+
+func (rl *recordingListener) OnKilled(_ *job.Job, err error) {
+	if errors.Is(err, gqs.ErrKill) {
+		rl.add("killed")
+	}
+}
+
+func (rl *recordingListener) OnCleanup(status job.Status, count int64) {
+	if status == job.Done {
+		rl.add("cleanup")
+	}
+}
+
+func TestWorkerEvents(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+
+	logger := slog.Default()
+	listener := &recordingListener{}
+
+	var failed atomic.Bool
+	handler := func(ctx context.Context, msg *message.Message) error {
+		switch msg.Type {
+		case "kill":
+			return gqs.ErrKill
+		case "retry":
+			if !failed.Swap(true) {
+				return errors.New("fail once")
+			}
+		}
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  2,
+		Queue:        10,
+		BatchSize:    10,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  200 * time.Millisecond,
+		Backoff: gqs.BackoffConfig{
+			MaxRetries:      3,
+			InitialInterval: 10 * time.Millisecond,
+			MaxInterval:     100 * time.Millisecond,
+			Multiplier:      1,
+		},
+		Events: listener,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_ = worker.Start(ctx)
+
+	for _, kind := range []string{"ok", "kill", "retry"} {
+		msg := message.NewMessage()
+		msg.Type = kind
+		_ = pusher.Push(ctx, msg, 0)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+
+	_ = worker.Stop(time.Second)
+
+	expected := map[string]int{"pulled": 4, "completed": 2, "retried": 1, "killed": 1}
+	for event, count := range expected {
+		if actual := listener.count(event); actual != count {
+			t.Fatalf("expected %d %s events, got %d", count, event, actual)
+		}
+	}
+}
+
+func TestCleanWorkerEvents(t *testing.T) {
+	listener := &recordingListener{}
+
+	cfg := &gqs.CleanConfig{
+		Status:   job.Done,
+		Interval: 50 * time.Millisecond,
+		Events:   listener,
+	}
+
+	w := gqs.NewCleanWorker(&mockCleaner{}, cfg, slog.Default())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := w.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(80 * time.Millisecond)
+	if err := w.Stop(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if listener.count("cleanup") == 0 {
+		t.Fatal("expected cleanup event")
+	}
+}
@@ -0,0 +1,179 @@
+package gqs
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/romanqed/gqs/job"
+	"hash"
+	"io"
+	"maps"
+	"time"
+)
+
+var (
+	// ErrManifestMismatch indicates that exported job data does not match
+	// its manifest, for example because jobs were lost or altered while
+	// migrating between backends.
+	ErrManifestMismatch = errors.New("manifest mismatch")
+)
+
+// Manifest summarizes exported job data, so that a migration between
+// backends can be verified end to end.
+//
+// Jobs is the total number of exported jobs and Statuses the number of
+// jobs per status. Checksum is the hex-encoded SHA-256 of the export
+// stream; as jobs are exported in id order with normalized timestamps,
+// equal job sets produce equal checksums regardless of the backend.
+type Manifest struct {
+	Jobs     int64                `json:"jobs"`
+	Statuses map[job.Status]int64 `json:"statuses"`
+	Checksum string               `json:"checksum"`
+}
+
+// Verify compares m with other, typically the manifest of the source
+// with the manifest recomputed from the migrated data.
+//
+// Verify returns an error wrapping ErrManifestMismatch that describes
+// the first difference found, or nil if the manifests are equal.
+func (m *Manifest) Verify(other *Manifest) error {
+	if m.Jobs != other.Jobs {
+		return fmt.Errorf("%w: %d jobs, expected %d", ErrManifestMismatch, other.Jobs, m.Jobs)
+	}
+	if !maps.Equal(m.Statuses, other.Statuses) {
+		return fmt.Errorf("%w: status counts %v, expected %v", ErrManifestMismatch, other.Statuses, m.Statuses)
+	}
+	if m.Checksum != other.Checksum {
+		return fmt.Errorf("%w: checksum %s, expected %s", ErrManifestMismatch, other.Checksum, m.Checksum)
+	}
+	return nil
+}
+
+// Exporter is an optional extension of Observer that exports all jobs
+// for migration between backends.
+type Exporter interface {
+
+	// Export writes every job to w using an ExportWriter and returns
+	// the resulting manifest.
+	//
+	// Implementations must read all jobs from a single consistent
+	// snapshot of storage and write them ordered by id, so that the
+	// manifest describes one point in time and checksums of equal
+	// job sets match.
+	Export(ctx context.Context, w io.Writer) (*Manifest, error)
+}
+
+// ExportWriter writes jobs in the export format, one JSON object per
+// line, and computes the Manifest of the written data.
+//
+// Timestamps are converted to UTC and truncated to microseconds before
+// encoding, as backends differ in the precision and time zone of stored
+// timestamps.
+type ExportWriter struct {
+	w        io.Writer
+	hash     hash.Hash
+	jobs     int64
+	statuses map[job.Status]int64
+}
+
+// NewExportWriter creates an ExportWriter writing to w.
+func NewExportWriter(w io.Writer) *ExportWriter {
+	return &ExportWriter{
+		w:        w,
+		hash:     sha256.New(),
+		statuses: make(map[job.Status]int64),
+	}
+}
+
+func normalizeTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Microsecond)
+}
+
+func normalize(jb *job.Job) *job.Job {
+	ret := *jb
+	ret.CreatedAt = normalizeTime(jb.CreatedAt)
+	ret.UpdatedAt = normalizeTime(jb.UpdatedAt)
+	ret.NextRunAt = normalizeTime(jb.NextRunAt)
+	ret.ScheduledAt = normalizeTime(jb.ScheduledAt)
+	if jb.LockedUntil != nil {
+		lockedUntil := normalizeTime(*jb.LockedUntil)
+		ret.LockedUntil = &lockedUntil
+	}
+	if jb.Logs != nil {
+		ret.Logs = make([]job.LogLine, len(jb.Logs))
+		for i, line := range jb.Logs {
+			line.Time = normalizeTime(line.Time)
+			ret.Logs[i] = line
+		}
+	}
+	if jb.Diagnostics != nil {
+		diag := *jb.Diagnostics
+		diag.Time = normalizeTime(diag.Time)
+		ret.Diagnostics = &diag
+	}
+	return &ret
+}
+
+// Write appends jb to the export.
+func (ew *ExportWriter) Write(jb *job.Job) error {
+	line, err := json.Marshal(normalize(jb))
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if _, err := ew.w.Write(line); err != nil {
+		return err
+	}
+	ew.hash.Write(line)
+	ew.jobs++
+	ew.statuses[jb.Status]++
+	return nil
+}
+
+// Manifest returns the manifest of the jobs written so far.
+func (ew *ExportWriter) Manifest() *Manifest {
+	return &Manifest{
+		Jobs:     ew.jobs,
+		Statuses: maps.Clone(ew.statuses),
+		Checksum: hex.EncodeToString(ew.hash.Sum(nil)),
+	}
+}
+
+// ReadExport reads an export produced by an ExportWriter from r,
+// calling fn (if not nil) for every job, for example to import it into
+// another backend.
+//
+// ReadExport returns the manifest recomputed from the read data, to be
+// verified against the manifest of the export with Manifest.Verify.
+// If fn returns an error, reading stops and the error is returned.
+func ReadExport(r io.Reader, fn func(jb *job.Job) error) (*Manifest, error) {
+	reader := bufio.NewReader(r)
+	ew := NewExportWriter(io.Discard)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) != 0 {
+			var jb job.Job
+			if err := json.Unmarshal(line, &jb); err != nil {
+				return nil, err
+			}
+			ew.hash.Write(line)
+			ew.jobs++
+			ew.statuses[jb.Status]++
+			if fn != nil {
+				if err := fn(&jb); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return ew.Manifest(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -7,6 +7,7 @@ require (
 	github.com/romanqed/gqs/sql v0.0.0
 	github.com/uptrace/bun v1.2.16
 	github.com/uptrace/bun/dialect/sqlitedialect v1.2.16
+	google.golang.org/protobuf v1.36.9
 	modernc.org/sqlite v1.45.0
 )
 
@@ -0,0 +1,91 @@
+package gqs
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	"slices"
+	"time"
+)
+
+// MetaGroupId is the metadata key under which GroupPusher stores the
+// group id in the callback of a group.
+const MetaGroupId = "gqs_group"
+
+// Group is a set of related messages pushed together, with an optional
+// callback message enqueued once every member has ended.
+//
+// Id identifies the group; if it is uuid.Nil, a random id is assigned
+// by PushGroup. Members are pushed with their GroupId set to Id.
+//
+// Callback, if set, is delivered once every member reached a terminal
+// state, whether Done, Dead or Cancelled, so that fan-in steps can
+// aggregate the results of the members. The group id is stored in its
+// metadata under MetaGroupId. Delay applies to members and callback.
+type Group struct {
+	Id       uuid.UUID
+	Members  []*message.Message
+	Callback *message.Message
+	Delay    time.Duration
+}
+
+// GroupPusher is an optional extension of Pusher enqueuing groups of
+// messages.
+type GroupPusher interface {
+
+	// PushGroup enqueues the members and the callback of group within
+	// a single atomic operation: either all of them are enqueued, or
+	// none is. The messages of group are modified: members get the
+	// group id and the callback gets its metadata entry.
+	//
+	// Messages pushed later with the GroupId of an existing group are
+	// counted by GroupObserver, but the callback does not wait for
+	// them.
+	PushGroup(ctx context.Context, group *Group) error
+}
+
+// GroupStatus summarizes the members of a group.
+//
+// Counts holds the number of members per status; statuses without
+// members are omitted. Total is the number of members.
+type GroupStatus struct {
+	Id     uuid.UUID            `json:"id"`
+	Total  int64                `json:"total"`
+	Counts map[job.Status]int64 `json:"counts"`
+}
+
+// Terminal reports whether every member of the group has ended.
+func (gs *GroupStatus) Terminal() bool {
+	for status, count := range gs.Counts {
+		if count != 0 && !slices.Contains(job.TerminalStatuses, status) {
+			return false
+		}
+	}
+	return true
+}
+
+// GroupObserver is an optional extension of Observer reporting the
+// status of groups.
+type GroupObserver interface {
+
+	// Group returns the status of the group id. A group without members
+	// is reported with a zero Total rather than as an error, as its
+	// members may have been cleaned.
+	Group(ctx context.Context, id uuid.UUID) (*GroupStatus, error)
+}
+
+// PrepareGroup assigns a random id to group if it has none, and sets
+// the group id of its members and the metadata entry of its callback.
+// It is intended for GroupPusher implementations.
+func PrepareGroup(group *Group) {
+	if group.Id == uuid.Nil {
+		group.Id = uuid.New()
+	}
+	for _, msg := range group.Members {
+		msg.GroupId = group.Id
+	}
+	if group.Callback != nil {
+		group.Callback.Set(MetaGroupId, group.Id.String())
+	}
+}
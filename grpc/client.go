@@ -0,0 +1,158 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/grpc/pb"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// Client implements gqs.Pusher, gqs.SchedulePusher, gqs.QueryObserver
+// and gqs.Admin by calling a remote Server.
+//
+// Errors returned by the server are converted back into gqs errors
+// where possible (see the package documentation).
+type Client struct {
+	client   pb.QueueClient
+	notFound bool
+}
+
+// ClientOptions defines optional behavior of a Client.
+//
+// NotFoundError makes Get return an error wrapping gqs.ErrNotFound for
+// missing jobs instead of (nil, nil), as the sql Observer does with
+// the same option.
+type ClientOptions struct {
+	NotFoundError bool
+}
+
+// NewClient creates a new Client using the given connection.
+//
+// The connection is owned by the caller, who must close it once the
+// Client is no longer used.
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return NewClientWithOptions(conn, &ClientOptions{})
+}
+
+// NewClientWithOptions creates a new Client using the given connection
+// and options.
+func NewClientWithOptions(conn grpc.ClientConnInterface, opts *ClientOptions) *Client {
+	return &Client{
+		client:   pb.NewQueueClient(conn),
+		notFound: opts.NotFoundError,
+	}
+}
+
+// Capabilities implements gqs.Capable.
+func (c *Client) Capabilities() gqs.Capability {
+	return gqs.CapSchedulePush | gqs.CapQuery
+}
+
+func (c *Client) push(ctx context.Context, req *pb.PushRequest, msg *message.Message) error {
+	converted, err := toMessage(msg)
+	if err != nil {
+		return err
+	}
+	req.Message = converted
+	_, err = c.client.Push(ctx, req)
+	return fromStatus(err)
+}
+
+// Push enqueues msg on the remote queue.
+func (c *Client) Push(ctx context.Context, msg *message.Message, delay time.Duration) error {
+	return c.push(ctx, &pb.PushRequest{Delay: durationTo(delay)}, msg)
+}
+
+// PushAt enqueues msg on the remote queue, eligible at time at.
+func (c *Client) PushAt(ctx context.Context, msg *message.Message, at time.Time) error {
+	return c.push(ctx, &pb.PushRequest{At: timestamppb.New(at)}, msg)
+}
+
+// Get returns the job identified by id. If it does not exist, Get
+// returns (nil, nil), or an error wrapping gqs.ErrNotFound if
+// ClientOptions.NotFoundError is set.
+func (c *Client) Get(ctx context.Context, id uuid.UUID) (*job.Job, error) {
+	ret, err := c.client.Get(ctx, &pb.GetRequest{Id: id.String()})
+	if err != nil {
+		err = fromStatus(err)
+		if !c.notFound && errors.Is(err, gqs.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return fromJob(ret)
+}
+
+// List returns up to limit jobs with the given status.
+func (c *Client) List(ctx context.Context, status job.Status, limit int) ([]*job.Job, error) {
+	ret, err := c.client.List(ctx, &pb.ListRequest{
+		Status: pb.Status(status),
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	return fromJobs(ret.GetJobs())
+}
+
+// Query returns a page of jobs matching opts.
+func (c *Client) Query(ctx context.Context, opts *gqs.ListOptions) (*gqs.Page, error) {
+	ret, err := c.client.Query(ctx, toOptions(opts))
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	jobs, err := fromJobs(ret.GetJobs())
+	if err != nil {
+		return nil, err
+	}
+	return &gqs.Page{Jobs: jobs, Next: ret.GetNext()}, nil
+}
+
+// Count returns the number of jobs matching the filters of opts.
+func (c *Client) Count(ctx context.Context, opts *gqs.ListOptions) (int64, error) {
+	ret, err := c.client.Count(ctx, toOptions(opts))
+	if err != nil {
+		return 0, fromStatus(err)
+	}
+	return ret.GetCount(), nil
+}
+
+func affectedOf(ret *pb.AffectedResponse, err error) (int64, error) {
+	if err != nil {
+		return 0, fromStatus(err)
+	}
+	return ret.GetAffected(), nil
+}
+
+// KillByStatus implements gqs.Admin.
+func (c *Client) KillByStatus(ctx context.Context, filter *gqs.ListOptions) (int64, error) {
+	return affectedOf(c.client.KillByStatus(ctx, toOptions(filter)))
+}
+
+// RequeueByStatus implements gqs.Admin.
+func (c *Client) RequeueByStatus(ctx context.Context, filter *gqs.ListOptions) (int64, error) {
+	return affectedOf(c.client.RequeueByStatus(ctx, toOptions(filter)))
+}
+
+// DeleteByIds implements gqs.Admin.
+func (c *Client) DeleteByIds(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	raw := make([]string, len(ids))
+	for i, id := range ids {
+		raw[i] = id.String()
+	}
+	return affectedOf(c.client.DeleteByIds(ctx, &pb.DeleteRequest{Ids: raw}))
+}
+
+// UpdateNextRun implements gqs.Admin.
+func (c *Client) UpdateNextRun(ctx context.Context, filter *gqs.ListOptions, at time.Time) (int64, error) {
+	return affectedOf(c.client.UpdateNextRun(ctx, &pb.RescheduleRequest{
+		Filter: toOptions(filter),
+		At:     timestamppb.New(at),
+	}))
+}
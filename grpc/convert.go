@@ -0,0 +1,244 @@
+package grpc
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/grpc/pb"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+func durationOf(d *durationpb.Duration) time.Duration {
+	if d == nil {
+		return 0
+	}
+	return d.AsDuration()
+}
+
+func durationTo(d time.Duration) *durationpb.Duration {
+	if d == 0 {
+		return nil
+	}
+	return durationpb.New(d)
+}
+
+func timeOf(t *timestamppb.Timestamp) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.AsTime()
+}
+
+func timeTo(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func timePtrOf(t *timestamppb.Timestamp) *time.Time {
+	if t == nil {
+		return nil
+	}
+	ret := t.AsTime()
+	return &ret
+}
+
+func timePtrTo(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func parseId(raw string) (uuid.UUID, error) {
+	ret, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("bad id %q: %w", raw, err)
+	}
+	return ret, nil
+}
+
+func toMessage(msg *message.Message) (*pb.Message, error) {
+	ret := &pb.Message{
+		Id:            msg.Id.String(),
+		Queue:         msg.Queue,
+		TenantId:      msg.TenantId,
+		Type:          msg.Type,
+		Payload:       msg.Payload,
+		SchemaVersion: msg.SchemaVersion,
+		Priority:      int32(msg.Priority),
+		Region:        msg.Region,
+		OrderingKey:   msg.OrderingKey,
+		Ttl:           durationTo(msg.TTL),
+		MaxRetries:    msg.MaxRetries,
+		LockTimeout:   durationTo(msg.LockTimeout),
+		Timeout:       durationTo(msg.Timeout),
+	}
+	if len(msg.Metadata) != 0 {
+		metadata, err := structpb.NewStruct(msg.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("bad metadata: %w", err)
+		}
+		ret.Metadata = metadata
+	}
+	return ret, nil
+}
+
+func fromMessage(msg *pb.Message) (*message.Message, error) {
+	ret := &message.Message{
+		Queue:         msg.GetQueue(),
+		TenantId:      msg.GetTenantId(),
+		Type:          msg.GetType(),
+		Payload:       msg.GetPayload(),
+		SchemaVersion: msg.GetSchemaVersion(),
+		Priority:      int(msg.GetPriority()),
+		Region:        msg.GetRegion(),
+		OrderingKey:   msg.GetOrderingKey(),
+		TTL:           durationOf(msg.GetTtl()),
+		MaxRetries:    msg.GetMaxRetries(),
+		LockTimeout:   durationOf(msg.GetLockTimeout()),
+		Timeout:       durationOf(msg.GetTimeout()),
+	}
+	if msg.GetId() == "" {
+		ret.Id = uuid.New()
+	} else {
+		id, err := parseId(msg.GetId())
+		if err != nil {
+			return nil, err
+		}
+		ret.Id = id
+	}
+	if msg.GetMetadata() != nil {
+		ret.Metadata = msg.GetMetadata().AsMap()
+	}
+	return ret, nil
+}
+
+func toJob(jb *job.Job) (*pb.Job, error) {
+	msg, err := toMessage(&jb.Message)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Job{
+		Message:     msg,
+		CreatedAt:   timeTo(jb.CreatedAt),
+		UpdatedAt:   timeTo(jb.UpdatedAt),
+		Status:      pb.Status(jb.Status),
+		Attempts:    jb.Attempts,
+		LockedUntil: timePtrTo(jb.LockedUntil),
+		NextRunAt:   timeTo(jb.NextRunAt),
+		ScheduledAt: timeTo(jb.ScheduledAt),
+		ExpiresAt:   timePtrTo(jb.ExpiresAt),
+		LockedBy:    jb.LockedBy,
+		LockLosses:  jb.LockLosses,
+		LastError:   jb.LastError,
+		Result:      jb.Result,
+	}, nil
+}
+
+func toJobs(jobs []*job.Job) ([]*pb.Job, error) {
+	ret := make([]*pb.Job, len(jobs))
+	for i, jb := range jobs {
+		converted, err := toJob(jb)
+		if err != nil {
+			return nil, err
+		}
+		ret[i] = converted
+	}
+	return ret, nil
+}
+
+func fromJob(jb *pb.Job) (*job.Job, error) {
+	msg, err := fromMessage(jb.GetMessage())
+	if err != nil {
+		return nil, err
+	}
+	return &job.Job{
+		Message:     *msg,
+		CreatedAt:   timeOf(jb.GetCreatedAt()),
+		UpdatedAt:   timeOf(jb.GetUpdatedAt()),
+		Status:      job.Status(jb.GetStatus()),
+		Attempts:    jb.GetAttempts(),
+		LockedUntil: timePtrOf(jb.GetLockedUntil()),
+		NextRunAt:   timeOf(jb.GetNextRunAt()),
+		ScheduledAt: timeOf(jb.GetScheduledAt()),
+		ExpiresAt:   timePtrOf(jb.GetExpiresAt()),
+		LockedBy:    jb.GetLockedBy(),
+		LockLosses:  jb.GetLockLosses(),
+		LastError:   jb.GetLastError(),
+		Result:      jb.GetResult(),
+	}, nil
+}
+
+func fromJobs(jobs []*pb.Job) ([]*job.Job, error) {
+	ret := make([]*job.Job, len(jobs))
+	for i, jb := range jobs {
+		converted, err := fromJob(jb)
+		if err != nil {
+			return nil, err
+		}
+		ret[i] = converted
+	}
+	return ret, nil
+}
+
+func toOptions(opts *gqs.ListOptions) *pb.ListOptions {
+	if opts == nil {
+		return &pb.ListOptions{}
+	}
+	ret := &pb.ListOptions{
+		Queues:        opts.Queues,
+		Tenants:       opts.Tenants,
+		LockedBy:      opts.LockedBy,
+		CreatedAfter:  timePtrTo(opts.CreatedAfter),
+		CreatedBefore: timePtrTo(opts.CreatedBefore),
+		UpdatedAfter:  timePtrTo(opts.UpdatedAfter),
+		UpdatedBefore: timePtrTo(opts.UpdatedBefore),
+		Metadata:      opts.Metadata,
+		Order:         pb.Order(opts.Order),
+		Limit:         int32(opts.Limit),
+		Cursor:        opts.Cursor,
+		Offset:        int32(opts.Offset),
+	}
+	for _, id := range opts.Ids {
+		ret.Ids = append(ret.Ids, id.String())
+	}
+	for _, status := range opts.Statuses {
+		ret.Statuses = append(ret.Statuses, pb.Status(status))
+	}
+	return ret
+}
+
+func fromOptions(opts *pb.ListOptions) (*gqs.ListOptions, error) {
+	ret := &gqs.ListOptions{
+		Queues:        opts.GetQueues(),
+		Tenants:       opts.GetTenants(),
+		LockedBy:      opts.GetLockedBy(),
+		CreatedAfter:  timePtrOf(opts.GetCreatedAfter()),
+		CreatedBefore: timePtrOf(opts.GetCreatedBefore()),
+		UpdatedAfter:  timePtrOf(opts.GetUpdatedAfter()),
+		UpdatedBefore: timePtrOf(opts.GetUpdatedBefore()),
+		Metadata:      opts.GetMetadata(),
+		Order:         gqs.Order(opts.GetOrder()),
+		Limit:         int(opts.GetLimit()),
+		Cursor:        opts.GetCursor(),
+		Offset:        int(opts.GetOffset()),
+	}
+	for _, raw := range opts.GetIds() {
+		id, err := parseId(raw)
+		if err != nil {
+			return nil, err
+		}
+		ret.Ids = append(ret.Ids, id)
+	}
+	for _, status := range opts.GetStatuses() {
+		ret.Statuses = append(ret.Statuses, job.Status(status))
+	}
+	return ret, nil
+}
@@ -0,0 +1,29 @@
+// Package grpc exposes gqs queues over gRPC.
+//
+// Server serves the Queue service defined in pb/gqs.proto on top of a
+// local Pusher, Observer and Admin, typically backed by the sql package.
+// Producers written in any language can enqueue messages using clients
+// generated from the same protobuf definitions.
+//
+// Client implements gqs.Pusher, gqs.SchedulePusher, gqs.QueryObserver
+// and gqs.Admin by calling a remote Server, so that Go producers and
+// tools can use a remote queue interchangeably with a local one.
+//
+// # Conversions
+//
+// Message metadata is transmitted as google.protobuf.Struct, so values
+// must be representable as JSON; numbers are decoded as float64. Jobs
+// are transmitted without their logs and diagnostics.
+//
+// # Errors
+//
+// gqs errors are mapped to gRPC status codes: ErrDuplicateID to
+// AlreadyExists, ErrBadStatus and ErrBadCursor to InvalidArgument and
+// ErrNotFound to NotFound. Client maps them back, so errors.Is works on
+// both sides of the connection.
+//
+// # Code Generation
+//
+// The pb package is generated from pb/gqs.proto with protoc-gen-go and
+// protoc-gen-go-grpc; see generate.go.
+package grpc
@@ -0,0 +1,74 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"github.com/romanqed/gqs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+var errorCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{gqs.ErrDuplicateID, codes.AlreadyExists},
+	{gqs.ErrNotFound, codes.NotFound},
+	{gqs.ErrBadStatus, codes.InvalidArgument},
+	{gqs.ErrBadCursor, codes.InvalidArgument},
+	{context.Canceled, codes.Canceled},
+	{context.DeadlineExceeded, codes.DeadlineExceeded},
+}
+
+// remoteError carries the message of an error returned by a Server
+// while unwrapping to the matching gqs error.
+type remoteError struct {
+	msg string
+	err error
+}
+
+func (e *remoteError) Error() string {
+	return e.msg
+}
+
+func (e *remoteError) Unwrap() error {
+	return e.err
+}
+
+// toStatus converts err returned by a local gqs implementation into a
+// gRPC status error.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, entry := range errorCodes {
+		if errors.Is(err, entry.err) {
+			return status.Error(entry.code, err.Error())
+		}
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// fromStatus converts a gRPC status error into an error wrapping the
+// matching gqs error, if any. Codes shared by several errors are
+// disambiguated by the error message.
+func fromStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	for _, entry := range errorCodes {
+		if st.Code() != entry.code {
+			continue
+		}
+		if entry.code == codes.InvalidArgument && !strings.Contains(st.Message(), entry.err.Error()) {
+			continue
+		}
+		return &remoteError{msg: st.Message(), err: entry.err}
+	}
+	return err
+}
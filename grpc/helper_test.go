@@ -0,0 +1,60 @@
+package grpc_test
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"testing"
+
+	ggrpc "github.com/romanqed/gqs/grpc"
+	gsql "github.com/romanqed/gqs/sql"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	_ "modernc.org/sqlite"
+)
+
+func newTestDB(t *testing.T) *bun.DB {
+	t.Helper()
+	sqlDB, err := sql.Open("sqlite", "file::memory:?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1) // important for sqlite
+	db := bun.NewDB(sqlDB, sqlitedialect.New())
+	ctx := context.Background()
+	if err := gsql.InitDB(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// newTestConn serves srv over an in-memory listener and returns a
+// connection to it.
+func newTestConn(t *testing.T, srv *ggrpc.Server) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	srv.Register(gs)
+	go func() {
+		_ = gs.Serve(lis)
+	}()
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
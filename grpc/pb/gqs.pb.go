@@ -0,0 +1,1288 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: gqs.proto
+
+// Package gqs.v1 exposes a gqs queue to remote producers and
+// administrative tools.
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Status mirrors job.Status.
+type Status int32
+
+const (
+	Status_STATUS_UNKNOWN    Status = 0
+	Status_STATUS_PENDING    Status = 1
+	Status_STATUS_PROCESSING Status = 2
+	Status_STATUS_DONE       Status = 3
+	Status_STATUS_DEAD       Status = 4
+	Status_STATUS_CANCELLED  Status = 5
+	Status_STATUS_SCHEDULED  Status = 6
+)
+
+// Enum value maps for Status.
+var (
+	Status_name = map[int32]string{
+		0: "STATUS_UNKNOWN",
+		1: "STATUS_PENDING",
+		2: "STATUS_PROCESSING",
+		3: "STATUS_DONE",
+		4: "STATUS_DEAD",
+		5: "STATUS_CANCELLED",
+		6: "STATUS_SCHEDULED",
+	}
+	Status_value = map[string]int32{
+		"STATUS_UNKNOWN":    0,
+		"STATUS_PENDING":    1,
+		"STATUS_PROCESSING": 2,
+		"STATUS_DONE":       3,
+		"STATUS_DEAD":       4,
+		"STATUS_CANCELLED":  5,
+		"STATUS_SCHEDULED":  6,
+	}
+)
+
+func (x Status) Enum() *Status {
+	p := new(Status)
+	*p = x
+	return p
+}
+
+func (x Status) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Status) Descriptor() protoreflect.EnumDescriptor {
+	return file_gqs_proto_enumTypes[0].Descriptor()
+}
+
+func (Status) Type() protoreflect.EnumType {
+	return &file_gqs_proto_enumTypes[0]
+}
+
+func (x Status) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Status.Descriptor instead.
+func (Status) EnumDescriptor() ([]byte, []int) {
+	return file_gqs_proto_rawDescGZIP(), []int{0}
+}
+
+// Order mirrors gqs.Order.
+type Order int32
+
+const (
+	Order_ORDER_CREATED_ASC  Order = 0
+	Order_ORDER_CREATED_DESC Order = 1
+	Order_ORDER_UPDATED_ASC  Order = 2
+	Order_ORDER_UPDATED_DESC Order = 3
+)
+
+// Enum value maps for Order.
+var (
+	Order_name = map[int32]string{
+		0: "ORDER_CREATED_ASC",
+		1: "ORDER_CREATED_DESC",
+		2: "ORDER_UPDATED_ASC",
+		3: "ORDER_UPDATED_DESC",
+	}
+	Order_value = map[string]int32{
+		"ORDER_CREATED_ASC":  0,
+		"ORDER_CREATED_DESC": 1,
+		"ORDER_UPDATED_ASC":  2,
+		"ORDER_UPDATED_DESC": 3,
+	}
+)
+
+func (x Order) Enum() *Order {
+	p := new(Order)
+	*p = x
+	return p
+}
+
+func (x Order) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Order) Descriptor() protoreflect.EnumDescriptor {
+	return file_gqs_proto_enumTypes[1].Descriptor()
+}
+
+func (Order) Type() protoreflect.EnumType {
+	return &file_gqs_proto_enumTypes[1]
+}
+
+func (x Order) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Order.Descriptor instead.
+func (Order) EnumDescriptor() ([]byte, []int) {
+	return file_gqs_proto_rawDescGZIP(), []int{1}
+}
+
+// Message mirrors message.Message. An empty id makes the server
+// generate a random one.
+type Message struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Queue         string                 `protobuf:"bytes,2,opt,name=queue,proto3" json:"queue,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Metadata      *structpb.Struct       `protobuf:"bytes,4,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Payload       []byte                 `protobuf:"bytes,5,opt,name=payload,proto3" json:"payload,omitempty"`
+	SchemaVersion uint32                 `protobuf:"varint,6,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	Priority      int32                  `protobuf:"varint,7,opt,name=priority,proto3" json:"priority,omitempty"`
+	Region        string                 `protobuf:"bytes,8,opt,name=region,proto3" json:"region,omitempty"`
+	OrderingKey   string                 `protobuf:"bytes,9,opt,name=ordering_key,json=orderingKey,proto3" json:"ordering_key,omitempty"`
+	Ttl           *durationpb.Duration   `protobuf:"bytes,10,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	MaxRetries    uint32                 `protobuf:"varint,11,opt,name=max_retries,json=maxRetries,proto3" json:"max_retries,omitempty"`
+	LockTimeout   *durationpb.Duration   `protobuf:"bytes,12,opt,name=lock_timeout,json=lockTimeout,proto3" json:"lock_timeout,omitempty"`
+	Timeout       *durationpb.Duration   `protobuf:"bytes,13,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	TenantId      string                 `protobuf:"bytes,14,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Message) Reset() {
+	*x = Message{}
+	mi := &file_gqs_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Message) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Message) ProtoMessage() {}
+
+func (x *Message) ProtoReflect() protoreflect.Message {
+	mi := &file_gqs_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Message.ProtoReflect.Descriptor instead.
+func (*Message) Descriptor() ([]byte, []int) {
+	return file_gqs_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Message) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Message) GetQueue() string {
+	if x != nil {
+		return x.Queue
+	}
+	return ""
+}
+
+func (x *Message) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Message) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Message) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Message) GetSchemaVersion() uint32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+func (x *Message) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *Message) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *Message) GetOrderingKey() string {
+	if x != nil {
+		return x.OrderingKey
+	}
+	return ""
+}
+
+func (x *Message) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
+	}
+	return nil
+}
+
+func (x *Message) GetMaxRetries() uint32 {
+	if x != nil {
+		return x.MaxRetries
+	}
+	return 0
+}
+
+func (x *Message) GetLockTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.LockTimeout
+	}
+	return nil
+}
+
+func (x *Message) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *Message) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+// Job mirrors job.Job, without logs and diagnostics.
+type Job struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       *Message               `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Status        Status                 `protobuf:"varint,4,opt,name=status,proto3,enum=gqs.v1.Status" json:"status,omitempty"`
+	Attempts      uint32                 `protobuf:"varint,5,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	LockedUntil   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=locked_until,json=lockedUntil,proto3" json:"locked_until,omitempty"`
+	NextRunAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=next_run_at,json=nextRunAt,proto3" json:"next_run_at,omitempty"`
+	ScheduledAt   *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=scheduled_at,json=scheduledAt,proto3" json:"scheduled_at,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	LockedBy      string                 `protobuf:"bytes,10,opt,name=locked_by,json=lockedBy,proto3" json:"locked_by,omitempty"`
+	LockLosses    uint32                 `protobuf:"varint,11,opt,name=lock_losses,json=lockLosses,proto3" json:"lock_losses,omitempty"`
+	LastError     string                 `protobuf:"bytes,12,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	Result        []byte                 `protobuf:"bytes,13,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Job) Reset() {
+	*x = Job{}
+	mi := &file_gqs_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Job) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job) ProtoMessage() {}
+
+func (x *Job) ProtoReflect() protoreflect.Message {
+	mi := &file_gqs_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job.ProtoReflect.Descriptor instead.
+func (*Job) Descriptor() ([]byte, []int) {
+	return file_gqs_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Job) GetMessage() *Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *Job) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Job) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Job) GetStatus() Status {
+	if x != nil {
+		return x.Status
+	}
+	return Status_STATUS_UNKNOWN
+}
+
+func (x *Job) GetAttempts() uint32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *Job) GetLockedUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LockedUntil
+	}
+	return nil
+}
+
+func (x *Job) GetNextRunAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NextRunAt
+	}
+	return nil
+}
+
+func (x *Job) GetScheduledAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ScheduledAt
+	}
+	return nil
+}
+
+func (x *Job) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *Job) GetLockedBy() string {
+	if x != nil {
+		return x.LockedBy
+	}
+	return ""
+}
+
+func (x *Job) GetLockLosses() uint32 {
+	if x != nil {
+		return x.LockLosses
+	}
+	return 0
+}
+
+func (x *Job) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *Job) GetResult() []byte {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+// ListOptions mirrors gqs.ListOptions.
+type ListOptions struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Statuses      []Status               `protobuf:"varint,1,rep,packed,name=statuses,proto3,enum=gqs.v1.Status" json:"statuses,omitempty"`
+	Queues        []string               `protobuf:"bytes,2,rep,name=queues,proto3" json:"queues,omitempty"`
+	CreatedAfter  *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_after,json=createdAfter,proto3" json:"created_after,omitempty"`
+	CreatedBefore *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_before,json=createdBefore,proto3" json:"created_before,omitempty"`
+	UpdatedAfter  *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=updated_after,json=updatedAfter,proto3" json:"updated_after,omitempty"`
+	UpdatedBefore *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_before,json=updatedBefore,proto3" json:"updated_before,omitempty"`
+	Metadata      map[string]string      `protobuf:"bytes,7,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Order         Order                  `protobuf:"varint,8,opt,name=order,proto3,enum=gqs.v1.Order" json:"order,omitempty"`
+	Limit         int32                  `protobuf:"varint,9,opt,name=limit,proto3" json:"limit,omitempty"`
+	Cursor        string                 `protobuf:"bytes,10,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Offset        int32                  `protobuf:"varint,11,opt,name=offset,proto3" json:"offset,omitempty"`
+	Ids           []string               `protobuf:"bytes,12,rep,name=ids,proto3" json:"ids,omitempty"`
+	LockedBy      []string               `protobuf:"bytes,13,rep,name=locked_by,json=lockedBy,proto3" json:"locked_by,omitempty"`
+	Tenants       []string               `protobuf:"bytes,14,rep,name=tenants,proto3" json:"tenants,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOptions) Reset() {
+	*x = ListOptions{}
+	mi := &file_gqs_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOptions) ProtoMessage() {}
+
+func (x *ListOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_gqs_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOptions.ProtoReflect.Descriptor instead.
+func (*ListOptions) Descriptor() ([]byte, []int) {
+	return file_gqs_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListOptions) GetStatuses() []Status {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *ListOptions) GetQueues() []string {
+	if x != nil {
+		return x.Queues
+	}
+	return nil
+}
+
+func (x *ListOptions) GetCreatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return nil
+}
+
+func (x *ListOptions) GetCreatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedBefore
+	}
+	return nil
+}
+
+func (x *ListOptions) GetUpdatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAfter
+	}
+	return nil
+}
+
+func (x *ListOptions) GetUpdatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedBefore
+	}
+	return nil
+}
+
+func (x *ListOptions) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *ListOptions) GetOrder() Order {
+	if x != nil {
+		return x.Order
+	}
+	return Order_ORDER_CREATED_ASC
+}
+
+func (x *ListOptions) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListOptions) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListOptions) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListOptions) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *ListOptions) GetLockedBy() []string {
+	if x != nil {
+		return x.LockedBy
+	}
+	return nil
+}
+
+func (x *ListOptions) GetTenants() []string {
+	if x != nil {
+		return x.Tenants
+	}
+	return nil
+}
+
+type PushRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Message *Message               `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// Either delay or at may be set; at takes precedence.
+	Delay         *durationpb.Duration   `protobuf:"bytes,2,opt,name=delay,proto3" json:"delay,omitempty"`
+	At            *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=at,proto3" json:"at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PushRequest) Reset() {
+	*x = PushRequest{}
+	mi := &file_gqs_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PushRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushRequest) ProtoMessage() {}
+
+func (x *PushRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gqs_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushRequest.ProtoReflect.Descriptor instead.
+func (*PushRequest) Descriptor() ([]byte, []int) {
+	return file_gqs_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PushRequest) GetMessage() *Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *PushRequest) GetDelay() *durationpb.Duration {
+	if x != nil {
+		return x.Delay
+	}
+	return nil
+}
+
+func (x *PushRequest) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+type PushResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PushResponse) Reset() {
+	*x = PushResponse{}
+	mi := &file_gqs_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PushResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushResponse) ProtoMessage() {}
+
+func (x *PushResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gqs_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushResponse.ProtoReflect.Descriptor instead.
+func (*PushResponse) Descriptor() ([]byte, []int) {
+	return file_gqs_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PushResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_gqs_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gqs_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_gqs_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        Status                 `protobuf:"varint,1,opt,name=status,proto3,enum=gqs.v1.Status" json:"status,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	mi := &file_gqs_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gqs_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_gqs_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListRequest) GetStatus() Status {
+	if x != nil {
+		return x.Status
+	}
+	return Status_STATUS_UNKNOWN
+}
+
+func (x *ListRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jobs          []*Job                 `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListResponse) Reset() {
+	*x = ListResponse{}
+	mi := &file_gqs_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListResponse) ProtoMessage() {}
+
+func (x *ListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gqs_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return file_gqs_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListResponse) GetJobs() []*Job {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+type QueryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jobs          []*Job                 `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	Next          string                 `protobuf:"bytes,2,opt,name=next,proto3" json:"next,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryResponse) Reset() {
+	*x = QueryResponse{}
+	mi := &file_gqs_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryResponse) ProtoMessage() {}
+
+func (x *QueryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gqs_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryResponse.ProtoReflect.Descriptor instead.
+func (*QueryResponse) Descriptor() ([]byte, []int) {
+	return file_gqs_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *QueryResponse) GetJobs() []*Job {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+func (x *QueryResponse) GetNext() string {
+	if x != nil {
+		return x.Next
+	}
+	return ""
+}
+
+type CountResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Count         int64                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountResponse) Reset() {
+	*x = CountResponse{}
+	mi := &file_gqs_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountResponse) ProtoMessage() {}
+
+func (x *CountResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gqs_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountResponse.ProtoReflect.Descriptor instead.
+func (*CountResponse) Descriptor() ([]byte, []int) {
+	return file_gqs_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CountResponse) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type DeleteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []string               `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRequest) Reset() {
+	*x = DeleteRequest{}
+	mi := &file_gqs_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRequest) ProtoMessage() {}
+
+func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gqs_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return file_gqs_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DeleteRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type RescheduleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filter        *ListOptions           `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	At            *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=at,proto3" json:"at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RescheduleRequest) Reset() {
+	*x = RescheduleRequest{}
+	mi := &file_gqs_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RescheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RescheduleRequest) ProtoMessage() {}
+
+func (x *RescheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gqs_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RescheduleRequest.ProtoReflect.Descriptor instead.
+func (*RescheduleRequest) Descriptor() ([]byte, []int) {
+	return file_gqs_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RescheduleRequest) GetFilter() *ListOptions {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *RescheduleRequest) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+type AffectedResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Affected      int64                  `protobuf:"varint,1,opt,name=affected,proto3" json:"affected,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AffectedResponse) Reset() {
+	*x = AffectedResponse{}
+	mi := &file_gqs_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AffectedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AffectedResponse) ProtoMessage() {}
+
+func (x *AffectedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gqs_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AffectedResponse.ProtoReflect.Descriptor instead.
+func (*AffectedResponse) Descriptor() ([]byte, []int) {
+	return file_gqs_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *AffectedResponse) GetAffected() int64 {
+	if x != nil {
+		return x.Affected
+	}
+	return 0
+}
+
+var File_gqs_proto protoreflect.FileDescriptor
+
+const file_gqs_proto_rawDesc = "" +
+	"\n" +
+	"\tgqs.proto\x12\x06gqs.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xee\x03\n" +
+	"\aMessage\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05queue\x18\x02 \x01(\tR\x05queue\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x123\n" +
+	"\bmetadata\x18\x04 \x01(\v2\x17.google.protobuf.StructR\bmetadata\x12\x18\n" +
+	"\apayload\x18\x05 \x01(\fR\apayload\x12%\n" +
+	"\x0eschema_version\x18\x06 \x01(\rR\rschemaVersion\x12\x1a\n" +
+	"\bpriority\x18\a \x01(\x05R\bpriority\x12\x16\n" +
+	"\x06region\x18\b \x01(\tR\x06region\x12!\n" +
+	"\fordering_key\x18\t \x01(\tR\vorderingKey\x12+\n" +
+	"\x03ttl\x18\n" +
+	" \x01(\v2\x19.google.protobuf.DurationR\x03ttl\x12\x1f\n" +
+	"\vmax_retries\x18\v \x01(\rR\n" +
+	"maxRetries\x12<\n" +
+	"\flock_timeout\x18\f \x01(\v2\x19.google.protobuf.DurationR\vlockTimeout\x123\n" +
+	"\atimeout\x18\r \x01(\v2\x19.google.protobuf.DurationR\atimeout\x12\x1b\n" +
+	"\ttenant_id\x18\x0e \x01(\tR\btenantId\"\xd4\x04\n" +
+	"\x03Job\x12)\n" +
+	"\amessage\x18\x01 \x01(\v2\x0f.gqs.v1.MessageR\amessage\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12&\n" +
+	"\x06status\x18\x04 \x01(\x0e2\x0e.gqs.v1.StatusR\x06status\x12\x1a\n" +
+	"\battempts\x18\x05 \x01(\rR\battempts\x12=\n" +
+	"\flocked_until\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\vlockedUntil\x12:\n" +
+	"\vnext_run_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tnextRunAt\x12=\n" +
+	"\fscheduled_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\vscheduledAt\x129\n" +
+	"\n" +
+	"expires_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x1b\n" +
+	"\tlocked_by\x18\n" +
+	" \x01(\tR\blockedBy\x12\x1f\n" +
+	"\vlock_losses\x18\v \x01(\rR\n" +
+	"lockLosses\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\f \x01(\tR\tlastError\x12\x16\n" +
+	"\x06result\x18\r \x01(\fR\x06result\"\x89\x05\n" +
+	"\vListOptions\x12*\n" +
+	"\bstatuses\x18\x01 \x03(\x0e2\x0e.gqs.v1.StatusR\bstatuses\x12\x16\n" +
+	"\x06queues\x18\x02 \x03(\tR\x06queues\x12?\n" +
+	"\rcreated_after\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\fcreatedAfter\x12A\n" +
+	"\x0ecreated_before\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\rcreatedBefore\x12?\n" +
+	"\rupdated_after\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\fupdatedAfter\x12A\n" +
+	"\x0eupdated_before\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\rupdatedBefore\x12=\n" +
+	"\bmetadata\x18\a \x03(\v2!.gqs.v1.ListOptions.MetadataEntryR\bmetadata\x12#\n" +
+	"\x05order\x18\b \x01(\x0e2\r.gqs.v1.OrderR\x05order\x12\x14\n" +
+	"\x05limit\x18\t \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06cursor\x18\n" +
+	" \x01(\tR\x06cursor\x12\x16\n" +
+	"\x06offset\x18\v \x01(\x05R\x06offset\x12\x10\n" +
+	"\x03ids\x18\f \x03(\tR\x03ids\x12\x1b\n" +
+	"\tlocked_by\x18\r \x03(\tR\blockedBy\x12\x18\n" +
+	"\atenants\x18\x0e \x03(\tR\atenants\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x95\x01\n" +
+	"\vPushRequest\x12)\n" +
+	"\amessage\x18\x01 \x01(\v2\x0f.gqs.v1.MessageR\amessage\x12/\n" +
+	"\x05delay\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\x05delay\x12*\n" +
+	"\x02at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02at\"\x1e\n" +
+	"\fPushResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x1c\n" +
+	"\n" +
+	"GetRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"K\n" +
+	"\vListRequest\x12&\n" +
+	"\x06status\x18\x01 \x01(\x0e2\x0e.gqs.v1.StatusR\x06status\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"/\n" +
+	"\fListResponse\x12\x1f\n" +
+	"\x04jobs\x18\x01 \x03(\v2\v.gqs.v1.JobR\x04jobs\"D\n" +
+	"\rQueryResponse\x12\x1f\n" +
+	"\x04jobs\x18\x01 \x03(\v2\v.gqs.v1.JobR\x04jobs\x12\x12\n" +
+	"\x04next\x18\x02 \x01(\tR\x04next\"%\n" +
+	"\rCountResponse\x12\x14\n" +
+	"\x05count\x18\x01 \x01(\x03R\x05count\"!\n" +
+	"\rDeleteRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\"l\n" +
+	"\x11RescheduleRequest\x12+\n" +
+	"\x06filter\x18\x01 \x01(\v2\x13.gqs.v1.ListOptionsR\x06filter\x12*\n" +
+	"\x02at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02at\".\n" +
+	"\x10AffectedResponse\x12\x1a\n" +
+	"\baffected\x18\x01 \x01(\x03R\baffected*\x95\x01\n" +
+	"\x06Status\x12\x12\n" +
+	"\x0eSTATUS_UNKNOWN\x10\x00\x12\x12\n" +
+	"\x0eSTATUS_PENDING\x10\x01\x12\x15\n" +
+	"\x11STATUS_PROCESSING\x10\x02\x12\x0f\n" +
+	"\vSTATUS_DONE\x10\x03\x12\x0f\n" +
+	"\vSTATUS_DEAD\x10\x04\x12\x14\n" +
+	"\x10STATUS_CANCELLED\x10\x05\x12\x14\n" +
+	"\x10STATUS_SCHEDULED\x10\x06*e\n" +
+	"\x05Order\x12\x15\n" +
+	"\x11ORDER_CREATED_ASC\x10\x00\x12\x16\n" +
+	"\x12ORDER_CREATED_DESC\x10\x01\x12\x15\n" +
+	"\x11ORDER_UPDATED_ASC\x10\x02\x12\x16\n" +
+	"\x12ORDER_UPDATED_DESC\x10\x032\x86\x04\n" +
+	"\x05Queue\x121\n" +
+	"\x04Push\x12\x13.gqs.v1.PushRequest\x1a\x14.gqs.v1.PushResponse\x12&\n" +
+	"\x03Get\x12\x12.gqs.v1.GetRequest\x1a\v.gqs.v1.Job\x121\n" +
+	"\x04List\x12\x13.gqs.v1.ListRequest\x1a\x14.gqs.v1.ListResponse\x123\n" +
+	"\x05Query\x12\x13.gqs.v1.ListOptions\x1a\x15.gqs.v1.QueryResponse\x123\n" +
+	"\x05Count\x12\x13.gqs.v1.ListOptions\x1a\x15.gqs.v1.CountResponse\x12=\n" +
+	"\fKillByStatus\x12\x13.gqs.v1.ListOptions\x1a\x18.gqs.v1.AffectedResponse\x12@\n" +
+	"\x0fRequeueByStatus\x12\x13.gqs.v1.ListOptions\x1a\x18.gqs.v1.AffectedResponse\x12>\n" +
+	"\vDeleteByIds\x12\x15.gqs.v1.DeleteRequest\x1a\x18.gqs.v1.AffectedResponse\x12D\n" +
+	"\rUpdateNextRun\x12\x19.gqs.v1.RescheduleRequest\x1a\x18.gqs.v1.AffectedResponseB!Z\x1fgithub.com/romanqed/gqs/grpc/pbb\x06proto3"
+
+var (
+	file_gqs_proto_rawDescOnce sync.Once
+	file_gqs_proto_rawDescData []byte
+)
+
+func file_gqs_proto_rawDescGZIP() []byte {
+	file_gqs_proto_rawDescOnce.Do(func() {
+		file_gqs_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_gqs_proto_rawDesc), len(file_gqs_proto_rawDesc)))
+	})
+	return file_gqs_proto_rawDescData
+}
+
+var file_gqs_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_gqs_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_gqs_proto_goTypes = []any{
+	(Status)(0),                   // 0: gqs.v1.Status
+	(Order)(0),                    // 1: gqs.v1.Order
+	(*Message)(nil),               // 2: gqs.v1.Message
+	(*Job)(nil),                   // 3: gqs.v1.Job
+	(*ListOptions)(nil),           // 4: gqs.v1.ListOptions
+	(*PushRequest)(nil),           // 5: gqs.v1.PushRequest
+	(*PushResponse)(nil),          // 6: gqs.v1.PushResponse
+	(*GetRequest)(nil),            // 7: gqs.v1.GetRequest
+	(*ListRequest)(nil),           // 8: gqs.v1.ListRequest
+	(*ListResponse)(nil),          // 9: gqs.v1.ListResponse
+	(*QueryResponse)(nil),         // 10: gqs.v1.QueryResponse
+	(*CountResponse)(nil),         // 11: gqs.v1.CountResponse
+	(*DeleteRequest)(nil),         // 12: gqs.v1.DeleteRequest
+	(*RescheduleRequest)(nil),     // 13: gqs.v1.RescheduleRequest
+	(*AffectedResponse)(nil),      // 14: gqs.v1.AffectedResponse
+	nil,                           // 15: gqs.v1.ListOptions.MetadataEntry
+	(*structpb.Struct)(nil),       // 16: google.protobuf.Struct
+	(*durationpb.Duration)(nil),   // 17: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil), // 18: google.protobuf.Timestamp
+}
+var file_gqs_proto_depIdxs = []int32{
+	16, // 0: gqs.v1.Message.metadata:type_name -> google.protobuf.Struct
+	17, // 1: gqs.v1.Message.ttl:type_name -> google.protobuf.Duration
+	17, // 2: gqs.v1.Message.lock_timeout:type_name -> google.protobuf.Duration
+	17, // 3: gqs.v1.Message.timeout:type_name -> google.protobuf.Duration
+	2,  // 4: gqs.v1.Job.message:type_name -> gqs.v1.Message
+	18, // 5: gqs.v1.Job.created_at:type_name -> google.protobuf.Timestamp
+	18, // 6: gqs.v1.Job.updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 7: gqs.v1.Job.status:type_name -> gqs.v1.Status
+	18, // 8: gqs.v1.Job.locked_until:type_name -> google.protobuf.Timestamp
+	18, // 9: gqs.v1.Job.next_run_at:type_name -> google.protobuf.Timestamp
+	18, // 10: gqs.v1.Job.scheduled_at:type_name -> google.protobuf.Timestamp
+	18, // 11: gqs.v1.Job.expires_at:type_name -> google.protobuf.Timestamp
+	0,  // 12: gqs.v1.ListOptions.statuses:type_name -> gqs.v1.Status
+	18, // 13: gqs.v1.ListOptions.created_after:type_name -> google.protobuf.Timestamp
+	18, // 14: gqs.v1.ListOptions.created_before:type_name -> google.protobuf.Timestamp
+	18, // 15: gqs.v1.ListOptions.updated_after:type_name -> google.protobuf.Timestamp
+	18, // 16: gqs.v1.ListOptions.updated_before:type_name -> google.protobuf.Timestamp
+	15, // 17: gqs.v1.ListOptions.metadata:type_name -> gqs.v1.ListOptions.MetadataEntry
+	1,  // 18: gqs.v1.ListOptions.order:type_name -> gqs.v1.Order
+	2,  // 19: gqs.v1.PushRequest.message:type_name -> gqs.v1.Message
+	17, // 20: gqs.v1.PushRequest.delay:type_name -> google.protobuf.Duration
+	18, // 21: gqs.v1.PushRequest.at:type_name -> google.protobuf.Timestamp
+	0,  // 22: gqs.v1.ListRequest.status:type_name -> gqs.v1.Status
+	3,  // 23: gqs.v1.ListResponse.jobs:type_name -> gqs.v1.Job
+	3,  // 24: gqs.v1.QueryResponse.jobs:type_name -> gqs.v1.Job
+	4,  // 25: gqs.v1.RescheduleRequest.filter:type_name -> gqs.v1.ListOptions
+	18, // 26: gqs.v1.RescheduleRequest.at:type_name -> google.protobuf.Timestamp
+	5,  // 27: gqs.v1.Queue.Push:input_type -> gqs.v1.PushRequest
+	7,  // 28: gqs.v1.Queue.Get:input_type -> gqs.v1.GetRequest
+	8,  // 29: gqs.v1.Queue.List:input_type -> gqs.v1.ListRequest
+	4,  // 30: gqs.v1.Queue.Query:input_type -> gqs.v1.ListOptions
+	4,  // 31: gqs.v1.Queue.Count:input_type -> gqs.v1.ListOptions
+	4,  // 32: gqs.v1.Queue.KillByStatus:input_type -> gqs.v1.ListOptions
+	4,  // 33: gqs.v1.Queue.RequeueByStatus:input_type -> gqs.v1.ListOptions
+	12, // 34: gqs.v1.Queue.DeleteByIds:input_type -> gqs.v1.DeleteRequest
+	13, // 35: gqs.v1.Queue.UpdateNextRun:input_type -> gqs.v1.RescheduleRequest
+	6,  // 36: gqs.v1.Queue.Push:output_type -> gqs.v1.PushResponse
+	3,  // 37: gqs.v1.Queue.Get:output_type -> gqs.v1.Job
+	9,  // 38: gqs.v1.Queue.List:output_type -> gqs.v1.ListResponse
+	10, // 39: gqs.v1.Queue.Query:output_type -> gqs.v1.QueryResponse
+	11, // 40: gqs.v1.Queue.Count:output_type -> gqs.v1.CountResponse
+	14, // 41: gqs.v1.Queue.KillByStatus:output_type -> gqs.v1.AffectedResponse
+	14, // 42: gqs.v1.Queue.RequeueByStatus:output_type -> gqs.v1.AffectedResponse
+	14, // 43: gqs.v1.Queue.DeleteByIds:output_type -> gqs.v1.AffectedResponse
+	14, // 44: gqs.v1.Queue.UpdateNextRun:output_type -> gqs.v1.AffectedResponse
+	36, // [36:45] is the sub-list for method output_type
+	27, // [27:36] is the sub-list for method input_type
+	27, // [27:27] is the sub-list for extension type_name
+	27, // [27:27] is the sub-list for extension extendee
+	0,  // [0:27] is the sub-list for field type_name
+}
+
+func init() { file_gqs_proto_init() }
+func file_gqs_proto_init() {
+	if File_gqs_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_gqs_proto_rawDesc), len(file_gqs_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_gqs_proto_goTypes,
+		DependencyIndexes: file_gqs_proto_depIdxs,
+		EnumInfos:         file_gqs_proto_enumTypes,
+		MessageInfos:      file_gqs_proto_msgTypes,
+	}.Build()
+	File_gqs_proto = out.File
+	file_gqs_proto_goTypes = nil
+	file_gqs_proto_depIdxs = nil
+}
@@ -0,0 +1,434 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: gqs.proto
+
+// Package gqs.v1 exposes a gqs queue to remote producers and
+// administrative tools.
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Queue_Push_FullMethodName            = "/gqs.v1.Queue/Push"
+	Queue_Get_FullMethodName             = "/gqs.v1.Queue/Get"
+	Queue_List_FullMethodName            = "/gqs.v1.Queue/List"
+	Queue_Query_FullMethodName           = "/gqs.v1.Queue/Query"
+	Queue_Count_FullMethodName           = "/gqs.v1.Queue/Count"
+	Queue_KillByStatus_FullMethodName    = "/gqs.v1.Queue/KillByStatus"
+	Queue_RequeueByStatus_FullMethodName = "/gqs.v1.Queue/RequeueByStatus"
+	Queue_DeleteByIds_FullMethodName     = "/gqs.v1.Queue/DeleteByIds"
+	Queue_UpdateNextRun_FullMethodName   = "/gqs.v1.Queue/UpdateNextRun"
+)
+
+// QueueClient is the client API for Queue service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Queue is the remote interface of a gqs queue. Each method mirrors the
+// gqs interface method of the same name.
+type QueueClient interface {
+	Push(ctx context.Context, in *PushRequest, opts ...grpc.CallOption) (*PushResponse, error)
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Job, error)
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	Query(ctx context.Context, in *ListOptions, opts ...grpc.CallOption) (*QueryResponse, error)
+	Count(ctx context.Context, in *ListOptions, opts ...grpc.CallOption) (*CountResponse, error)
+	KillByStatus(ctx context.Context, in *ListOptions, opts ...grpc.CallOption) (*AffectedResponse, error)
+	RequeueByStatus(ctx context.Context, in *ListOptions, opts ...grpc.CallOption) (*AffectedResponse, error)
+	DeleteByIds(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*AffectedResponse, error)
+	UpdateNextRun(ctx context.Context, in *RescheduleRequest, opts ...grpc.CallOption) (*AffectedResponse, error)
+}
+
+type queueClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewQueueClient(cc grpc.ClientConnInterface) QueueClient {
+	return &queueClient{cc}
+}
+
+func (c *queueClient) Push(ctx context.Context, in *PushRequest, opts ...grpc.CallOption) (*PushResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PushResponse)
+	err := c.cc.Invoke(ctx, Queue_Push_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, Queue_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, Queue_List_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueClient) Query(ctx context.Context, in *ListOptions, opts ...grpc.CallOption) (*QueryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryResponse)
+	err := c.cc.Invoke(ctx, Queue_Query_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueClient) Count(ctx context.Context, in *ListOptions, opts ...grpc.CallOption) (*CountResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CountResponse)
+	err := c.cc.Invoke(ctx, Queue_Count_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueClient) KillByStatus(ctx context.Context, in *ListOptions, opts ...grpc.CallOption) (*AffectedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AffectedResponse)
+	err := c.cc.Invoke(ctx, Queue_KillByStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueClient) RequeueByStatus(ctx context.Context, in *ListOptions, opts ...grpc.CallOption) (*AffectedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AffectedResponse)
+	err := c.cc.Invoke(ctx, Queue_RequeueByStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueClient) DeleteByIds(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*AffectedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AffectedResponse)
+	err := c.cc.Invoke(ctx, Queue_DeleteByIds_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueClient) UpdateNextRun(ctx context.Context, in *RescheduleRequest, opts ...grpc.CallOption) (*AffectedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AffectedResponse)
+	err := c.cc.Invoke(ctx, Queue_UpdateNextRun_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// QueueServer is the server API for Queue service.
+// All implementations must embed UnimplementedQueueServer
+// for forward compatibility.
+//
+// Queue is the remote interface of a gqs queue. Each method mirrors the
+// gqs interface method of the same name.
+type QueueServer interface {
+	Push(context.Context, *PushRequest) (*PushResponse, error)
+	Get(context.Context, *GetRequest) (*Job, error)
+	List(context.Context, *ListRequest) (*ListResponse, error)
+	Query(context.Context, *ListOptions) (*QueryResponse, error)
+	Count(context.Context, *ListOptions) (*CountResponse, error)
+	KillByStatus(context.Context, *ListOptions) (*AffectedResponse, error)
+	RequeueByStatus(context.Context, *ListOptions) (*AffectedResponse, error)
+	DeleteByIds(context.Context, *DeleteRequest) (*AffectedResponse, error)
+	UpdateNextRun(context.Context, *RescheduleRequest) (*AffectedResponse, error)
+	mustEmbedUnimplementedQueueServer()
+}
+
+// UnimplementedQueueServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedQueueServer struct{}
+
+func (UnimplementedQueueServer) Push(context.Context, *PushRequest) (*PushResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Push not implemented")
+}
+func (UnimplementedQueueServer) Get(context.Context, *GetRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedQueueServer) List(context.Context, *ListRequest) (*ListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedQueueServer) Query(context.Context, *ListOptions) (*QueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Query not implemented")
+}
+func (UnimplementedQueueServer) Count(context.Context, *ListOptions) (*CountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Count not implemented")
+}
+func (UnimplementedQueueServer) KillByStatus(context.Context, *ListOptions) (*AffectedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method KillByStatus not implemented")
+}
+func (UnimplementedQueueServer) RequeueByStatus(context.Context, *ListOptions) (*AffectedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequeueByStatus not implemented")
+}
+func (UnimplementedQueueServer) DeleteByIds(context.Context, *DeleteRequest) (*AffectedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteByIds not implemented")
+}
+func (UnimplementedQueueServer) UpdateNextRun(context.Context, *RescheduleRequest) (*AffectedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateNextRun not implemented")
+}
+func (UnimplementedQueueServer) mustEmbedUnimplementedQueueServer() {}
+func (UnimplementedQueueServer) testEmbeddedByValue()               {}
+
+// UnsafeQueueServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to QueueServer will
+// result in compilation errors.
+type UnsafeQueueServer interface {
+	mustEmbedUnimplementedQueueServer()
+}
+
+func RegisterQueueServer(s grpc.ServiceRegistrar, srv QueueServer) {
+	// If the following call pancis, it indicates UnimplementedQueueServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Queue_ServiceDesc, srv)
+}
+
+func _Queue_Push_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PushRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServer).Push(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Queue_Push_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServer).Push(ctx, req.(*PushRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Queue_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Queue_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Queue_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Queue_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Queue_Query_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOptions)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServer).Query(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Queue_Query_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServer).Query(ctx, req.(*ListOptions))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Queue_Count_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOptions)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServer).Count(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Queue_Count_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServer).Count(ctx, req.(*ListOptions))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Queue_KillByStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOptions)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServer).KillByStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Queue_KillByStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServer).KillByStatus(ctx, req.(*ListOptions))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Queue_RequeueByStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOptions)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServer).RequeueByStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Queue_RequeueByStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServer).RequeueByStatus(ctx, req.(*ListOptions))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Queue_DeleteByIds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServer).DeleteByIds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Queue_DeleteByIds_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServer).DeleteByIds(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Queue_UpdateNextRun_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RescheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServer).UpdateNextRun(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Queue_UpdateNextRun_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServer).UpdateNextRun(ctx, req.(*RescheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Queue_ServiceDesc is the grpc.ServiceDesc for Queue service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Queue_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gqs.v1.Queue",
+	HandlerType: (*QueueServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Push",
+			Handler:    _Queue_Push_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _Queue_Get_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _Queue_List_Handler,
+		},
+		{
+			MethodName: "Query",
+			Handler:    _Queue_Query_Handler,
+		},
+		{
+			MethodName: "Count",
+			Handler:    _Queue_Count_Handler,
+		},
+		{
+			MethodName: "KillByStatus",
+			Handler:    _Queue_KillByStatus_Handler,
+		},
+		{
+			MethodName: "RequeueByStatus",
+			Handler:    _Queue_RequeueByStatus_Handler,
+		},
+		{
+			MethodName: "DeleteByIds",
+			Handler:    _Queue_DeleteByIds_Handler,
+		},
+		{
+			MethodName: "UpdateNextRun",
+			Handler:    _Queue_UpdateNextRun_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "gqs.proto",
+}
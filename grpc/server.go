@@ -0,0 +1,222 @@
+package grpc
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/grpc/pb"
+	"github.com/romanqed/gqs/job"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// Server implements the Queue gRPC service on top of local gqs
+// implementations.
+//
+// Each of pusher, observer and admin may be nil; the methods backed by
+// a missing implementation return codes.Unimplemented. Query and Count
+// additionally require observer to implement gqs.QueryObserver.
+type Server struct {
+	pb.UnimplementedQueueServer
+	pusher   gqs.Pusher
+	observer gqs.Observer
+	admin    gqs.Admin
+}
+
+// NewServer creates a new Server serving the given implementations.
+func NewServer(pusher gqs.Pusher, observer gqs.Observer, admin gqs.Admin) *Server {
+	return &Server{
+		pusher:   pusher,
+		observer: observer,
+		admin:    admin,
+	}
+}
+
+// Register registers s on the given gRPC server.
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	pb.RegisterQueueServer(registrar, s)
+}
+
+func unimplemented(method string) error {
+	return status.Errorf(codes.Unimplemented, "%s is not supported by the server", method)
+}
+
+func invalid(err error) error {
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// Push enqueues the requested message. If At is set, the message is
+// scheduled for that time, using gqs.SchedulePusher if the pusher
+// supports it.
+func (s *Server) Push(ctx context.Context, req *pb.PushRequest) (*pb.PushResponse, error) {
+	if s.pusher == nil {
+		return nil, unimplemented("Push")
+	}
+	if req.GetMessage() == nil {
+		return nil, status.Error(codes.InvalidArgument, "message is required")
+	}
+	msg, err := fromMessage(req.GetMessage())
+	if err != nil {
+		return nil, invalid(err)
+	}
+	if req.GetAt() != nil {
+		at := req.GetAt().AsTime()
+		if scheduler, ok := s.pusher.(gqs.SchedulePusher); ok && gqs.Supports(s.pusher, gqs.CapSchedulePush) {
+			err = scheduler.PushAt(ctx, msg, at)
+		} else {
+			err = s.pusher.Push(ctx, msg, max(time.Until(at), 0))
+		}
+	} else {
+		err = s.pusher.Push(ctx, msg, durationOf(req.GetDelay()))
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.PushResponse{Id: msg.Id.String()}, nil
+}
+
+// Get returns the requested job, or codes.NotFound if it does not exist.
+func (s *Server) Get(ctx context.Context, req *pb.GetRequest) (*pb.Job, error) {
+	if s.observer == nil {
+		return nil, unimplemented("Get")
+	}
+	id, err := parseId(req.GetId())
+	if err != nil {
+		return nil, invalid(err)
+	}
+	jb, err := gqs.GetExisting(ctx, s.observer, id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	ret, err := toJob(jb)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return ret, nil
+}
+
+// List returns jobs with the requested status.
+func (s *Server) List(ctx context.Context, req *pb.ListRequest) (*pb.ListResponse, error) {
+	if s.observer == nil {
+		return nil, unimplemented("List")
+	}
+	jobs, err := s.observer.List(ctx, job.Status(req.GetStatus()), int(req.GetLimit()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	ret, err := toJobs(jobs)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.ListResponse{Jobs: ret}, nil
+}
+
+func (s *Server) query() (gqs.QueryObserver, bool) {
+	if s.observer == nil || !gqs.Supports(s.observer, gqs.CapQuery) {
+		return nil, false
+	}
+	ret, ok := s.observer.(gqs.QueryObserver)
+	return ret, ok
+}
+
+// Query returns a page of jobs matching the requested options.
+func (s *Server) Query(ctx context.Context, req *pb.ListOptions) (*pb.QueryResponse, error) {
+	obs, ok := s.query()
+	if !ok {
+		return nil, unimplemented("Query")
+	}
+	opts, err := fromOptions(req)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	page, err := obs.Query(ctx, opts)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	jobs, err := toJobs(page.Jobs)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.QueryResponse{Jobs: jobs, Next: page.Next}, nil
+}
+
+// Count returns the number of jobs matching the requested options.
+func (s *Server) Count(ctx context.Context, req *pb.ListOptions) (*pb.CountResponse, error) {
+	obs, ok := s.query()
+	if !ok {
+		return nil, unimplemented("Count")
+	}
+	opts, err := fromOptions(req)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	count, err := obs.Count(ctx, opts)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.CountResponse{Count: count}, nil
+}
+
+func affected(count int64, err error) (*pb.AffectedResponse, error) {
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.AffectedResponse{Affected: count}, nil
+}
+
+// KillByStatus implements gqs.Admin.KillByStatus remotely.
+func (s *Server) KillByStatus(ctx context.Context, req *pb.ListOptions) (*pb.AffectedResponse, error) {
+	if s.admin == nil {
+		return nil, unimplemented("KillByStatus")
+	}
+	opts, err := fromOptions(req)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	return affected(s.admin.KillByStatus(ctx, opts))
+}
+
+// RequeueByStatus implements gqs.Admin.RequeueByStatus remotely.
+func (s *Server) RequeueByStatus(ctx context.Context, req *pb.ListOptions) (*pb.AffectedResponse, error) {
+	if s.admin == nil {
+		return nil, unimplemented("RequeueByStatus")
+	}
+	opts, err := fromOptions(req)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	return affected(s.admin.RequeueByStatus(ctx, opts))
+}
+
+// DeleteByIds implements gqs.Admin.DeleteByIds remotely.
+func (s *Server) DeleteByIds(ctx context.Context, req *pb.DeleteRequest) (*pb.AffectedResponse, error) {
+	if s.admin == nil {
+		return nil, unimplemented("DeleteByIds")
+	}
+	ids := make([]uuid.UUID, len(req.GetIds()))
+	for i, raw := range req.GetIds() {
+		id, err := parseId(raw)
+		if err != nil {
+			return nil, invalid(err)
+		}
+		ids[i] = id
+	}
+	return affected(s.admin.DeleteByIds(ctx, ids))
+}
+
+// UpdateNextRun implements gqs.Admin.UpdateNextRun remotely.
+func (s *Server) UpdateNextRun(ctx context.Context, req *pb.RescheduleRequest) (*pb.AffectedResponse, error) {
+	if s.admin == nil {
+		return nil, unimplemented("UpdateNextRun")
+	}
+	if req.GetAt() == nil {
+		return nil, status.Error(codes.InvalidArgument, "at is required")
+	}
+	opts, err := fromOptions(req.GetFilter())
+	if err != nil {
+		return nil, invalid(err)
+	}
+	return affected(s.admin.UpdateNextRun(ctx, opts, req.GetAt().AsTime()))
+}
@@ -0,0 +1,111 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	ggrpc "github.com/romanqed/gqs/grpc"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestClientPushAndObserve(t *testing.T) {
+	db := newTestDB(t)
+	srv := ggrpc.NewServer(gsql.NewPusher(db), gsql.NewObserver(db), gsql.NewAdmin(db))
+	conn := newTestConn(t, srv)
+	client := ggrpc.NewClient(conn)
+
+	ctx := context.Background()
+
+	msg := message.NewMessage()
+	msg.Queue = "emails"
+	msg.Type = "welcome"
+	msg.Payload = []byte("hello")
+	msg.Priority = 5
+	msg.TTL = time.Hour
+	msg.Set("tenant", "acme")
+	if err := client.Push(ctx, msg, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Push(ctx, msg, 0); !errors.Is(err, gqs.ErrDuplicateID) {
+		t.Fatalf("expected ErrDuplicateID, got %v", err)
+	}
+
+	later := message.NewMessage()
+	if err := client.PushAt(ctx, later, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := client.Get(ctx, msg.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j == nil || j.Status != job.Pending || j.Queue != "emails" || j.Type != "welcome" ||
+		string(j.Payload) != "hello" || j.Priority != 5 || j.TTL != time.Hour || j.ExpiresAt == nil {
+		t.Fatalf("unexpected job %+v", j)
+	}
+	if tenant, _ := message.Get[string](&j.Message, "tenant"); tenant != "acme" {
+		t.Fatalf("expected metadata to round-trip, got %v", j.Metadata)
+	}
+
+	missing, err := client.Get(ctx, uuid.New())
+	if missing != nil || err != nil {
+		t.Fatalf("expected (nil, nil) for a missing job, got %v, %v", missing, err)
+	}
+
+	strict := ggrpc.NewClientWithOptions(conn, &ggrpc.ClientOptions{NotFoundError: true})
+	if _, err := strict.Get(ctx, uuid.New()); !errors.Is(err, gqs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	page, err := client.Query(ctx, &gqs.ListOptions{Queues: []string{"emails"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Jobs) != 1 || page.Jobs[0].Id != msg.Id {
+		t.Fatalf("unexpected page %+v", page)
+	}
+	if _, err := client.Query(ctx, &gqs.ListOptions{Cursor: "garbage"}); !errors.Is(err, gqs.ErrBadCursor) {
+		t.Fatalf("expected ErrBadCursor, got %v", err)
+	}
+
+	at := time.Now().Add(2 * time.Hour).Truncate(time.Millisecond)
+	n, err := client.UpdateNextRun(ctx, &gqs.ListOptions{Queues: []string{"emails"}}, at)
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 rescheduled job, got %d, %v", n, err)
+	}
+	n, err = client.KillByStatus(ctx, &gqs.ListOptions{})
+	if err != nil || n != 2 {
+		t.Fatalf("expected 2 killed jobs, got %d, %v", n, err)
+	}
+	count, err := client.Count(ctx, &gqs.ListOptions{Statuses: []job.Status{job.Dead}})
+	if err != nil || count != 2 {
+		t.Fatalf("expected 2 dead jobs, got %d, %v", count, err)
+	}
+	n, err = client.DeleteByIds(ctx, []uuid.UUID{msg.Id, later.Id})
+	if err != nil || n != 2 {
+		t.Fatalf("expected 2 deleted jobs, got %d, %v", n, err)
+	}
+}
+
+func TestServerUnimplemented(t *testing.T) {
+	db := newTestDB(t)
+	client := ggrpc.NewClient(newTestConn(t, ggrpc.NewServer(gsql.NewPusher(db), nil, nil)))
+
+	ctx := context.Background()
+
+	if err := client.Push(ctx, message.NewMessage(), 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.List(ctx, job.Unknown, 0); err == nil {
+		t.Fatal("expected List to fail without an observer")
+	}
+	if _, err := client.RequeueByStatus(ctx, &gqs.ListOptions{}); err == nil {
+		t.Fatal("expected RequeueByStatus to fail without an admin")
+	}
+}
@@ -0,0 +1,151 @@
+package gqs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/romanqed/gqs/message"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrLimitExceeded indicates that a message was rejected by
+	// a GuardPusher because it exceeds a configured limit.
+	ErrLimitExceeded = errors.New("limit exceeded")
+)
+
+// Limit identifies a limit checked by GuardPusher.
+type Limit uint8
+
+const (
+	// LimitPayload limits the payload size in bytes.
+	LimitPayload Limit = iota
+
+	// LimitMetadata limits the number of metadata keys.
+	LimitMetadata
+
+	// LimitDelay limits the push delay.
+	LimitDelay
+
+	limitCount
+)
+
+// String returns the name of the limit.
+func (l Limit) String() string {
+	switch l {
+	case LimitPayload:
+		return "payload"
+	case LimitMetadata:
+		return "metadata"
+	case LimitDelay:
+		return "delay"
+	}
+	return "unknown"
+}
+
+// LimitError describes a message exceeding a limit.
+//
+// Value is the measured value and Max the configured limit, both in
+// the unit of the limit (bytes, keys or nanoseconds).
+//
+// LimitError matches ErrLimitExceeded with errors.Is.
+type LimitError struct {
+	Limit Limit
+	Value int64
+	Max   int64
+}
+
+func (le *LimitError) Error() string {
+	return fmt.Sprintf("%s limit exceeded: %d > %d", le.Limit, le.Value, le.Max)
+}
+
+// Is reports whether target is ErrLimitExceeded.
+func (le *LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// Limits defines thresholds of message properties. Zero values disable
+// the corresponding check.
+type Limits struct {
+	MaxPayload  int
+	MaxMetadata int
+	MaxDelay    time.Duration
+}
+
+func (l *Limits) check(msg *message.Message, delay time.Duration) *LimitError {
+	if l.MaxPayload > 0 && len(msg.Payload) > l.MaxPayload {
+		return &LimitError{LimitPayload, int64(len(msg.Payload)), int64(l.MaxPayload)}
+	}
+	if l.MaxMetadata > 0 && len(msg.Metadata) > l.MaxMetadata {
+		return &LimitError{LimitMetadata, int64(len(msg.Metadata)), int64(l.MaxMetadata)}
+	}
+	if l.MaxDelay > 0 && delay > l.MaxDelay {
+		return &LimitError{LimitDelay, int64(delay), int64(l.MaxDelay)}
+	}
+	return nil
+}
+
+// GuardConfig defines the limits enforced by a GuardPusher.
+//
+// Messages exceeding Warn are enqueued, but logged as warnings.
+// Messages exceeding Reject are not enqueued and Push returns
+// a *LimitError. Reject limits are checked first.
+type GuardConfig struct {
+	Warn   Limits
+	Reject Limits
+}
+
+// GuardStats holds the number of messages a GuardPusher warned about
+// and rejected, indexed by Limit.
+type GuardStats struct {
+	Warned   [limitCount]int64
+	Rejected [limitCount]int64
+}
+
+// GuardPusher is a Pusher checking messages against size and delay
+// limits before delegating to the underlying Pusher, to catch
+// pathological producers before they degrade the whole queue.
+type GuardPusher struct {
+	pusher   Pusher
+	warn     Limits
+	reject   Limits
+	log      Logger
+	warned   [limitCount]atomic.Int64
+	rejected [limitCount]atomic.Int64
+}
+
+// NewGuardPusher creates a GuardPusher delegating to pusher.
+func NewGuardPusher(pusher Pusher, config *GuardConfig, log Logger) *GuardPusher {
+	return &GuardPusher{
+		pusher: pusher,
+		warn:   config.Warn,
+		reject: config.Reject,
+		log:    loggerOf(log),
+	}
+}
+
+// Push checks msg against the configured limits and enqueues it
+// unless a Reject limit is exceeded.
+func (gp *GuardPusher) Push(ctx context.Context, msg *message.Message, delay time.Duration) error {
+	if err := gp.reject.check(msg, delay); err != nil {
+		gp.rejected[err.Limit].Add(1)
+		return err
+	}
+	if err := gp.warn.check(msg, delay); err != nil {
+		gp.warned[err.Limit].Add(1)
+		gp.log.Warn("message exceeds soft limit", "id", msg.Id, "queue", msg.Queue,
+			"limit", err.Limit.String(), "value", err.Value, "max", err.Max)
+	}
+	return gp.pusher.Push(ctx, msg, delay)
+}
+
+// Stats returns the current counters of the GuardPusher.
+func (gp *GuardPusher) Stats() GuardStats {
+	var ret GuardStats
+	for i := range ret.Warned {
+		ret.Warned[i] = gp.warned[i].Load()
+		ret.Rejected[i] = gp.rejected[i].Load()
+	}
+	return ret
+}
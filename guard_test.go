@@ -0,0 +1,54 @@
+package gqs_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/message"
+)
+
+func TestGuardPusher(t *testing.T) {
+	capture := &capturePusher{}
+	pusher := gqs.NewGuardPusher(capture, &gqs.GuardConfig{
+		Warn:   gqs.Limits{MaxPayload: 4},
+		Reject: gqs.Limits{MaxPayload: 8, MaxDelay: time.Hour},
+	}, slog.Default())
+	ctx := context.Background()
+
+	msg := message.NewMessage()
+	msg.Payload = []byte("123456")
+	if err := pusher.Push(ctx, msg, 0); err != nil {
+		t.Fatal(err)
+	}
+	if capture.msg.Id != msg.Id {
+		t.Fatal("expected message over soft limit to be pushed")
+	}
+
+	big := message.NewMessage()
+	big.Payload = []byte("123456789")
+	err := pusher.Push(ctx, big, 0)
+	var limitErr *gqs.LimitError
+	if !errors.As(err, &limitErr) || !errors.Is(err, gqs.ErrLimitExceeded) {
+		t.Fatalf("expected LimitError, got %v", err)
+	}
+	if limitErr.Limit != gqs.LimitPayload || limitErr.Value != 9 || limitErr.Max != 8 {
+		t.Fatalf("unexpected error %+v", limitErr)
+	}
+
+	late := message.NewMessage()
+	if err := pusher.Push(ctx, late, 2*time.Hour); !errors.Is(err, gqs.ErrLimitExceeded) {
+		t.Fatalf("expected delay to be rejected, got %v", err)
+	}
+	if capture.msg.Id != msg.Id {
+		t.Fatal("expected rejected messages not to be pushed")
+	}
+
+	stats := pusher.Stats()
+	if stats.Warned[gqs.LimitPayload] != 1 || stats.Rejected[gqs.LimitPayload] != 1 || stats.Rejected[gqs.LimitDelay] != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
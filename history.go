@@ -0,0 +1,18 @@
+package gqs
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs/job"
+)
+
+// HistoryObserver is an optional extension of Observer that returns
+// the recorded state transitions of a job, for investigating how it
+// reached its current state.
+type HistoryObserver interface {
+
+	// History returns the events of the job identified by id, oldest
+	// first. If the job is unknown or no events were recorded for it,
+	// History returns an empty slice.
+	History(ctx context.Context, id uuid.UUID) ([]*job.Event, error)
+}
@@ -0,0 +1,27 @@
+// Package httpapi exposes gqs enqueue and administrative operations
+// over HTTP with JSON bodies, for dashboards and curl-based operations.
+//
+// NewHandler serves the following routes:
+//
+//	POST   /messages           — push a message (see PushRequest)
+//	GET    /jobs/{id}          — get a job
+//	GET    /jobs               — list jobs (see ListResponse)
+//	POST   /jobs/{id}/requeue  — requeue a terminal job
+//	DELETE /jobs?id=...        — delete jobs by id
+//
+// GET /jobs accepts the query parameters status (a canonical status
+// name, such as "Dead"), queue, tenant and locked_by (a worker instance
+// id), all of which may be repeated, and limit, cursor and order
+// (created_asc, created_desc, updated_asc or updated_desc). Only status
+// and limit are supported if the observer does not implement
+// gqs.QueryObserver.
+//
+// Jobs are encoded as by encoding/json. Errors are reported as
+// {"error": "..."} with a status code derived from the gqs error:
+// 404 for ErrNotFound, 409 for ErrDuplicateID and ErrNotTerminal,
+// 400 for malformed requests, ErrBadStatus and ErrBadCursor, and 501
+// for operations the configured backend does not support.
+//
+// The handler performs no authentication; it should be mounted behind
+// the access control of the hosting application.
+package httpapi
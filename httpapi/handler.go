@@ -0,0 +1,392 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultMaxBodySize is the default limit of request body sizes.
+const DefaultMaxBodySize = 1 << 20
+
+var errUnsupported = errors.New("operation is not supported by the backend")
+
+// Config defines the backends and limits of the handler returned by
+// NewHandler.
+//
+// Each of Pusher, Observer and Admin may be nil; the routes backed by
+// a missing implementation respond with 501 Not Implemented.
+//
+// MaxBodySize limits the size of request bodies; zero means
+// DefaultMaxBodySize.
+type Config struct {
+	Pusher      gqs.Pusher
+	Observer    gqs.Observer
+	Admin       gqs.Admin
+	MaxBodySize int64
+}
+
+type handler struct {
+	pusher   gqs.Pusher
+	observer gqs.Observer
+	admin    gqs.Admin
+	maxBody  int64
+}
+
+// NewHandler returns an HTTP handler serving the routes described in
+// the package documentation.
+func NewHandler(cfg *Config) http.Handler {
+	h := &handler{
+		pusher:   cfg.Pusher,
+		observer: cfg.Observer,
+		admin:    cfg.Admin,
+		maxBody:  cfg.MaxBodySize,
+	}
+	if h.maxBody <= 0 {
+		h.maxBody = DefaultMaxBodySize
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /messages", h.push)
+	mux.HandleFunc("GET /jobs/{id}", h.get)
+	mux.HandleFunc("GET /jobs", h.list)
+	mux.HandleFunc("POST /jobs/{id}/requeue", h.requeue)
+	mux.HandleFunc("DELETE /jobs", h.delete)
+	return mux
+}
+
+// PushRequest is the request body of POST /messages.
+//
+// Id is optional; a random id is generated if it is empty. Payload is
+// encoded in base64, as by encoding/json. TTL, LockTimeout, Timeout and
+// Delay are durations in the format accepted by time.ParseDuration,
+// such as "1m30s". At, if set, schedules the message for the given
+// time instead of after Delay.
+type PushRequest struct {
+	Id            string         `json:"id,omitempty"`
+	Queue         string         `json:"queue,omitempty"`
+	TenantId      string         `json:"tenant_id,omitempty"`
+	Type          string         `json:"type,omitempty"`
+	Metadata      map[string]any `json:"metadata,omitempty"`
+	Payload       []byte         `json:"payload,omitempty"`
+	SchemaVersion uint32         `json:"schema_version,omitempty"`
+	Priority      int            `json:"priority,omitempty"`
+	Region        string         `json:"region,omitempty"`
+	OrderingKey   string         `json:"ordering_key,omitempty"`
+	TTL           string         `json:"ttl,omitempty"`
+	DependsOn     []uuid.UUID    `json:"depends_on,omitempty"`
+	MaxRetries    uint32         `json:"max_retries,omitempty"`
+	LockTimeout   string         `json:"lock_timeout,omitempty"`
+	Timeout       string         `json:"timeout,omitempty"`
+	Delay         string         `json:"delay,omitempty"`
+	At            *time.Time     `json:"at,omitempty"`
+}
+
+// PushResponse is the response body of POST /messages.
+type PushResponse struct {
+	Id uuid.UUID `json:"id"`
+}
+
+// ListResponse is the response body of GET /jobs.
+//
+// Next is the cursor of the next page; it is empty on the last page or
+// if the observer does not support pagination.
+type ListResponse struct {
+	Jobs []*job.Job `json:"jobs"`
+	Next string     `json:"next,omitempty"`
+}
+
+// AffectedResponse is the response body of requeue and delete
+// operations.
+type AffectedResponse struct {
+	Affected int64 `json:"affected"`
+}
+
+type badRequest struct {
+	err error
+}
+
+func (e *badRequest) Error() string {
+	return e.err.Error()
+}
+
+func (e *badRequest) Unwrap() error {
+	return e.err
+}
+
+func invalid(format string, args ...any) error {
+	return &badRequest{err: fmt.Errorf(format, args...)}
+}
+
+func statusOf(err error) int {
+	var bad *badRequest
+	switch {
+	case errors.As(err, &bad):
+		return http.StatusBadRequest
+	case errors.Is(err, errUnsupported):
+		return http.StatusNotImplemented
+	case errors.Is(err, gqs.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, gqs.ErrDuplicateID), errors.Is(err, gqs.ErrNotTerminal):
+		return http.StatusConflict
+	case errors.Is(err, gqs.ErrBadStatus), errors.Is(err, gqs.ErrBadCursor):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, statusOf(err), map[string]string{"error": err.Error()})
+}
+
+func parseDuration(name, raw string) (time.Duration, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	ret, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, invalid("bad %s: %w", name, err)
+	}
+	return ret, nil
+}
+
+func (r *PushRequest) message() (*message.Message, error) {
+	ret := &message.Message{
+		Queue:         r.Queue,
+		TenantId:      r.TenantId,
+		Type:          r.Type,
+		Metadata:      r.Metadata,
+		Payload:       r.Payload,
+		SchemaVersion: r.SchemaVersion,
+		Priority:      r.Priority,
+		Region:        r.Region,
+		OrderingKey:   r.OrderingKey,
+		DependsOn:     r.DependsOn,
+		MaxRetries:    r.MaxRetries,
+	}
+	ret.Id = uuid.New()
+	if r.Id != "" {
+		id, err := uuid.Parse(r.Id)
+		if err != nil {
+			return nil, invalid("bad id: %w", err)
+		}
+		ret.Id = id
+	}
+	var err error
+	if ret.TTL, err = parseDuration("ttl", r.TTL); err != nil {
+		return nil, err
+	}
+	if ret.LockTimeout, err = parseDuration("lock_timeout", r.LockTimeout); err != nil {
+		return nil, err
+	}
+	if ret.Timeout, err = parseDuration("timeout", r.Timeout); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (h *handler) push(w http.ResponseWriter, r *http.Request) {
+	if h.pusher == nil {
+		writeError(w, errUnsupported)
+		return
+	}
+	var req PushRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, h.maxBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, invalid("bad request body: %w", err))
+		return
+	}
+	msg, err := req.message()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	delay, err := parseDuration("delay", req.Delay)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if req.At != nil {
+		if scheduler, ok := h.pusher.(gqs.SchedulePusher); ok && gqs.Supports(h.pusher, gqs.CapSchedulePush) {
+			err = scheduler.PushAt(r.Context(), msg, *req.At)
+		} else {
+			err = h.pusher.Push(r.Context(), msg, max(time.Until(*req.At), 0))
+		}
+	} else {
+		err = h.pusher.Push(r.Context(), msg, delay)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, &PushResponse{Id: msg.Id})
+}
+
+func pathId(r *http.Request) (uuid.UUID, error) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		return uuid.Nil, invalid("bad id: %w", err)
+	}
+	return id, nil
+}
+
+func (h *handler) get(w http.ResponseWriter, r *http.Request) {
+	if h.observer == nil {
+		writeError(w, errUnsupported)
+		return
+	}
+	id, err := pathId(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	jb, err := gqs.GetExisting(r.Context(), h.observer, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, jb)
+}
+
+var orders = map[string]gqs.Order{
+	"created_asc":  gqs.OrderCreatedAsc,
+	"created_desc": gqs.OrderCreatedDesc,
+	"updated_asc":  gqs.OrderUpdatedAsc,
+	"updated_desc": gqs.OrderUpdatedDesc,
+}
+
+func listOptions(r *http.Request) (*gqs.ListOptions, error) {
+	query := r.URL.Query()
+	ret := &gqs.ListOptions{
+		Queues:   query["queue"],
+		Tenants:  query["tenant"],
+		LockedBy: query["locked_by"],
+		Cursor:   query.Get("cursor"),
+	}
+	for _, raw := range query["status"] {
+		status, err := job.ParseStatus(raw)
+		if err != nil {
+			return nil, invalid("bad status: %w", err)
+		}
+		ret.Statuses = append(ret.Statuses, status)
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, invalid("bad limit: %w", err)
+		}
+		ret.Limit = limit
+	}
+	if raw := query.Get("order"); raw != "" {
+		order, ok := orders[raw]
+		if !ok {
+			return nil, invalid("bad order %q", raw)
+		}
+		ret.Order = order
+	}
+	return ret, nil
+}
+
+func (h *handler) list(w http.ResponseWriter, r *http.Request) {
+	if h.observer == nil {
+		writeError(w, errUnsupported)
+		return
+	}
+	opts, err := listOptions(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if obs, ok := h.observer.(gqs.QueryObserver); ok && gqs.Supports(h.observer, gqs.CapQuery) {
+		page, err := obs.Query(r.Context(), opts)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, &ListResponse{Jobs: page.Jobs, Next: page.Next})
+		return
+	}
+	if len(opts.Statuses) > 1 || len(opts.Queues) != 0 || len(opts.Tenants) != 0 || len(opts.LockedBy) != 0 ||
+		opts.Cursor != "" || opts.Order != gqs.OrderCreatedAsc {
+		writeError(w, fmt.Errorf("%w: filtering requires a query observer", errUnsupported))
+		return
+	}
+	var status job.Status
+	if len(opts.Statuses) == 1 {
+		status = opts.Statuses[0]
+	}
+	jobs, err := h.observer.List(r.Context(), status, opts.Limit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, &ListResponse{Jobs: jobs})
+}
+
+func (h *handler) requeue(w http.ResponseWriter, r *http.Request) {
+	if h.admin == nil {
+		writeError(w, errUnsupported)
+		return
+	}
+	id, err := pathId(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	count, err := h.admin.RequeueByStatus(r.Context(), &gqs.ListOptions{Ids: []uuid.UUID{id}})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if count == 0 && h.observer != nil {
+		jb, err := gqs.GetExisting(r.Context(), h.observer, id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeError(w, fmt.Errorf("%w: %s is %s", gqs.ErrNotTerminal, id, jb.Status))
+		return
+	}
+	writeJSON(w, http.StatusOK, &AffectedResponse{Affected: count})
+}
+
+func (h *handler) delete(w http.ResponseWriter, r *http.Request) {
+	if h.admin == nil {
+		writeError(w, errUnsupported)
+		return
+	}
+	raw := r.URL.Query()["id"]
+	if len(raw) == 0 {
+		writeError(w, invalid("at least one id is required"))
+		return
+	}
+	ids := make([]uuid.UUID, len(raw))
+	for i, value := range raw {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			writeError(w, invalid("bad id: %w", err))
+			return
+		}
+		ids[i] = id
+	}
+	count, err := h.admin.DeleteByIds(r.Context(), ids)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, &AffectedResponse{Affected: count})
+}
@@ -0,0 +1,121 @@
+package httpapi_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs/httpapi"
+	"github.com/romanqed/gqs/job"
+	gsql "github.com/romanqed/gqs/sql"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+
+	_ "modernc.org/sqlite"
+)
+
+func newTestDB(t *testing.T) *bun.DB {
+	t.Helper()
+	sqlDB, err := sql.Open("sqlite", "file::memory:?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1) // important for sqlite
+	db := bun.NewDB(sqlDB, sqlitedialect.New())
+	if err := gsql.InitDB(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func do(t *testing.T, h http.Handler, method, target, body string, out any) int {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if out != nil && rec.Code < 300 {
+		if err := json.NewDecoder(rec.Body).Decode(out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return rec.Code
+}
+
+func TestHandler(t *testing.T) {
+	db := newTestDB(t)
+	h := httpapi.NewHandler(&httpapi.Config{
+		Pusher:   gsql.NewPusher(db),
+		Observer: gsql.NewObserver(db),
+		Admin:    gsql.NewAdmin(db),
+	})
+	puller := gsql.NewPuller(db)
+
+	var pushed httpapi.PushResponse
+	body := `{"queue":"emails","type":"welcome","payload":"aGVsbG8=","metadata":{"tenant":"acme"},"ttl":"1h"}`
+	if code := do(t, h, http.MethodPost, "/messages", body, &pushed); code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", code)
+	}
+	duplicate := `{"id":"` + pushed.Id.String() + `"}`
+	if code := do(t, h, http.MethodPost, "/messages", duplicate, nil); code != http.StatusConflict {
+		t.Fatalf("expected 409 for a duplicate id, got %d", code)
+	}
+	if code := do(t, h, http.MethodPost, "/messages", `{"delay":"soon"}`, nil); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad delay, got %d", code)
+	}
+
+	var j job.Job
+	if code := do(t, h, http.MethodGet, "/jobs/"+pushed.Id.String(), "", &j); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if j.Queue != "emails" || string(j.Payload) != "hello" || j.Status != job.Pending || j.ExpiresAt == nil {
+		t.Fatalf("unexpected job %+v", j)
+	}
+	if code := do(t, h, http.MethodGet, "/jobs/"+uuid.NewString(), "", nil); code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", code)
+	}
+
+	var list httpapi.ListResponse
+	if code := do(t, h, http.MethodGet, "/jobs?status=Pending&queue=emails", "", &list); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if len(list.Jobs) != 1 || list.Jobs[0].Id != pushed.Id {
+		t.Fatalf("unexpected list %+v", list)
+	}
+
+	target := "/jobs/" + pushed.Id.String() + "/requeue"
+	if code := do(t, h, http.MethodPost, target, "", nil); code != http.StatusConflict {
+		t.Fatalf("expected 409 when requeueing a Pending job, got %d", code)
+	}
+	jobs, _ := puller.Pull(context.Background(), 1, 0)
+	if len(jobs) != 1 {
+		t.Fatal("expected job to be pulled")
+	}
+	_ = puller.Complete(context.Background(), jobs[0])
+
+	var affected httpapi.AffectedResponse
+	if code := do(t, h, http.MethodPost, target, "", &affected); code != http.StatusOK || affected.Affected != 1 {
+		t.Fatalf("expected job to be requeued, got %d, %+v", code, affected)
+	}
+
+	if code := do(t, h, http.MethodDelete, "/jobs", "", nil); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without ids, got %d", code)
+	}
+	if code := do(t, h, http.MethodDelete, "/jobs?id="+pushed.Id.String(), "", &affected); code != http.StatusOK || affected.Affected != 1 {
+		t.Fatalf("expected job to be deleted, got %d, %+v", code, affected)
+	}
+}
+
+func TestHandlerUnsupported(t *testing.T) {
+	h := httpapi.NewHandler(&httpapi.Config{})
+	if code := do(t, h, http.MethodPost, "/messages", `{}`, nil); code != http.StatusNotImplemented {
+		t.Fatalf("expected 501, got %d", code)
+	}
+	if code := do(t, h, http.MethodGet, "/jobs", "", nil); code != http.StatusNotImplemented {
+		t.Fatalf("expected 501, got %d", code)
+	}
+}
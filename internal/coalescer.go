@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"context"
+	"time"
+)
+
+type BatchHandler[T any] func(context.Context, []T) []error
+
+type coalesced[T any] struct {
+	item T
+	ret  chan error
+}
+
+type Coalescer[T any] struct {
+	window time.Duration
+	limit  int
+	in     chan coalesced[T]
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   DoneChan
+}
+
+func NewCoalescer[T any](window time.Duration, limit int) *Coalescer[T] {
+	return &Coalescer[T]{
+		window: window,
+		limit:  limit,
+	}
+}
+
+func (c *Coalescer[T]) flush(ctx context.Context, h BatchHandler[T], batch []coalesced[T]) {
+	items := make([]T, len(batch))
+	for i, entry := range batch {
+		items[i] = entry.item
+	}
+	errs := h(ctx, items)
+	for i, entry := range batch {
+		entry.ret <- errs[i]
+	}
+}
+
+func (c *Coalescer[T]) collect(ctx context.Context, first coalesced[T]) ([]coalesced[T], bool) {
+	batch := []coalesced[T]{first}
+	timer := time.NewTimer(c.window)
+	defer timer.Stop()
+	for len(batch) < c.limit {
+		select {
+		case <-ctx.Done():
+			for _, entry := range batch {
+				entry.ret <- ctx.Err()
+			}
+			return nil, false
+		case entry := <-c.in:
+			batch = append(batch, entry)
+		case <-timer.C:
+			return batch, true
+		}
+	}
+	return batch, true
+}
+
+func (c *Coalescer[T]) do(ctx context.Context, h BatchHandler[T]) {
+	defer close(c.done)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case entry := <-c.in:
+			batch, ok := c.collect(ctx, entry)
+			if !ok {
+				return
+			}
+			c.flush(ctx, h, batch)
+		}
+	}
+}
+
+func (c *Coalescer[T]) Submit(ctx context.Context, t T) error {
+	entry := coalesced[T]{item: t, ret: make(chan error, 1)}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.ctx.Done():
+		return c.ctx.Err()
+	case c.in <- entry:
+	}
+	// the handler may still be using t, so the result is awaited even
+	// if ctx is done; every accepted entry receives one
+	return <-entry.ret
+}
+
+func (c *Coalescer[T]) Start(ctx context.Context, h BatchHandler[T]) {
+	c.done = make(DoneChan)
+	c.in = make(chan coalesced[T])
+	c.ctx, c.cancel = context.WithCancel(ctx)
+	go c.do(c.ctx, h)
+}
+
+func (c *Coalescer[T]) Stop() DoneChan {
+	c.cancel()
+	return c.done
+}
@@ -15,11 +15,12 @@ func wrapWaitGroup(wg *sync.WaitGroup) DoneChan {
 	return ret
 }
 
-func Combine(first DoneChan, second DoneChan) DoneChan {
+func Combine(chans ...DoneChan) DoneChan {
 	ret := make(DoneChan)
 	go func() {
-		<-first
-		<-second
+		for _, ch := range chans {
+			<-ch
+		}
 		close(ret)
 	}()
 	return ret
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter is a keyed token bucket limiter. Every key has its own
+// bucket of the same rate and burst.
+type RateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		rate:    rate,
+		burst:   float64(max(burst, 1)),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// reserve takes a token from the bucket of key, possibly going into
+// debt, and returns how long the caller must wait before using it.
+func (rl *RateLimiter) reserve(key string, now time.Time) time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.sweep(now)
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &bucket{tokens: rl.burst, last: now}
+		rl.buckets[key] = b
+	}
+	b.tokens = min(rl.burst, b.tokens+now.Sub(b.last).Seconds()*rl.rate)
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / rl.rate * float64(time.Second))
+}
+
+// sweep drops buckets that refilled to burst, as they are equivalent
+// to new ones. It runs at most once per the time a bucket takes to
+// refill from empty, so its cost is amortized over reservations.
+func (rl *RateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.swept).Seconds()*rl.rate < rl.burst {
+		return
+	}
+	rl.swept = now
+	for key, b := range rl.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*rl.rate >= rl.burst {
+			delete(rl.buckets, key)
+		}
+	}
+}
+
+func (rl *RateLimiter) cancel(key string) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if b, ok := rl.buckets[key]; ok {
+		b.tokens = min(rl.burst, b.tokens+1)
+	}
+}
+
+// Wait blocks until a token of the bucket of key is available or ctx
+// is done. If ctx is done first, the token is given back.
+func (rl *RateLimiter) Wait(ctx context.Context, key string) error {
+	delay := rl.reserve(key, time.Now())
+	if delay == 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		rl.cancel(key)
+		return ctx.Err()
+	}
+}
@@ -8,29 +8,59 @@ import (
 type TimerHandler func(context.Context)
 
 type TimerTask struct {
-	cancel context.CancelFunc
-	done   DoneChan
+	cancel   context.CancelFunc
+	done     DoneChan
+	wake     chan struct{}
+	interval time.Duration
 }
 
-func (t *TimerTask) do(ctx context.Context, h TimerHandler, timeout time.Duration) {
+func (t *TimerTask) do(ctx context.Context, h TimerHandler) {
 	defer close(t.done)
-	ticker := time.NewTicker(timeout)
+	current := t.interval
+	ticker := time.NewTicker(current)
 	defer ticker.Stop()
-	h(ctx)
+	run := func() {
+		h(ctx)
+		if t.interval != current {
+			current = t.interval
+			ticker.Reset(current)
+		}
+	}
+	run()
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			h(ctx)
+			run()
+		case <-t.wake:
+			run()
+			ticker.Reset(current)
 		}
 	}
 }
 
 func (t *TimerTask) Start(ctx context.Context, h TimerHandler, timeout time.Duration) {
 	t.done = make(DoneChan)
+	t.wake = make(chan struct{}, 1)
+	t.interval = timeout
 	ctx, t.cancel = context.WithCancel(ctx)
-	go t.do(ctx, h, timeout)
+	go t.do(ctx, h)
+}
+
+// SetInterval changes the interval between runs, taking effect after
+// the current run. It must only be called from the handler.
+func (t *TimerTask) SetInterval(interval time.Duration) {
+	t.interval = interval
+}
+
+// Trigger runs the handler as soon as possible without waiting for the
+// next tick. Triggers arriving while the handler runs are coalesced.
+func (t *TimerTask) Trigger() {
+	select {
+	case t.wake <- struct{}{}:
+	default:
+	}
 }
 
 func (t *TimerTask) Stop() DoneChan {
@@ -2,23 +2,32 @@ package internal
 
 import (
 	"context"
-	"log/slog"
 	"sync"
+	"sync/atomic"
 )
 
+// Logger is the subset of gqs.Logger used by WorkerPool.
+type Logger interface {
+	Error(msg string, args ...any)
+}
+
 type WorkHandler[T any] func(context.Context, T)
 
 type WorkerPool[T any] struct {
 	concurrency int
 	queue       int
 	wg          sync.WaitGroup
+	busy        *sync.WaitGroup
+	pending     atomic.Int64
 	in          chan T
+	leftMutex   sync.Mutex
+	left        []T
 	ctx         context.Context
 	cancel      context.CancelFunc
-	log         *slog.Logger
+	log         Logger
 }
 
-func NewWorkerPool[T any](concurrency int, queue int, log *slog.Logger) *WorkerPool[T] {
+func NewWorkerPool[T any](concurrency int, queue int, log Logger) *WorkerPool[T] {
 	return &WorkerPool[T]{
 		concurrency: concurrency,
 		queue:       queue,
@@ -42,23 +51,68 @@ func (wp *WorkerPool[T]) worker(ctx context.Context, wh WorkHandler[T]) {
 		case <-ctx.Done():
 			return
 		case t := <-wp.in:
+			// select picks randomly among ready cases, so an item may
+			// be received after the pool was stopped
+			if ctx.Err() != nil {
+				wp.leftMutex.Lock()
+				wp.left = append(wp.left, t)
+				wp.leftMutex.Unlock()
+				return
+			}
 			wp.safeHandle(ctx, wh, t)
+			wp.pending.Add(-1)
+			wp.busy.Done()
 		}
 	}
 }
 
 func (wp *WorkerPool[T]) Push(t T) bool {
+	wp.busy.Add(1)
+	wp.pending.Add(1)
 	select {
 	case <-wp.ctx.Done():
+		wp.pending.Add(-1)
+		wp.busy.Done()
 		return false
 	case wp.in <- t:
 		return true
 	}
 }
 
+func (wp *WorkerPool[T]) TryPush(t T) bool {
+	wp.busy.Add(1)
+	wp.pending.Add(1)
+	select {
+	case <-wp.ctx.Done():
+		wp.pending.Add(-1)
+		wp.busy.Done()
+		return false
+	case wp.in <- t:
+		return true
+	default:
+		wp.pending.Add(-1)
+		wp.busy.Done()
+		return false
+	}
+}
+
+// Free returns the number of items the pool can accept without
+// blocking or waiting behind other items: idle workers plus free queue
+// slots.
+func (wp *WorkerPool[T]) Free() int {
+	return max(wp.concurrency+wp.queue-int(wp.pending.Load()), 0)
+}
+
+// Pending returns the number of accepted items not handled yet,
+// including the items being handled.
+func (wp *WorkerPool[T]) Pending() int {
+	return int(wp.pending.Load())
+}
+
 func (wp *WorkerPool[T]) Start(ctx context.Context, wh WorkHandler[T]) {
 	wp.ctx, wp.cancel = context.WithCancel(ctx)
 	wp.in = make(chan T, wp.queue)
+	wp.busy = &sync.WaitGroup{}
 	for i := 0; i < wp.concurrency; i++ {
 		wp.wg.Add(1)
 		go wp.worker(wp.ctx, wh)
@@ -69,3 +123,33 @@ func (wp *WorkerPool[T]) Stop() DoneChan {
 	wp.cancel()
 	return wrapWaitGroup(&wp.wg)
 }
+
+// Abandoned removes and returns the accepted items left unhandled by
+// a stopped pool. The caller must ensure the workers have exited and
+// no more items are pushed.
+func (wp *WorkerPool[T]) Abandoned() []T {
+	wp.leftMutex.Lock()
+	ret := wp.left
+	wp.left = nil
+	wp.leftMutex.Unlock()
+	for range ret {
+		wp.pending.Add(-1)
+		wp.busy.Done()
+	}
+	for {
+		select {
+		case t := <-wp.in:
+			ret = append(ret, t)
+			wp.pending.Add(-1)
+			wp.busy.Done()
+		default:
+			return ret
+		}
+	}
+}
+
+// Drain returns a channel closed once every accepted item has been
+// handled. The caller must ensure no more items are pushed.
+func (wp *WorkerPool[T]) Drain() DoneChan {
+	return wrapWaitGroup(wp.busy)
+}
@@ -0,0 +1,32 @@
+package job
+
+import "time"
+
+// Diagnostics holds extended information captured for a failed
+// attempt of a job, for investigating rare failures.
+//
+// Time records when the failure occurred and Attempt the attempt
+// number that failed. Error is the text of the handler error.
+//
+// Payload and Metadata are snapshots of the message as the handler
+// received it.
+//
+// QueueWait is the time the job spent between being claimed and the
+// start of its handler. Duration is the wall-clock duration of the
+// handler.
+//
+// Stack holds the stack traces of all goroutines at the time of the
+// failure, possibly truncated.
+//
+// Attrs holds optional application-defined attributes.
+type Diagnostics struct {
+	Time      time.Time      `json:"time"`
+	Attempt   uint32         `json:"attempt"`
+	Error     string         `json:"error"`
+	Payload   []byte         `json:"payload,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+	QueueWait time.Duration  `json:"queue_wait"`
+	Duration  time.Duration  `json:"duration"`
+	Stack     string         `json:"stack,omitempty"`
+	Attrs     map[string]any `json:"attrs,omitempty"`
+}
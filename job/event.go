@@ -0,0 +1,20 @@
+package job
+
+import "time"
+
+// Event records a single state transition of a job.
+//
+// Time records when the transition happened.
+// From and To are the statuses before and after the transition; From
+// is Unknown for the event recording the creation of the job. A
+// redelivery of a job whose lease expired is recorded as an event
+// from Processing to Processing with increased Attempts.
+// Attempts is the attempt count after the transition.
+// Error holds the error text that caused the transition, if known.
+type Event struct {
+	Time     time.Time
+	From     Status
+	To       Status
+	Attempts uint32
+	Error    string
+}
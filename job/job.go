@@ -18,6 +18,45 @@ import (
 // LockedUntil defines the visibility timeout; while set and in the future,
 // the job is considered owned by a worker.
 // NextRunAt specifies the earliest time the job may be pulled.
+// ScheduledAt records the time the job was originally scheduled for
+// when it was pushed; unlike NextRunAt, it is not changed by retries.
+// ExpiresAt is ScheduledAt + TTL for messages with a TTL, nil otherwise;
+// once it passes, the job is no longer pulled.
+// LockedBy identifies the worker instance that last pulled the job,
+// if the storage records it (see gqs.Registry).
+// LockLosses counts how many times a worker lost the lease of the job
+// while handling it (see gqs.LockLossRecorder).
+// CancelRequested reports whether cancellation of the Processing job
+// was requested (see gqs.Canceler); the worker handling it aborts it
+// at its next lease extension.
+// Version is incremented by the storage on every update of the job.
+// Backends supporting it perform transitions only if the stored
+// version still equals the version of the snapshot, so that a stale
+// snapshot, for example of a worker whose lease expired and whose job
+// was pulled again, can never change the job. Snapshots are advanced
+// by the transitions performed through them.
+// LastError holds the text of the handler error of the most recent
+// failed attempt, persisted by Return and Kill. It is empty if no
+// attempt has failed.
+//
+// WaitTime is the queueing delay of the current attempt: the time
+// between max(CreatedAt, NextRunAt) and the moment the job was pulled.
+// It is computed by Puller.Pull and not persisted, so it is zero in
+// snapshots returned by other methods.
+//
+// RetainDone, RetainDead and RetainCancelled hold how long the job is
+// kept once it ended with the respective status (see
+// gqs.RetentionConfig). Zero leaves the job to the policy of
+// gqs.CleanWorker.
+//
+// Result holds the output stored by the handler on successful
+// completion (see gqs.SetResult). It is nil if no result was stored.
+//
+// Logs holds the most recent log lines written by handlers across
+// all attempts (see gqs.SaveLog), oldest first.
+//
+// Diagnostics holds extended diagnostics of the most recent sampled
+// failure (see gqs.DiagnosticsConfig). It is nil if none was captured.
 //
 // Job instances should be treated as snapshots of storage state.
 // Mutating fields directly does not change the underlying queue state;
@@ -28,8 +67,24 @@ type Job struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
-	Status      Status
-	Attempts    uint32
-	LockedUntil *time.Time
-	NextRunAt   time.Time
+	Status          Status
+	Attempts        uint32
+	LockedUntil     *time.Time
+	NextRunAt       time.Time
+	ScheduledAt     time.Time
+	ExpiresAt       *time.Time
+	LockedBy        string
+	LockLosses      uint32
+	CancelRequested bool
+	Version         uint64
+	LastError       string
+	WaitTime        time.Duration
+
+	RetainDone      time.Duration
+	RetainDead      time.Duration
+	RetainCancelled time.Duration
+
+	Result      []byte
+	Logs        []LogLine
+	Diagnostics *Diagnostics
 }
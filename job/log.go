@@ -0,0 +1,17 @@
+package job
+
+import "time"
+
+// LogLine is a structured log record written by a handler while
+// processing a job.
+//
+// Time records when the line was written.
+// Attempt is the attempt number the line belongs to.
+// Message is the log message.
+// Attrs holds optional structured attributes.
+type LogLine struct {
+	Time    time.Time      `json:"time"`
+	Attempt uint32         `json:"attempt"`
+	Message string         `json:"message"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+}
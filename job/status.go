@@ -6,10 +6,16 @@ import "fmt"
 //
 // The state machine is:
 //
+//	Scheduled  -> Pending   (once NextRunAt passes)
+//	Scheduled  -> Processing
 //	Pending    -> Processing
 //	Processing -> Done
 //	Processing -> Pending   (via Return)
+//	Processing -> Scheduled (via Return with a backoff)
 //	Processing -> Dead
+//	Processing -> Cancelled
+//	Pending    -> Cancelled
+//	Scheduled  -> Cancelled
 //
 // Unknown is reserved as a zero value and may be used to indicate
 // an unspecified or invalid state in filtering contexts.
@@ -21,7 +27,9 @@ const (
 	Unknown Status = iota
 
 	// Pending indicates that the job is available for pulling.
-	// A Pending job may have a future NextRunAt, delaying execution.
+	//
+	// Jobs stored before Scheduled was introduced may be Pending with
+	// a future NextRunAt, delaying execution.
 	Pending
 
 	// Processing indicates that the job has been pulled and is currently
@@ -36,8 +44,37 @@ const (
 	// Dead indicates that the job has permanently failed and will not
 	// be retried.
 	Dead
+
+	// Cancelled indicates that the job was intentionally aborted from
+	// the outside (see gqs.Canceler) and will not be executed again.
+	// Unlike Dead, it does not denote a failure of the job.
+	Cancelled
+
+	// Scheduled indicates that the job waits for a NextRunAt in the
+	// future, for example a delayed push or a retry backoff. Once
+	// NextRunAt passes, storage may promote it to Pending; a due
+	// Scheduled job is pulled like a Pending one.
+	//
+	// Telling Scheduled jobs apart from Pending ones separates the
+	// deferred backlog from the backlog ready to run.
+	Scheduled
 )
 
+// Waiting reports whether s is a state of a job waiting to be pulled,
+// that is Pending or Scheduled.
+func (s Status) Waiting() bool {
+	return s == Pending || s == Scheduled
+}
+
+// Terminal reports whether s is a final state of the job lifecycle,
+// that is Done, Dead or Cancelled.
+func (s Status) Terminal() bool {
+	return s == Done || s == Dead || s == Cancelled
+}
+
+// TerminalStatuses lists all terminal statuses.
+var TerminalStatuses = []Status{Done, Dead, Cancelled}
+
 func statusToString(status Status) string {
 	switch status {
 	case Pending:
@@ -48,6 +85,10 @@ func statusToString(status Status) string {
 		return "Done"
 	case Dead:
 		return "Dead"
+	case Cancelled:
+		return "Cancelled"
+	case Scheduled:
+		return "Scheduled"
 	default:
 		return "Unknown"
 	}
@@ -63,6 +104,10 @@ func statusFromString(status string) (Status, error) {
 		return Done, nil
 	case "Dead":
 		return Dead, nil
+	case "Cancelled":
+		return Cancelled, nil
+	case "Scheduled":
+		return Scheduled, nil
 	case "Unknown":
 		return Unknown, nil
 	default:
@@ -78,6 +123,8 @@ func statusFromString(status string) (Status, error) {
 //	"Processing"
 //	"Done"
 //	"Dead"
+//	"Cancelled"
+//	"Scheduled"
 //	"Unknown"
 //
 // An error is returned for unrecognized strings.
@@ -0,0 +1,45 @@
+package gqs
+
+import (
+	"context"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+)
+
+// JobHandler is a handler receiving the whole job instead of its
+// message, so that it can see delivery metadata such as Attempts,
+// MaxRetries, CreatedAt or NextRunAt, for example to behave differently
+// on the last attempt.
+//
+// JobHandler follows the error contract of MessageHandler.
+type JobHandler func(ctx context.Context, jb *job.Job) error
+
+// HandleJob wraps fn into a MessageHandler passing it the job being
+// handled.
+//
+// The job is a copy of the pulled job taken before the handler is
+// invoked, so lease extensions running meanwhile do not change it, and
+// modifying it affects neither storage nor the worker. If a middleware replaced the message, the job carries
+// the replaced message. If the handler is not invoked by Worker or
+// Replay, fn receives a job holding only the message.
+func HandleJob(fn JobHandler) MessageHandler {
+	return func(ctx context.Context, msg *message.Message) error {
+		return fn(ctx, jobOf(ctx, msg))
+	}
+}
+
+// NewJobWorker creates a Worker invoking a JobHandler, as NewWorker
+// with HandleJob(handler).
+func NewJobWorker(puller Puller, handler JobHandler, config *WorkerConfig, log Logger) *Worker {
+	return NewWorker(puller, HandleJob(handler), config, log)
+}
+
+func jobOf(ctx context.Context, msg *message.Message) *job.Job {
+	at, ok := attemptFrom(ctx)
+	if !ok || at.job == nil {
+		return &job.Job{Message: *msg}
+	}
+	ret := *at.job
+	ret.Message = *msg
+	return &ret
+}
@@ -0,0 +1,57 @@
+package gqs
+
+import (
+	"context"
+	"github.com/romanqed/gqs/job"
+	"time"
+)
+
+// StatsWindows are the windows over which StatsObserver implementations
+// measure throughput.
+var StatsWindows = []time.Duration{time.Minute, 5 * time.Minute, time.Hour}
+
+// Throughput counts the jobs that finished within a recent window.
+//
+// Done and Dead are the numbers of jobs whose last transition to the
+// respective status happened within Window before the measurement.
+type Throughput struct {
+	Window time.Duration `json:"window"`
+	Done   int64         `json:"done"`
+	Dead   int64         `json:"dead"`
+}
+
+// Rate returns the number of jobs completed per second within the window.
+func (t Throughput) Rate() float64 {
+	if t.Window <= 0 {
+		return 0
+	}
+	return float64(t.Done) / t.Window.Seconds()
+}
+
+// JobStats summarizes the state of all jobs for health checks.
+//
+// Counts holds the number of jobs per status; the number of dead jobs
+// is Counts[job.Dead].
+//
+// OldestPending is the age of the oldest Pending job, measured from its
+// creation; it is zero if there is no Pending job.
+//
+// AvgAttempts is the mean number of attempts of terminal (Done, Dead or
+// Cancelled) jobs, or zero if there are none.
+//
+// Throughput holds one entry per window of StatsWindows, in the same
+// order. Durations are encoded in nanoseconds.
+type JobStats struct {
+	Counts        map[job.Status]int64 `json:"counts"`
+	OldestPending time.Duration        `json:"oldest_pending"`
+	AvgAttempts   float64              `json:"avg_attempts"`
+	Throughput    []Throughput         `json:"throughput"`
+}
+
+// StatsObserver is an optional extension of Observer computing job
+// statistics with aggregate queries, without listing jobs.
+type StatsObserver interface {
+
+	// Stats returns the current statistics of all jobs.
+	Stats(ctx context.Context) (*JobStats, error)
+}
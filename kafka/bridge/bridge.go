@@ -0,0 +1,244 @@
+// Package bridge consumes messages from an Apache Kafka topic and
+// pushes them into a gqs queue, so that pipelines ingesting from Kafka
+// get the retry and visibility timeout semantics of gqs for processing.
+//
+// A Bridge commits the Kafka offset of a record only after the
+// corresponding message is pushed, so every record is pushed at least
+// once. DefaultMapper derives message ids from the record position, so
+// that a record redelivered after a crash is rejected by the Pusher
+// with gqs.ErrDuplicateID instead of being enqueued twice.
+//
+// Outcomes of the pushed jobs can be published back to Kafka with an
+// event sink (see NewEventSink) driven by gqs.RelayWorker.
+package bridge
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	gkafka "github.com/romanqed/gqs/kafka"
+	"github.com/romanqed/gqs/message"
+	"github.com/segmentio/kafka-go"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultRetryInterval is the delay between attempts to fetch or push
+// a record used when Config.RetryInterval is zero.
+const DefaultRetryInterval = time.Second
+
+// Metadata keys set by DefaultMapper.
+const (
+	MetaTopic     = "kafka_topic"
+	MetaPartition = "kafka_partition"
+	MetaOffset    = "kafka_offset"
+)
+
+// Reader is the subset of *kafka.Reader used by Bridge. The reader
+// must be configured with a consumer group, so that offsets can be
+// committed.
+type Reader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// Mapper converts a Kafka record into a message to push. A Mapper
+// returning an error marks the record as poison: it is logged, skipped
+// and committed.
+type Mapper func(record kafka.Message) (*message.Message, error)
+
+// namespace scopes the ids derived by DefaultMapper.
+var namespace = uuid.MustParse("6f1c1d2e-8a0b-4c5e-9f3a-2b7d4e6a8c10")
+
+// DefaultMapper maps the value of a record to the message payload and
+// its headers to metadata, adding the topic, partition and offset of
+// the record under MetaTopic, MetaPartition and MetaOffset.
+//
+// The message id is derived from the topic, partition and offset, so
+// that it is stable across redeliveries of the record.
+func DefaultMapper(record kafka.Message) (*message.Message, error) {
+	position := record.Topic + "/" + strconv.Itoa(record.Partition) + "/" +
+		strconv.FormatInt(record.Offset, 10)
+	ret := &message.Message{
+		Id:      uuid.NewSHA1(namespace, []byte(position)),
+		Payload: record.Value,
+	}
+	for _, header := range record.Headers {
+		ret.Set(header.Key, string(header.Value))
+	}
+	ret.Set(MetaTopic, record.Topic)
+	ret.Set(MetaPartition, record.Partition)
+	ret.Set(MetaOffset, record.Offset)
+	return ret, nil
+}
+
+// Config defines the behavior of a Bridge.
+//
+// Queue is the queue messages are pushed into, unless the Mapper sets
+// one.
+//
+// Mapper converts records into messages; DefaultMapper is used if nil.
+//
+// RetryInterval is the delay between attempts to fetch or push a record
+// after a failure; DefaultRetryInterval is used if zero. A record is
+// retried until it is pushed or the bridge is stopped, so that records
+// are never lost and their order within a partition is preserved.
+type Config struct {
+	Queue         string
+	Mapper        Mapper
+	RetryInterval time.Duration
+}
+
+// Bridge consumes records from a Kafka reader and pushes them into
+// gqs. It implements gqs.Service.
+//
+// Bridge has a strict lifecycle:
+//   - Start may only be called once.
+//   - Stop must be called to terminate the bridge.
+type Bridge struct {
+	reader   Reader
+	pusher   gqs.Pusher
+	log      gqs.Logger
+	queue    string
+	mapper   Mapper
+	interval time.Duration
+	running  atomic.Bool
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// New creates a new Bridge pushing records of reader into pusher.
+//
+// The bridge is not started automatically. Call Start to begin
+// consuming. If log is nil, nothing is logged.
+func New(reader Reader, pusher gqs.Pusher, config *Config, log gqs.Logger) *Bridge {
+	mapper := config.Mapper
+	if mapper == nil {
+		mapper = DefaultMapper
+	}
+	interval := config.RetryInterval
+	if interval <= 0 {
+		interval = DefaultRetryInterval
+	}
+	if log == nil {
+		log = gqs.NopLogger{}
+	}
+	return &Bridge{
+		reader:   reader,
+		pusher:   pusher,
+		log:      log,
+		queue:    config.Queue,
+		mapper:   mapper,
+		interval: interval,
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (b *Bridge) push(ctx context.Context, record kafka.Message) error {
+	msg, err := b.mapper(record)
+	if err != nil {
+		b.log.Error("skipping kafka record", "topic", record.Topic,
+			"partition", record.Partition, "offset", record.Offset, "err", err)
+		return nil
+	}
+	if msg.Queue == "" {
+		msg.Queue = b.queue
+	}
+	for {
+		err := b.pusher.Push(ctx, msg, 0)
+		if err == nil || errors.Is(err, gqs.ErrDuplicateID) {
+			return nil
+		}
+		b.log.Error("cannot push kafka record", "id", msg.Id, "err", err)
+		if !sleep(ctx, b.interval) {
+			return ctx.Err()
+		}
+	}
+}
+
+func (b *Bridge) consume(ctx context.Context) {
+	for {
+		record, err := b.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			b.log.Error("cannot fetch kafka record", "err", err)
+			if !sleep(ctx, b.interval) {
+				return
+			}
+			continue
+		}
+		if err := b.push(ctx, record); err != nil {
+			return
+		}
+		if err := b.reader.CommitMessages(ctx, record); err != nil && ctx.Err() == nil {
+			// the record is pushed again after a restart and then
+			// rejected as a duplicate by the Pusher
+			b.log.Error("cannot commit kafka record", "offset", record.Offset, "err", err)
+		}
+	}
+}
+
+// Start begins consuming records in a background goroutine.
+//
+// Start returns gqs.ErrDoubleStarted if the bridge is already running.
+// The provided context controls cancellation of the consumer.
+func (b *Bridge) Start(ctx context.Context) error {
+	if !b.running.CompareAndSwap(false, true) {
+		return gqs.ErrDoubleStarted
+	}
+	ctx, b.cancel = context.WithCancel(ctx)
+	b.done = make(chan struct{})
+	go func() {
+		defer close(b.done)
+		b.consume(ctx)
+	}()
+	return nil
+}
+
+// Stop stops consuming and waits until the record in flight is pushed
+// or abandoned, or the timeout expires, in which case
+// gqs.ErrStopTimeout is returned. An abandoned record is not committed
+// and is consumed again after a restart.
+//
+// Stop returns gqs.ErrDoubleStopped if the bridge is not running.
+func (b *Bridge) Stop(timeout time.Duration) error {
+	if !b.running.CompareAndSwap(true, false) {
+		return gqs.ErrDoubleStopped
+	}
+	b.cancel()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-b.done:
+		return nil
+	case <-timer.C:
+		return gqs.ErrStopTimeout
+	}
+}
+
+// NewEventSink creates a gqs.TransitionSink publishing the outcomes of
+// jobs, that is their transitions to terminal statuses (see
+// job.TerminalStatuses), to the topic of writer. Other transitions are skipped.
+//
+// Use it with gqs.RelayWorker over an outbox to report the results of
+// bridged messages back to Kafka.
+func NewEventSink(writer gkafka.Writer) *gkafka.Sink {
+	return gkafka.NewSinkWithOptions(writer, &gkafka.SinkOptions{
+		Statuses: job.TerminalStatuses,
+	})
+}
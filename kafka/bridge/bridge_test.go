@@ -0,0 +1,146 @@
+package bridge_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/kafka/bridge"
+	"github.com/romanqed/gqs/message"
+	"github.com/segmentio/kafka-go"
+)
+
+type fakeReader struct {
+	records chan kafka.Message
+	mu      sync.Mutex
+	commits []int64
+}
+
+func (fr *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	select {
+	case <-ctx.Done():
+		return kafka.Message{}, ctx.Err()
+	case record := <-fr.records:
+		return record, nil
+	}
+}
+
+func (fr *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	for _, msg := range msgs {
+		fr.commits = append(fr.commits, msg.Offset)
+	}
+	return nil
+}
+
+func (fr *fakeReader) committed() []int64 {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	return append([]int64(nil), fr.commits...)
+}
+
+type fakePusher struct {
+	mu       sync.Mutex
+	failures int
+	msgs     map[uuid.UUID]*message.Message
+}
+
+func (fp *fakePusher) Push(ctx context.Context, msg *message.Message, delay time.Duration) error {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+	if fp.failures > 0 {
+		fp.failures--
+		return errors.New("database unavailable")
+	}
+	if _, ok := fp.msgs[msg.Id]; ok {
+		return gqs.ErrDuplicateID
+	}
+	fp.msgs[msg.Id] = msg
+	return nil
+}
+
+func (fp *fakePusher) count() int {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+	return len(fp.msgs)
+}
+
+func TestBridge(t *testing.T) {
+	reader := &fakeReader{records: make(chan kafka.Message, 10)}
+	pusher := &fakePusher{failures: 1, msgs: map[uuid.UUID]*message.Message{}}
+
+	b := bridge.New(reader, pusher, &bridge.Config{
+		Queue:         "ingest",
+		RetryInterval: 10 * time.Millisecond,
+	}, slog.Default())
+
+	record := kafka.Message{
+		Topic:     "orders",
+		Partition: 2,
+		Offset:    41,
+		Value:     []byte("order"),
+		Headers:   []kafka.Header{{Key: "type", Value: []byte("created")}},
+	}
+	reader.records <- record
+	// a redelivery of the same record must not be enqueued twice
+	reader.records <- record
+	next := record
+	next.Offset = 42
+	reader.records <- next
+
+	if err := b.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Start(context.Background()); !errors.Is(err, gqs.ErrDoubleStarted) {
+		t.Fatalf("expected ErrDoubleStarted, got %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := b.Stop(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if pusher.count() != 2 {
+		t.Fatalf("expected 2 pushed messages, got %d", pusher.count())
+	}
+	if commits := reader.committed(); len(commits) != 3 {
+		t.Fatalf("expected every record to be committed, got %v", commits)
+	}
+	msg, _ := bridge.DefaultMapper(record)
+	pushed := pusher.msgs[msg.Id]
+	if pushed == nil || pushed.Queue != "ingest" || string(pushed.Payload) != "order" ||
+		pushed.Metadata["type"] != "created" || pushed.Metadata[bridge.MetaOffset] != int64(41) {
+		t.Fatalf("unexpected pushed message %+v", pushed)
+	}
+}
+
+type fakeWriter struct {
+	msgs []kafka.Message
+}
+
+func (fw *fakeWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	fw.msgs = append(fw.msgs, msgs...)
+	return nil
+}
+
+func TestEventSink(t *testing.T) {
+	writer := &fakeWriter{}
+	sink := bridge.NewEventSink(writer)
+	events := []gqs.Transition{
+		{JobId: uuid.New(), From: job.Pending, To: job.Processing},
+		{JobId: uuid.New(), From: job.Processing, To: job.Done},
+		{JobId: uuid.New(), From: job.Processing, To: job.Dead},
+	}
+	if err := sink.Publish(context.Background(), events); err != nil {
+		t.Fatal(err)
+	}
+	if len(writer.msgs) != 2 {
+		t.Fatalf("expected only outcomes to be published, got %d messages", len(writer.msgs))
+	}
+}
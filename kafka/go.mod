@@ -0,0 +1,20 @@
+module github.com/romanqed/gqs/kafka
+
+go 1.24.0
+
+require (
+	github.com/google/uuid v1.6.0
+	github.com/romanqed/gqs v0.0.0
+	github.com/segmentio/kafka-go v0.4.51
+)
+
+require (
+	github.com/klauspost/compress v1.15.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.15 // indirect
+	google.golang.org/protobuf v1.36.9 // indirect
+)
+
+replace (
+	github.com/romanqed/gqs => ../
+	github.com/romanqed/gqs/sql => ../sql
+)
@@ -0,0 +1,83 @@
+// Package kafka provides a gqs.TransitionSink publishing job lifecycle
+// events to Apache Kafka using github.com/segmentio/kafka-go.
+//
+// Combined with an outbox (see the sql package) and gqs.RelayWorker,
+// it delivers every job state transition to a Kafka topic at least
+// once.
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/segmentio/kafka-go"
+	"slices"
+)
+
+// Writer is the subset of *kafka.Writer used by Sink.
+type Writer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// Sink is a gqs.TransitionSink writing every transition as a JSON
+// encoded Kafka message.
+//
+// Messages are keyed by job id, so that transitions of a job land in
+// the same partition and keep their order. The topic is the one
+// configured on the writer.
+//
+// The writer must be synchronous (the default for *kafka.Writer): a
+// batch is acknowledged in the outbox only once WriteMessages returns
+// nil, so an asynchronous writer would break at-least-once delivery.
+type Sink struct {
+	writer   Writer
+	statuses []job.Status
+}
+
+// SinkOptions defines optional behavior of a Sink.
+//
+// Statuses, if set, restricts published transitions to those into one
+// of the listed statuses, for example job.Done and job.Dead to publish
+// only outcomes. Other transitions are acknowledged without being
+// written.
+type SinkOptions struct {
+	Statuses []job.Status
+}
+
+// NewSink creates a new Sink using the given writer.
+func NewSink(writer Writer) *Sink {
+	return NewSinkWithOptions(writer, &SinkOptions{})
+}
+
+// NewSinkWithOptions creates a new Sink using the given writer and
+// options.
+func NewSinkWithOptions(writer Writer, opts *SinkOptions) *Sink {
+	return &Sink{
+		writer:   writer,
+		statuses: opts.Statuses,
+	}
+}
+
+// Publish writes events to Kafka.
+func (s *Sink) Publish(ctx context.Context, events []gqs.Transition) error {
+	msgs := make([]kafka.Message, 0, len(events))
+	for _, event := range events {
+		if len(s.statuses) != 0 && !slices.Contains(s.statuses, event.To) {
+			continue
+		}
+		value, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, kafka.Message{
+			Key:   []byte(event.JobId.String()),
+			Value: value,
+			Time:  event.Time,
+		})
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return s.writer.WriteMessages(ctx, msgs...)
+}
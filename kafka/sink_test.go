@@ -0,0 +1,59 @@
+package kafka_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	gkafka "github.com/romanqed/gqs/kafka"
+	"github.com/segmentio/kafka-go"
+)
+
+type fakeWriter struct {
+	msgs []kafka.Message
+	err  error
+}
+
+func (fw *fakeWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if fw.err != nil {
+		return fw.err
+	}
+	fw.msgs = append(fw.msgs, msgs...)
+	return nil
+}
+
+func TestSink(t *testing.T) {
+	writer := &fakeWriter{}
+	sink := gkafka.NewSink(writer)
+
+	event := gqs.Transition{
+		Seq:   1,
+		JobId: uuid.New(),
+		Time:  time.Now(),
+		From:  job.Processing,
+		To:    job.Done,
+	}
+	if err := sink.Publish(context.Background(), []gqs.Transition{event}); err != nil {
+		t.Fatal(err)
+	}
+	if len(writer.msgs) != 1 || string(writer.msgs[0].Key) != event.JobId.String() {
+		t.Fatalf("expected message keyed by job id, got %+v", writer.msgs)
+	}
+	var decoded gqs.Transition
+	if err := json.Unmarshal(writer.msgs[0].Value, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Seq != 1 || decoded.To != job.Done {
+		t.Fatalf("unexpected payload %+v", decoded)
+	}
+
+	writer.err = errors.New("broker unavailable")
+	if err := sink.Publish(context.Background(), []gqs.Transition{event}); err == nil {
+		t.Fatal("expected writer errors to fail the batch")
+	}
+}
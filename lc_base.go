@@ -29,6 +29,10 @@ var (
 	//
 	// In this case, the worker may still be terminating in the background.
 	ErrStopTimeout = errors.New("worker stop timeout")
+
+	// ErrNotRunning is returned when an operation requiring a running
+	// worker, such as Drain, is called on a worker that is not started.
+	ErrNotRunning = errors.New("worker not running")
 )
 
 type lcBase struct {
@@ -42,6 +46,10 @@ func (lb *lcBase) tryStart() error {
 	return nil
 }
 
+func (lb *lcBase) running() bool {
+	return lb.state.Load() == started
+}
+
 func (lb *lcBase) tryStop(timeout time.Duration, df internal.DoneFunc) error {
 	if !lb.state.CompareAndSwap(started, stopped) {
 		return ErrDoubleStopped
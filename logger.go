@@ -0,0 +1,46 @@
+package gqs
+
+import "log/slog"
+
+// Logger is the logging interface of workers and other services.
+//
+// Messages are followed by alternating keys and values, as accepted by
+// log/slog. *slog.Logger implements Logger, so slog.Default or any
+// slog handler wrapped with slog.New can be passed directly. Other
+// logging libraries are integrated either through their slog handler
+// or with a small adapter implementing the four methods.
+//
+// Constructors accepting a Logger treat nil, including a nil
+// *slog.Logger, as NopLogger.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// NopLogger is a Logger discarding every message.
+type NopLogger struct{}
+
+// Debug implements Logger.
+func (NopLogger) Debug(string, ...any) {}
+
+// Info implements Logger.
+func (NopLogger) Info(string, ...any) {}
+
+// Warn implements Logger.
+func (NopLogger) Warn(string, ...any) {}
+
+// Error implements Logger.
+func (NopLogger) Error(string, ...any) {}
+
+// loggerOf returns log, or NopLogger if log is nil.
+func loggerOf(log Logger) Logger {
+	if log == nil {
+		return NopLogger{}
+	}
+	if sl, ok := log.(*slog.Logger); ok && sl == nil {
+		return NopLogger{}
+	}
+	return log
+}
@@ -0,0 +1,73 @@
+package gqs
+
+import (
+	"context"
+	"fmt"
+	"github.com/romanqed/gqs/job"
+	"time"
+)
+
+const (
+	// DefaultMaxLogLines is the number of log lines kept per job when
+	// WorkerConfig.MaxLogLines is zero.
+	DefaultMaxLogLines = 100
+
+	// MaxLogLineSize is the maximum size of a log line message in bytes.
+	// Longer messages are truncated.
+	MaxLogLineSize = 1024
+
+	badKey = "!BADKEY"
+)
+
+func logAttrs(args []any) map[string]any {
+	if len(args) == 0 {
+		return nil
+	}
+	ret := make(map[string]any, len(args)/2+1)
+	for len(args) > 0 {
+		key, ok := args[0].(string)
+		if !ok || len(args) == 1 {
+			ret[badKey] = args[0]
+			args = args[1:]
+			continue
+		}
+		ret[key] = args[1]
+		args = args[2:]
+	}
+	return ret
+}
+
+// SaveLog appends a structured log line to the job being handled.
+//
+// ctx must be the context passed to a MessageHandler by Worker.
+// args are key-value pairs, as accepted by log/slog.
+//
+// Log lines are persisted with the job when the attempt finishes,
+// if the Puller implements LogSaver, and are retrievable afterwards
+// via job.Job.Logs (for example, through Observer.Get). Only the most
+// recent WorkerConfig.MaxLogLines lines are kept per job, and messages
+// longer than MaxLogLineSize are truncated.
+//
+// SaveLog returns false if ctx does not belong to a handler.
+func SaveLog(ctx context.Context, msg string, args ...any) bool {
+	at, ok := attemptFrom(ctx)
+	if !ok {
+		return false
+	}
+	if len(msg) > MaxLogLineSize {
+		msg = msg[:MaxLogLineSize]
+	}
+	at.addLog(job.LogLine{
+		Time:    time.Now(),
+		Attempt: at.number,
+		Message: msg,
+		Attrs:   logAttrs(args),
+	})
+	return true
+}
+
+// SaveLogf is like SaveLog, but formats the message according to
+// a format specifier and carries no attributes.
+func SaveLogf(ctx context.Context, format string, args ...any) bool {
+	return SaveLog(ctx, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,75 @@
+package gqs
+
+import (
+	"context"
+	"github.com/romanqed/gqs/internal"
+	"time"
+)
+
+// Maintainer performs periodic storage maintenance, such as creating
+// and dropping table partitions.
+//
+// Maintainer does not participate in job processing.
+type Maintainer interface {
+
+	// Maintain performs a single maintenance pass.
+	Maintain(ctx context.Context) error
+}
+
+// MaintenanceWorker periodically invokes a Maintainer.
+//
+// MaintenanceWorker has a strict lifecycle:
+//   - Start may only be called once.
+//   - Stop must be called to terminate the worker.
+//   - Stop waits for the internal task to finish or until the timeout
+//     expires.
+type MaintenanceWorker struct {
+	lcBase
+	maintainer Maintainer
+	task       internal.TimerTask
+	log        Logger
+	interval   time.Duration
+}
+
+// NewMaintenanceWorker creates a new MaintenanceWorker invoking
+// maintainer every interval.
+//
+// The worker is not started automatically. Call Start to begin
+// periodic maintenance.
+func NewMaintenanceWorker(maintainer Maintainer, interval time.Duration, log Logger) *MaintenanceWorker {
+	return &MaintenanceWorker{
+		maintainer: maintainer,
+		log:        loggerOf(log),
+		interval:   interval,
+	}
+}
+
+func (mw *MaintenanceWorker) maintain(ctx context.Context) {
+	if err := mw.maintainer.Maintain(ctx); err != nil {
+		mw.log.Error("error while maintaining storage", "error", err)
+	}
+}
+
+// Start begins periodic maintenance.
+//
+// Start returns ErrDoubleStarted if the worker has already been started.
+//
+// The provided context controls cancellation of the background task.
+func (mw *MaintenanceWorker) Start(ctx context.Context) error {
+	if err := mw.tryStart(); err != nil {
+		return err
+	}
+	mw.task.Start(ctx, mw.maintain, mw.interval)
+	return nil
+}
+
+// Stop terminates the background maintenance task.
+//
+// Stop waits until the task finishes or the specified timeout expires.
+// If shutdown does not complete within the timeout, ErrStopTimeout
+// is returned.
+//
+// Stop returns ErrDoubleStopped if the worker is not running.
+func (mw *MaintenanceWorker) Stop(timeout time.Duration) error {
+	return mw.tryStop(timeout, mw.task.Stop)
+}
@@ -11,9 +11,22 @@
 //   - lightweight
 //   - safe to pass to user handlers
 //
+// The Queue field names the logical queue of the message.
+// The TenantId field optionally names the tenant owning the message.
+// The Type field optionally names the kind of the message for routing.
 // The Payload field contains the opaque binary body of the message.
 // The Metadata field is an optional key-value map for arbitrary structured
 // data associated with the message.
+// The SchemaVersion field identifies the payload format version.
+// The Priority field is a scheduling hint used to order eligible jobs.
+// The Region field optionally restricts processing to workers of a region.
+// The OrderingKey field optionally serializes messages of a group.
+// The TTL field optionally limits how late the message may be delivered.
+// The GroupId field optionally names the group of the message.
+// The DependsOn field optionally defers the message until other jobs are
+// Done.
+// The MaxRetries, LockTimeout and Timeout fields optionally override
+// worker-wide processing limits.
 //
 // Message does not enforce immutability. Callers should treat Message
 // instances as immutable once they are submitted to a queue to avoid
@@ -2,6 +2,7 @@ package message
 
 import (
 	"github.com/google/uuid"
+	"time"
 )
 
 // Message represents a transport-level unit of data in gqs.
@@ -17,10 +18,69 @@ import (
 // has been set.
 //
 // Payload contains arbitrary binary data and may be nil.
+//
+// Queue names the logical queue the message belongs to. The empty
+// string denotes the default queue.
+//
+// TenantId optionally names the tenant owning the message in
+// multi-tenant deployments. It isolates tenants from each other in
+// tenant-scoped wrappers (see gqs.TenantPusher and gqs.TenantObserver)
+// and pull filters, and lets storage schedule tenants fairly. The empty
+// string denotes no tenant.
+//
+// Type is an optional application-defined kind of the message, used to
+// route it to the appropriate handler (see gqs.Router).
+//
+// SchemaVersion identifies the payload format the producer used.
+// Consumers may upgrade payloads of older versions before handling
+// them (see gqs.Migrations). The zero value denotes the initial version.
+//
+// Priority is a scheduling hint: among jobs eligible at the same time,
+// jobs with a higher Priority are pulled first. The zero value is the
+// default priority; negative values are allowed.
+//
+// Region optionally pins the message to workers of a region, so that it
+// is processed close to its data. The empty string means any region.
+//
+// OrderingKey optionally serializes processing of messages sharing the
+// key: such messages are delivered one at a time, in push order, so
+// that updates of a single entity (for example, an account) are never
+// processed concurrently. The empty string means no ordering.
+//
+// TTL, if positive, makes the message expire TTL after the time it is
+// scheduled for: a job not pulled by then is not delivered anymore,
+// which suits notifications that are worthless if delivered late.
+//
+// GroupId optionally names the group the message belongs to (see
+// gqs.Group). uuid.Nil denotes no group.
+//
+// DependsOn lists the ids of jobs that must be Done before the message
+// is delivered, so that simple workflows can be expressed without an
+// external orchestrator. If any of them dies or is cancelled instead,
+// storage kills the message too. Dependencies should be pushed before
+// the message or together with it; a dependency unknown to storage is
+// considered satisfied.
+//
+// MaxRetries, LockTimeout and Timeout override the worker-wide retry
+// limit, visibility timeout and handler timeout for this message. Zero
+// values inherit the worker configuration.
 type Message struct {
-	Id       uuid.UUID
-	Metadata map[string]any
-	Payload  []byte
+	Id            uuid.UUID
+	Queue         string
+	TenantId      string
+	Type          string
+	Metadata      map[string]any
+	Payload       []byte
+	SchemaVersion uint32
+	Priority      int
+	Region        string
+	OrderingKey   string
+	TTL           time.Duration
+	GroupId       uuid.UUID
+	DependsOn     []uuid.UUID
+	MaxRetries    uint32
+	LockTimeout   time.Duration
+	Timeout       time.Duration
 }
 
 // NewMessage creates a new Message with a randomly generated UUID.
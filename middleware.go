@@ -0,0 +1,50 @@
+package gqs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/romanqed/gqs/message"
+)
+
+var (
+	// ErrPanic indicates that a MessageHandler panicked.
+	//
+	// Errors returned by the Recover middleware wrap ErrPanic, so the
+	// job follows the normal retry path instead of being abandoned
+	// until its lease expires.
+	ErrPanic = errors.New("handler panic")
+)
+
+// Middleware wraps a MessageHandler with additional behavior, such as
+// logging, metrics, tracing or payload decoding.
+//
+// A Middleware must call the wrapped handler to continue processing,
+// and may inspect or replace the error it returns.
+type Middleware func(MessageHandler) MessageHandler
+
+// Chain composes the given middlewares around handler.
+//
+// The first middleware becomes the outermost one: it is invoked first
+// and observes the result of all subsequent middlewares.
+func Chain(handler MessageHandler, mws ...Middleware) MessageHandler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		handler = mws[i](handler)
+	}
+	return handler
+}
+
+// Recover returns a Middleware that converts handler panics into
+// errors wrapping ErrPanic.
+func Recover() Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, msg *message.Message) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("%w: %v", ErrPanic, r)
+				}
+			}()
+			return next(ctx, msg)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package gqs
+
+import (
+	"context"
+	"github.com/romanqed/gqs/message"
+)
+
+// MigrationFunc upgrades the payload of msg by exactly one schema version.
+//
+// A MigrationFunc may modify Payload and Metadata of msg in place.
+// It must not change SchemaVersion; Migrations increments it after
+// a successful step.
+type MigrationFunc func(ctx context.Context, msg *message.Message) error
+
+type migrationKey struct {
+	kind string
+	from uint32
+}
+
+// Migrations is a registry of payload migrators keyed by message type
+// and source schema version.
+//
+// Migrations lets consumers process jobs enqueued by previous producer
+// versions after a payload format change. Old payloads are upgraded
+// step by step right before the handler runs, so rolling upgrades do
+// not break on in-flight jobs.
+//
+// Migrated payloads are not persisted: if the job is retried, the
+// migration is applied again to the stored payload.
+//
+// Migrations is applied by a Worker configured with
+// WorkerConfig.Migrations, or by any handler wrapped with Middleware.
+//
+// Migrators must be registered before the worker is started;
+// Migrations is not safe for concurrent registration and migration.
+type Migrations struct {
+	steps map[migrationKey]MigrationFunc
+}
+
+// NewMigrations creates an empty migration registry.
+func NewMigrations() *Migrations {
+	return &Migrations{
+		steps: make(map[migrationKey]MigrationFunc),
+	}
+}
+
+// Register adds a migrator upgrading messages of the given type
+// from schema version from to version from+1.
+func (m *Migrations) Register(kind string, from uint32, fn MigrationFunc) {
+	m.steps[migrationKey{kind: kind, from: from}] = fn
+}
+
+// Migrate applies registered migrators to msg until no migrator exists
+// for its current schema version.
+//
+// If a migrator fails, Migrate stops and returns its error; msg keeps
+// the version reached by the last successful step.
+func (m *Migrations) Migrate(ctx context.Context, msg *message.Message) error {
+	for {
+		step, ok := m.steps[migrationKey{kind: msg.Type, from: msg.SchemaVersion}]
+		if !ok {
+			return nil
+		}
+		if err := step(ctx, msg); err != nil {
+			return err
+		}
+		msg.SchemaVersion++
+	}
+}
+
+// Middleware returns a Middleware that migrates each message before
+// invoking the handler.
+//
+// Migration errors are returned as handler errors and follow the normal
+// retry path. Migrators should return ErrKill for payloads that can
+// never be upgraded.
+func (m *Migrations) Middleware() Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, msg *message.Message) error {
+			if err := m.Migrate(ctx, msg); err != nil {
+				return err
+			}
+			return next(ctx, msg)
+		}
+	}
+}
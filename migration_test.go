@@ -0,0 +1,102 @@
+package gqs_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestMigrations(t *testing.T) {
+	migrations := gqs.NewMigrations()
+	migrations.Register("user", 0, func(ctx context.Context, msg *message.Message) error {
+		msg.Payload = append(msg.Payload, '1')
+		return nil
+	})
+	migrations.Register("user", 1, func(ctx context.Context, msg *message.Message) error {
+		msg.Payload = append(msg.Payload, '2')
+		return nil
+	})
+
+	var handled *message.Message
+	handler := gqs.Chain(func(ctx context.Context, msg *message.Message) error {
+		handled = msg
+		return nil
+	}, migrations.Middleware())
+
+	msg := message.NewMessage()
+	msg.Type = "user"
+	msg.Payload = []byte("v")
+
+	if err := handler(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	if handled.SchemaVersion != 2 {
+		t.Fatalf("expected schema version 2, got %d", handled.SchemaVersion)
+	}
+	if string(handled.Payload) != "v12" {
+		t.Fatalf("expected migrated payload, got %q", handled.Payload)
+	}
+}
+
+func TestWorkerMigrations(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	migrations := gqs.NewMigrations()
+	migrations.Register("user", 0, func(ctx context.Context, msg *message.Message) error {
+		msg.Payload = append(msg.Payload, '1')
+		return nil
+	})
+
+	handled := make(chan string, 1)
+	handler := func(ctx context.Context, msg *message.Message) error {
+		handled <- fmt.Sprintf("%s@%d", msg.Payload, msg.SchemaVersion)
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    10,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  time.Second,
+		Migrations:   migrations,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, slog.Default())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msg := message.NewMessage()
+	msg.Type = "user"
+	msg.Payload = []byte("v")
+	_ = pusher.Push(ctx, msg, 0)
+
+	_ = worker.Start(ctx)
+	defer worker.Stop(time.Second)
+
+	select {
+	case got := <-handled:
+		if got != "v1@1" {
+			t.Fatalf("expected migrated message, got %s", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler was not called")
+	}
+	// migrated payloads are not persisted
+	jb := waitStatus(t, observer, msg.Id, job.Done)
+	if string(jb.Payload) != "v" || jb.SchemaVersion != 0 {
+		t.Fatalf("expected the stored payload unchanged, got %q@%d", jb.Payload, jb.SchemaVersion)
+	}
+}
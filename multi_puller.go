@@ -0,0 +1,322 @@
+package gqs
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs/job"
+	"slices"
+	"sync"
+	"time"
+)
+
+// multiCaps are the optional features MultiPuller forwards to its
+// pullers. Batch and stream features are not forwarded, as a batch
+// may span several pullers.
+var multiCaps = []Capability{
+	CapRelease,
+	CapResult,
+	CapLogs,
+	CapLockLoss,
+	CapDiagnostics,
+	CapChain,
+	CapPause,
+	CapFilter,
+	CapOwner,
+}
+
+type multiLease struct {
+	puller int
+	until  time.Time
+}
+
+// multiState is shared by a MultiPuller and the MultiPullers derived
+// from it, so that jobs pulled by any of them can be transitioned by
+// every other.
+type multiState struct {
+	mutex   sync.Mutex
+	current []int
+	leases  map[uuid.UUID]multiLease
+}
+
+// MultiPuller is a Puller interleaving pulls from several Pullers, for
+// example one per queue table or database shard, so that a single
+// Worker consumes all of them.
+//
+// Every Pull starts with the next puller selected by smooth weighted
+// round-robin and fills the rest of the batch from the other pullers in
+// order, so a puller receives a share of pulls proportional to its
+// weight while idle pullers leave capacity to busy ones. Transitions of
+// pulled jobs are routed to the puller that returned them.
+//
+// MultiPuller supports an optional feature of Puller only if every
+// puller supports it. Batch transitions and streaming are not
+// supported. MultiPullers returned by WithFilter and WithInstance share
+// the routing state of the receiver.
+type MultiPuller struct {
+	pullers []Puller
+	weights []int
+	caps    Capability
+	state   *multiState
+}
+
+// NewMultiPuller creates a MultiPuller over pullers. weights, if not
+// nil, must have one positive weight per puller; if nil, pullers are
+// weighted equally.
+func NewMultiPuller(pullers []Puller, weights []int) *MultiPuller {
+	return newMultiPuller(pullers, weights, &multiState{
+		current: make([]int, len(pullers)),
+		leases:  make(map[uuid.UUID]multiLease),
+	})
+}
+
+func newMultiPuller(pullers []Puller, weights []int, state *multiState) *MultiPuller {
+	if weights == nil {
+		weights = make([]int, len(pullers))
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+	var caps Capability
+	for _, c := range multiCaps {
+		if !slices.ContainsFunc(pullers, func(p Puller) bool { return !Supports(p, c) }) {
+			caps |= c
+		}
+	}
+	return &MultiPuller{
+		pullers: pullers,
+		weights: weights,
+		caps:    caps,
+		state:   state,
+	}
+}
+
+// Capabilities implements Capable.
+func (mp *MultiPuller) Capabilities() Capability {
+	return mp.caps
+}
+
+// next selects the puller to start a pull with. The caller must hold
+// the mutex of the state.
+func (mp *MultiPuller) next() int {
+	total := 0
+	best := 0
+	for i, weight := range mp.weights {
+		mp.state.current[i] += weight
+		total += weight
+		if mp.state.current[i] > mp.state.current[best] {
+			best = i
+		}
+	}
+	mp.state.current[best] -= total
+	return best
+}
+
+// Pull pulls up to batch jobs, starting with the puller selected by
+// weighted round-robin and continuing with the others until the batch
+// is filled.
+//
+// A failing puller is skipped; its error is returned only if no jobs
+// were pulled, so that jobs already transitioned to Processing are not
+// dropped.
+func (mp *MultiPuller) Pull(ctx context.Context, batch int, lock time.Duration) ([]*job.Job, error) {
+	mp.state.mutex.Lock()
+	start := mp.next()
+	mp.state.mutex.Unlock()
+	var ret []*job.Job
+	var errs []error
+	for i := range mp.pullers {
+		if len(ret) >= batch {
+			break
+		}
+		index := (start + i) % len(mp.pullers)
+		jobs, err := mp.pullers[index].Pull(ctx, batch-len(ret), lock)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		mp.track(index, jobs, lock)
+		ret = append(ret, jobs...)
+	}
+	if len(ret) == 0 && len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+	return ret, nil
+}
+
+// track records the origin of pulled jobs and forgets jobs whose lease
+// ended longer than lock ago, as their worker no longer transitions
+// them.
+func (mp *MultiPuller) track(index int, jobs []*job.Job, lock time.Duration) {
+	now := time.Now()
+	mp.state.mutex.Lock()
+	defer mp.state.mutex.Unlock()
+	for id, lease := range mp.state.leases {
+		if lease.until.Add(lock).Before(now) {
+			delete(mp.state.leases, id)
+		}
+	}
+	for _, jb := range jobs {
+		mp.state.leases[jb.Id] = multiLease{puller: index, until: now.Add(lock)}
+	}
+}
+
+func (mp *MultiPuller) origin(jb *job.Job) (Puller, error) {
+	mp.state.mutex.Lock()
+	defer mp.state.mutex.Unlock()
+	lease, ok := mp.state.leases[jb.Id]
+	if !ok {
+		return nil, ErrJobLost
+	}
+	return mp.pullers[lease.puller], nil
+}
+
+func (mp *MultiPuller) forget(jb *job.Job) {
+	mp.state.mutex.Lock()
+	delete(mp.state.leases, jb.Id)
+	mp.state.mutex.Unlock()
+}
+
+// finish performs a terminal transition of jb on its origin puller and
+// forgets jb on success.
+func (mp *MultiPuller) finish(jb *job.Job, fn func(p Puller) error) error {
+	p, err := mp.origin(jb)
+	if err != nil {
+		return err
+	}
+	if err := fn(p); err != nil {
+		return err
+	}
+	mp.forget(jb)
+	return nil
+}
+
+// ExtendLock extends the lock of jb on the puller that returned it.
+// Jobs not pulled by mp are reported as ErrJobLost.
+func (mp *MultiPuller) ExtendLock(ctx context.Context, jb *job.Job, lock time.Duration) error {
+	p, err := mp.origin(jb)
+	if err != nil {
+		return err
+	}
+	if err := p.ExtendLock(ctx, jb, lock); err != nil {
+		return err
+	}
+	mp.state.mutex.Lock()
+	if lease, ok := mp.state.leases[jb.Id]; ok {
+		lease.until = time.Now().Add(lock)
+		mp.state.leases[jb.Id] = lease
+	}
+	mp.state.mutex.Unlock()
+	return nil
+}
+
+// Complete completes jb on the puller that returned it.
+func (mp *MultiPuller) Complete(ctx context.Context, jb *job.Job) error {
+	return mp.finish(jb, func(p Puller) error {
+		return p.Complete(ctx, jb)
+	})
+}
+
+// Return returns jb to the puller that returned it.
+func (mp *MultiPuller) Return(ctx context.Context, jb *job.Job, backoff time.Duration) error {
+	return mp.finish(jb, func(p Puller) error {
+		return p.Return(ctx, jb, backoff)
+	})
+}
+
+// Kill kills jb on the puller that returned it.
+func (mp *MultiPuller) Kill(ctx context.Context, jb *job.Job) error {
+	return mp.finish(jb, func(p Puller) error {
+		return p.Kill(ctx, jb)
+	})
+}
+
+// Release implements Releaser.
+func (mp *MultiPuller) Release(ctx context.Context, jb *job.Job) error {
+	return mp.finish(jb, func(p Puller) error {
+		return p.(Releaser).Release(ctx, jb)
+	})
+}
+
+// CompleteWithResult implements ResultCompleter.
+func (mp *MultiPuller) CompleteWithResult(ctx context.Context, jb *job.Job, result []byte) error {
+	return mp.finish(jb, func(p Puller) error {
+		return p.(ResultCompleter).CompleteWithResult(ctx, jb, result)
+	})
+}
+
+// CompleteAndPush implements ChainCompleter. Follow-ups are enqueued
+// by the puller that returned jb.
+func (mp *MultiPuller) CompleteAndPush(ctx context.Context, jb *job.Job, next []Continuation) error {
+	return mp.finish(jb, func(p Puller) error {
+		return p.(ChainCompleter).CompleteAndPush(ctx, jb, next)
+	})
+}
+
+// SaveLogs implements LogSaver.
+func (mp *MultiPuller) SaveLogs(ctx context.Context, jb *job.Job) error {
+	p, err := mp.origin(jb)
+	if err != nil {
+		return err
+	}
+	return p.(LogSaver).SaveLogs(ctx, jb)
+}
+
+// SaveDiagnostics implements DiagnosticsSaver.
+func (mp *MultiPuller) SaveDiagnostics(ctx context.Context, jb *job.Job) error {
+	p, err := mp.origin(jb)
+	if err != nil {
+		return err
+	}
+	return p.(DiagnosticsSaver).SaveDiagnostics(ctx, jb)
+}
+
+// RecordLockLoss implements LockLossRecorder. jb is forgotten, as its
+// lease is lost.
+func (mp *MultiPuller) RecordLockLoss(ctx context.Context, jb *job.Job, penalty time.Duration) error {
+	p, err := mp.origin(jb)
+	if err != nil {
+		return err
+	}
+	mp.forget(jb)
+	return p.(LockLossRecorder).RecordLockLoss(ctx, jb, penalty)
+}
+
+// PausedQueues implements PauseObserver, merging the paused queues of
+// all pullers.
+func (mp *MultiPuller) PausedQueues(ctx context.Context) ([]string, error) {
+	var ret []string
+	for _, p := range mp.pullers {
+		queues, err := p.(PauseObserver).PausedQueues(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, queues...)
+	}
+	slices.Sort(ret)
+	return slices.Compact(ret), nil
+}
+
+func (mp *MultiPuller) derive(fn func(p Puller) Puller) *MultiPuller {
+	pullers := make([]Puller, len(mp.pullers))
+	for i, p := range mp.pullers {
+		pullers[i] = fn(p)
+	}
+	return newMultiPuller(pullers, mp.weights, mp.state)
+}
+
+// WithFilter implements FilterPuller by applying filter to every
+// puller.
+func (mp *MultiPuller) WithFilter(filter *PullFilter) Puller {
+	return mp.derive(func(p Puller) Puller {
+		return p.(FilterPuller).WithFilter(filter)
+	})
+}
+
+// WithInstance implements OwnerPuller by recording the instance id
+// with every puller.
+func (mp *MultiPuller) WithInstance(id string) Puller {
+	return mp.derive(func(p Puller) Puller {
+		return p.(OwnerPuller).WithInstance(id)
+	})
+}
@@ -0,0 +1,107 @@
+package gqs_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestMultiPuller(t *testing.T) {
+	first := newTestDB(t)
+	second := newTestDB(t)
+	ctx := context.Background()
+
+	var ids []*message.Message
+	for _, db := range []*gsql.Pusher{gsql.NewPusher(first), gsql.NewPusher(second)} {
+		for i := 0; i < 3; i++ {
+			msg := message.NewMessage()
+			if err := db.Push(ctx, msg, 0); err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, msg)
+		}
+	}
+
+	puller := gqs.NewMultiPuller([]gqs.Puller{gsql.NewPuller(first), gsql.NewPuller(second)}, nil)
+	if !gqs.Supports(puller, gqs.CapResult) || gqs.Supports(puller, gqs.CapBatchExtend) {
+		t.Fatal("expected forwarded single-job features only")
+	}
+
+	// the second pull starts with the second puller
+	jobs, err := puller.Pull(ctx, 2, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	more, err := puller.Pull(ctx, 2, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 2 || len(more) != 2 {
+		t.Fatalf("expected two batches of 2 jobs, got %d and %d", len(jobs), len(more))
+	}
+	for _, jb := range append(jobs, more...) {
+		if err := puller.Complete(ctx, jb); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the remaining jobs are spread across both pullers and fill one batch
+	rest, err := puller.Pull(ctx, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rest) != 2 {
+		t.Fatalf("expected the remaining 2 jobs, got %d", len(rest))
+	}
+	for _, jb := range rest {
+		if err := puller.Complete(ctx, jb); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	observers := []*gsql.Observer{gsql.NewObserver(first), gsql.NewObserver(second)}
+	for i, msg := range ids {
+		jb, err := observers[i/3].Get(ctx, msg.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if jb.Status != job.Done {
+			t.Fatalf("expected job %d Done, got %v", i, jb.Status)
+		}
+	}
+
+	if err := puller.Complete(ctx, rest[0]); err != gqs.ErrJobLost {
+		t.Fatalf("expected ErrJobLost for a completed job, got %v", err)
+	}
+}
+
+func TestMultiPullerWeights(t *testing.T) {
+	first := newTestDB(t)
+	second := newTestDB(t)
+	ctx := context.Background()
+	for _, db := range []*gsql.Pusher{gsql.NewPusher(first), gsql.NewPusher(second)} {
+		for i := 0; i < 8; i++ {
+			if err := db.Push(ctx, message.NewMessage(), 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	observer := gsql.NewObserver(first)
+	puller := gqs.NewMultiPuller([]gqs.Puller{gsql.NewPuller(first), gsql.NewPuller(second)}, []int{3, 1})
+	for i := 0; i < 4; i++ {
+		if _, err := puller.Pull(ctx, 1, time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+	processing, err := observer.Count(ctx, &gqs.ListOptions{Statuses: []job.Status{job.Processing}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if processing != 3 {
+		t.Fatalf("expected 3 of 4 pulls from the first puller, got %d", processing)
+	}
+}
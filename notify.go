@@ -0,0 +1,41 @@
+package gqs
+
+import (
+	"context"
+	"github.com/romanqed/gqs/internal"
+)
+
+// Notifier announces that new jobs may be available, letting Worker
+// pull immediately instead of waiting for the next PullInterval.
+//
+// Notifications are hints: Worker keeps pulling every PullInterval, so
+// a lost notification only delays a job, and a spurious one costs an
+// empty pull.
+type Notifier interface {
+
+	// Listen subscribes to notifications. A value is delivered on the
+	// returned channel whenever new jobs may be available; bursts of
+	// notifications may be coalesced into one value.
+	//
+	// The channel is closed once ctx is done. An error is returned only
+	// if the subscription cannot be established at all.
+	Listen(ctx context.Context) (<-chan struct{}, error)
+}
+
+func (w *Worker) listen(ctx context.Context) {
+	ctx, w.stopListen = context.WithCancel(ctx)
+	w.listenDone = make(internal.DoneChan)
+	ch, err := w.notifier.Listen(ctx)
+	if err != nil {
+		// the worker keeps polling, notifications only reduce latency
+		w.log.Error("cannot listen for notifications", "err", err)
+		close(w.listenDone)
+		return
+	}
+	go func() {
+		defer close(w.listenDone)
+		for range ch {
+			w.pullTask.Trigger()
+		}
+	}()
+}
@@ -2,8 +2,20 @@ package gqs
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/romanqed/gqs/job"
+	"time"
+)
+
+var (
+	// ErrBadCursor indicates that a pagination cursor is malformed.
+	ErrBadCursor = errors.New("bad cursor")
+
+	// ErrNotFound indicates that a job with the requested id does not
+	// exist.
+	ErrNotFound = errors.New("job not found")
 )
 
 // Observer provides read-only access to jobs stored in the queue.
@@ -19,7 +31,11 @@ type Observer interface {
 
 	// Get returns the job identified by id.
 	//
-	// If no job with the given id exists, Get returns (nil, nil).
+	// If no job with the given id exists, Get returns (nil, nil). For
+	// compatibility this remains the default; implementations may
+	// offer an option to return an error wrapping ErrNotFound instead.
+	// Callers that need a job should use GetExisting, which handles
+	// both conventions.
 	//
 	// The returned Job represents the current storage snapshot,
 	// including its Status, Attempts, and scheduling metadata.
@@ -42,3 +58,101 @@ type Observer interface {
 	// not be used as part of the normal consumption workflow.
 	List(ctx context.Context, status job.Status, limit int) ([]*job.Job, error)
 }
+
+// GetExisting returns the job identified by id, like Observer.Get, but
+// never returns a nil job without an error: if the job does not exist,
+// GetExisting returns an error wrapping ErrNotFound, regardless of the
+// convention used by obs.
+func GetExisting(ctx context.Context, obs Observer, id uuid.UUID) (*job.Job, error) {
+	ret, err := obs.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ret == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return ret, nil
+}
+
+// Order defines the sort order of jobs returned by Observer.Query.
+type Order uint8
+
+const (
+	// OrderCreatedAsc sorts jobs by CreatedAt, oldest first.
+	OrderCreatedAsc Order = iota
+
+	// OrderCreatedDesc sorts jobs by CreatedAt, newest first.
+	OrderCreatedDesc
+
+	// OrderUpdatedAsc sorts jobs by UpdatedAt, least recently updated first.
+	OrderUpdatedAsc
+
+	// OrderUpdatedDesc sorts jobs by UpdatedAt, most recently updated first.
+	OrderUpdatedDesc
+)
+
+// ListOptions defines filtering, ordering and pagination for
+// Observer.Query and Observer.Count.
+//
+// Ids, Statuses, Queues, Tenants and LockedBy restrict results to jobs
+// with any of the listed values. Empty slices apply no restriction.
+// Tenants matches message.Message.TenantId. LockedBy matches the worker
+// instance that last pulled a job (see OwnerPuller).
+//
+// CreatedAfter, CreatedBefore, UpdatedAfter and UpdatedBefore restrict
+// the corresponding timestamps to an inclusive range. Nil bounds apply
+// no restriction.
+//
+// Metadata restricts results to jobs whose metadata contains every
+// listed key with the given value, compared by textual representation.
+//
+// Order defines the sort order. Ties are broken by job id.
+//
+// Limit defines the maximum page size; zero or negative means no limit.
+//
+// Cursor continues listing after the last job of a previous page
+// (see Page.Next). If Cursor is set, Offset is ignored. A cursor is only
+// valid with the same Order it was produced with.
+//
+// Offset skips the given number of jobs.
+//
+// Count ignores Order, Limit, Cursor and Offset.
+type ListOptions struct {
+	Ids           []uuid.UUID
+	Statuses      []job.Status
+	Queues        []string
+	Tenants       []string
+	LockedBy      []string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	Metadata      map[string]string
+	Order         Order
+	Limit         int
+	Cursor        string
+	Offset        int
+}
+
+// Page is a single page of jobs returned by Observer.Query.
+//
+// Next is an opaque cursor pointing after the last returned job.
+// It is empty if the page is the last one.
+type Page struct {
+	Jobs []*job.Job
+	Next string
+}
+
+// QueryObserver is an extension of Observer providing filtered and
+// paginated access to jobs, intended for administrative interfaces.
+type QueryObserver interface {
+	Observer
+
+	// Query returns a page of jobs matching opts.
+	//
+	// The returned jobs are independent snapshots of storage state.
+	Query(ctx context.Context, opts *ListOptions) (*Page, error)
+
+	// Count returns the number of jobs matching the filters of opts.
+	Count(ctx context.Context, opts *ListOptions) (int64, error)
+}
@@ -0,0 +1,169 @@
+package gqs
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs/internal"
+	"github.com/romanqed/gqs/job"
+	"time"
+)
+
+// DefaultRelayBatch is the number of transitions a RelayWorker
+// publishes at once if RelayConfig.BatchSize is not positive.
+const DefaultRelayBatch = 100
+
+// Transition is a job state transition exported to external systems.
+//
+// Seq is a storage-assigned sequence number, increasing in the order
+// transitions were recorded. As delivery is at-least-once, consumers
+// should deduplicate transitions by Seq.
+//
+// From is job.Unknown for the transition recording the creation of the
+// job. Attempts is the attempt count after the transition. Error holds
+// the error text of a failed attempt, if the transition was caused by
+// one.
+type Transition struct {
+	Seq      int64      `json:"seq"`
+	JobId    uuid.UUID  `json:"job_id"`
+	Queue    string     `json:"queue"`
+	Type     string     `json:"type"`
+	Time     time.Time  `json:"time"`
+	From     job.Status `json:"from"`
+	To       job.Status `json:"to"`
+	Attempts uint32     `json:"attempts"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// TransitionSink publishes transitions to an external system, such as
+// a message broker or a webhook.
+type TransitionSink interface {
+
+	// Publish delivers events, ordered by Seq.
+	//
+	// Publish must return nil only once every event has been accepted
+	// by the external system. If it returns an error, the whole batch
+	// is delivered again later, so events may be published more than
+	// once.
+	Publish(ctx context.Context, events []Transition) error
+}
+
+// Outbox stores transitions recorded by the storage until they are
+// published.
+//
+// Transitions must be recorded atomically with the state change they
+// describe, so that no transition is lost when a process crashes.
+type Outbox interface {
+
+	// Fetch returns up to limit unpublished transitions, ordered by Seq.
+	Fetch(ctx context.Context, limit int) ([]Transition, error)
+
+	// Ack removes the given published transitions from the outbox.
+	Ack(ctx context.Context, events []Transition) error
+}
+
+// RelayConfig defines the scheduling of a RelayWorker.
+//
+// Interval defines how often the outbox is polled. BatchSize is the
+// maximum number of transitions published at once; if it is not
+// positive, DefaultRelayBatch is used.
+type RelayConfig struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+// RelayWorker periodically publishes transitions from an Outbox to a
+// TransitionSink, providing at-least-once delivery of job lifecycle
+// events to systems such as analytics or billing.
+//
+// Transitions are acknowledged only after the sink accepted them. If
+// publishing fails, the batch stays in the outbox and is retried on the
+// next cycle, preserving order. A single RelayWorker should run per
+// outbox; concurrent relays publish the same transitions twice.
+//
+// RelayWorker has a strict lifecycle:
+//   - Start may only be called once.
+//   - Stop must be called to terminate the worker.
+//   - Stop waits for the internal task to finish or until the timeout
+//     expires.
+type RelayWorker struct {
+	lcBase
+	outbox   Outbox
+	sink     TransitionSink
+	task     internal.TimerTask
+	log      Logger
+	interval time.Duration
+	batch    int
+}
+
+// NewRelayWorker creates a new RelayWorker publishing transitions of
+// outbox to sink.
+//
+// The worker is not started automatically. Call Start to begin
+// publishing.
+func NewRelayWorker(outbox Outbox, sink TransitionSink, config *RelayConfig, log Logger) *RelayWorker {
+	batch := config.BatchSize
+	if batch <= 0 {
+		batch = DefaultRelayBatch
+	}
+	return &RelayWorker{
+		outbox:   outbox,
+		sink:     sink,
+		log:      loggerOf(log),
+		interval: config.Interval,
+		batch:    batch,
+	}
+}
+
+// relay publishes one batch and reports whether the outbox may hold
+// more transitions.
+func (rw *RelayWorker) relay(ctx context.Context) bool {
+	events, err := rw.outbox.Fetch(ctx, rw.batch)
+	if err != nil {
+		rw.log.Error("cannot fetch transitions", "error", err)
+		return false
+	}
+	if len(events) == 0 {
+		return false
+	}
+	if err := rw.sink.Publish(ctx, events); err != nil {
+		rw.log.Error("cannot publish transitions", "count", len(events), "error", err)
+		return false
+	}
+	if err := rw.outbox.Ack(ctx, events); err != nil {
+		rw.log.Error("cannot acknowledge transitions", "count", len(events), "error", err)
+		return false
+	}
+	return len(events) == rw.batch
+}
+
+func (rw *RelayWorker) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		if !rw.relay(ctx) {
+			return
+		}
+	}
+}
+
+// Start begins periodic publishing of transitions.
+//
+// Start returns ErrDoubleStarted if the worker has already been started.
+//
+// The provided context controls cancellation of the background task.
+func (rw *RelayWorker) Start(ctx context.Context) error {
+	if err := rw.tryStart(); err != nil {
+		return err
+	}
+	rw.task.Start(ctx, rw.run, rw.interval)
+	return nil
+}
+
+// Stop terminates the background publishing task.
+//
+// Stop waits until the task finishes or the specified timeout expires.
+// If shutdown does not complete within the timeout, ErrStopTimeout
+// is returned.
+//
+// Stop returns ErrDoubleStopped if the worker is not running.
+func (rw *RelayWorker) Stop(timeout time.Duration) error {
+	return rw.tryStop(timeout, rw.task.Stop)
+}
@@ -0,0 +1,40 @@
+package gqs
+
+import (
+	"context"
+	"time"
+)
+
+// QueueOverview summarizes the jobs of a single queue for dashboards.
+//
+// Pending, Scheduled, Processing, Done, Dead and Cancelled count the
+// jobs of the queue by status. InFlight is the number of Processing jobs whose lease has not
+// expired, that is jobs a live worker is handling at the moment.
+//
+// OldestPending is the age of the oldest Pending job, measured from
+// its creation; it is zero if the queue has no Pending job. Durations
+// are encoded in nanoseconds.
+type QueueOverview struct {
+	Queue         string        `json:"queue"`
+	Pending       int64         `json:"pending"`
+	Scheduled     int64         `json:"scheduled"`
+	Processing    int64         `json:"processing"`
+	Done          int64         `json:"done"`
+	Dead          int64         `json:"dead"`
+	Cancelled     int64         `json:"cancelled"`
+	InFlight      int64         `json:"in_flight"`
+	OldestPending time.Duration `json:"oldest_pending"`
+}
+
+// OverviewObserver is an optional extension of Observer summarizing
+// every queue at once.
+type OverviewObserver interface {
+
+	// QueueOverview returns the overview of every queue with at least
+	// one job, ordered by queue name.
+	//
+	// Implementations must compute all overviews from a single
+	// consistent snapshot of storage, so that counts of different
+	// queues and statuses add up even under load.
+	QueueOverview(ctx context.Context) ([]QueueOverview, error)
+}
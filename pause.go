@@ -0,0 +1,161 @@
+package gqs
+
+import (
+	"context"
+	"github.com/romanqed/gqs/message"
+	"sync"
+)
+
+// QueueController pauses and resumes queues.
+//
+// Pausing a queue makes Pullers skip its jobs until it is resumed,
+// acting as a kill switch during incidents without stopping worker
+// processes. Jobs already being processed are not affected, and
+// pushing to a paused queue is still allowed.
+type QueueController interface {
+
+	// Pause pauses queue. Pausing a paused queue is not an error.
+	Pause(ctx context.Context, queue string) error
+
+	// Resume resumes queue. Resuming a queue that is not paused is
+	// not an error.
+	Resume(ctx context.Context, queue string) error
+
+	// Paused returns all paused queues ordered by name.
+	Paused(ctx context.Context) ([]string, error)
+}
+
+// PauseObserver is an optional extension of Puller reporting the
+// paused queues it skips.
+//
+// Worker uses it to log when queues it consumes are paused or resumed.
+// Paused queues are checked after pulls returning fewer jobs than the
+// batch size, so a saturated worker does not pay for the check.
+type PauseObserver interface {
+
+	// PausedQueues returns the paused queues the Puller would otherwise
+	// consume, ordered by name.
+	PausedQueues(ctx context.Context) ([]string, error)
+}
+
+// checkPaused logs queues paused or resumed since the previous check.
+// It is called by the pull loop only, so w.paused needs no locking.
+func (w *Worker) checkPaused(ctx context.Context) {
+	if w.pauses == nil {
+		return
+	}
+	queues, err := w.pauses.PausedQueues(ctx)
+	if err != nil {
+		w.log.Error("cannot check paused queues", "err", err)
+		return
+	}
+	current := make(map[string]bool, len(queues))
+	for _, queue := range queues {
+		current[queue] = true
+		if !w.paused[queue] {
+			w.log.Warn("queue paused, its jobs are not pulled", "queue", queue)
+		}
+	}
+	for queue := range w.paused {
+		if !current[queue] {
+			w.log.Info("queue resumed", "queue", queue)
+		}
+	}
+	w.paused = current
+}
+
+// pauseGate holds the pause state of a Worker.
+type pauseGate struct {
+	mutex    sync.Mutex
+	pulling  bool
+	dispatch bool
+	resumed  chan struct{}
+}
+
+func (pg *pauseGate) pause(dispatch bool) {
+	pg.mutex.Lock()
+	defer pg.mutex.Unlock()
+	pg.pulling = true
+	if dispatch && !pg.dispatch {
+		pg.dispatch = true
+		pg.resumed = make(chan struct{})
+	}
+}
+
+func (pg *pauseGate) resume() {
+	pg.mutex.Lock()
+	defer pg.mutex.Unlock()
+	pg.pulling = false
+	if pg.dispatch {
+		pg.dispatch = false
+		close(pg.resumed)
+	}
+}
+
+func (pg *pauseGate) paused() bool {
+	pg.mutex.Lock()
+	defer pg.mutex.Unlock()
+	return pg.pulling
+}
+
+// wait blocks while dispatching is paused.
+func (pg *pauseGate) wait(ctx context.Context) error {
+	pg.mutex.Lock()
+	if !pg.dispatch {
+		pg.mutex.Unlock()
+		return nil
+	}
+	resumed := pg.resumed
+	pg.mutex.Unlock()
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Pause suspends pulling of new jobs until Resume is called. Jobs
+// already pulled are still handled, and the worker keeps its pool,
+// leases and registration, so that consumption can be halted briefly,
+// for example during a maintenance window, without stopping the worker.
+//
+// Pause may be called whether or not the worker is running; the pause
+// outlasts restarts. Pausing a paused worker is not an error.
+func (w *Worker) Pause() {
+	w.pause.pause(false)
+}
+
+// PauseDispatch suspends pulling like Pause and additionally holds
+// pulled jobs from starting until Resume is called. Held jobs occupy
+// their handler slots and keep their leases extended; on shutdown they
+// are treated as interrupted (see WorkerConfig.OnCancel).
+func (w *Worker) PauseDispatch() {
+	w.pause.pause(true)
+}
+
+// Resume resumes pulling and dispatching suspended by Pause or
+// PauseDispatch. Resuming a worker that is not paused is not an error.
+func (w *Worker) Resume() {
+	w.pause.resume()
+	if w.running() {
+		w.pullTask.Trigger()
+	}
+}
+
+// Paused reports whether pulling is suspended by Pause or
+// PauseDispatch.
+func (w *Worker) Paused() bool {
+	return w.pause.paused()
+}
+
+// hold makes handler wait while dispatching is paused. The wait runs
+// as a part of the handler, so the job lease is extended meanwhile.
+func (w *Worker) hold(handler MessageHandler) MessageHandler {
+	return func(ctx context.Context, msg *message.Message) error {
+		if err := w.pause.wait(ctx); err != nil {
+			return err
+		}
+		return handler(ctx, msg)
+	}
+}
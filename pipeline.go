@@ -0,0 +1,70 @@
+package gqs
+
+import (
+	"context"
+	"errors"
+	"github.com/romanqed/gqs/message"
+	"time"
+)
+
+var (
+	// ErrNotHandled indicates that a PipelineHandler was invoked outside
+	// of a Worker, so its output could not be delivered.
+	ErrNotHandled = errors.New("output requires a worker handler context")
+)
+
+// Output is the result of a pipeline stage: an optional handler result
+// and the follow-up messages forming the next stages.
+//
+// Result, if not nil, is stored as by SetResult.
+//
+// Next holds the follow-up messages, enqueued atomically with the
+// completion of the job (see ChainCompleter and PushNext).
+type Output struct {
+	Result []byte
+	Next   []Continuation
+}
+
+// Forward appends msg to the follow-ups of o, routing it to queue after
+// delay. If queue is empty, msg is pushed to the queue it names.
+// Forward returns o to allow chaining.
+func (o *Output) Forward(queue string, msg *message.Message, delay time.Duration) *Output {
+	if queue != "" {
+		msg.Queue = queue
+	}
+	o.Next = append(o.Next, Continuation{Message: msg, Delay: delay})
+	return o
+}
+
+// PipelineHandler is a handler of a pipeline stage returning its output
+// instead of pushing follow-ups itself.
+type PipelineHandler func(ctx context.Context, msg *message.Message) (*Output, error)
+
+// Pipeline wraps fn into a MessageHandler delivering its Output.
+//
+// If fn returns an error, its output is discarded and the job is
+// retried as usual, so the next stage is enqueued only together with
+// the completion of the current one. A nil Output completes the job
+// without follow-ups.
+//
+// The returned MessageHandler fails with ErrNotHandled if it is not
+// invoked by a Worker.
+func Pipeline(fn PipelineHandler) MessageHandler {
+	return func(ctx context.Context, msg *message.Message) error {
+		out, err := fn(ctx, msg)
+		if err != nil || out == nil {
+			return err
+		}
+		at, ok := attemptFrom(ctx)
+		if !ok {
+			return ErrNotHandled
+		}
+		if out.Result != nil {
+			at.setResult(out.Result)
+		}
+		for _, next := range out.Next {
+			at.addNext(next)
+		}
+		return nil
+	}
+}
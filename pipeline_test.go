@@ -0,0 +1,71 @@
+package gqs_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestPipeline(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	next := message.NewMessage()
+	handler := gqs.Pipeline(func(ctx context.Context, msg *message.Message) (*gqs.Output, error) {
+		if msg.Queue == "store" {
+			return nil, nil
+		}
+		next.Payload = append([]byte("parsed "), msg.Payload...)
+		out := &gqs.Output{Result: []byte("ok")}
+		return out.Forward("store", next, 0), nil
+	})
+
+	if err := handler(context.Background(), message.NewMessage()); !errors.Is(err, gqs.ErrNotHandled) {
+		t.Fatalf("expected ErrNotHandled outside of a worker, got %v", err)
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    1,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  200 * time.Millisecond,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_ = worker.Start(ctx)
+
+	msg := message.NewMessage()
+	msg.Queue = "parse"
+	msg.Payload = []byte("input")
+	_ = pusher.Push(ctx, msg, 0)
+
+	time.Sleep(300 * time.Millisecond)
+
+	j, _ := observer.Get(ctx, msg.Id)
+	if j.Status != job.Done || string(j.Result) != "ok" {
+		t.Fatalf("expected first stage Done with result, got %v %q", j.Status, j.Result)
+	}
+	j, _ = observer.Get(ctx, next.Id)
+	if j == nil || j.Queue != "store" || j.Status != job.Done || string(j.Payload) != "parsed input" {
+		t.Fatal("expected second stage to be routed and processed")
+	}
+
+	_ = worker.Stop(time.Second)
+}
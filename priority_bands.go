@@ -0,0 +1,131 @@
+package gqs
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"github.com/romanqed/gqs/job"
+	"math"
+	"slices"
+	"time"
+)
+
+// PriorityBand assigns a share of pulled jobs to a range of priorities
+// (see WorkerConfig.PriorityBands).
+//
+// A band covers the priorities from Min up to the Min of the next
+// higher band. The highest band has no upper bound, and the lowest one
+// covers every priority below its Min as well, so that no job is left
+// out. Weight is the relative share of pulled jobs the band receives
+// while it has eligible jobs; bands with a Weight below one receive a
+// share of one.
+type PriorityBand struct {
+	Min    int
+	Weight int
+}
+
+// priorityBands pulls jobs band by band through filtered pullers,
+// highest band first.
+type priorityBands struct {
+	pullers []Puller
+	weights []int
+	current []int
+}
+
+func newPriorityBands(puller FilterPuller, filter *PullFilter, bands []PriorityBand) *priorityBands {
+	bands = slices.SortedFunc(slices.Values(bands), func(a, b PriorityBand) int {
+		return cmp.Compare(b.Min, a.Min)
+	})
+	ret := &priorityBands{
+		pullers: make([]Puller, len(bands)),
+		weights: make([]int, len(bands)),
+		current: make([]int, len(bands)),
+	}
+	upper := math.MaxInt
+	for i, band := range bands {
+		lower := band.Min
+		if i == len(bands)-1 {
+			lower = math.MinInt
+		}
+		var bandFilter PullFilter
+		if filter != nil {
+			bandFilter = *filter
+		}
+		bandFilter.Priority = &PriorityRange{Min: lower, Max: upper}
+		ret.pullers[i] = puller.WithFilter(&bandFilter)
+		ret.weights[i] = max(band.Weight, 1)
+		upper = band.Min - 1
+	}
+	return ret
+}
+
+// quotas splits batch among the bands by smooth weighted round-robin.
+// The credit carries over between calls, so that bands get their share
+// even if batch is smaller than the number of bands.
+func (pb *priorityBands) quotas(batch int) []int {
+	total := 0
+	for _, weight := range pb.weights {
+		total += weight
+	}
+	ret := make([]int, len(pb.weights))
+	for range batch {
+		best := 0
+		for i, weight := range pb.weights {
+			pb.current[i] += weight
+			if pb.current[i] > pb.current[best] {
+				best = i
+			}
+		}
+		pb.current[best] -= total
+		ret[best]++
+	}
+	return ret
+}
+
+// pull pulls up to batch jobs, giving each band its quota first. The
+// quota left unused by bands without eligible jobs is offered to the
+// remaining bands, highest first, so that no capacity is wasted.
+//
+// A failing band is skipped; its error is returned only if no jobs were
+// pulled, as pulled jobs are already Processing.
+func (pb *priorityBands) pull(ctx context.Context, batch int, lock time.Duration) ([]*job.Job, error) {
+	quotas := pb.quotas(batch)
+	bands := make([][]*job.Job, len(pb.pullers))
+	full := make([]bool, len(pb.pullers))
+	var errs []error
+	left := batch
+	for i, quota := range quotas {
+		if quota == 0 {
+			full[i] = true
+			continue
+		}
+		jobs, err := pb.pullers[i].Pull(ctx, quota, lock)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		bands[i] = jobs
+		full[i] = len(jobs) == quota
+		left -= len(jobs)
+	}
+	for i := range pb.pullers {
+		if left <= 0 {
+			break
+		}
+		if !full[i] {
+			continue
+		}
+		jobs, err := pb.pullers[i].Pull(ctx, left, lock)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		bands[i] = append(bands[i], jobs...)
+		left -= len(jobs)
+	}
+	ret := slices.Concat(bands...)
+	if len(ret) == 0 && len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+	return ret, nil
+}
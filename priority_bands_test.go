@@ -0,0 +1,74 @@
+package gqs_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestWorkerPriorityBands(t *testing.T) {
+	db := newTestDB(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pusher := gsql.NewPusher(db)
+	for _, priority := range []int{10, 0} {
+		for i := 0; i < 8; i++ {
+			msg := message.NewMessage()
+			msg.Priority = priority
+			if err := pusher.Push(ctx, msg, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	var mutex sync.Mutex
+	var handled []int
+	done := make(chan struct{})
+	handler := func(ctx context.Context, msg *message.Message) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		handled = append(handled, msg.Priority)
+		if len(handled) == 4 {
+			close(done)
+		}
+		return nil
+	}
+	worker := gqs.NewWorker(gsql.NewPuller(db), handler, &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    4,
+		PullInterval: time.Hour,
+		LockTimeout:  time.Second,
+		PriorityBands: []gqs.PriorityBand{
+			{Min: 10, Weight: 1},
+			{Min: 0, Weight: 1},
+		},
+	}, nil)
+	if err := worker.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer worker.Stop(time.Second)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("jobs not handled")
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	low := 0
+	for _, priority := range handled[:4] {
+		if priority == 0 {
+			low++
+		}
+	}
+	if low != 2 {
+		t.Fatalf("expected 2 low-priority jobs in the first batch, got %v", handled[:4])
+	}
+}
@@ -0,0 +1,80 @@
+package gqs
+
+import (
+	"fmt"
+	"github.com/romanqed/gqs/job"
+	"slices"
+)
+
+// PullFilter restricts the jobs a Puller hands out, so that a worker
+// only receives a subset of the jobs of its queues, for example to
+// shard consumers by job kind.
+//
+// Types restricts jobs to the listed message types. An empty slice
+// applies no restriction.
+//
+// Tenants restricts jobs to the listed tenants (see
+// message.Message.TenantId), so that a worker can be dedicated to
+// some tenants. An empty slice applies no restriction.
+//
+// Metadata restricts jobs to those whose metadata contains every
+// listed key with the given value, compared by textual representation.
+//
+// Priority, if set, restricts jobs to those whose Priority lies within
+// the range.
+type PullFilter struct {
+	Types    []string
+	Tenants  []string
+	Metadata map[string]string
+	Priority *PriorityRange
+}
+
+// PriorityRange is an inclusive range of job priorities.
+type PriorityRange struct {
+	Min int
+	Max int
+}
+
+// Contains reports whether priority lies within the range.
+func (r PriorityRange) Contains(priority int) bool {
+	return priority >= r.Min && priority <= r.Max
+}
+
+// Match reports whether jb satisfies the filter. A nil filter matches
+// every job.
+//
+// Match serves backends evaluating filters in memory; storage-backed
+// implementations should translate the filter into their queries.
+func (f *PullFilter) Match(jb *job.Job) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.Types) != 0 && !slices.Contains(f.Types, jb.Type) {
+		return false
+	}
+	if len(f.Tenants) != 0 && !slices.Contains(f.Tenants, jb.TenantId) {
+		return false
+	}
+	if f.Priority != nil && !f.Priority.Contains(jb.Priority) {
+		return false
+	}
+	for key, value := range f.Metadata {
+		actual, ok := jb.Metadata[key]
+		if !ok || fmt.Sprint(actual) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterPuller is an optional extension of Puller supporting pull
+// filters.
+//
+// Worker uses it when WorkerConfig.Filter is set.
+type FilterPuller interface {
+
+	// WithFilter returns a Puller behaving like the receiver, except
+	// that Pull hands out only jobs matching filter. The receiver is
+	// not modified.
+	WithFilter(filter *PullFilter) Puller
+}
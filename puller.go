@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"github.com/romanqed/gqs/job"
+	"iter"
 	"time"
 )
 
@@ -22,12 +23,36 @@ var (
 	// extends the lock.
 	ErrLockLost = errors.New("lock lost")
 
+	// ErrCancelRequested indicates that cancellation of a Processing job
+	// was requested from the outside (see Canceler).
+	//
+	// It is returned by lock extensions instead of extending the lease,
+	// which also set job.CancelRequested. Worker then cancels the handler
+	// context with it as the cause and kills the job, which storage
+	// transitions to Cancelled.
+	ErrCancelRequested = errors.New("job cancel requested")
+
 	// ErrCompleteFailed indicates that a job could not be completed due to
 	// a state mismatch or concurrent modification.
 	//
 	// Implementations may return this error when Complete is called on a job
 	// that is not currently in the Processing state.
 	ErrCompleteFailed = errors.New("complete failed")
+
+	// ErrExpired indicates that a job expired before it was delivered
+	// (see message.Message.TTL).
+	//
+	// Implementations killing expired jobs record its text as their
+	// LastError.
+	ErrExpired = errors.New("job expired")
+
+	// ErrDependencyFailed indicates that a job was killed because a job
+	// it depends on (see message.Message.DependsOn) died or was
+	// cancelled.
+	//
+	// Implementations killing such jobs record its text as their
+	// LastError.
+	ErrDependencyFailed = errors.New("dependency failed")
 )
 
 // Puller defines the read-write contract for consuming and managing jobs
@@ -56,10 +81,14 @@ type Puller interface {
 	//   - Attempts is incremented for each pulled job
 	//   - LockedUntil is set to now + lock
 	//
-	// Only jobs whose NextRunAt is in the past and whose lock (if any)
-	// has expired are eligible.
+	// Only jobs whose NextRunAt is in the past, whose lock (if any)
+	// has expired and whose dependencies (see message.Message.DependsOn)
+	// are Done are eligible. Among eligible jobs, those with a higher
+	// Priority should be selected first.
 	//
 	// The returned jobs represent authoritative storage state.
+	// Implementations should set WaitTime of every returned job (see
+	// job.Job.WaitTime); Worker aggregates it in WorkerStats.
 	//
 	// If ctx is canceled, Pull should abort and return an error.
 	Pull(ctx context.Context, batch int, lock time.Duration) ([]*job.Job, error)
@@ -68,10 +97,13 @@ type Puller interface {
 	// the Processing state.
 	//
 	// The lock parameter defines the new lease duration starting from
-	// the time of the call.
+	// the time of the call. On success, implementations should update
+	// job.LockedUntil, as Worker schedules the next extension from it.
 	//
 	// If the job is no longer in Processing state or the caller no longer
-	// owns the lease, ErrLockLost should be returned.
+	// owns the lease, ErrLockLost should be returned. If cancellation of
+	// the job was requested, implementations supporting Canceler must
+	// return ErrCancelRequested.
 	//
 	// ExtendLock must not succeed if the job is already transitioned
 	// to a terminal state.
@@ -99,6 +131,10 @@ type Puller interface {
 	//   - set Status to Pending
 	//   - clear LockedUntil
 	//   - set NextRunAt to now + backoff
+	//   - persist the Priority of the provided job, allowing the caller
+	//     to adjust it before rescheduling
+	//   - persist the LastError of the provided job, allowing the caller
+	//     to record the error that caused the retry
 	//
 	// Return must only succeed if the job is currently in Processing state.
 	// If the lease is lost or the job no longer exists, ErrJobLost or
@@ -108,8 +144,140 @@ type Puller interface {
 	// Kill transitions a job to the Dead state.
 	//
 	// A Dead job is considered permanently failed and will not be retried.
+	// If job.CancelRequested is set, implementations supporting Canceler
+	// transition the job to Cancelled instead.
+	//
+	// Implementations must persist the LastError of the provided job,
+	// allowing the caller to record the error that killed it.
 	//
 	// Implementations may allow Kill to be called on Pending or Processing
 	// jobs. If the job does not exist, ErrJobLost should be returned.
 	Kill(ctx context.Context, job *job.Job) error
 }
+
+// BatchLockExtender is an optional extension of Puller that extends
+// the visibility timeout of several Processing jobs in one operation.
+//
+// Worker uses it to coalesce lease extensions of concurrently running
+// handlers when WorkerConfig.ExtendBatchWindow is set.
+type BatchLockExtender interface {
+
+	// ExtendLockBatch extends the visibility timeout of each job in jobs,
+	// following the same rules as Puller.ExtendLock.
+	//
+	// The returned slice has the same length as jobs. Its i-th element is
+	// nil if the lock of the i-th job was extended, ErrLockLost if the
+	// job is no longer Processing, or ErrCancelRequested if its
+	// cancellation was requested.
+	//
+	// A non-nil error indicates a failure of the whole operation; in this
+	// case no per-job results are returned.
+	ExtendLockBatch(ctx context.Context, jobs []*job.Job, lock time.Duration) ([]error, error)
+}
+
+// BatchCompleter is an optional extension of Puller that completes or
+// reschedules several Processing jobs in one operation.
+//
+// Worker uses it to coalesce completions and retries of concurrently
+// finishing handlers when WorkerConfig.CompleteBatchWindow is set.
+type BatchCompleter interface {
+
+	// CompleteBatch transitions each job in jobs to Done, following the
+	// same rules as Puller.Complete.
+	//
+	// The returned slice has the same length as jobs. Its i-th element
+	// is nil if the i-th job was completed, or the error Complete would
+	// have returned otherwise.
+	//
+	// A non-nil error indicates a failure of the whole operation; in this
+	// case no per-job results are returned.
+	CompleteBatch(ctx context.Context, jobs []*job.Job) ([]error, error)
+
+	// ReturnBatch reschedules each job in jobs with the backoff of the
+	// same index, following the same rules as Puller.Return.
+	//
+	// Per-job results and the overall error follow CompleteBatch.
+	ReturnBatch(ctx context.Context, jobs []*job.Job, backoffs []time.Duration) ([]error, error)
+}
+
+// StreamPuller is an optional extension of Puller that claims jobs one
+// at a time as they are consumed.
+//
+// Worker uses it when WorkerConfig.Stream is set, so that jobs waiting
+// for a free handler are not claimed (and their leases not ticking)
+// ahead of time.
+type StreamPuller interface {
+
+	// PullStream returns an iterator claiming eligible jobs lazily:
+	// each job is transitioned to Processing, following the same rules
+	// as Puller.Pull, only when the consumer requests it.
+	//
+	// The iteration ends when no eligible job is left, when ctx is done
+	// or when the consumer stops. A claim failure is yielded as a non-nil
+	// error and ends the iteration.
+	PullStream(ctx context.Context, lock time.Duration) iter.Seq2[*job.Job, error]
+}
+
+// Releaser is an optional extension of Puller that gives a job back
+// to the queue without counting the current attempt.
+//
+// Worker uses it to return jobs interrupted by shutdown when
+// WorkerConfig.OnCancel is CancelRelease.
+type Releaser interface {
+
+	// Release transitions a job from Processing back to Pending,
+	// making it immediately eligible for pulling.
+	//
+	// Implementations must:
+	//
+	//   - set Status to Pending
+	//   - clear LockedUntil
+	//   - set NextRunAt to now
+	//   - decrement Attempts, undoing the increment made by Pull
+	//
+	// Release follows the same ownership rules as Puller.Return.
+	Release(ctx context.Context, job *job.Job) error
+}
+
+// ResultCompleter is an optional extension of Puller that persists
+// a handler result together with the Done transition.
+//
+// Worker uses it when a handler stores a result with SetResult.
+type ResultCompleter interface {
+
+	// CompleteWithResult behaves like Puller.Complete and additionally
+	// stores result on the job, making it available as job.Job.Result.
+	CompleteWithResult(ctx context.Context, job *job.Job, result []byte) error
+}
+
+// LogSaver is an optional extension of Puller that persists handler
+// log lines of a job.
+//
+// Worker uses it at the end of each attempt in which the handler
+// wrote log lines with SaveLog.
+type LogSaver interface {
+
+	// SaveLogs replaces the stored log lines of job with job.Logs.
+	//
+	// SaveLogs must only succeed if the job is currently Processing.
+	SaveLogs(ctx context.Context, job *job.Job) error
+}
+
+// LockLossRecorder is an optional extension of Puller that records
+// lease losses on the job.
+//
+// Worker uses it whenever extending the lease of an in-flight job
+// fails with ErrLockLost.
+type LockLossRecorder interface {
+
+	// RecordLockLoss increments the LockLosses counter of job and
+	// updates job.LockLosses accordingly.
+	//
+	// If penalty is positive and the job is Pending, its next delivery
+	// is postponed to at least now + penalty * 2^(LockLosses-1), so
+	// that chronically failing leases back off exponentially.
+	//
+	// RecordLockLoss must not fail because of the current job status.
+	// If the job does not exist, ErrJobLost should be returned.
+	RecordLockLoss(ctx context.Context, job *job.Job, penalty time.Duration) error
+}
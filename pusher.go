@@ -2,10 +2,26 @@ package gqs
 
 import (
 	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs/job"
 	"github.com/romanqed/gqs/message"
 	"time"
 )
 
+var (
+	// ErrBatchAborted indicates that a message from a batch was not enqueued
+	// because another message of the same batch failed and the batch was
+	// pushed in atomic mode.
+	//
+	// The message itself is valid and may be safely re-submitted.
+	ErrBatchAborted = errors.New("batch aborted")
+
+	// ErrDuplicateID indicates that a message could not be enqueued
+	// because a job with the same id already exists.
+	ErrDuplicateID = errors.New("duplicate message id")
+)
+
 // Pusher defines the write-side entry point of a queue.
 type Pusher interface {
 
@@ -30,7 +46,89 @@ type Pusher interface {
 	// If Push returns a non-nil error, the message must not be considered
 	// enqueued.
 	//
+	// If a job with the id of msg already exists, implementations must
+	// return an error wrapping ErrDuplicateID, unless they document a
+	// different policy (for example, ignoring or replacing duplicates),
+	// so that producers retrying after a timeout get deterministic
+	// results.
+	//
 	// Implementations may return context-related errors if ctx is canceled
 	// or times out.
 	Push(ctx context.Context, msg *message.Message, delay time.Duration) error
 }
+
+// SchedulePusher is an optional extension of Pusher that schedules
+// messages at an absolute time.
+//
+// Unlike computing a delay from the caller's clock, the schedule is
+// stored as is, so that no drift is introduced between the moment the
+// delay is computed and the moment storage applies it.
+type SchedulePusher interface {
+
+	// PushAt behaves like Pusher.Push, but the message becomes eligible
+	// for pulling at time at instead of after a delay.
+	//
+	// A time in the past makes the message immediately available.
+	PushAt(ctx context.Context, msg *message.Message, at time.Time) error
+}
+
+// BatchMode controls how a BatchPusher reacts to a failure of a single
+// message within a batch.
+type BatchMode uint8
+
+const (
+	// BatchAtomic enqueues either all messages of the batch or none of them.
+	//
+	// On the first failure the batch is aborted: the failed message reports
+	// its own error, all other messages report ErrBatchAborted.
+	BatchAtomic BatchMode = iota
+
+	// BatchContinueOnError enqueues every message independently.
+	//
+	// A failure of one message does not affect the others, so the results
+	// describe exactly which messages must be re-submitted.
+	BatchContinueOnError
+)
+
+// PushResult describes the outcome of pushing a single message of a batch.
+//
+// Id is the identifier of the corresponding message.
+// Err is nil if the message was enqueued.
+type PushResult struct {
+	Id  uuid.UUID
+	Err error
+}
+
+// BatchPusher is an optional extension of Pusher for bulk ingestion.
+type BatchPusher interface {
+
+	// PushBatch enqueues msgs using the same delay for every message.
+	//
+	// The returned slice always has the same length as msgs, and the
+	// i-th result corresponds to the i-th message.
+	//
+	// The mode parameter selects between all-or-nothing (BatchAtomic)
+	// and independent (BatchContinueOnError) semantics.
+	//
+	// In BatchAtomic mode, PushBatch returns the error that caused the
+	// batch to be aborted. In BatchContinueOnError mode, per-message
+	// failures are reported only through results, and a non-nil error
+	// indicates a failure of the batch operation itself (for example,
+	// a canceled context).
+	//
+	// PushBatch must not mutate any message after returning.
+	PushBatch(ctx context.Context, msgs []*message.Message, delay time.Duration, mode BatchMode) ([]PushResult, error)
+}
+
+// SnapshotPusher is an optional extension of Pusher that returns the
+// stored state of the enqueued job.
+//
+// The returned snapshot lets producers display job status right after
+// Push without reading it back, which may not reflect the write yet on
+// deployments reading from lagging replicas.
+type SnapshotPusher interface {
+
+	// PushSnapshot behaves like Pusher.Push and returns the job as it
+	// was persisted by storage.
+	PushSnapshot(ctx context.Context, msg *message.Message, delay time.Duration) (*job.Job, error)
+}
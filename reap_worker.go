@@ -0,0 +1,85 @@
+package gqs
+
+import (
+	"context"
+	"github.com/romanqed/gqs/internal"
+	"time"
+)
+
+// ReapConfig defines the behavior of a ReapWorker.
+//
+// Interval defines how often dead instances are reaped.
+//
+// DeadAfter is the heartbeat age after which an instance is
+// considered dead. It must be noticeably larger than the heartbeat
+// interval of workers, otherwise live instances may be reaped.
+type ReapConfig struct {
+	Interval  time.Duration
+	DeadAfter time.Duration
+}
+
+// ReapWorker periodically invokes a Reaper, reassigning jobs of
+// worker instances that stopped heartbeating.
+//
+// ReapWorker has a strict lifecycle:
+//   - Start may only be called once.
+//   - Stop must be called to terminate the worker.
+//   - Stop waits for the internal task to finish or until the timeout
+//     expires.
+type ReapWorker struct {
+	lcBase
+	reaper    Reaper
+	task      internal.TimerTask
+	log       Logger
+	interval  time.Duration
+	deadAfter time.Duration
+}
+
+// NewReapWorker creates a new ReapWorker using the provided
+// Reaper implementation and configuration.
+//
+// The worker is not started automatically. Call Start to begin
+// periodic reaping.
+func NewReapWorker(reaper Reaper, config *ReapConfig, log Logger) *ReapWorker {
+	return &ReapWorker{
+		reaper:    reaper,
+		log:       loggerOf(log),
+		interval:  config.Interval,
+		deadAfter: config.DeadAfter,
+	}
+}
+
+func (rw *ReapWorker) reap(ctx context.Context) {
+	count, err := rw.reaper.Reap(ctx, time.Now().Add(-rw.deadAfter))
+	if err != nil {
+		rw.log.Error("error while reaping", "error", err)
+		return
+	}
+	if count != 0 {
+		rw.log.Warn("reassigned jobs of dead instances", "count", count)
+	}
+}
+
+// Start begins periodic execution of the reaping task.
+//
+// Start returns ErrDoubleStarted if the worker has already been started.
+//
+// The provided context controls cancellation of the background task.
+func (rw *ReapWorker) Start(ctx context.Context) error {
+	if err := rw.tryStart(); err != nil {
+		return err
+	}
+	rw.task.Start(ctx, rw.reap, rw.interval)
+	return nil
+}
+
+// Stop terminates the background reaping task.
+//
+// Stop waits until the task finishes or the specified timeout expires.
+// If shutdown does not complete within the timeout, ErrStopTimeout
+// is returned.
+//
+// Stop returns ErrDoubleStopped if the worker is not running.
+func (rw *ReapWorker) Stop(timeout time.Duration) error {
+	return rw.tryStop(timeout, rw.task.Stop)
+}
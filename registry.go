@@ -0,0 +1,79 @@
+package gqs
+
+import (
+	"context"
+	"time"
+)
+
+// Instance describes a running Worker registered in a Registry.
+//
+// Id uniquely identifies the worker instance.
+// Host is the host name the instance runs on.
+// StartedAt records when the instance was started.
+// HeartbeatAt records the last heartbeat of the instance.
+// InFlight is the number of jobs the instance was handling at the
+// time of the last heartbeat.
+type Instance struct {
+	Id          string
+	Host        string
+	StartedAt   time.Time
+	HeartbeatAt time.Time
+	InFlight    int64
+}
+
+// Registry records liveness of worker instances.
+//
+// A Worker configured with a Registry periodically reports itself
+// via Heartbeat and removes itself via Deregister on Stop.
+type Registry interface {
+
+	// Heartbeat registers the instance or refreshes its record.
+	//
+	// Implementations set HeartbeatAt to the current time.
+	Heartbeat(ctx context.Context, instance *Instance) error
+
+	// Deregister removes the instance record.
+	//
+	// Deregistering an unknown instance is not an error.
+	Deregister(ctx context.Context, id string) error
+}
+
+// OwnerPuller is an optional extension of Puller recording the worker
+// instance that pulled a job as its owner (see job.Job.LockedBy).
+//
+// Worker uses it to pass its Instance id to the Puller, so that the
+// owner of every Processing job can be identified, for example to find
+// the instance holding a stuck job with ListOptions.LockedBy.
+type OwnerPuller interface {
+
+	// WithInstance returns a Puller behaving like the receiver, except
+	// that pulled jobs are recorded as owned by the instance id. The
+	// receiver is not modified.
+	WithInstance(id string) Puller
+}
+
+// InstanceObserver is an optional extension of Observer listing
+// registered worker instances.
+type InstanceObserver interface {
+
+	// Instances returns all registered instances ordered by Id.
+	//
+	// Instances that stopped heartbeating without deregistering remain
+	// listed until they are reaped; callers may judge liveness by
+	// HeartbeatAt.
+	Instances(ctx context.Context) ([]*Instance, error)
+}
+
+// Reaper reassigns jobs held by dead worker instances.
+//
+// Reaping makes jobs of crashed instances eligible again without
+// waiting for their visibility timeout to expire.
+type Reaper interface {
+
+	// Reap treats every instance whose last heartbeat is older than
+	// deadline as dead: its Processing jobs are returned to Pending
+	// and its record is removed.
+	//
+	// Reap returns the number of reassigned jobs.
+	Reap(ctx context.Context, deadline time.Time) (int64, error)
+}
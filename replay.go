@@ -0,0 +1,104 @@
+package gqs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs/job"
+	"time"
+)
+
+var (
+	// ErrNotTerminal indicates that a job cannot be replayed because it
+	// is not in a terminal state, so a worker may still process it.
+	ErrNotTerminal = errors.New("job is not terminal")
+)
+
+// ReplayOptions defines optional behavior of Replay.
+//
+// Middlewares are applied around the handler, as by Worker.Use.
+// Replay always recovers handler panics, converting them into errors
+// wrapping ErrPanic.
+//
+// Prepare, if set, derives the handler context, for example to inject
+// mocks of side-effecting dependencies.
+//
+// Timeout, if positive, limits the duration of the handler; the
+// handler context is then canceled once it expires.
+type ReplayOptions struct {
+	Middlewares []Middleware
+	Prepare     func(ctx context.Context) context.Context
+	Timeout     time.Duration
+}
+
+// ReplayResult is the outcome of a replayed job.
+//
+// Job is the replayed job snapshot. Err is the error returned by the
+// handler. Took is the duration of the handler.
+//
+// Result, Logs and Next hold what the handler stored with SetResult,
+// SaveLog and PushNext; they are captured instead of being persisted.
+type ReplayResult struct {
+	Job    *job.Job
+	Err    error
+	Took   time.Duration
+	Result []byte
+	Logs   []job.LogLine
+	Next   []Continuation
+}
+
+// Replay loads the terminal job identified by id from obs and executes
+// handler on its message locally, to reproduce failures of Dead jobs
+// without hand-crafting messages.
+//
+// Replay performs no state transitions and persists nothing: the job
+// stays in storage as it is. The handler context is a handler context
+// like the one provided by Worker, with the attempt number and previous
+// error (see Attempt) taken from the stored job.
+//
+// Replay returns ErrNotFound if the job does not exist and
+// ErrNotTerminal if it is not terminal. Handler errors are
+// reported in ReplayResult.Err, not returned.
+func Replay(ctx context.Context, obs Observer, id uuid.UUID, handler MessageHandler, opts *ReplayOptions) (*ReplayResult, error) {
+	if opts == nil {
+		opts = &ReplayOptions{}
+	}
+	jb, err := GetExisting(ctx, obs, id)
+	if err != nil {
+		return nil, err
+	}
+	if !jb.Status.Terminal() {
+		return nil, fmt.Errorf("%w: %s is %s", ErrNotTerminal, id, jb.Status)
+	}
+	if opts.Prepare != nil {
+		ctx = opts.Prepare(ctx)
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	started := time.Now()
+	at := &attempt{
+		job:       jb,
+		number:    jb.Attempts,
+		started:   started,
+		scheduled: jb.NextRunAt,
+		lastError: jb.LastError,
+	}
+	chain := Chain(handler, append([]Middleware{Recover()}, opts.Middlewares...)...)
+	msg := jb.Message
+	err = chain(withAttempt(ctx, at), &msg)
+	ret := &ReplayResult{
+		Job:  jb,
+		Err:  err,
+		Took: time.Since(started),
+		Logs: at.takeLogs(),
+		Next: at.getNext(),
+	}
+	if result, ok := at.getResult(); ok {
+		ret.Result = result
+	}
+	return ret, nil
+}
@@ -0,0 +1,100 @@
+package gqs_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestReplay(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		if string(msg.Payload) == "bad" {
+			return fmt.Errorf("bad payload: %w", gqs.ErrKill)
+		}
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    1,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  200 * time.Millisecond,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msg := message.NewMessage()
+	msg.Payload = []byte("bad")
+	_ = pusher.Push(ctx, msg, 0)
+
+	pending := message.NewMessage()
+	_ = pusher.Push(ctx, pending, time.Hour)
+
+	_ = worker.Start(ctx)
+	time.Sleep(200 * time.Millisecond)
+	_ = worker.Stop(time.Second)
+
+	j, _ := observer.Get(ctx, msg.Id)
+	if j.Status != job.Dead {
+		t.Fatalf("expected Dead, got %v", j.Status)
+	}
+
+	var info gqs.AttemptInfo
+	replayed := func(ctx context.Context, msg *message.Message) error {
+		info, _ = gqs.Attempt(ctx)
+		gqs.SetResult(ctx, []byte("partial"))
+		gqs.PushNext(ctx, message.NewMessage(), 0)
+		return handler(ctx, msg)
+	}
+
+	ret, err := gqs.Replay(ctx, observer, msg.Id, replayed, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(ret.Err, gqs.ErrKill) || ret.Err.Error() != j.LastError {
+		t.Fatalf("expected handler error to be reproduced, got %v", ret.Err)
+	}
+	if string(ret.Result) != "partial" || len(ret.Next) != 1 {
+		t.Fatalf("expected captured outputs, got %+v", ret)
+	}
+	if info.Number != j.Attempts || info.PreviousError != j.LastError {
+		t.Fatalf("unexpected attempt info %+v", info)
+	}
+
+	after, _ := observer.Get(ctx, msg.Id)
+	if after.Status != job.Dead || !after.UpdatedAt.Equal(j.UpdatedAt) {
+		t.Fatal("expected replay to leave the job untouched")
+	}
+	count, _ := observer.Count(ctx, &gqs.ListOptions{})
+	if count != 2 {
+		t.Fatalf("expected no follow-up to be pushed, got %d jobs", count)
+	}
+
+	if _, err := gqs.Replay(ctx, observer, pending.Id, handler, nil); !errors.Is(err, gqs.ErrNotTerminal) {
+		t.Fatalf("expected ErrNotTerminal, got %v", err)
+	}
+	if _, err := gqs.Replay(ctx, observer, uuid.New(), handler, nil); !errors.Is(err, gqs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,259 @@
+package gqs
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/romanqed/gqs/job"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Column identifies a job field written to a CSV report.
+type Column string
+
+const (
+	ColumnId         Column = "id"
+	ColumnQueue      Column = "queue"
+	ColumnType       Column = "type"
+	ColumnStatus     Column = "status"
+	ColumnPriority   Column = "priority"
+	ColumnAttempts   Column = "attempts"
+	ColumnMaxRetries Column = "max_retries"
+	ColumnCreatedAt  Column = "created_at"
+	ColumnUpdatedAt  Column = "updated_at"
+	ColumnNextRunAt  Column = "next_run_at"
+	ColumnLockedBy   Column = "locked_by"
+	ColumnLastError  Column = "last_error"
+	ColumnMetadata   Column = "metadata"
+)
+
+// DefaultColumns are the columns of a CSV report if none are given.
+var DefaultColumns = []Column{
+	ColumnId,
+	ColumnQueue,
+	ColumnType,
+	ColumnStatus,
+	ColumnPriority,
+	ColumnAttempts,
+	ColumnCreatedAt,
+	ColumnUpdatedAt,
+	ColumnNextRunAt,
+	ColumnLastError,
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func (c Column) value(jb *job.Job) (string, error) {
+	switch c {
+	case ColumnId:
+		return jb.Id.String(), nil
+	case ColumnQueue:
+		return jb.Queue, nil
+	case ColumnType:
+		return jb.Type, nil
+	case ColumnStatus:
+		return jb.Status.String(), nil
+	case ColumnPriority:
+		return strconv.Itoa(jb.Priority), nil
+	case ColumnAttempts:
+		return strconv.FormatUint(uint64(jb.Attempts), 10), nil
+	case ColumnMaxRetries:
+		return strconv.FormatUint(uint64(jb.MaxRetries), 10), nil
+	case ColumnCreatedAt:
+		return formatTime(jb.CreatedAt), nil
+	case ColumnUpdatedAt:
+		return formatTime(jb.UpdatedAt), nil
+	case ColumnNextRunAt:
+		return formatTime(jb.NextRunAt), nil
+	case ColumnLockedBy:
+		return jb.LockedBy, nil
+	case ColumnLastError:
+		return jb.LastError, nil
+	case ColumnMetadata:
+		if len(jb.Metadata) == 0 {
+			return "", nil
+		}
+		raw, err := json.Marshal(jb.Metadata)
+		return string(raw), err
+	default:
+		return "", fmt.Errorf("unknown report column %q", string(c))
+	}
+}
+
+// ReportWriter writes jobs of a report in a tabular or line-based format.
+//
+// Flush must be called after the last job to write buffered data.
+type ReportWriter interface {
+
+	// Write appends jb to the report.
+	Write(jb *job.Job) error
+
+	// Flush writes any buffered data to the underlying writer.
+	Flush() error
+}
+
+// CSVWriter writes a report as CSV with a header row, one row per job.
+//
+// Timestamps are formatted as RFC 3339 in UTC, empty if unset; metadata
+// is encoded as a JSON object.
+type CSVWriter struct {
+	w       *csv.Writer
+	columns []Column
+	header  bool
+}
+
+// NewCSVWriter creates a CSVWriter writing the given columns to w.
+// If columns is empty, DefaultColumns are written.
+func NewCSVWriter(w io.Writer, columns ...Column) *CSVWriter {
+	if len(columns) == 0 {
+		columns = DefaultColumns
+	}
+	return &CSVWriter{
+		w:       csv.NewWriter(w),
+		columns: columns,
+	}
+}
+
+func (cw *CSVWriter) writeHeader() error {
+	record := make([]string, len(cw.columns))
+	for i, column := range cw.columns {
+		record[i] = string(column)
+	}
+	cw.header = true
+	return cw.w.Write(record)
+}
+
+// Write appends jb to the report, preceded by the header row if it is
+// the first written job.
+func (cw *CSVWriter) Write(jb *job.Job) error {
+	if !cw.header {
+		if err := cw.writeHeader(); err != nil {
+			return err
+		}
+	}
+	record := make([]string, len(cw.columns))
+	for i, column := range cw.columns {
+		value, err := column.value(jb)
+		if err != nil {
+			return err
+		}
+		record[i] = value
+	}
+	return cw.w.Write(record)
+}
+
+// Flush writes buffered rows to the underlying writer. If no job was
+// written, Flush writes the header row, so that empty reports remain
+// valid CSV documents.
+func (cw *CSVWriter) Flush() error {
+	if !cw.header {
+		if err := cw.writeHeader(); err != nil {
+			return err
+		}
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// NDJSONWriter writes a report as newline-delimited JSON, one job
+// object per line.
+type NDJSONWriter struct {
+	enc *json.Encoder
+}
+
+// NewNDJSONWriter creates an NDJSONWriter writing to w.
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{enc: json.NewEncoder(w)}
+}
+
+// Write appends jb to the report.
+func (nw *NDJSONWriter) Write(jb *job.Job) error {
+	return nw.enc.Encode(jb)
+}
+
+// Flush implements ReportWriter. Lines are written unbuffered, so
+// Flush does nothing.
+func (nw *NDJSONWriter) Flush() error {
+	return nil
+}
+
+// Reporter is an optional extension of QueryObserver streaming jobs
+// directly from the storage cursor, without loading pages into memory.
+type Reporter interface {
+
+	// Report calls fn for every job matching opts, in the order of
+	// opts.Order. Limit, Cursor and Offset are applied as by Query.
+	//
+	// If fn returns an error, iteration stops and the error is returned.
+	Report(ctx context.Context, opts *ListOptions, fn func(jb *job.Job) error) error
+}
+
+const reportPage = 1000
+
+// WriteReport writes every job of obs matching opts to rw, flushes rw
+// and returns the number of written jobs.
+//
+// If obs supports Reporter, jobs are streamed from the storage cursor.
+// Otherwise they are read with Query in keyset pages; opts.Limit then
+// still bounds the total number of written jobs.
+func WriteReport(ctx context.Context, obs QueryObserver, opts *ListOptions, rw ReportWriter) (int64, error) {
+	if opts == nil {
+		opts = &ListOptions{}
+	}
+	var count int64
+	write := func(jb *job.Job) error {
+		if err := rw.Write(jb); err != nil {
+			return err
+		}
+		count++
+		return nil
+	}
+	var err error
+	if reporter, ok := feature[Reporter](obs, CapReport); ok {
+		err = reporter.Report(ctx, opts, write)
+	} else {
+		err = queryReport(ctx, obs, opts, write)
+	}
+	if err != nil {
+		return count, err
+	}
+	return count, rw.Flush()
+}
+
+func queryReport(ctx context.Context, obs QueryObserver, opts *ListOptions, fn func(jb *job.Job) error) error {
+	page := *opts
+	remaining := opts.Limit
+	for {
+		page.Limit = reportPage
+		if remaining > 0 && remaining < reportPage {
+			page.Limit = remaining
+		}
+		ret, err := obs.Query(ctx, &page)
+		if err != nil {
+			return err
+		}
+		for _, jb := range ret.Jobs {
+			if err := fn(jb); err != nil {
+				return err
+			}
+		}
+		if remaining > 0 {
+			remaining -= len(ret.Jobs)
+			if remaining <= 0 {
+				return nil
+			}
+		}
+		if ret.Next == "" {
+			return nil
+		}
+		page.Cursor = ret.Next
+	}
+}
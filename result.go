@@ -0,0 +1,25 @@
+package gqs
+
+import (
+	"context"
+)
+
+// SetResult stores result as the output of the job being handled.
+//
+// ctx must be the context passed to a MessageHandler by Worker.
+// The result is persisted when the job is completed, if the Puller
+// implements ResultCompleter, and is retrievable afterwards via
+// job.Job.Result (for example, through Observer.Get). The result is
+// discarded if the handler returns an error.
+//
+// Calling SetResult again replaces the previous result.
+//
+// SetResult returns false if ctx does not belong to a handler.
+func SetResult(ctx context.Context, result []byte) bool {
+	at, ok := attemptFrom(ctx)
+	if !ok {
+		return false
+	}
+	at.setResult(result)
+	return true
+}
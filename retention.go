@@ -0,0 +1,101 @@
+package gqs
+
+import (
+	"context"
+	"github.com/romanqed/gqs/job"
+	"time"
+)
+
+// RetentionPolicy defines how long terminal jobs of a status are kept.
+//
+// Status must be a terminal status (job.Done, job.Dead or job.Cancelled).
+// Jobs whose UpdatedAt is older than now - MaxAge are deleted.
+type RetentionPolicy struct {
+	Status job.Status
+	MaxAge time.Duration
+}
+
+// RetentionStore is an optional extension of Admin that stores
+// retention policies, so that they can be changed at runtime.
+//
+// CleanWorker reloads policies from a RetentionStore on every cycle
+// when CleanConfig.Retention is set.
+type RetentionStore interface {
+
+	// SetRetention inserts the policy or replaces the policy of the
+	// same status.
+	//
+	// SetRetention returns ErrBadStatus if the status is not terminal.
+	SetRetention(ctx context.Context, policy *RetentionPolicy) error
+
+	// DeleteRetention removes the policy of the given status.
+	DeleteRetention(ctx context.Context, status job.Status) error
+
+	// Retentions returns all stored policies.
+	Retentions(ctx context.Context) ([]*RetentionPolicy, error)
+}
+
+// RetentionConfig defines how long terminal jobs are kept after they
+// ended, per terminal status. It is recorded on every job when it is
+// pushed, so that jobs of different queues or types may be kept for
+// different periods regardless of the policy of CleanWorker.
+//
+// A job with a non-zero retention for its terminal status is deleted
+// by CleanWorker once that period elapsed since its UpdatedAt, and is
+// not affected by the global policy of CleanWorker (see
+// RetentionCleaner). Zero leaves the job to the global policy.
+type RetentionConfig struct {
+	Done      time.Duration
+	Dead      time.Duration
+	Cancelled time.Duration
+}
+
+// Of returns the retention of the given status, zero for non-terminal
+// statuses.
+func (rc *RetentionConfig) Of(status job.Status) time.Duration {
+	switch status {
+	case job.Done:
+		return rc.Done
+	case job.Dead:
+		return rc.Dead
+	case job.Cancelled:
+		return rc.Cancelled
+	default:
+		return 0
+	}
+}
+
+// RetentionCleaner is an optional extension of Cleaner telling jobs
+// with their own retention (see RetentionConfig) apart from the others.
+//
+// CleanWorker calls CleanRetained or ArchiveRetained on every cycle for
+// every terminal status, and applies its global policy with
+// CleanUnretained or ArchiveUnretained, so that jobs with their own
+// retention are kept until it elapsed. Cleaner.Clean is not affected.
+type RetentionCleaner interface {
+
+	// CleanRetained deletes at most limit jobs of the given terminal
+	// status whose retention for the status elapsed since their
+	// UpdatedAt, and returns the number of deleted jobs. Fewer than
+	// limit deleted jobs mean that no such job is left; zero or
+	// negative limit deletes all of them. If status is job.Unknown,
+	// jobs of all terminal statuses are considered.
+	//
+	// CleanRetained returns ErrBadStatus if status is not terminal.
+	CleanRetained(ctx context.Context, status job.Status, limit int) (int64, error)
+
+	// ArchiveRetained moves the jobs CleanRetained would delete into
+	// the archive instead (see Archiver). Implementations without
+	// archive support return ErrArchiveUnsupported.
+	ArchiveRetained(ctx context.Context, status job.Status) (int64, error)
+
+	// CleanUnretained deletes at most limit jobs matching the given
+	// status and time condition, interpreted as by Cleaner.Clean, that
+	// have no retention of their own for their status. Limit is
+	// interpreted as by CleanRetained.
+	CleanUnretained(ctx context.Context, status job.Status, before *time.Time, limit int) (int64, error)
+
+	// ArchiveUnretained moves the jobs CleanUnretained would delete
+	// into the archive instead (see Archiver).
+	ArchiveUnretained(ctx context.Context, status job.Status, before *time.Time) (int64, error)
+}
@@ -0,0 +1,72 @@
+package gqs
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrRetryAfter matches errors returned by RetryAfter and RetryAt
+// with errors.Is.
+var ErrRetryAfter = errors.New("retry after")
+
+// RetryAfterError requests a specific reschedule of the failed job,
+// bypassing the backoff computation of the retry policy.
+//
+// If At is set, the job is rescheduled to run at At; otherwise it is
+// rescheduled Delay after the failure. Err optionally carries the
+// underlying cause, which is recorded as the last error of the job.
+//
+// The retry limit still applies: a job that exceeded its MaxRetries,
+// or the MaxRetries of the applicable BackoffConfig, becomes Dead.
+type RetryAfterError struct {
+	Delay time.Duration
+	At    time.Time
+	Err   error
+}
+
+// RetryAfter returns an error requesting the job to be retried after d,
+// for example as indicated by the Retry-After header of an upstream
+// response. Use WithCause to attach the underlying error.
+func RetryAfter(d time.Duration) *RetryAfterError {
+	return &RetryAfterError{Delay: d}
+}
+
+// RetryAt returns an error requesting the job to be retried at t.
+func RetryAt(t time.Time) *RetryAfterError {
+	return &RetryAfterError{At: t}
+}
+
+// WithCause sets the underlying error and returns e.
+func (e *RetryAfterError) WithCause(err error) *RetryAfterError {
+	e.Err = err
+	return e
+}
+
+func (e *RetryAfterError) delay(now time.Time) time.Duration {
+	if e.At.IsZero() {
+		return max(e.Delay, 0)
+	}
+	return max(e.At.Sub(now), 0)
+}
+
+func (e *RetryAfterError) Error() string {
+	when := e.Delay.String()
+	if !e.At.IsZero() {
+		when = e.At.Format(time.RFC3339)
+	}
+	if e.Err == nil {
+		return fmt.Sprintf("retry after %s", when)
+	}
+	return fmt.Sprintf("retry after %s: %v", when, e.Err)
+}
+
+// Unwrap returns the underlying error, if any.
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrRetryAfter.
+func (e *RetryAfterError) Is(target error) bool {
+	return target == ErrRetryAfter
+}
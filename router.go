@@ -0,0 +1,63 @@
+package gqs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/romanqed/gqs/message"
+)
+
+var (
+	// ErrNoRoute indicates that Router has no handler registered for
+	// the message Type and no fallback handler is configured.
+	//
+	// The job follows the normal retry path, so that messages produced
+	// ahead of a consumer deployment are not lost. Register a fallback
+	// returning ErrKill to fail unknown types permanently instead.
+	ErrNoRoute = errors.New("no route for message type")
+)
+
+// Router dispatches messages to handlers by message.Message.Type.
+//
+// Router.Route satisfies the MessageHandler signature and may be passed
+// directly to NewWorker.
+//
+// Handlers must be registered before the worker is started;
+// Router is not safe for concurrent registration and routing.
+type Router struct {
+	routes   map[string]MessageHandler
+	fallback MessageHandler
+}
+
+// NewRouter creates an empty Router.
+func NewRouter() *Router {
+	return &Router{
+		routes: make(map[string]MessageHandler),
+	}
+}
+
+// Register associates handler with the given message type,
+// replacing any previously registered handler.
+func (r *Router) Register(kind string, handler MessageHandler) {
+	r.routes[kind] = handler
+}
+
+// Fallback sets the handler invoked for messages whose type
+// is not registered.
+func (r *Router) Fallback(handler MessageHandler) {
+	r.fallback = handler
+}
+
+// Route dispatches msg to the handler registered for msg.Type.
+//
+// If no handler is registered, Route invokes the fallback handler,
+// or returns an error wrapping ErrNoRoute if there is none.
+func (r *Router) Route(ctx context.Context, msg *message.Message) error {
+	if handler, ok := r.routes[msg.Type]; ok {
+		return handler(ctx, msg)
+	}
+	if r.fallback != nil {
+		return r.fallback(ctx, msg)
+	}
+	return fmt.Errorf("%w: %q", ErrNoRoute, msg.Type)
+}
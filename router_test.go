@@ -0,0 +1,43 @@
+package gqs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/message"
+)
+
+func TestRouter(t *testing.T) {
+	router := gqs.NewRouter()
+
+	var routed string
+	router.Register("email", func(ctx context.Context, msg *message.Message) error {
+		routed = "email"
+		return nil
+	})
+
+	ctx := context.Background()
+
+	msg := message.NewMessage()
+	msg.Type = "email"
+	if err := router.Route(ctx, msg); err != nil {
+		t.Fatal(err)
+	}
+	if routed != "email" {
+		t.Fatalf("expected email handler, got %q", routed)
+	}
+
+	msg.Type = "sms"
+	if err := router.Route(ctx, msg); !errors.Is(err, gqs.ErrNoRoute) {
+		t.Fatalf("expected ErrNoRoute, got %v", err)
+	}
+
+	router.Fallback(func(ctx context.Context, msg *message.Message) error {
+		return gqs.ErrKill
+	})
+	if err := router.Route(ctx, msg); !errors.Is(err, gqs.ErrKill) {
+		t.Fatalf("expected fallback ErrKill, got %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+package gqs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/romanqed/gqs/message"
+	"time"
+)
+
+// RuleMatch defines the conditions a message must satisfy for a Rule
+// to apply.
+//
+// Empty fields match any message. Queue and Type are compared exactly.
+// Every Metadata entry must be present in the message metadata with
+// an equal string representation (as formatted by fmt.Sprint).
+type RuleMatch struct {
+	Queue    string            `json:"queue,omitempty"`
+	Type     string            `json:"type,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+func (rm *RuleMatch) matches(msg *message.Message) bool {
+	if rm.Queue != "" && rm.Queue != msg.Queue {
+		return false
+	}
+	if rm.Type != "" && rm.Type != msg.Type {
+		return false
+	}
+	for key, expected := range rm.Metadata {
+		value, ok := msg.Metadata[key]
+		if !ok || fmt.Sprint(value) != expected {
+			return false
+		}
+	}
+	return true
+}
+
+// RuleAction defines the changes applied to a matched message.
+//
+// Nil fields leave the corresponding property unchanged. Delay
+// replaces the delay requested by the producer.
+//
+// When decoded from JSON, Delay is a string accepted by
+// time.ParseDuration, such as "1m30s".
+type RuleAction struct {
+	Queue      *string        `json:"queue,omitempty"`
+	Priority   *int           `json:"priority,omitempty"`
+	Delay      *time.Duration `json:"delay,omitempty"`
+	MaxRetries *uint32        `json:"max_retries,omitempty"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (ra *RuleAction) UnmarshalJSON(data []byte) error {
+	type plain RuleAction
+	var raw struct {
+		plain
+		Delay *string `json:"delay,omitempty"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*ra = RuleAction(raw.plain)
+	ra.Delay = nil
+	if raw.Delay != nil {
+		delay, err := time.ParseDuration(*raw.Delay)
+		if err != nil {
+			return fmt.Errorf("bad rule delay: %w", err)
+		}
+		ra.Delay = &delay
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (ra RuleAction) MarshalJSON() ([]byte, error) {
+	type plain RuleAction
+	var raw struct {
+		plain
+		Delay *string `json:"delay,omitempty"`
+	}
+	raw.plain = plain(ra)
+	if ra.Delay != nil {
+		delay := ra.Delay.String()
+		raw.Delay = &delay
+	}
+	return json.Marshal(raw)
+}
+
+func (ra *RuleAction) apply(msg *message.Message, delay time.Duration) time.Duration {
+	if ra.Queue != nil {
+		msg.Queue = *ra.Queue
+	}
+	if ra.Priority != nil {
+		msg.Priority = *ra.Priority
+	}
+	if ra.MaxRetries != nil {
+		msg.MaxRetries = *ra.MaxRetries
+	}
+	if ra.Delay != nil {
+		return *ra.Delay
+	}
+	return delay
+}
+
+// Rule is a single push-time routing rule: when a message satisfies
+// Match, Action is applied to it.
+//
+// Name is optional and used for diagnostics only.
+type Rule struct {
+	Name   string     `json:"name,omitempty"`
+	Match  RuleMatch  `json:"match"`
+	Action RuleAction `json:"action"`
+}
+
+// Rules is an ordered list of routing rules.
+//
+// Rules may be defined in code or decoded from configuration with
+// ParseRules.
+type Rules []Rule
+
+// ParseRules decodes a JSON array of rules.
+func ParseRules(data []byte) (Rules, error) {
+	var ret Rules
+	if err := json.Unmarshal(data, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Apply applies the first rule matching msg and returns the resulting
+// delay. Subsequent rules are not evaluated.
+//
+// If no rule matches, msg is left unchanged and delay is returned as is.
+func (rs Rules) Apply(msg *message.Message, delay time.Duration) time.Duration {
+	for i := range rs {
+		if rs[i].Match.matches(msg) {
+			return rs[i].Action.apply(msg, delay)
+		}
+	}
+	return delay
+}
+
+// RulesPusher is a Pusher applying Rules to every message before
+// delegating to the underlying Pusher.
+//
+// Centralizing routing policy in RulesPusher keeps producers unaware
+// of queue layout, priorities and retry limits.
+//
+// The caller's message is never modified: rules are applied to
+// a shallow copy of it.
+type RulesPusher struct {
+	pusher Pusher
+	rules  Rules
+}
+
+// NewRulesPusher creates a RulesPusher delegating to pusher.
+func NewRulesPusher(pusher Pusher, rules Rules) *RulesPusher {
+	return &RulesPusher{
+		pusher: pusher,
+		rules:  rules,
+	}
+}
+
+// Push applies the rules to a copy of msg and enqueues the copy.
+func (rp *RulesPusher) Push(ctx context.Context, msg *message.Message, delay time.Duration) error {
+	routed := *msg
+	delay = rp.rules.Apply(&routed, delay)
+	return rp.pusher.Push(ctx, &routed, delay)
+}
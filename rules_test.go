@@ -0,0 +1,64 @@
+package gqs_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/message"
+)
+
+type capturePusher struct {
+	msg   message.Message
+	delay time.Duration
+}
+
+func (cp *capturePusher) Push(ctx context.Context, msg *message.Message, delay time.Duration) error {
+	cp.msg = *msg
+	cp.delay = delay
+	return nil
+}
+
+func TestRulesPusher(t *testing.T) {
+	rules, err := gqs.ParseRules([]byte(`[
+		{"name": "vip", "match": {"type": "email", "metadata": {"tier": "gold"}},
+		 "action": {"queue": "vip", "priority": 10, "max_retries": 7}},
+		{"match": {"type": "email"}, "action": {"queue": "bulk", "delay": "1m"}}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	capture := &capturePusher{}
+	pusher := gqs.NewRulesPusher(capture, rules)
+	ctx := context.Background()
+
+	msg := message.NewMessage()
+	msg.Type = "email"
+	msg.Set("tier", "gold")
+	if err := pusher.Push(ctx, msg, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if capture.msg.Queue != "vip" || capture.msg.Priority != 10 || capture.msg.MaxRetries != 7 {
+		t.Fatalf("vip rule not applied: %+v", capture.msg)
+	}
+	if capture.delay != time.Second {
+		t.Fatalf("expected producer delay, got %v", capture.delay)
+	}
+	if msg.Queue != "" {
+		t.Fatal("caller message was modified")
+	}
+
+	msg.Set("tier", "silver")
+	_ = pusher.Push(ctx, msg, 0)
+	if capture.msg.Queue != "bulk" || capture.delay != time.Minute {
+		t.Fatalf("bulk rule not applied: %+v %v", capture.msg, capture.delay)
+	}
+
+	msg.Type = "sms"
+	_ = pusher.Push(ctx, msg, 0)
+	if capture.msg.Queue != "" || capture.delay != 0 {
+		t.Fatalf("unexpected routing: %+v %v", capture.msg, capture.delay)
+	}
+}
@@ -0,0 +1,114 @@
+package gqs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// DefaultStopTimeout is the per-service shutdown timeout used by Run
+// when RunConfig.StopTimeout is zero.
+const DefaultStopTimeout = 30 * time.Second
+
+// Service is a background component with a strict Start/Stop lifecycle.
+//
+// Worker and CleanWorker implement Service. Worker also implements
+// Drainer.
+type Service interface {
+	Start(ctx context.Context) error
+	Stop(timeout time.Duration) error
+}
+
+// Drainer is an optional extension of Service that supports finishing
+// already accepted work before Stop is called.
+type Drainer interface {
+
+	// Drain stops accepting new work and blocks until accepted work
+	// is finished or ctx is done.
+	Drain(ctx context.Context) error
+}
+
+// RunConfig defines the behavior of RunWithConfig.
+//
+// Signals lists the OS signals that trigger shutdown. If empty,
+// os.Interrupt and syscall.SIGTERM are used.
+//
+// StopTimeout is the timeout passed to Stop of each service.
+// If zero, DefaultStopTimeout is used.
+//
+// DrainTimeout enables drain mode: before stopping, every service
+// implementing Drainer is drained for at most DrainTimeout.
+// Zero disables draining.
+type RunConfig struct {
+	Signals      []os.Signal
+	StopTimeout  time.Duration
+	DrainTimeout time.Duration
+}
+
+func stopAll(services []Service, timeout time.Duration) error {
+	var errs []error
+	for i := len(services) - 1; i >= 0; i-- {
+		errs = append(errs, services[i].Stop(timeout))
+	}
+	return errors.Join(errs...)
+}
+
+func drainAll(ctx context.Context, services []Service, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var errs []error
+	for _, service := range services {
+		if drainer, ok := service.(Drainer); ok {
+			errs = append(errs, drainer.Drain(ctx))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run starts services and blocks until ctx is canceled or the process
+// receives SIGINT or SIGTERM, then gracefully stops them.
+//
+// Run is equivalent to RunWithConfig with a zero RunConfig.
+func Run(ctx context.Context, services ...Service) error {
+	return RunWithConfig(ctx, &RunConfig{}, services...)
+}
+
+// RunWithConfig starts services in the given order and blocks until
+// ctx is canceled or one of the configured signals is received.
+//
+// Services are started with a context detached from ctx cancellation,
+// so in-flight work is not interrupted by the shutdown signal itself;
+// instead, services are drained (if enabled) and then stopped in
+// reverse order.
+//
+// If any service fails to start, already started services are stopped
+// and the start error is returned.
+//
+// RunWithConfig returns the joined errors of draining and stopping.
+func RunWithConfig(ctx context.Context, config *RunConfig, services ...Service) error {
+	signals := config.Signals
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	timeout := config.StopTimeout
+	if timeout == 0 {
+		timeout = DefaultStopTimeout
+	}
+	base := context.WithoutCancel(ctx)
+	ctx, stop := signal.NotifyContext(ctx, signals...)
+	defer stop()
+	for i, service := range services {
+		if err := service.Start(base); err != nil {
+			return errors.Join(err, stopAll(services[:i], timeout))
+		}
+	}
+	<-ctx.Done()
+	var drainErr error
+	if config.DrainTimeout > 0 {
+		drainErr = drainAll(base, services, config.DrainTimeout)
+	}
+	return errors.Join(drainErr, stopAll(services, timeout))
+}
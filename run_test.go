@@ -0,0 +1,82 @@
+package gqs_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+)
+
+type mockService struct {
+	name     string
+	startErr error
+	mu       *sync.Mutex
+	events   *[]string
+}
+
+func (m *mockService) record(event string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	*m.events = append(*m.events, event+" "+m.name)
+}
+
+func (m *mockService) Start(ctx context.Context) error {
+	if m.startErr != nil {
+		return m.startErr
+	}
+	m.record("start")
+	return nil
+}
+
+func (m *mockService) Stop(timeout time.Duration) error {
+	m.record("stop")
+	return nil
+}
+
+func TestRunOrder(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+
+	first := &mockService{name: "first", mu: &mu, events: &events}
+	second := &mockService{name: "second", mu: &mu, events: &events}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	if err := gqs.Run(ctx, first, second); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"start first", "start second", "stop second", "stop first"}
+	if len(events) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, events)
+		}
+	}
+}
+
+func TestRunStartFailure(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+
+	startErr := errors.New("start failed")
+	first := &mockService{name: "first", mu: &mu, events: &events}
+	second := &mockService{name: "second", startErr: startErr, mu: &mu, events: &events}
+
+	err := gqs.Run(context.Background(), first, second)
+	if !errors.Is(err, startErr) {
+		t.Fatalf("expected start error, got %v", err)
+	}
+	if len(events) != 2 || events[1] != "stop first" {
+		t.Fatalf("expected started services to be stopped, got %v", events)
+	}
+}
@@ -0,0 +1,99 @@
+package gqs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// QueueMetrics describes the backlog of a single queue, as needed by
+// autoscalers sizing worker deployments.
+//
+// Ready is the number of jobs eligible for pulling now, including
+// Processing jobs with an expired lease. Scheduled is the number of
+// Pending jobs waiting for their delay or backoff. Processing is the
+// number of jobs currently leased by workers.
+//
+// Backlog is Ready plus Processing: the number of jobs requiring
+// a worker at the moment, the usual autoscaling target.
+//
+// OldestReady is the time the oldest ready job has waited since it
+// became eligible; it is zero if no job is ready. Durations are
+// encoded in nanoseconds.
+type QueueMetrics struct {
+	Queue       string        `json:"queue"`
+	Ready       int64         `json:"ready"`
+	Scheduled   int64         `json:"scheduled"`
+	Processing  int64         `json:"processing"`
+	Backlog     int64         `json:"backlog"`
+	OldestReady time.Duration `json:"oldest_ready"`
+}
+
+// MetricsObserver is an optional extension of Observer reporting
+// per-queue backlog metrics.
+type MetricsObserver interface {
+
+	// QueueMetrics returns the metrics of every queue with at least
+	// one Pending or Processing job, ordered by queue name.
+	QueueMetrics(ctx context.Context) ([]QueueMetrics, error)
+}
+
+// ScalerReport is the response body of the handler returned by
+// NewScalerHandler.
+//
+// Backlog is the sum of the backlogs of the reported queues, so that
+// external autoscalers may read a single value (for example, with the
+// KEDA metrics-api scaler and valueLocation "backlog").
+type ScalerReport struct {
+	Backlog int64          `json:"backlog"`
+	Queues  []QueueMetrics `json:"queues"`
+}
+
+// NewScalerHandler returns an HTTP handler exposing the queue metrics
+// of obs as a JSON ScalerReport, for external autoscalers.
+//
+// The handler accepts GET requests. One or more "queue" query
+// parameters restrict the report to the given queues; queues without
+// Pending or Processing jobs are reported with zero metrics, so that
+// autoscalers can scale them down. Without the parameter, every queue
+// with work is reported.
+func NewScalerHandler(obs MetricsObserver) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		metrics, err := obs.QueueMetrics(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		report := scalerReport(metrics, r.URL.Query()["queue"])
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
+
+func scalerReport(metrics []QueueMetrics, queues []string) *ScalerReport {
+	ret := &ScalerReport{Queues: []QueueMetrics{}}
+	if len(queues) == 0 {
+		ret.Queues = append(ret.Queues, metrics...)
+	}
+	for _, queue := range queues {
+		i := slices.IndexFunc(metrics, func(m QueueMetrics) bool {
+			return m.Queue == queue
+		})
+		if i < 0 {
+			ret.Queues = append(ret.Queues, QueueMetrics{Queue: queue})
+			continue
+		}
+		ret.Queues = append(ret.Queues, metrics[i])
+	}
+	for _, m := range ret.Queues {
+		ret.Backlog += m.Backlog
+	}
+	return ret
+}
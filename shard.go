@@ -0,0 +1,96 @@
+package gqs
+
+import (
+	"context"
+	"fmt"
+	"github.com/romanqed/gqs/message"
+	"hash/fnv"
+	"time"
+)
+
+// ShardRouter assigns messages to shards.
+//
+// Shard must return a value in [0, shards) and must be deterministic:
+// messages that should keep their relative order, such as all jobs of
+// one order, must be assigned to the same shard.
+type ShardRouter interface {
+	Shard(msg *message.Message, shards int) int
+}
+
+// ShardRouterFunc adapts an ordinary function to ShardRouter.
+type ShardRouterFunc func(msg *message.Message, shards int) int
+
+// Shard calls f(msg, shards).
+func (f ShardRouterFunc) Shard(msg *message.Message, shards int) int {
+	return f(msg, shards)
+}
+
+func hashShard(data []byte, shards int) int {
+	h := fnv.New32a()
+	h.Write(data)
+	return int(h.Sum32() % uint32(shards))
+}
+
+// IdRouter is the default ShardRouter spreading messages evenly across
+// shards by a hash of their id.
+//
+// IdRouter gives no ordering guarantees between related messages; use
+// KeyRouter when per-entity ordering is required.
+type IdRouter struct{}
+
+// Shard implements ShardRouter.
+func (IdRouter) Shard(msg *message.Message, shards int) int {
+	return hashShard(msg.Id[:], shards)
+}
+
+// KeyRouter is a ShardRouter assigning messages by a hash of a metadata
+// field, so that related messages, for example those sharing an order
+// id, land on the same shard, while unrelated ones spread evenly.
+//
+// Values are compared by textual representation. Messages without the
+// field are routed by id, as with IdRouter.
+type KeyRouter struct {
+	Key string
+}
+
+// Shard implements ShardRouter.
+func (kr KeyRouter) Shard(msg *message.Message, shards int) int {
+	value, ok := msg.Metadata[kr.Key]
+	if !ok {
+		return IdRouter{}.Shard(msg, shards)
+	}
+	return hashShard([]byte(fmt.Sprint(value)), shards)
+}
+
+// ShardedPusher is a Pusher distributing messages across several
+// Pushers, for example one per database or table partition, using
+// a ShardRouter.
+type ShardedPusher struct {
+	shards []Pusher
+	router ShardRouter
+}
+
+// NewShardedPusher creates a ShardedPusher over shards using router.
+// If router is nil, IdRouter is used.
+//
+// The order of shards is significant: changing it, or their number,
+// reassigns messages to different shards.
+func NewShardedPusher(shards []Pusher, router ShardRouter) *ShardedPusher {
+	if router == nil {
+		router = IdRouter{}
+	}
+	return &ShardedPusher{
+		shards: shards,
+		router: router,
+	}
+}
+
+// Shard returns the index of the shard msg is routed to.
+func (sp *ShardedPusher) Shard(msg *message.Message) int {
+	return sp.router.Shard(msg, len(sp.shards))
+}
+
+// Push enqueues msg into the shard selected by the router.
+func (sp *ShardedPusher) Push(ctx context.Context, msg *message.Message, delay time.Duration) error {
+	return sp.shards[sp.Shard(msg)].Push(ctx, msg, delay)
+}
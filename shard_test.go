@@ -0,0 +1,62 @@
+package gqs_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/message"
+)
+
+type countPusher struct {
+	count int
+}
+
+func (cp *countPusher) Push(ctx context.Context, msg *message.Message, delay time.Duration) error {
+	cp.count++
+	return nil
+}
+
+func TestShardedPusherKeyRouter(t *testing.T) {
+	shards := []*countPusher{{}, {}, {}, {}}
+	pushers := make([]gqs.Pusher, len(shards))
+	for i, shard := range shards {
+		pushers[i] = shard
+	}
+	pusher := gqs.NewShardedPusher(pushers, gqs.KeyRouter{Key: "order"})
+	ctx := context.Background()
+
+	first := -1
+	for i := 0; i < 10; i++ {
+		msg := message.NewMessage()
+		msg.Set("order", 42)
+		shard := pusher.Shard(msg)
+		if first == -1 {
+			first = shard
+		}
+		if shard != first {
+			t.Fatal("expected messages of one order to share a shard")
+		}
+		if err := pusher.Push(ctx, msg, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if shards[first].count != 10 {
+		t.Fatalf("expected 10 messages in shard %d, got %d", first, shards[first].count)
+	}
+
+	for i := 0; i < 400; i++ {
+		msg := message.NewMessage()
+		msg.Set("order", fmt.Sprintf("order-%d", i))
+		if err := pusher.Push(ctx, msg, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, shard := range shards {
+		if shard.count < 50 {
+			t.Fatalf("expected unrelated messages to spread, shard %d got %d", i, shard.count)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/uptrace/bun"
+	"slices"
+	"time"
+)
+
+// Admin implements gqs.Admin, gqs.Canceler and gqs.RetentionStore
+// using a SQL backend.
+//
+// Every bulk operation is performed with a single UPDATE or DELETE
+// statement and does not coordinate with running workers beyond the
+// status checks. Retention policies are stored in the retention_policies
+// table, created by InitDB.
+type Admin struct {
+	db    *bun.DB
+	clock *Clock
+}
+
+// AdminOptions defines optional behavior of an Admin.
+//
+// Clock, if set, provides the time written to next_run_at and
+// updated_at instead of the local clock (see Clock).
+type AdminOptions struct {
+	Clock *Clock
+}
+
+// NewAdmin creates a new SQL-backed Admin.
+//
+// The provided *bun.DB must be properly configured and connected.
+// Schema initialization must be completed before using Admin.
+func NewAdmin(db *bun.DB) *Admin {
+	return NewAdminWithOptions(db, &AdminOptions{})
+}
+
+// NewAdminWithOptions creates a new SQL-backed Admin using the
+// provided options.
+func NewAdminWithOptions(db *bun.DB, opts *AdminOptions) *Admin {
+	return &Admin{
+		db:    db,
+		clock: opts.Clock,
+	}
+}
+
+// restrictStatuses returns a copy of filter targeting allowed statuses
+// only, or ErrBadStatus if the filter lists a status outside allowed.
+func restrictStatuses(filter *gqs.ListOptions, allowed ...job.Status) (*gqs.ListOptions, error) {
+	ret := *filter
+	if len(ret.Statuses) == 0 {
+		ret.Statuses = allowed
+		return &ret, nil
+	}
+	for _, status := range ret.Statuses {
+		if !slices.Contains(allowed, status) {
+			return nil, gqs.ErrBadStatus
+		}
+	}
+	return &ret, nil
+}
+
+func (a *Admin) update(ctx context.Context, op string, filter *gqs.ListOptions, set func(*bun.UpdateQuery, time.Time)) (int64, error) {
+	now := a.clock.now(ctx, a.db)
+	query := a.db.NewUpdate().
+		Model((*jobModel)(nil)).
+		Set("version = version + 1").
+		Set("updated_at = ?", now)
+	set(query, now)
+	res, err := query.
+		ApplyQueryBuilder(applyFilter(a.db.Dialect().Name(), filter)).
+		Exec(ctx)
+	if err != nil {
+		return 0, wrap(op, uuid.Nil, err)
+	}
+	return getAffected(res), nil
+}
+
+// KillByStatus sets status to Dead for matching Pending, Scheduled and
+// Processing jobs. locked_until is cleared and updated_at is refreshed.
+func (a *Admin) KillByStatus(ctx context.Context, filter *gqs.ListOptions) (int64, error) {
+	filter, err := restrictStatuses(filter, job.Pending, job.Scheduled, job.Processing)
+	if err != nil {
+		return 0, err
+	}
+	return a.update(ctx, "kill", filter, func(q *bun.UpdateQuery, _ time.Time) {
+		q.Set("status = ?", job.Dead).
+			Set("locked_until = NULL")
+	})
+}
+
+// RequeueByStatus sets status to Pending for matching Done, Dead and
+// Cancelled jobs. attempts is reset to zero, a cancellation request is cleared,
+// next_run_at is set to now and updated_at is refreshed.
+func (a *Admin) RequeueByStatus(ctx context.Context, filter *gqs.ListOptions) (int64, error) {
+	filter, err := restrictStatuses(filter, job.TerminalStatuses...)
+	if err != nil {
+		return 0, err
+	}
+	return a.update(ctx, "requeue", filter, func(q *bun.UpdateQuery, now time.Time) {
+		q.Set("status = ?", job.Pending).
+			Set("attempts = 0").
+			Set("cancel_requested = ?", false).
+			Set("next_run_at = ?", now).
+			Set("locked_until = NULL")
+	})
+}
+
+// DeleteByIds deletes the jobs with the given ids that are not
+// Processing.
+func (a *Admin) DeleteByIds(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	res, err := a.db.NewDelete().
+		Model((*jobModel)(nil)).
+		Where("id IN (?)", bun.In(ids)).
+		Where("status != ?", job.Processing).
+		Exec(ctx)
+	if err != nil {
+		return 0, wrap("delete", uuid.Nil, err)
+	}
+	return getAffected(res), nil
+}
+
+// UpdateNextRun sets next_run_at of matching Pending and Scheduled jobs
+// to at and refreshes updated_at. Jobs become Scheduled if at is in the
+// future and Pending otherwise.
+func (a *Admin) UpdateNextRun(ctx context.Context, filter *gqs.ListOptions, at time.Time) (int64, error) {
+	filter, err := restrictStatuses(filter, waiting...)
+	if err != nil {
+		return 0, err
+	}
+	return a.update(ctx, "reschedule", filter, func(q *bun.UpdateQuery, now time.Time) {
+		q.Set("status = ?", waitStatus(now, at)).
+			Set("next_run_at = ?", at)
+	})
+}
+
+// Cancel sets status to Cancelled for a Pending or Scheduled job, or
+// sets cancel_requested for a Processing job, in a single UPDATE
+// statement.
+// The last_error of a canceled waiting job is set to the text of
+// ErrCancelRequested.
+//
+// If no row is affected, the job is read to report ErrNotFound or
+// ErrBadStatus.
+func (a *Admin) Cancel(ctx context.Context, id uuid.UUID) error {
+	now := a.clock.now(ctx, a.db)
+	res, err := a.db.NewUpdate().
+		Model((*jobModel)(nil)).
+		Set("cancel_requested = (status = ?)", job.Processing).
+		Set("last_error = CASE WHEN status = ? THEN last_error ELSE ? END", job.Processing, gqs.ErrCancelRequested.Error()).
+		// the request is addressed to the worker holding the job, so
+		// it must not invalidate its snapshot
+		Set("version = CASE WHEN status = ? THEN version ELSE version + 1 END", job.Processing).
+		// status is assigned last, as MySQL evaluates assignments in order
+		Set("status = CASE WHEN status = ? THEN status ELSE ? END", job.Processing, job.Cancelled).
+		Set("updated_at = ?", now).
+		Where("id = ?", id).
+		Where("status IN (?)", bun.In([]job.Status{job.Pending, job.Scheduled, job.Processing})).
+		Exec(ctx)
+	if err != nil {
+		return wrap("cancel", id, err)
+	}
+	if isAffected(res) {
+		return nil
+	}
+	jb, err := get(ctx, a.db, id)
+	if err != nil {
+		return wrap("cancel", id, err)
+	}
+	if jb == nil {
+		return fmt.Errorf("%w: %s", gqs.ErrNotFound, id)
+	}
+	return fmt.Errorf("%w: %s is %s", gqs.ErrBadStatus, id, jb.Status)
+}
+
+type retentionModel struct {
+	bun.BaseModel `bun:"table:retention_policies"`
+
+	Status job.Status    `bun:"status,pk"`
+	MaxAge time.Duration `bun:"max_age,notnull,default:0"`
+}
+
+// SetRetention inserts the policy or updates max_age of the existing
+// policy of the same status.
+func (a *Admin) SetRetention(ctx context.Context, policy *gqs.RetentionPolicy) error {
+	if !policy.Status.Terminal() {
+		return gqs.ErrBadStatus
+	}
+	_, err := a.db.NewInsert().
+		Model(&retentionModel{Status: policy.Status, MaxAge: policy.MaxAge}).
+		On("CONFLICT (status) DO UPDATE").
+		Set("max_age = EXCLUDED.max_age").
+		Exec(ctx)
+	return wrap("set retention", uuid.Nil, err)
+}
+
+// DeleteRetention removes the policy of the given status.
+func (a *Admin) DeleteRetention(ctx context.Context, status job.Status) error {
+	_, err := a.db.NewDelete().
+		Model((*retentionModel)(nil)).
+		Where("status = ?", status).
+		Exec(ctx)
+	return wrap("delete retention", uuid.Nil, err)
+}
+
+// Retentions returns all stored policies ordered by status.
+func (a *Admin) Retentions(ctx context.Context) ([]*gqs.RetentionPolicy, error) {
+	var models []*retentionModel
+	err := a.db.NewSelect().
+		Model(&models).
+		Order("status ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, wrap("list retentions", uuid.Nil, err)
+	}
+	ret := make([]*gqs.RetentionPolicy, len(models))
+	for i, model := range models {
+		ret[i] = &gqs.RetentionPolicy{Status: model.Status, MaxAge: model.MaxAge}
+	}
+	return ret, nil
+}
@@ -0,0 +1,201 @@
+package sql_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestAdminBulkOperations(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	admin := gsql.NewAdmin(db)
+	pusher := gsql.NewPusher(db)
+	observer := gsql.NewObserver(db)
+
+	var ids []uuid.UUID
+	for _, queue := range []string{"mail", "mail", "sms"} {
+		msg := message.NewMessage()
+		msg.Queue = queue
+		_ = pusher.Push(ctx, msg, 0)
+		ids = append(ids, msg.Id)
+	}
+
+	if _, err := admin.KillByStatus(ctx, &gqs.ListOptions{Statuses: []job.Status{job.Done}}); !errors.Is(err, gqs.ErrBadStatus) {
+		t.Fatalf("expected ErrBadStatus, got %v", err)
+	}
+
+	count, err := admin.KillByStatus(ctx, &gqs.ListOptions{Queues: []string{"mail"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 killed jobs, got %d", count)
+	}
+
+	later := time.Now().Add(time.Hour)
+	count, err = admin.UpdateNextRun(ctx, &gqs.ListOptions{}, later)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 rescheduled job, got %d", count)
+	}
+	j, _ := observer.Get(ctx, ids[2])
+	if !j.NextRunAt.Round(time.Second).Equal(later.Round(time.Second)) {
+		t.Fatalf("expected next run at %v, got %v", later, j.NextRunAt)
+	}
+
+	count, err = admin.RequeueByStatus(ctx, &gqs.ListOptions{Statuses: []job.Status{job.Dead}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 requeued jobs, got %d", count)
+	}
+	j, _ = observer.Get(ctx, ids[0])
+	if j.Status != job.Pending || j.Attempts != 0 {
+		t.Fatalf("expected requeued job, got %+v", j)
+	}
+
+	count, err = admin.DeleteByIds(ctx, ids[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 deleted jobs, got %d", count)
+	}
+	if j, _ := observer.Get(ctx, ids[0]); j != nil {
+		t.Fatal("expected job to be deleted")
+	}
+}
+
+func TestAdminTableStats(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	admin := gsql.NewAdmin(db)
+
+	for i := 0; i < 3; i++ {
+		if err := pusher.Push(ctx, message.NewMessage(), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := puller.Pull(ctx, 1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := admin.TableStats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Rows[job.Pending] != 2 || stats.Rows[job.Processing] != 1 {
+		t.Fatalf("unexpected row counts %v", stats.Rows)
+	}
+	if stats.TableSize <= 0 || stats.IndexSize <= 0 || stats.FreeSize < 0 {
+		t.Fatalf("expected sizes to be reported, got %+v", stats)
+	}
+	if stats.DeadRows != -1 {
+		t.Fatal("expected dead rows to be unknown on SQLite")
+	}
+}
+
+func TestAdminCancel(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	admin := gsql.NewAdmin(db)
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	running := message.NewMessage()
+	_ = pusher.Push(ctx, running, 0)
+	jobs, err := puller.Pull(ctx, 1, time.Minute)
+	if err != nil || len(jobs) != 1 {
+		t.Fatalf("expected 1 pulled job, got %d: %v", len(jobs), err)
+	}
+
+	pending := message.NewMessage()
+	_ = pusher.Push(ctx, pending, 0)
+
+	if err := admin.Cancel(ctx, pending.Id); err != nil {
+		t.Fatal(err)
+	}
+	j, _ := observer.Get(ctx, pending.Id)
+	if j.Status != job.Cancelled || j.CancelRequested || j.LastError != gqs.ErrCancelRequested.Error() {
+		t.Fatalf("expected cancelled job, got %+v", j)
+	}
+
+	if err := admin.Cancel(ctx, running.Id); err != nil {
+		t.Fatal(err)
+	}
+	j, _ = observer.Get(ctx, running.Id)
+	if j.Status != job.Processing || !j.CancelRequested {
+		t.Fatalf("expected cancel-requested processing job, got %+v", j)
+	}
+	if err := puller.ExtendLock(ctx, jobs[0], time.Minute); !errors.Is(err, gqs.ErrCancelRequested) {
+		t.Fatalf("expected ErrCancelRequested, got %v", err)
+	}
+	results, err := puller.ExtendLockBatch(ctx, jobs, time.Minute)
+	if err != nil || !errors.Is(results[0], gqs.ErrCancelRequested) {
+		t.Fatalf("expected ErrCancelRequested, got %v: %v", results, err)
+	}
+
+	if err := admin.Cancel(ctx, pending.Id); !errors.Is(err, gqs.ErrBadStatus) {
+		t.Fatalf("expected ErrBadStatus, got %v", err)
+	}
+	if err := admin.Cancel(ctx, uuid.New()); !errors.Is(err, gqs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestAdminCancelAbandoned(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	admin := gsql.NewAdmin(db)
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	abandoned := message.NewMessage()
+	_ = pusher.Push(ctx, abandoned, 0)
+	if _, err := puller.Pull(ctx, 1, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := admin.Cancel(ctx, abandoned.Id); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	// the worker holding the job died before seeing the request
+	jobs, err := puller.Pull(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 0 {
+		t.Fatalf("expected the abandoned job not to run again, got %d jobs", len(jobs))
+	}
+	j, _ := observer.Get(ctx, abandoned.Id)
+	if j.Status != job.Cancelled || j.Attempts != 1 || j.LastError != gqs.ErrCancelRequested.Error() {
+		t.Fatalf("expected the abandoned job cancelled, got %+v", j)
+	}
+
+	next := message.NewMessage()
+	_ = pusher.Push(ctx, next, 0)
+	jobs, err = puller.Pull(ctx, 10, time.Minute)
+	if err != nil || len(jobs) != 1 || jobs[0].Id != next.Id {
+		t.Fatalf("expected the next job pulled, got %d: %v", len(jobs), err)
+	}
+}
@@ -0,0 +1,148 @@
+package sql
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type alertThresholdModel struct {
+	bun.BaseModel `bun:"table:alert_thresholds"`
+
+	Queue      string        `bun:"queue,pk"`
+	Status     job.Status    `bun:"status,pk"`
+	MaxCount   int64         `bun:"max_count,notnull,default:0"`
+	MaxAge     time.Duration `bun:"max_age,notnull,default:0"`
+	MaxPerHour int64         `bun:"max_per_hour,notnull,default:0"`
+}
+
+func (tm *alertThresholdModel) toThreshold() *gqs.AlertThreshold {
+	return &gqs.AlertThreshold{
+		Queue:      tm.Queue,
+		Status:     tm.Status,
+		MaxCount:   tm.MaxCount,
+		MaxAge:     tm.MaxAge,
+		MaxPerHour: tm.MaxPerHour,
+	}
+}
+
+type thresholdState struct {
+	Count  int64        `bun:"count"`
+	Oldest bun.NullTime `bun:"oldest"`
+	Recent int64        `bun:"recent"`
+}
+
+// Alerter implements gqs.Alerter using a SQL backend.
+//
+// Thresholds are stored in the alert_thresholds table, created by InitDB.
+// Evaluation is performed with one aggregate query per threshold.
+type Alerter struct {
+	db *bun.DB
+}
+
+// NewAlerter creates a new SQL-backed Alerter.
+//
+// The provided *bun.DB must be properly configured and connected.
+// Schema initialization must be completed before using Alerter.
+func NewAlerter(db *bun.DB) *Alerter {
+	return &Alerter{
+		db: db,
+	}
+}
+
+// SetThreshold inserts the threshold or updates the limits of an
+// existing threshold with the same queue and status.
+func (a *Alerter) SetThreshold(ctx context.Context, threshold *gqs.AlertThreshold) error {
+	model := &alertThresholdModel{
+		Queue:      threshold.Queue,
+		Status:     threshold.Status,
+		MaxCount:   threshold.MaxCount,
+		MaxAge:     threshold.MaxAge,
+		MaxPerHour: threshold.MaxPerHour,
+	}
+	_, err := a.db.NewInsert().
+		Model(model).
+		On("CONFLICT (queue, status) DO UPDATE").
+		Set("max_count = EXCLUDED.max_count").
+		Set("max_age = EXCLUDED.max_age").
+		Set("max_per_hour = EXCLUDED.max_per_hour").
+		Exec(ctx)
+	return wrap("set threshold", uuid.Nil, err)
+}
+
+// DeleteThreshold removes the threshold with the given queue and status.
+func (a *Alerter) DeleteThreshold(ctx context.Context, queue string, status job.Status) error {
+	_, err := a.db.NewDelete().
+		Model((*alertThresholdModel)(nil)).
+		Where("queue = ?", queue).
+		Where("status = ?", status).
+		Exec(ctx)
+	return wrap("delete threshold", uuid.Nil, err)
+}
+
+// Thresholds returns all stored thresholds ordered by queue and status.
+func (a *Alerter) Thresholds(ctx context.Context) ([]*gqs.AlertThreshold, error) {
+	var models []*alertThresholdModel
+	err := a.db.NewSelect().
+		Model(&models).
+		Order("queue ASC", "status ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, wrap("list thresholds", uuid.Nil, err)
+	}
+	ret := make([]*gqs.AlertThreshold, len(models))
+	for i, model := range models {
+		ret[i] = model.toThreshold()
+	}
+	return ret, nil
+}
+
+func (a *Alerter) measure(ctx context.Context, threshold *gqs.AlertThreshold, now time.Time) (*thresholdState, error) {
+	var state thresholdState
+	query := a.db.NewSelect().
+		Model((*jobModel)(nil)).
+		ColumnExpr("COUNT(*) AS count").
+		ColumnExpr("MIN(created_at) AS oldest").
+		ColumnExpr("COALESCE(SUM(CASE WHEN updated_at >= ? THEN 1 ELSE 0 END), 0) AS recent", now.Add(-time.Hour)).
+		Where("queue = ?", threshold.Queue)
+	if threshold.Status != 0 {
+		query.Where("status = ?", threshold.Status)
+	}
+	if err := query.Scan(ctx, &state); err != nil {
+		return nil, wrap("evaluate", uuid.Nil, err)
+	}
+	return &state, nil
+}
+
+// Evaluate measures every stored threshold and returns exceeded limits.
+//
+// The age of the oldest job is measured from its created_at timestamp.
+// The hourly rate counts jobs whose updated_at falls within the last
+// hour, which for terminal statuses equals the time of the transition.
+func (a *Alerter) Evaluate(ctx context.Context) ([]*gqs.Alert, error) {
+	thresholds, err := a.Thresholds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var ret []*gqs.Alert
+	for _, threshold := range thresholds {
+		state, err := a.measure(ctx, threshold, now)
+		if err != nil {
+			return nil, err
+		}
+		if threshold.MaxCount > 0 && state.Count > threshold.MaxCount {
+			ret = append(ret, &gqs.Alert{Kind: gqs.AlertCount, Threshold: *threshold, Count: state.Count})
+		}
+		if age := now.Sub(state.Oldest.Time); threshold.MaxAge > 0 && !state.Oldest.IsZero() && age > threshold.MaxAge {
+			ret = append(ret, &gqs.Alert{Kind: gqs.AlertAge, Threshold: *threshold, Age: age})
+		}
+		if threshold.MaxPerHour > 0 && state.Recent > threshold.MaxPerHour {
+			ret = append(ret, &gqs.Alert{Kind: gqs.AlertRate, Threshold: *threshold, Count: state.Recent})
+		}
+	}
+	return ret, nil
+}
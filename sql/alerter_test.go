@@ -0,0 +1,110 @@
+package sql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestAlerterThresholds(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	alerter := gsql.NewAlerter(db)
+
+	threshold := &gqs.AlertThreshold{Queue: "mail", Status: job.Pending, MaxCount: 1}
+	if err := alerter.SetThreshold(ctx, threshold); err != nil {
+		t.Fatal(err)
+	}
+	threshold.MaxCount = 5
+	if err := alerter.SetThreshold(ctx, threshold); err != nil {
+		t.Fatal(err)
+	}
+
+	thresholds, err := alerter.Thresholds(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(thresholds) != 1 || thresholds[0].MaxCount != 5 {
+		t.Fatalf("expected single updated threshold, got %+v", thresholds)
+	}
+
+	if err := alerter.DeleteThreshold(ctx, "mail", job.Pending); err != nil {
+		t.Fatal(err)
+	}
+	thresholds, err = alerter.Thresholds(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(thresholds) != 0 {
+		t.Fatalf("expected no thresholds, got %d", len(thresholds))
+	}
+}
+
+func TestAlerterEvaluate(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	alerter := gsql.NewAlerter(db)
+
+	for i := 0; i < 3; i++ {
+		msg := message.NewMessage()
+		msg.Queue = "mail"
+		if err := pusher.Push(ctx, msg, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := alerter.SetThreshold(ctx, &gqs.AlertThreshold{
+		Queue:    "mail",
+		Status:   job.Pending,
+		MaxCount: 2,
+		MaxAge:   time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = alerter.SetThreshold(ctx, &gqs.AlertThreshold{
+		Queue:      "mail",
+		Status:     job.Dead,
+		MaxPerHour: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alerts, err := alerter.Evaluate(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("expected 1 alert, got %d", len(alerts))
+	}
+	if alerts[0].Kind != gqs.AlertCount || alerts[0].Count != 3 {
+		t.Fatalf("expected count alert with 3 jobs, got %+v", alerts[0])
+	}
+
+	err = alerter.SetThreshold(ctx, &gqs.AlertThreshold{
+		Queue:  "mail",
+		Status: job.Pending,
+		MaxAge: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	alerts, err = alerter.Evaluate(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 || alerts[0].Kind != gqs.AlertAge {
+		t.Fatalf("expected age alert, got %+v", alerts)
+	}
+}
@@ -0,0 +1,250 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/uptrace/bun"
+	"reflect"
+	"time"
+)
+
+// archiveTable is the name of the table archived jobs are moved into.
+const archiveTable = "jobs_archive"
+
+type archiveModel struct {
+	bun.BaseModel `bun:"table:jobs_archive"`
+	jobModel
+
+	ArchivedAt time.Time `bun:"archived_at,notnull"`
+}
+
+func (opts *InitOptions) createArchive(ctx context.Context, db bun.IDB) error {
+	if !opts.Archive {
+		return nil
+	}
+	_, err := db.NewCreateTable().
+		Model((*archiveModel)(nil)).
+		IfNotExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = db.NewCreateIndex().
+		Model((*archiveModel)(nil)).
+		Index("idx_jobs_archive_status_updated").
+		Column("status", "updated_at").
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+// jobColumns returns the identifiers of all columns of the jobs table.
+func jobColumns(db bun.IDB) []bun.Ident {
+	table := db.Dialect().Tables().Get(reflect.TypeFor[jobModel]())
+	ret := make([]bun.Ident, len(table.Fields))
+	for i, field := range table.Fields {
+		ret[i] = bun.Ident(field.Name)
+	}
+	return ret
+}
+
+// Archive moves jobs matching the provided status and time filter from
+// the jobs table into the jobs_archive table, within a single
+// transaction, and returns the number of archived jobs.
+//
+// Status and before are interpreted as by Clean. The archive table must
+// be created with InitOptions.Archive. Archived jobs keep all their
+// columns and additionally record the time of archiving in archived_at;
+// they can be inspected with an ArchiveObserver.
+//
+// Archived jobs are deleted from the jobs table, so their events are
+// removed from the job history, if enabled. If the id of an archived
+// job is reused by a later job, archiving the later job replaces the
+// archived copy, so the archive keeps the last archived job of every
+// id. archived_at is taken from CleanerOptions.Clock, if set.
+func (c *Cleaner) Archive(ctx context.Context, status job.Status, before *time.Time) (int64, error) {
+	if status != 0 && !status.Terminal() {
+		return 0, gqs.ErrBadStatus
+	}
+	ret, err := c.archive(ctx, terminalFilter(status, before, false), 0)
+	return ret, wrap("archive", uuid.Nil, err)
+}
+
+// ArchiveUnretained moves the jobs CleanUnretained would delete into
+// the archive, as Archive does.
+func (c *Cleaner) ArchiveUnretained(ctx context.Context, status job.Status, before *time.Time) (int64, error) {
+	if status != 0 && !status.Terminal() {
+		return 0, gqs.ErrBadStatus
+	}
+	ret, err := c.archive(ctx, terminalFilter(status, before, true), 0)
+	return ret, wrap("archive", uuid.Nil, err)
+}
+
+// ArchiveRetained moves the jobs CleanRetained would delete into the
+// archive, as Archive does.
+func (c *Cleaner) ArchiveRetained(ctx context.Context, status job.Status) (int64, error) {
+	return c.purgeRetained(ctx, "archive", status, 0, c.archive)
+}
+
+// archive archives all jobs matching filter; it has the signature of a
+// purgeFunc but ignores the limit.
+func (c *Cleaner) archive(ctx context.Context, filter func(bun.QueryBuilder) bun.QueryBuilder, _ int) (int64, error) {
+	var ret int64
+	now := c.clock.now(ctx, c.db)
+	err := c.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		// archived copies of reused ids are replaced
+		_, err := tx.NewDelete().
+			Model((*archiveModel)(nil)).
+			Where("id IN (?)", tx.NewSelect().
+				Model((*jobModel)(nil)).
+				Column("id").
+				ApplyQueryBuilder(filter)).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		columns := jobColumns(tx)
+		selected := tx.NewSelect().
+			Model((*jobModel)(nil)).
+			ColumnExpr("?, ?", bun.In(columns), now).
+			ApplyQueryBuilder(filter)
+		_, err = tx.NewRaw("INSERT INTO ? (?, archived_at) ?",
+			bun.Ident(archiveTable), bun.In(columns), selected).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		res, err := tx.NewDelete().
+			Model((*jobModel)(nil)).
+			ApplyQueryBuilder(filter).
+			Where("id IN (?)", tx.NewSelect().Table(archiveTable).Column("id")).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		ret = getAffected(res)
+		return nil
+	})
+	return ret, err
+}
+
+// ArchiveObserver implements gqs.Observer, gqs.QueryObserver and
+// gqs.Reporter over the jobs_archive table, populated by
+// Cleaner.Archive.
+//
+// Archived jobs are terminal and no longer change, so Query and Report
+// results are stable.
+type ArchiveObserver struct {
+	db *bun.DB
+}
+
+// NewArchiveObserver creates a new ArchiveObserver.
+//
+// The archive table must be created with InitOptions.Archive.
+func NewArchiveObserver(db *bun.DB) *ArchiveObserver {
+	return &ArchiveObserver{
+		db: db,
+	}
+}
+
+func toJobs(models []archiveModel) []*job.Job {
+	ret := make([]*job.Job, len(models))
+	for i := range models {
+		ret[i] = models[i].toJob()
+	}
+	return ret
+}
+
+// Get retrieves an archived job by its identifier. If no job with the
+// given id is archived, Get returns (nil, nil).
+func (ao *ArchiveObserver) Get(ctx context.Context, id uuid.UUID) (*job.Job, error) {
+	var ret archiveModel
+	err := ao.db.NewSelect().
+		Model(&ret).
+		Where("id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, wrap("get", id, err)
+	}
+	return ret.toJob(), nil
+}
+
+// List returns up to limit archived jobs filtered by status, as
+// Observer.List does for live jobs.
+func (ao *ArchiveObserver) List(ctx context.Context, status job.Status, limit int) ([]*job.Job, error) {
+	var models []archiveModel
+	query := ao.db.NewSelect().Model(&models)
+	if status != 0 {
+		query.Where("status = ?", status)
+	}
+	if limit > 0 {
+		query.Limit(limit)
+	}
+	if err := query.Scan(ctx); err != nil {
+		return nil, wrap("list", uuid.Nil, err)
+	}
+	return toJobs(models), nil
+}
+
+// Query returns a page of archived jobs matching opts, as Observer.Query
+// does for live jobs.
+func (ao *ArchiveObserver) Query(ctx context.Context, opts *gqs.ListOptions) (*gqs.Page, error) {
+	var models []archiveModel
+	query := ao.db.NewSelect().
+		Model(&models).
+		ApplyQueryBuilder(applyFilter(ao.db.Dialect().Name(), opts))
+	if err := applyPage(query, opts); err != nil {
+		return nil, err
+	}
+	if err := query.Scan(ctx); err != nil {
+		return nil, wrap("query", uuid.Nil, err)
+	}
+	return newPage(toJobs(models), opts), nil
+}
+
+// Count returns the number of archived jobs matching the filters of opts.
+func (ao *ArchiveObserver) Count(ctx context.Context, opts *gqs.ListOptions) (int64, error) {
+	count, err := ao.db.NewSelect().
+		Model((*archiveModel)(nil)).
+		ApplyQueryBuilder(applyFilter(ao.db.Dialect().Name(), opts)).
+		Count(ctx)
+	return int64(count), wrap("count", uuid.Nil, err)
+}
+
+// Report calls fn for every archived job matching opts, reading rows
+// one by one from a single query.
+func (ao *ArchiveObserver) Report(ctx context.Context, opts *gqs.ListOptions, fn func(jb *job.Job) error) error {
+	query := ao.db.NewSelect().
+		Model((*archiveModel)(nil)).
+		ApplyQueryBuilder(applyFilter(ao.db.Dialect().Name(), opts))
+	if err := applyPage(query, opts); err != nil {
+		return err
+	}
+	rows, err := query.Rows(ctx)
+	if err != nil {
+		return wrap("report", uuid.Nil, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var model archiveModel
+		if err := ao.db.ScanRow(ctx, rows, &model); err != nil {
+			return wrap("report", uuid.Nil, err)
+		}
+		if err := fn(model.toJob()); err != nil {
+			return err
+		}
+	}
+	return wrap("report", uuid.Nil, rows.Err())
+}
+
+// Capabilities implements gqs.Capable.
+func (ao *ArchiveObserver) Capabilities() gqs.Capability {
+	return gqs.CapQuery | gqs.CapReport
+}
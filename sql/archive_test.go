@@ -0,0 +1,126 @@
+package sql_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+)
+
+func newArchiveDB(t *testing.T) *bun.DB {
+	t.Helper()
+	sqlDB, err := sql.Open("sqlite", "file::memory:?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	db := bun.NewDB(sqlDB, sqlitedialect.New())
+	if err := gsql.InitDBWithOptions(context.Background(), db, &gsql.InitOptions{Archive: true}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestArchive(t *testing.T) {
+	db := newArchiveDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	cleaner := gsql.NewCleaner(db)
+	observer := gsql.NewObserver(db)
+	archive := gsql.NewArchiveObserver(db)
+
+	done := message.NewMessage()
+	done.Queue = "billing"
+	done.Payload = []byte("invoice")
+	done.Set("tenant", "acme")
+	_ = pusher.Push(ctx, done, 0)
+	jobs, _ := puller.Pull(ctx, 1, time.Minute)
+	_ = puller.Complete(ctx, jobs[0])
+
+	pending := message.NewMessage()
+	_ = pusher.Push(ctx, pending, 0)
+
+	if _, err := cleaner.Archive(ctx, job.Pending, nil); err != gqs.ErrBadStatus {
+		t.Fatalf("expected ErrBadStatus, got %v", err)
+	}
+	count, err := cleaner.Archive(ctx, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 archived job, got %d", count)
+	}
+
+	if j, _ := observer.Get(ctx, done.Id); j != nil {
+		t.Fatal("expected the archived job to leave the jobs table")
+	}
+	if j, _ := observer.Get(ctx, pending.Id); j == nil {
+		t.Fatal("expected the pending job to stay")
+	}
+
+	j, err := archive.Get(ctx, done.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j == nil || j.Status != job.Done || j.Queue != "billing" || string(j.Payload) != "invoice" || j.Metadata["tenant"] != "acme" {
+		t.Fatalf("expected the archived job to keep its fields, got %+v", j)
+	}
+	page, err := archive.Query(ctx, &gqs.ListOptions{Queues: []string{"billing"}, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Jobs) != 1 || page.Jobs[0].Id != done.Id {
+		t.Fatalf("expected the archived job, got %+v", page.Jobs)
+	}
+	n, _ := archive.Count(ctx, &gqs.ListOptions{Statuses: []job.Status{job.Dead}})
+	if n != 0 {
+		t.Fatalf("expected no dead archived jobs, got %d", n)
+	}
+	var reported int
+	_ = archive.Report(ctx, &gqs.ListOptions{}, func(*job.Job) error {
+		reported++
+		return nil
+	})
+	if reported != 1 {
+		t.Fatalf("expected 1 reported job, got %d", reported)
+	}
+
+	// a reused id replaces the archived copy
+	reused := message.NewMessage()
+	reused.Id = done.Id
+	if err := pusher.Push(ctx, reused, 0); err != nil {
+		t.Fatal(err)
+	}
+	jobs, _ = puller.Pull(ctx, 10, time.Minute)
+	for _, jb := range jobs {
+		if jb.Id == done.Id {
+			_ = puller.Kill(ctx, jb)
+		}
+	}
+	count, err = cleaner.Archive(ctx, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected the reused job archived, got %d", count)
+	}
+	j, err = archive.Get(ctx, done.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j == nil || j.Status != job.Dead {
+		t.Fatalf("expected the archived copy replaced, got %+v", j)
+	}
+	if j, _ := observer.Get(ctx, done.Id); j != nil {
+		t.Fatal("expected the reused job to leave the jobs table")
+	}
+}
@@ -2,13 +2,15 @@ package sql
 
 import (
 	"context"
+	"github.com/google/uuid"
 	"github.com/romanqed/gqs"
 	"github.com/romanqed/gqs/job"
 	"github.com/uptrace/bun"
 	"time"
 )
 
-// Cleaner implements gqs.Cleaner using a SQL backend.
+// Cleaner implements gqs.Cleaner, gqs.BatchCleaner, gqs.Archiver and
+// gqs.RetentionCleaner using a SQL backend.
 //
 // Cleaner permanently removes terminal jobs from storage.
 // It is intended for retention management and administrative cleanup.
@@ -16,7 +18,17 @@ import (
 // This implementation deletes rows directly from the jobs table
 // and does not participate in visibility timeout or processing logic.
 type Cleaner struct {
-	db *bun.DB
+	db    *bun.DB
+	clock *Clock
+}
+
+// CleanerOptions defines optional behavior of a Cleaner.
+//
+// Clock, if set, provides the time the retention of jobs is measured
+// against and the archived_at of archived jobs instead of the local
+// clock (see Clock).
+type CleanerOptions struct {
+	Clock *Clock
 }
 
 // NewCleaner creates a new SQL-backed Cleaner.
@@ -24,8 +36,15 @@ type Cleaner struct {
 // The provided *bun.DB must be properly configured and connected.
 // Schema initialization must be completed before using Cleaner.
 func NewCleaner(db *bun.DB) *Cleaner {
+	return NewCleanerWithOptions(db, &CleanerOptions{})
+}
+
+// NewCleanerWithOptions creates a new SQL-backed Cleaner using the
+// provided options.
+func NewCleanerWithOptions(db *bun.DB, opts *CleanerOptions) *Cleaner {
 	return &Cleaner{
-		db: db,
+		db:    db,
+		clock: opts.Clock,
 	}
 }
 
@@ -35,8 +54,9 @@ func NewCleaner(db *bun.DB) *Cleaner {
 //
 //   - job.Done
 //   - job.Dead
+//   - job.Cancelled
 //
-// If status is job.Unknown (zero value), both Done and Dead jobs
+// If status is job.Unknown (zero value), jobs of all terminal states
 // are eligible for deletion.
 //
 // If status refers to a non-terminal state (such as Pending or Processing),
@@ -47,20 +67,176 @@ func NewCleaner(db *bun.DB) *Cleaner {
 //
 // Clean returns the number of deleted rows.
 //
+// Dead and Cancelled jobs that Pending or Scheduled jobs depend on are
+// kept until DependencyReaper kills those jobs (see DependencyReaper).
+//
 // Clean does not attempt to lock or coordinate with running workers.
 // Deleting Processing jobs is explicitly disallowed by status checks.
 func (c *Cleaner) Clean(ctx context.Context, status job.Status, before *time.Time) (int64, error) {
-	if status != 0 && status != job.Dead && status != job.Done {
+	if status != 0 && !status.Terminal() {
+		return 0, gqs.ErrBadStatus
+	}
+	ret, err := c.delete(ctx, terminalFilter(status, before, false), 0)
+	return ret, wrap("clean", uuid.Nil, err)
+}
+
+// CleanBatch deletes at most limit jobs matching the provided status
+// and time filter, as Clean does, and returns the number of deleted
+// rows.
+//
+// The jobs are selected in a subquery bounded by limit, so the DELETE
+// statement locks at most limit rows. Repeated calls delete all
+// matching jobs in short statements (see gqs.CleanConfig.BatchSize).
+func (c *Cleaner) CleanBatch(ctx context.Context, status job.Status, before *time.Time, limit int) (int64, error) {
+	if status != 0 && !status.Terminal() {
+		return 0, gqs.ErrBadStatus
+	}
+	ret, err := c.delete(ctx, terminalFilter(status, before, false), limit)
+	return ret, wrap("clean", uuid.Nil, err)
+}
+
+// CleanUnretained deletes at most limit jobs matching the provided
+// status and time filter, as CleanBatch does, skipping jobs with their
+// own retention for their status (see PusherOptions.Retention). If
+// limit is zero or negative, all matching jobs are deleted at once.
+func (c *Cleaner) CleanUnretained(ctx context.Context, status job.Status, before *time.Time, limit int) (int64, error) {
+	if status != 0 && !status.Terminal() {
+		return 0, gqs.ErrBadStatus
+	}
+	ret, err := c.delete(ctx, terminalFilter(status, before, true), limit)
+	return ret, wrap("clean", uuid.Nil, err)
+}
+
+// retainColumn returns the column holding the retention of jobs of the
+// terminal status.
+func retainColumn(status job.Status) bun.Ident {
+	switch status {
+	case job.Done:
+		return "retain_done"
+	case job.Dead:
+		return "retain_dead"
+	default:
+		return "retain_cancelled"
+	}
+}
+
+// terminalFilter restricts a query to terminal jobs of status updated
+// before before, and without their own retention if unretained is set.
+func terminalFilter(status job.Status, before *time.Time, unretained bool) func(bun.QueryBuilder) bun.QueryBuilder {
+	return func(q bun.QueryBuilder) bun.QueryBuilder {
+		switch {
+		case !unretained && status != 0:
+			q = q.Where("status = ?", status)
+		case !unretained:
+			q = q.Where("status IN (?)", bun.In(job.TerminalStatuses))
+		case status != 0:
+			q = q.Where("status = ? AND ? = 0", status, retainColumn(status))
+		default:
+			q = q.WhereGroup("AND", func(q bun.QueryBuilder) bun.QueryBuilder {
+				for _, status := range job.TerminalStatuses {
+					q = q.WhereOr("status = ? AND ? = 0", status, retainColumn(status))
+				}
+				return q
+			})
+		}
+		if before != nil {
+			// created_at never exceeds updated_at, the redundant predicate
+			// lets the planner prune partitions of a range-partitioned table
+			q = q.Where("updated_at <= ?", before).
+				Where("created_at <= ?", before)
+		}
+		return whereNotBlocking(q)
+	}
+}
+
+// retainedFilter restricts a query to jobs of the terminal status kept
+// for retention whose retention elapsed at now.
+func retainedFilter(status job.Status, retention time.Duration, now time.Time) func(bun.QueryBuilder) bun.QueryBuilder {
+	before := now.Add(-retention)
+	return func(q bun.QueryBuilder) bun.QueryBuilder {
+		q = q.
+			Where("status = ?", status).
+			Where("? = ?", retainColumn(status), retention).
+			Where("updated_at <= ?", before).
+			Where("created_at <= ?", before)
+		return whereNotBlocking(q)
+	}
+}
+
+// purgeFunc removes at most limit jobs matching filter, or all of them
+// if limit is not positive.
+type purgeFunc func(ctx context.Context, filter func(bun.QueryBuilder) bun.QueryBuilder, limit int) (int64, error)
+
+// purgeRetained calls purge with the filter of every retention recorded
+// on jobs of status, or of every terminal status if status is
+// job.Unknown, until limit jobs are purged, and returns the total of
+// purged jobs. Failures are reported as performing op.
+func (c *Cleaner) purgeRetained(ctx context.Context, op string, status job.Status, limit int, purge purgeFunc) (int64, error) {
+	if status != 0 && !status.Terminal() {
 		return 0, gqs.ErrBadStatus
 	}
-	query := c.db.NewDelete().Model((*jobModel)(nil))
+	statuses := job.TerminalStatuses
 	if status != 0 {
-		query.Where("status = ?", status)
-	} else {
-		query.Where("status IN (?, ?)", job.Done, job.Dead)
+		statuses = []job.Status{status}
 	}
-	if before != nil {
-		query.Where("updated_at <= ?", before)
+	now := c.clock.now(ctx, c.db)
+	var ret int64
+	for _, status := range statuses {
+		// jobs share few distinct retentions, each is purged with a
+		// plain comparison of updated_at
+		var retentions []time.Duration
+		err := c.db.NewSelect().
+			Model((*jobModel)(nil)).
+			Distinct().
+			ColumnExpr("?", retainColumn(status)).
+			Where("status = ?", status).
+			Where("? > 0", retainColumn(status)).
+			Scan(ctx, &retentions)
+		if err != nil {
+			return ret, wrap(op, uuid.Nil, err)
+		}
+		for _, retention := range retentions {
+			left := 0
+			if limit > 0 {
+				left = limit - int(ret)
+			}
+			count, err := purge(ctx, retainedFilter(status, retention, now), left)
+			ret += count
+			if err != nil {
+				return ret, wrap(op, uuid.Nil, err)
+			}
+			if limit > 0 && ret >= int64(limit) {
+				return ret, nil
+			}
+		}
+	}
+	return ret, nil
+}
+
+// CleanRetained deletes at most limit jobs of the terminal status whose
+// own retention elapsed since updated_at, or of every terminal status if
+// status is job.Unknown, and returns the number of deleted rows. If
+// limit is zero or negative, all such jobs are deleted.
+func (c *Cleaner) CleanRetained(ctx context.Context, status job.Status, limit int) (int64, error) {
+	return c.purgeRetained(ctx, "clean", status, limit, c.delete)
+}
+
+// delete deletes at most limit jobs matching filter, or all of them if
+// limit is not positive. A limited batch is selected in a subquery, so
+// the DELETE statement locks at most limit rows.
+func (c *Cleaner) delete(ctx context.Context, filter func(bun.QueryBuilder) bun.QueryBuilder, limit int) (int64, error) {
+	query := c.db.NewDelete().
+		Model((*jobModel)(nil))
+	if limit > 0 {
+		batch := c.db.NewSelect().
+			Model((*jobModel)(nil)).
+			Column("id").
+			ApplyQueryBuilder(filter).
+			Limit(limit)
+		// the derived table lets MySQL delete from the table it selects from
+		query.Where("id IN (SELECT id FROM (?) AS batch)", batch)
+	} else {
+		query.ApplyQueryBuilder(filter)
 	}
 	res, err := query.Exec(ctx)
 	if err != nil {
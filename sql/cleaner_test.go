@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/romanqed/gqs"
 	"github.com/romanqed/gqs/job"
 	"github.com/romanqed/gqs/message"
 	gsql "github.com/romanqed/gqs/sql"
@@ -35,3 +36,135 @@ func TestCleaner(t *testing.T) {
 		t.Fatalf("expected 1 deleted job, got %d", count)
 	}
 }
+
+func TestCleanerCancelled(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	admin := gsql.NewAdmin(db)
+	cleaner := gsql.NewCleaner(db)
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+	_ = pusher.Push(ctx, message.NewMessage(), 0)
+	if err := admin.Cancel(ctx, msg.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := cleaner.Clean(ctx, job.Cancelled, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 deleted job, got %d", count)
+	}
+	count, _ = cleaner.Clean(ctx, job.Unknown, nil)
+	if count != 0 {
+		t.Fatalf("expected pending job to be kept, got %d deleted", count)
+	}
+}
+
+func TestCleanBatch(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	admin := gsql.NewAdmin(db)
+	cleaner := gsql.NewCleaner(db)
+
+	for range 5 {
+		msg := message.NewMessage()
+		_ = pusher.Push(ctx, msg, 0)
+		if err := admin.Cancel(ctx, msg.Id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = pusher.Push(ctx, message.NewMessage(), 0)
+
+	for _, expected := range []int64{2, 2, 1, 0} {
+		count, err := cleaner.CleanBatch(ctx, 0, nil, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != expected {
+			t.Fatalf("expected %d deleted jobs, got %d", expected, count)
+		}
+	}
+	if _, err := cleaner.CleanBatch(ctx, job.Pending, nil, 2); err == nil {
+		t.Fatal("expected ErrBadStatus for a non-terminal status")
+	}
+}
+
+func TestCleanRetained(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusherWithOptions(db, &gsql.PusherOptions{
+		QueueRetention: map[string]gqs.RetentionConfig{
+			"short": {Done: 20 * time.Millisecond},
+			"long":  {Done: time.Hour},
+		},
+	})
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+	cleaner := gsql.NewCleaner(db)
+
+	msgs := make(map[string]*message.Message)
+	for _, queue := range []string{"", "short", "long"} {
+		msg := message.NewMessage()
+		msg.Queue = queue
+		msgs[queue] = msg
+		if err := pusher.Push(ctx, msg, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	jobs, err := puller.Pull(ctx, 3, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, jb := range jobs {
+		if err := puller.Complete(ctx, jb); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	count, err := cleaner.CleanUnretained(ctx, job.Done, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected only the job without retention to be deleted, got %d", count)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	count, err = cleaner.CleanRetained(ctx, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected the elapsed job to be deleted, got %d", count)
+	}
+	if count, _ = cleaner.CleanRetained(ctx, 0, 1); count != 0 {
+		t.Fatalf("expected no elapsed job left, got %d", count)
+	}
+	if jb, _ := observer.Get(ctx, msgs["short"].Id); jb != nil {
+		t.Fatal("expected the short job to be deleted")
+	}
+	jb, err := observer.Get(ctx, msgs["long"].Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jb.RetainDone != time.Hour {
+		t.Fatalf("expected the retention to be recorded, got %v", jb.RetainDone)
+	}
+
+	// Clean is not restricted by retention
+	count, err = cleaner.Clean(ctx, job.Done, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected Clean to delete the retained job, got %d", count)
+	}
+}
@@ -0,0 +1,200 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultClockSync is the interval at which a Clock measures the time
+// of the database if ClockOptions.Sync is not set.
+const DefaultClockSync = time.Minute
+
+// ClockOptions defines optional behavior of a Clock.
+//
+// Query is the statement returning the current time of the database
+// as a single value. If empty, a statement is chosen by dialect:
+// clock_timestamp() on PostgreSQL, UTC_TIMESTAMP(6) on MySQL, SYSUTCDATETIME() on
+// MSSQL, strftime('%Y-%m-%d %H:%M:%f', 'now') on SQLite and
+// CURRENT_TIMESTAMP otherwise. Values are accepted as time.Time or as
+// text in RFC 3339 or "2006-01-02 15:04:05" layouts, the latter taken
+// as UTC.
+//
+// Sync is the interval between measurements of the time of the
+// database. If zero, DefaultClockSync is used.
+type ClockOptions struct {
+	Query string
+	Sync  time.Duration
+}
+
+// Clock provides the current time of the database server.
+//
+// Leases and eligibility are decided by comparing timestamps written
+// by different hosts, so that hosts whose clocks are skewed by a few
+// seconds expire the leases of each other early and deliver jobs
+// twice. Puller, Pusher, Promoter and Expirer created with a Clock
+// (see their options) take all timestamps from it instead of from the
+// local clock of the application.
+//
+// Clock measures the offset of the database clock relative to the
+// local one every Sync interval and applies it to the local time, so
+// that it costs a single query per interval rather than one per
+// operation. The round trip of the measuring query is split evenly
+// between both directions. Measurements are taken lazily on the
+// database of the Clock when an operation needs the time; operations
+// running inside a transaction start the measurement in the background
+// and use the last measured offset. If a measurement fails, the last
+// measured offset is kept. Clock implements gqs.Maintainer, so that
+// gqs.MaintenanceWorker may keep it measured; calling Sync at startup
+// ensures no operation uses the local time.
+//
+// A nil *Clock provides the local time of the application.
+type Clock struct {
+	db     *bun.DB
+	query  string
+	sync   time.Duration
+	mutex  sync.Mutex
+	offset atomic.Int64
+	next   atomic.Int64
+}
+
+// NewClock creates a Clock measuring the time of db with default
+// options.
+func NewClock(db *bun.DB) *Clock {
+	return NewClockWithOptions(db, &ClockOptions{})
+}
+
+// NewClockWithOptions creates a Clock measuring the time of db using
+// the provided options.
+func NewClockWithOptions(db *bun.DB, opts *ClockOptions) *Clock {
+	query := opts.Query
+	if query == "" {
+		query = clockQuery(db.Dialect().Name())
+	}
+	interval := opts.Sync
+	if interval <= 0 {
+		interval = DefaultClockSync
+	}
+	return &Clock{
+		db:    db,
+		query: query,
+		sync:  interval,
+	}
+}
+
+func clockQuery(name dialect.Name) string {
+	switch name {
+	case dialect.PG:
+		// now() is the start of the current transaction
+		return "SELECT clock_timestamp()"
+	case dialect.MySQL:
+		return "SELECT UTC_TIMESTAMP(6)"
+	case dialect.MSSQL:
+		return "SELECT SYSUTCDATETIME()"
+	case dialect.SQLite:
+		return "SELECT strftime('%Y-%m-%d %H:%M:%f', 'now')"
+	default:
+		return "SELECT CURRENT_TIMESTAMP"
+	}
+}
+
+// Now returns the current time of the database, measuring it first if
+// the last measurement is older than the sync interval.
+func (c *Clock) Now(ctx context.Context) time.Time {
+	if c == nil {
+		return time.Now()
+	}
+	return c.now(ctx, c.db)
+}
+
+// Offset returns the last measured offset of the database clock
+// relative to the local one.
+func (c *Clock) Offset() time.Duration {
+	return time.Duration(c.offset.Load())
+}
+
+// Sync measures the time of the database.
+func (c *Clock) Sync(ctx context.Context) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.measure(ctx)
+}
+
+// Maintain implements gqs.Maintainer by calling Sync.
+func (c *Clock) Maintain(ctx context.Context) error {
+	return c.Sync(ctx)
+}
+
+// now returns the time of the clock for an operation running on db,
+// measuring it if due. A nil Clock returns the local time.
+func (c *Clock) now(ctx context.Context, db bun.IDB) time.Time {
+	if c == nil {
+		return time.Now()
+	}
+	// a single caller measures, the others use the previous offset
+	if time.Now().UnixNano() >= c.next.Load() && c.mutex.TryLock() {
+		if _, ok := db.(*bun.DB); ok {
+			_ = c.measure(ctx)
+			c.mutex.Unlock()
+		} else {
+			// the transaction of the caller may hold the only connection
+			// of the pool, and measuring within it would see its start
+			go func() {
+				defer c.mutex.Unlock()
+				ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.sync)
+				defer cancel()
+				_ = c.measure(ctx)
+			}()
+		}
+	}
+	return time.Now().Add(c.Offset())
+}
+
+func (c *Clock) measure(ctx context.Context) error {
+	var value any
+	start := time.Now()
+	err := c.db.QueryRowContext(ctx, c.query).Scan(&value)
+	end := time.Now()
+	if err != nil {
+		return wrap("sync clock", uuid.Nil, err)
+	}
+	remote, err := parseClock(value)
+	if err != nil {
+		return err
+	}
+	local := start.Add(end.Sub(start) / 2)
+	c.offset.Store(int64(remote.Sub(local)))
+	c.next.Store(end.Add(c.sync).UnixNano())
+	return nil
+}
+
+var clockLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+}
+
+func parseClock(value any) (time.Time, error) {
+	var text string
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	default:
+		return time.Time{}, fmt.Errorf("unexpected database time %T", value)
+	}
+	for _, layout := range clockLayouts {
+		if ret, err := time.ParseInLocation(layout, text, time.UTC); err == nil {
+			return ret, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unexpected database time %q", text)
+}
@@ -0,0 +1,97 @@
+package sql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestClock(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	clock := gsql.NewClock(db)
+	if err := clock.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if offset := clock.Offset(); offset.Abs() > time.Second {
+		t.Fatalf("expected no offset from the local database, got %v", offset)
+	}
+
+	// a database clock running an hour ahead of the application
+	clock = gsql.NewClockWithOptions(db, &gsql.ClockOptions{
+		Query: "SELECT strftime('%Y-%m-%d %H:%M:%f', 'now', '+1 hour')",
+	})
+	if err := clock.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if offset := clock.Offset(); (offset - time.Hour).Abs() > time.Second {
+		t.Fatalf("expected an offset of an hour, got %v", offset)
+	}
+
+	// a push within a transaction holding the only connection measures
+	// in the background once the transaction ends
+	pending := gsql.NewClockWithOptions(db, &gsql.ClockOptions{
+		Query: "SELECT strftime('%Y-%m-%d %H:%M:%f', 'now', '+1 hour')",
+	})
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txPusher := gsql.NewPusherWithOptions(db, &gsql.PusherOptions{Clock: pending})
+	if err := txPusher.PushTx(ctx, tx, message.NewMessage(), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for (pending.Offset() - time.Hour).Abs() > time.Second {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected a background measurement, got %v", pending.Offset())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	pusher := gsql.NewPusherWithOptions(db, &gsql.PusherOptions{Clock: clock})
+	local := gsql.NewPuller(db)
+
+	msg := message.NewMessage()
+	if err := pusher.Push(ctx, msg, 30*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	jobs, err := local.Pull(ctx, 1, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 0 {
+		t.Fatal("expected the job to be due only by the database clock")
+	}
+
+	// a clock running two hours ahead sees the job due and grants a
+	// lease outlasting the local clock
+	clock = gsql.NewClockWithOptions(db, &gsql.ClockOptions{
+		Query: "SELECT strftime('%Y-%m-%d %H:%M:%f', 'now', '+2 hours')",
+	})
+	puller := gsql.NewPullerWithOptions(db, &gsql.PullerOptions{Clock: clock})
+	jobs, err = puller.Pull(ctx, 1, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 {
+		t.Fatal("expected the job to be due by the database clock")
+	}
+	if jobs[0].LockedUntil.Before(time.Now().Add(time.Hour)) {
+		t.Fatalf("expected the lease to follow the database clock, got %v", jobs[0].LockedUntil)
+	}
+	jobs, err = local.Pull(ctx, 1, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 0 {
+		t.Fatal("expected the lease to be held")
+	}
+}
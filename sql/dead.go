@@ -0,0 +1,98 @@
+package sql
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"time"
+)
+
+type deadModel struct {
+	Id          uuid.UUID `bun:"id"`
+	Queue       string    `bun:"queue"`
+	TenantId    string    `bun:"tenant_id"`
+	Type        string    `bun:"type"`
+	Attempts    uint32    `bun:"attempts"`
+	MaxRetries  uint32    `bun:"max_retries"`
+	LastError   string    `bun:"last_error"`
+	Preview     []byte    `bun:"preview"`
+	PayloadSize int64     `bun:"payload_size"`
+	CreatedAt   time.Time `bun:"created_at"`
+	UpdatedAt   time.Time `bun:"updated_at"`
+}
+
+func (dm *deadModel) toLetter() *gqs.DeadLetter {
+	return &gqs.DeadLetter{
+		Id:          dm.Id,
+		Queue:       dm.Queue,
+		TenantId:    dm.TenantId,
+		Type:        dm.Type,
+		Attempts:    dm.Attempts,
+		MaxRetries:  dm.MaxRetries,
+		LastError:   dm.LastError,
+		Preview:     dm.Preview,
+		PayloadSize: dm.PayloadSize,
+		CreatedAt:   dm.CreatedAt,
+		UpdatedAt:   dm.UpdatedAt,
+	}
+}
+
+// previewExprs returns the expressions selecting the first bytes of
+// the payload and its size.
+func previewExprs(name dialect.Name) (string, string) {
+	if name == dialect.MSSQL {
+		return "SUBSTRING(payload, 1, ?)", "COALESCE(DATALENGTH(payload), 0)"
+	}
+	return "SUBSTR(payload, 1, ?)", "COALESCE(LENGTH(payload), 0)"
+}
+
+// ListDead returns a page of Dead jobs, most recently killed first (see
+// gqs.DeadLetterObserver).
+//
+// The payload is truncated by the database, so that large payloads are
+// not transferred. Pagination uses keyset conditions on updated_at and
+// id, served by the (status, updated_at) index or, if Queues is set,
+// by the (queue, status, updated_at) index.
+func (o *Observer) ListDead(ctx context.Context, opts *gqs.DeadListOptions) (*gqs.DeadPage, error) {
+	size := opts.PreviewSize
+	if size == 0 {
+		size = gqs.DefaultDeadPreview
+	}
+	preview, length := previewExprs(o.db.Dialect().Name())
+	query := o.db.NewSelect().
+		Model((*jobModel)(nil)).
+		Column("id", "queue", "tenant_id", "type", "attempts", "max_retries",
+			"last_error", "created_at", "updated_at").
+		ColumnExpr(length+" AS payload_size").
+		Where("status = ?", job.Dead)
+	if size > 0 {
+		query.ColumnExpr(preview+" AS preview", size)
+	}
+	if len(opts.Queues) != 0 {
+		query.Where("queue IN (?)", bun.In(opts.Queues))
+	}
+	page := &gqs.ListOptions{
+		Order:  gqs.OrderUpdatedDesc,
+		Limit:  opts.Limit,
+		Cursor: opts.Cursor,
+	}
+	if err := applyPage(query, page); err != nil {
+		return nil, err
+	}
+	var models []deadModel
+	if err := query.Scan(ctx, &models); err != nil {
+		return nil, wrap("list dead", uuid.Nil, err)
+	}
+	ret := &gqs.DeadPage{Letters: make([]*gqs.DeadLetter, len(models))}
+	for i := range models {
+		ret.Letters[i] = models[i].toLetter()
+	}
+	if opts.Limit > 0 && len(models) == opts.Limit {
+		last := models[len(models)-1]
+		ret.Next = encodeCursor(last.UpdatedAt, last.Id)
+	}
+	return ret, nil
+}
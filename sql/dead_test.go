@@ -0,0 +1,77 @@
+package sql_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestListDead(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	for range 3 {
+		msg := message.NewMessage()
+		msg.Payload = bytes.Repeat([]byte("x"), 10)
+		if err := pusher.Push(ctx, msg, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pusher.Push(ctx, message.NewMessage(), 0); err != nil {
+		t.Fatal(err)
+	}
+	jobs, err := puller.Pull(ctx, 4, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the job without payload stays Processing
+	for _, jb := range jobs {
+		if len(jb.Payload) == 0 {
+			continue
+		}
+		time.Sleep(5 * time.Millisecond)
+		if err := puller.Kill(ctx, jb); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	page, err := observer.ListDead(ctx, &gqs.DeadListOptions{Limit: 2, PreviewSize: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Letters) != 2 || page.Next == "" {
+		t.Fatalf("expected a first page of 2, got %d", len(page.Letters))
+	}
+	first := page.Letters[0]
+	if string(first.Preview) != "xxxx" || first.PayloadSize != 10 || first.Attempts != 1 {
+		t.Fatalf("unexpected letter %+v", first)
+	}
+	if first.UpdatedAt.Before(page.Letters[1].UpdatedAt) {
+		t.Fatal("expected the most recently killed job first")
+	}
+
+	next, err := observer.ListDead(ctx, &gqs.DeadListOptions{Limit: 2, Cursor: page.Next})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(next.Letters) != 1 || next.Next != "" {
+		t.Fatalf("expected a last page of 1, got %d", len(next.Letters))
+	}
+	if len(next.Letters[0].Preview) != 10 {
+		t.Fatal("expected the whole short payload as preview")
+	}
+
+	if _, err := observer.ListDead(ctx, &gqs.DeadListOptions{Cursor: "bad"}); !errors.Is(err, gqs.ErrBadCursor) {
+		t.Fatalf("expected ErrBadCursor, got %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package sql
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/uptrace/bun"
+	"slices"
+)
+
+// dependencyModel is an edge of the dependency graph: the job JobId
+// waits until the job DependsOn is Done or, if OnEnd is set, until it
+// reaches any terminal status.
+type dependencyModel struct {
+	bun.BaseModel `bun:"table:job_dependencies"`
+
+	JobId     uuid.UUID `bun:"job_id,pk,type:uuid"`
+	DependsOn uuid.UUID `bun:"depends_on,pk,type:uuid"`
+	OnEnd     bool      `bun:"on_end,notnull,default:false"`
+}
+
+func createDependencyTable(ctx context.Context, db bun.IDB) error {
+	_, err := db.NewCreateTable().
+		Model((*dependencyModel)(nil)).
+		IfNotExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = db.NewCreateIndex().
+		Model((*dependencyModel)(nil)).
+		Index("idx_job_dependencies_depends_on").
+		Column("depends_on").
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+// insertDependencies records the dependencies of the inserted models.
+// If replace is set, previously recorded dependencies of the models are
+// removed first, as their jobs were replaced by an upsert.
+func insertDependencies(ctx context.Context, db bun.IDB, models []*jobModel, replace bool) error {
+	var edges []dependencyModel
+	ids := make([]uuid.UUID, len(models))
+	for i, model := range models {
+		ids[i] = model.Id
+		for _, dep := range model.DependsOn {
+			edges = append(edges, dependencyModel{
+				JobId:     model.Id,
+				DependsOn: dep,
+				OnEnd:     slices.Contains(model.onEnd, dep),
+			})
+		}
+	}
+	if replace {
+		_, err := db.NewDelete().
+			Model((*dependencyModel)(nil)).
+			Where("job_id IN (?)", bun.In(ids)).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	if len(edges) == 0 {
+		return nil
+	}
+	// duplicate entries of DependsOn are ignored
+	_, err := db.NewInsert().
+		Model(&edges).
+		Ignore().
+		Exec(ctx)
+	return err
+}
+
+// selectBlocking selects the dependencies of the outer job that are not
+// satisfied yet. Dependencies unknown to the jobs table do not block.
+func selectBlocking(db bun.IDB) *bun.SelectQuery {
+	return db.NewSelect().
+		TableExpr("? AS dep", bun.Ident("job_dependencies")).
+		Join("JOIN ? AS dj ON dj.id = dep.depends_on", bun.Ident("jobs")).
+		ColumnExpr("1").
+		Where("dep.job_id = ?TableAlias.id").
+		Where("dj.status != ?", job.Done).
+		WhereGroup("AND", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.
+				Where("dep.on_end = ?", false).
+				WhereOr("dj.status NOT IN (?)", bun.In(job.TerminalStatuses))
+		})
+}
+
+// blockingIds selects the ids of jobs that waiting jobs depend on; the
+// derived table lets MySQL modify the table it selects from.
+const blockingIds = "SELECT depends_on FROM " +
+	"(SELECT DISTINCT dep.depends_on FROM ? AS dep JOIN ? AS w ON w.id = dep.job_id " +
+	"WHERE dep.on_end = ? AND w.status IN (?)) AS blocking"
+
+func blockingArgs() []any {
+	return []any{bun.Ident("job_dependencies"), bun.Ident("jobs"), false, bun.In(waiting)}
+}
+
+// whereBlocking restricts a query to jobs that waiting jobs depend on.
+func whereBlocking(q bun.QueryBuilder) bun.QueryBuilder {
+	return q.Where("id IN ("+blockingIds+")", blockingArgs()...)
+}
+
+// whereNotBlocking excludes Dead and Cancelled jobs that waiting jobs
+// depend on, so that their removal does not satisfy the dependency
+// before DependencyReaper kills the waiting jobs.
+func whereNotBlocking(q bun.QueryBuilder) bun.QueryBuilder {
+	return q.WhereGroup("AND", func(q bun.QueryBuilder) bun.QueryBuilder {
+		return q.
+			Where("status = ?", job.Done).
+			WhereOr("id NOT IN ("+blockingIds+")", blockingArgs()...)
+	})
+}
+
+// DependencyReaper kills jobs whose dependencies failed.
+//
+// Puller never pulls a job before all its dependencies (see
+// message.Message.DependsOn) are Done, so a job depending on a Dead or
+// Cancelled job would wait forever. DependencyReaper kills such jobs,
+// recording gqs.ErrDependencyFailed as their last error, and removes
+// the dependencies of Done jobs. It implements gqs.Maintainer and is
+// intended to be run periodically by gqs.MaintenanceWorker.
+//
+// Dependencies of Dead and Cancelled jobs are kept, so a job revived by
+// Admin.RequeueByStatus waits for them again. Cleaner keeps Dead and
+// Cancelled jobs that waiting jobs depend on until those jobs are
+// killed; a failed dependency deleted otherwise, such as by
+// Admin.DeleteByIds, is considered satisfied.
+type DependencyReaper struct {
+	db    *bun.DB
+	clock *Clock
+}
+
+// DependencyReaperOptions defines optional behavior of a
+// DependencyReaper.
+//
+// Clock, if set, provides the time written to updated_at of killed
+// jobs instead of the local clock (see Clock).
+type DependencyReaperOptions struct {
+	Clock *Clock
+}
+
+// NewDependencyReaper creates a new SQL-backed DependencyReaper.
+//
+// The provided *bun.DB must be properly configured and connected.
+// Schema initialization must be completed before using
+// DependencyReaper.
+func NewDependencyReaper(db *bun.DB) *DependencyReaper {
+	return NewDependencyReaperWithOptions(db, &DependencyReaperOptions{})
+}
+
+// NewDependencyReaperWithOptions creates a new SQL-backed
+// DependencyReaper using the provided options.
+func NewDependencyReaperWithOptions(db *bun.DB, opts *DependencyReaperOptions) *DependencyReaper {
+	return &DependencyReaper{
+		db:    db,
+		clock: opts.Clock,
+	}
+}
+
+// Reap kills every Pending or Scheduled job depending on a Dead or
+// Cancelled job and returns the number of killed jobs. Callbacks of
+// groups are not killed, as they wait for their members to end in any
+// way. Killing is repeated until no job is affected, so that failures
+// propagate through chains of dependencies.
+//
+// Afterwards, the dependencies of jobs that are Done, or that were
+// deleted, are removed.
+func (dr *DependencyReaper) Reap(ctx context.Context) (int64, error) {
+	var ret int64
+	for {
+		count, err := dr.kill(ctx)
+		if err != nil {
+			return ret, wrap("reap dependencies", uuid.Nil, err)
+		}
+		if count == 0 {
+			break
+		}
+		ret += count
+	}
+	_, err := dr.db.NewDelete().
+		Model((*dependencyModel)(nil)).
+		Where("job_id NOT IN (?)", dr.db.NewSelect().
+			Model((*jobModel)(nil)).
+			Column("id").
+			Where("status != ?", job.Done)).
+		Exec(ctx)
+	return ret, wrap("reap dependencies", uuid.Nil, err)
+}
+
+func (dr *DependencyReaper) kill(ctx context.Context) (int64, error) {
+	failed := dr.db.NewSelect().
+		TableExpr("? AS dep", bun.Ident("job_dependencies")).
+		Join("JOIN ? AS dj ON dj.id = dep.depends_on", bun.Ident("jobs")).
+		Column("dep.job_id").
+		Where("dj.status IN (?, ?)", job.Dead, job.Cancelled).
+		Where("dep.on_end = ?", false)
+	// the derived table lets MySQL update the table it selects from
+	res, err := dr.db.NewUpdate().
+		Model((*jobModel)(nil)).
+		Set("status = ?", job.Dead).
+		Set("last_error = ?", gqs.ErrDependencyFailed.Error()).
+		Set("version = version + 1").
+		Set("updated_at = ?", dr.clock.now(ctx, dr.db)).
+		Where("status IN (?)", bun.In(waiting)).
+		Where("id IN (SELECT job_id FROM (?) AS failed)", failed).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return getAffected(res), nil
+}
+
+// Maintain implements gqs.Maintainer by calling Reap.
+func (dr *DependencyReaper) Maintain(ctx context.Context) error {
+	_, err := dr.Reap(ctx)
+	return err
+}
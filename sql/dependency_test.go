@@ -0,0 +1,190 @@
+package sql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestPullDependencies(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	first := message.NewMessage()
+	second := message.NewMessage()
+	second.DependsOn = []uuid.UUID{first.Id, first.Id}
+	if err := pusher.Push(ctx, first, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := pusher.Push(ctx, second, 0); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := observer.Get(ctx, second.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.DependsOn) != 2 || stored.DependsOn[0] != first.Id {
+		t.Fatalf("expected stored dependencies, got %v", stored.DependsOn)
+	}
+
+	jobs, err := puller.Pull(ctx, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].Id != first.Id {
+		t.Fatal("expected only the job without pending dependencies")
+	}
+	if err := puller.Complete(ctx, jobs[0]); err != nil {
+		t.Fatal(err)
+	}
+	jobs, err = puller.Pull(ctx, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].Id != second.Id {
+		t.Fatal("expected the dependent job once its dependency is done")
+	}
+}
+
+func TestDependencyReaper(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+	reaper := gsql.NewDependencyReaper(db)
+
+	root := message.NewMessage()
+	child := message.NewMessage()
+	child.DependsOn = []uuid.UUID{root.Id}
+	grandchild := message.NewMessage()
+	grandchild.DependsOn = []uuid.UUID{child.Id}
+	for _, msg := range []*message.Message{root, child, grandchild} {
+		if err := pusher.Push(ctx, msg, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	jobs, err := puller.Pull(ctx, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("expected 1 pulled job, got %d", len(jobs))
+	}
+	count, err := reaper.Reap(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("expected no killed jobs while the root runs, got %d", count)
+	}
+	if err := puller.Kill(ctx, jobs[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err = reaper.Reap(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 killed dependents, got %d", count)
+	}
+	for _, msg := range []*message.Message{child, grandchild} {
+		jb, err := observer.Get(ctx, msg.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if jb.Status != job.Dead || jb.LastError != gqs.ErrDependencyFailed.Error() {
+			t.Fatalf("expected dependent killed by its dependency, got %v (%s)", jb.Status, jb.LastError)
+		}
+	}
+}
+
+func TestDependencyReaperKeepsFailed(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	admin := gsql.NewAdmin(db)
+	cleaner := gsql.NewCleaner(db)
+	reaper := gsql.NewDependencyReaper(db)
+
+	root := message.NewMessage()
+	child := message.NewMessage()
+	child.DependsOn = []uuid.UUID{root.Id}
+	for _, msg := range []*message.Message{root, child} {
+		if err := pusher.Push(ctx, msg, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	jobs, err := puller.Pull(ctx, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("expected 1 pulled job, got %d", len(jobs))
+	}
+	if err := puller.Kill(ctx, jobs[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	// the failed dependency outlives cleaning until its dependent is killed
+	count, err := cleaner.Clean(ctx, job.Dead, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("expected the blocking dependency kept, got %d deleted", count)
+	}
+	count, err = reaper.Reap(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected the dependent killed, got %d", count)
+	}
+
+	// a requeued dependent waits for its dependencies again
+	count, err = admin.RequeueByStatus(ctx, &gqs.ListOptions{Ids: []uuid.UUID{child.Id}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected the dependent requeued, got %d", count)
+	}
+	jobs, err = puller.Pull(ctx, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 0 {
+		t.Fatal("expected the requeued dependent blocked by its failed dependency")
+	}
+	count, err = reaper.Reap(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected the requeued dependent killed again, got %d", count)
+	}
+
+	count, err = cleaner.Clean(ctx, job.Dead, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("expected both jobs deleted once nothing waits, got %d", count)
+	}
+}
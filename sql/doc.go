@@ -21,6 +21,11 @@
 // with a subquery to avoid race conditions between selection and
 // state transition.
 //
+// On PostgreSQL, Puller may instead be created with PullSkipLocked mode
+// (see NewPullerWithOptions), which locks candidate rows using
+// SELECT ... FOR UPDATE SKIP LOCKED. This avoids row contention between
+// many concurrent workers.
+//
 // Correct behavior under high concurrency depends on:
 //
 //   - proper indexing
@@ -30,6 +35,26 @@
 // SQLite users are strongly encouraged to enable WAL mode and
 // configure an appropriate busy_timeout.
 //
+// By default the selection sorts every eligible job by priority, which
+// gets slower as the backlog grows. PullerOptions.ScanWindow bounds it
+// to index range scans over the jobs due first, at the cost of
+// honoring priority only within the window.
+//
+// Jobs whose lease expired keep their original next_run_at and are
+// therefore claimed before fresh jobs of equal priority.
+// PullerOptions.ExpiredOrder and RequeueExpired queue them up behind
+// fresh jobs instead, so that jobs crashing their workers do not block
+// new work.
+//
+// # Clock Skew
+//
+// Leases and eligibility are decided by comparing timestamps, which are
+// taken from the local clock of the application by default. In
+// deployments spanning several hosts, a shared Clock passed to Puller,
+// Pusher, Promoter, Expirer, Admin, Cleaner, Registry, DependencyReaper
+// and WorkflowStore takes them from the database server instead, so
+// that skewed host clocks do not expire leases early.
+//
 // # Schema
 //
 // The backend expects a "jobs" table corresponding to jobModel.
@@ -37,14 +62,24 @@
 //
 //   - the jobs table (if not exists)
 //   - index (status, next_run_at)
+//   - index (status, priority, next_run_at)
 //   - index (status, locked_until)
 //   - index (status, updated_at)
+//   - index (queue, status, created_at)
+//   - index (tenant_id, status, next_run_at)
+//   - index (group_id, status)
+//   - index (queue, status, updated_at)
+//   - the alert_thresholds table used by Alerter
+//   - the job_dependencies table recording message.Message.DependsOn
+//   - the workflows table used by WorkflowStore
 //
 // These indexes are required for efficient Pull and Clean operations.
 //
 // InitDB is idempotent and runs inside a transaction.
-// It does not perform destructive migrations.
-// Schema evolution must be handled externally.
+// It adds columns missing from tables created by earlier versions,
+// but does not perform destructive migrations: changes of existing
+// columns must be handled externally. Triggers installed by InitDB
+// and their functions are replaced on every call.
 //
 // # Database Lifecycle
 //
@@ -58,11 +93,96 @@
 //   - WAL/busy_timeout configuration (for SQLite)
 //   - running InitDB before use
 //
+// # Partitioning
+//
+// On PostgreSQL, InitDBWithOptions can create a declaratively
+// partitioned jobs table, either by range of created_at
+// (PartitionByCreatedAt) or by hash of queue (PartitionByQueue).
+//
+// Range partitions are created ahead of time and dropped after
+// retention by PartitionMaintainer, typically run by
+// gqs.MaintenanceWorker. Clean adds a created_at bound to its filter
+// so that only relevant partitions are scanned; Pull restricted to
+// specific queues scans only the matching hash partitions.
+//
+// The primary key of a partitioned table includes the partition key,
+// so Pushers of such a table must be created with
+// PusherOptions.Partitioned to keep rejecting duplicate ids.
+//
+// # Transactional Push
+//
+// Pusher.PushTx enqueues a job inside a caller-provided transaction,
+// so that the job is committed or rolled back together with the
+// application's own writes (outbox pattern).
+//
+// # Transition Outbox
+//
+// With InitOptions.Outbox, triggers record every job state transition
+// into the job_outbox table atomically with the change. Outbox exposes
+// it as a gqs.Outbox, so that gqs.RelayWorker can publish lifecycle
+// events to external sinks with at-least-once delivery.
+//
+// # Archive
+//
+// With InitOptions.Archive, Cleaner.Archive moves terminal jobs into the
+// jobs_archive table instead of deleting them, keeping the jobs table
+// small for Pull while retaining job records. gqs.CleanWorker archives
+// when CleanConfig.Archive is set; ArchiveObserver queries the archive.
+//
+// # Retention
+//
+// PusherOptions.Retention and QueueRetention record how long each job
+// is kept once it ended, per terminal status, in the retain_done,
+// retain_dead and retain_cancelled columns. gqs.CleanWorker deletes
+// them with Cleaner.CleanRetained once their retention elapsed, and
+// applies its global policy with CleanUnretained, which skips them;
+// Clean and Archive treat them like any other job.
+//
+// # Scheduled Jobs
+//
+// Jobs pushed or returned with a future next_run_at are stored as
+// job.Scheduled. Puller claims due Scheduled jobs directly; Promoter,
+// run by gqs.MaintenanceWorker, moves them to job.Pending, so that
+// observers separate the ready backlog from deferred jobs.
+//
+// # Dependencies
+//
+// Pusher records message.Message.DependsOn in the job_dependencies
+// table, and Puller skips jobs until all their dependencies are Done.
+// DependencyReaper, run by gqs.MaintenanceWorker, kills jobs whose
+// dependencies died or were cancelled, so they do not wait forever;
+// Cleaner keeps such dependencies until their dependents are killed.
+//
+// Pusher.PushGroup records the callback of a group as depending on every
+// member, with dependencies satisfied by any terminal status; the
+// group_id column lets Observer.Group count the members by status.
+//
+// # Embedded Mode
+//
+// Package sqlite (github.com/romanqed/gqs/sql/sqlite) opens an SQLite
+// database file with recommended pragmas, initializes the schema and
+// wires the backend with sensible worker defaults via OpenDefault.
+//
+// # Errors
+//
+// Puller, Pusher, Observer, Admin, Cleaner and the other components of
+// the package return failures of the database as *gqs.StorageError,
+// naming the operation and job and classifying the driver error by its
+// SQLSTATE or message, so that callers can retry transient failures
+// (see gqs.IsTransient). Errors reporting the state of jobs, such as
+// gqs.ErrLockLost, invalid arguments and context errors are returned
+// unwrapped, as are errors returned by callbacks such as the fn of
+// Observer.Report. InitDB returns the errors of the driver unchanged.
+//
 // # Limitations
 //
 // The SQL backend uses status + timestamp fields to implement
-// lease semantics. It does not use lease tokens or optimistic
-// locking versions.
+// lease semantics. It does not use lease tokens; instead, every update
+// of a job increments its version column, and transitions of a
+// pulled job apply only while the version matches the snapshot of
+// the worker. A worker whose lease expired therefore cannot extend,
+// complete, return or kill a job pulled again by another worker,
+// although it may still have run its handler concurrently.
 //
 // Exactly-once processing is not guaranteed.
 // Delivery semantics remain at-least-once.
@@ -0,0 +1,152 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/workflow"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// stateErrors report the state of jobs, invalid arguments or the end of
+// the context rather than storage failures, and are returned without
+// wrapping.
+var stateErrors = []error{
+	gqs.ErrBadCursor,
+	gqs.ErrBadStatus,
+	gqs.ErrBatchAborted,
+	gqs.ErrCancelRequested,
+	gqs.ErrCompleteFailed,
+	gqs.ErrDuplicateID,
+	gqs.ErrJobLost,
+	gqs.ErrLockLost,
+	gqs.ErrNotFound,
+	ErrUpsertUnsupported,
+	errIgnored,
+	workflow.ErrConflict,
+	context.Canceled,
+	context.DeadlineExceeded,
+}
+
+// wrap converts err, returned while performing op on the job id, into a
+// *gqs.StorageError. Nil, errors in stateErrors and errors already
+// wrapped are returned unchanged.
+func wrap(op string, id uuid.UUID, err error) error {
+	if err == nil {
+		return nil
+	}
+	var se *gqs.StorageError
+	if errors.As(err, &se) {
+		return err
+	}
+	for _, target := range stateErrors {
+		if errors.Is(err, target) {
+			return err
+		}
+	}
+	return &gqs.StorageError{
+		Op:   op,
+		Id:   id,
+		Kind: errorKind(err),
+		Err:  err,
+	}
+}
+
+// sqlState is implemented by errors of PostgreSQL drivers such as pgx
+// and lib/pq.
+type sqlState interface {
+	SQLState() string
+}
+
+// pgField is implemented by errors of the bun pgdriver.
+type pgField interface {
+	Field(k byte) string
+}
+
+// transientTexts are fragments of messages of drivers that do not
+// expose error codes through an interface, such as those of MySQL.
+var transientTexts = []string{
+	"Deadlock found",
+	"Lock wait timeout exceeded",
+	"connection refused",
+	"connection reset",
+	"broken pipe",
+	"bad connection",
+	"invalid connection",
+}
+
+var constraintTexts = []string{
+	"constraint failed",
+	"Duplicate entry",
+	"foreign key constraint fails",
+	"cannot be null",
+}
+
+// errorKind classifies err by its SQLSTATE, if the driver exposes one,
+// and otherwise by its type and text.
+func errorKind(err error) gqs.StorageErrorKind {
+	if state := stateOf(err); state != "" {
+		return stateKind(state)
+	}
+	var netErr net.Error
+	switch {
+	case errors.Is(err, driver.ErrBadConn),
+		errors.Is(err, sql.ErrConnDone),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.As(err, &netErr),
+		isBusy(err):
+		return gqs.StorageTransient
+	}
+	msg := err.Error()
+	for _, text := range transientTexts {
+		if strings.Contains(msg, text) {
+			return gqs.StorageTransient
+		}
+	}
+	for _, text := range constraintTexts {
+		if strings.Contains(msg, text) {
+			return gqs.StorageConstraint
+		}
+	}
+	return gqs.StorageUnknown
+}
+
+func stateOf(err error) string {
+	var state sqlState
+	if errors.As(err, &state) {
+		return state.SQLState()
+	}
+	var field pgField
+	if errors.As(err, &field) {
+		return field.Field('C')
+	}
+	return ""
+}
+
+// transientStates are SQLSTATE classes and codes of failures that may
+// not recur: connection exceptions, transaction rollbacks such as
+// serialization failures and deadlocks, insufficient resources,
+// operator intervention and unavailable locks.
+var transientStates = []string{"08", "40", "53", "57P", "55P03"}
+
+// stateKind classifies a SQLSTATE code.
+func stateKind(state string) gqs.StorageErrorKind {
+	// class 23 is integrity constraint violation
+	if strings.HasPrefix(state, "23") {
+		return gqs.StorageConstraint
+	}
+	for _, prefix := range transientStates {
+		if strings.HasPrefix(state, prefix) {
+			return gqs.StorageTransient
+		}
+	}
+	return gqs.StorageUnknown
+}
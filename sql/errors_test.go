@@ -0,0 +1,77 @@
+package sql_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestStorageError(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+
+	// errors reporting the state of a job are not wrapped
+	missing := &job.Job{Message: *message.NewMessage()}
+	err := puller.Complete(ctx, missing)
+	var se *gqs.StorageError
+	if !errors.Is(err, gqs.ErrCompleteFailed) || errors.As(err, &se) {
+		t.Fatalf("expected bare ErrCompleteFailed, got %v", err)
+	}
+
+	msg := message.NewMessage()
+	_ = db.Close()
+
+	err = pusher.Push(ctx, msg, 0)
+	if !errors.As(err, &se) {
+		t.Fatalf("expected StorageError, got %v", err)
+	}
+	if se.Op != "push" || se.Id != msg.Id || se.Err == nil {
+		t.Fatalf("unexpected storage error %+v", se)
+	}
+
+	_, err = puller.Pull(ctx, 1, time.Second)
+	if !errors.As(err, &se) || se.Op != "pull" {
+		t.Fatalf("expected StorageError of pull, got %v", err)
+	}
+	observer := gsql.NewObserver(db)
+	for op, fn := range map[string]func() error{
+		"stats": func() error {
+			_, err := observer.Stats(ctx)
+			return err
+		},
+		"queue metrics": func() error {
+			_, err := observer.QueueMetrics(ctx)
+			return err
+		},
+		"instances": func() error {
+			_, err := observer.Instances(ctx)
+			return err
+		},
+		"pause": func() error {
+			return gsql.NewQueueController(db).Pause(ctx, "q")
+		},
+		"fetch transitions": func() error {
+			_, err := gsql.NewOutbox(db).Fetch(ctx, 1)
+			return err
+		},
+	} {
+		if err := fn(); !errors.As(err, &se) || se.Op != op {
+			t.Fatalf("expected StorageError of %s, got %v", op, err)
+		}
+	}
+	if gqs.IsTransient(errors.New("plain")) {
+		t.Fatal("expected plain errors not to be transient")
+	}
+	if !gqs.IsTransient(&gqs.StorageError{Op: "pull", Kind: gqs.StorageTransient, Err: err}) {
+		t.Fatal("expected transient storage error")
+	}
+}
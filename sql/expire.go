@@ -0,0 +1,126 @@
+package sql
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// ExpireAction selects what an Expirer does with expired jobs.
+type ExpireAction uint8
+
+const (
+	// ExpireKill transitions expired jobs to Dead, recording
+	// gqs.ErrExpired as their last error. It is the default.
+	ExpireKill ExpireAction = iota
+
+	// ExpireDelete deletes expired jobs. Expired jobs that waiting jobs
+	// depend on are killed instead, as deleting them would satisfy the
+	// dependency (see DependencyReaper).
+	ExpireDelete
+)
+
+// ExpirerOptions defines optional behavior of an Expirer.
+//
+// Action selects what is done with expired jobs.
+//
+// Clock, if set, provides the time expiry and leases are determined by
+// instead of the local clock (see Clock).
+type ExpirerOptions struct {
+	Action ExpireAction
+	Clock  *Clock
+}
+
+// Expirer removes jobs whose TTL elapsed before they were delivered.
+//
+// Puller never pulls expired jobs, but leaves them Pending or
+// Scheduled (or Processing with an expired lease). Expirer kills or deletes them, so
+// they do not accumulate; it implements gqs.Maintainer and is intended
+// to be run periodically by gqs.MaintenanceWorker.
+type Expirer struct {
+	db     *bun.DB
+	action ExpireAction
+	clock  *Clock
+}
+
+// NewExpirer creates a new SQL-backed Expirer killing expired jobs.
+//
+// The provided *bun.DB must be properly configured and connected.
+// Schema initialization must be completed before using Expirer.
+func NewExpirer(db *bun.DB) *Expirer {
+	return NewExpirerWithOptions(db, &ExpirerOptions{})
+}
+
+// NewExpirerWithOptions creates a new SQL-backed Expirer using
+// the provided options.
+func NewExpirerWithOptions(db *bun.DB, opts *ExpirerOptions) *Expirer {
+	return &Expirer{
+		db:     db,
+		action: opts.Action,
+		clock:  opts.Clock,
+	}
+}
+
+func whereExpired(now time.Time) func(q bun.QueryBuilder) bun.QueryBuilder {
+	return func(q bun.QueryBuilder) bun.QueryBuilder {
+		return q.
+			Where("expires_at <= ?", now).
+			WhereGroup("AND", func(q bun.QueryBuilder) bun.QueryBuilder {
+				return q.
+					Where("status IN (?)", bun.In(waiting)).
+					WhereOr("status = ? AND locked_until < ?", job.Processing, now)
+			})
+	}
+}
+
+// Expire kills or deletes every job that expired before being pulled
+// and returns the number of affected jobs.
+//
+// Jobs whose lease is still active are left to their worker.
+func (e *Expirer) Expire(ctx context.Context) (int64, error) {
+	now := e.clock.now(ctx, e.db)
+	if e.action != ExpireDelete {
+		ret, err := e.kill(ctx, now, whereExpired(now))
+		return ret, wrap("expire", uuid.Nil, err)
+	}
+	killed, err := e.kill(ctx, now, func(q bun.QueryBuilder) bun.QueryBuilder {
+		return whereBlocking(whereExpired(now)(q))
+	})
+	if err != nil {
+		return 0, wrap("expire", uuid.Nil, err)
+	}
+	res, err := e.db.NewDelete().
+		Model((*jobModel)(nil)).
+		ApplyQueryBuilder(whereExpired(now)).
+		ApplyQueryBuilder(whereNotBlocking).
+		Exec(ctx)
+	if err != nil {
+		return killed, wrap("expire", uuid.Nil, err)
+	}
+	return killed + getAffected(res), nil
+}
+
+func (e *Expirer) kill(ctx context.Context, now time.Time, filter func(bun.QueryBuilder) bun.QueryBuilder) (int64, error) {
+	res, err := e.db.NewUpdate().
+		Model((*jobModel)(nil)).
+		Set("status = ?", job.Dead).
+		Set("last_error = ?", gqs.ErrExpired.Error()).
+		Set("locked_until = NULL").
+		Set("version = version + 1").
+		Set("updated_at = ?", now).
+		ApplyQueryBuilder(filter).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return getAffected(res), nil
+}
+
+// Maintain implements gqs.Maintainer by calling Expire.
+func (e *Expirer) Maintain(ctx context.Context) error {
+	_, err := e.Expire(ctx)
+	return err
+}
@@ -0,0 +1,114 @@
+package sql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestExpire(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	short := message.NewMessage()
+	short.TTL = 50 * time.Millisecond
+	long := message.NewMessage()
+	long.TTL = time.Hour
+	for _, msg := range []*message.Message{short, long} {
+		if err := pusher.Push(ctx, msg, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	jobs, err := puller.Pull(ctx, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].Id != long.Id {
+		t.Fatal("expected only the unexpired job to be pulled")
+	}
+	if jobs[0].ExpiresAt == nil || jobs[0].TTL != time.Hour {
+		t.Fatal("expected expiry to be stored")
+	}
+
+	count, err := gsql.NewExpirer(db).Expire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 expired job, got %d", count)
+	}
+	j, err := observer.Get(ctx, short.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.Status != job.Dead || j.LastError != gqs.ErrExpired.Error() {
+		t.Fatalf("expected expired job to be killed, got %v %q", j.Status, j.LastError)
+	}
+
+	gone := message.NewMessage()
+	gone.TTL = time.Millisecond
+	if err := pusher.Push(ctx, gone, 0); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	expirer := gsql.NewExpirerWithOptions(db, &gsql.ExpirerOptions{Action: gsql.ExpireDelete})
+	if err := expirer.Maintain(ctx); err != nil {
+		t.Fatal(err)
+	}
+	j, err = observer.Get(ctx, gone.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j != nil {
+		t.Fatal("expected expired job to be deleted")
+	}
+
+	// an expired dependency is killed rather than deleted
+	dependency := message.NewMessage()
+	dependency.TTL = time.Millisecond
+	dependent := message.NewMessage()
+	dependent.DependsOn = []uuid.UUID{dependency.Id}
+	for _, msg := range []*message.Message{dependency, dependent} {
+		if err := pusher.Push(ctx, msg, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	count, err = expirer.Expire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 expired job, got %d", count)
+	}
+	j, err = observer.Get(ctx, dependency.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j == nil || j.Status != job.Dead || j.LastError != gqs.ErrExpired.Error() {
+		t.Fatalf("expected the expired dependency killed, got %+v", j)
+	}
+	if _, err := gsql.NewDependencyReaper(db).Reap(ctx); err != nil {
+		t.Fatal(err)
+	}
+	j, err = observer.Get(ctx, dependent.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.Status != job.Dead || j.LastError != gqs.ErrDependencyFailed.Error() {
+		t.Fatalf("expected the dependent killed, got %v %q", j.Status, j.LastError)
+	}
+}
@@ -0,0 +1,64 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"io"
+)
+
+const exportBatch = 1000
+
+// Export writes every job to w in the gqs export format and returns
+// the manifest of the written data.
+//
+// Jobs are read within a single read-only transaction, in batches of
+// ascending id. On PostgreSQL the transaction uses the REPEATABLE READ
+// isolation level, so the export reflects one snapshot even while
+// workers keep modifying jobs; other dialects rely on their default
+// transaction isolation.
+//
+// If the Observer reads from a replica, the export reflects the state
+// of the replica.
+func (o *Observer) Export(ctx context.Context, w io.Writer) (*gqs.Manifest, error) {
+	var opts *sql.TxOptions
+	if o.db.Dialect().Name() == dialect.PG {
+		opts = &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	}
+	tx, err := o.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, wrap("export", uuid.Nil, err)
+	}
+	ret, err := export(ctx, tx, gqs.NewExportWriter(w))
+	return ret, errors.Join(err, wrap("export", uuid.Nil, tx.Rollback()))
+}
+
+func export(ctx context.Context, tx bun.Tx, ew *gqs.ExportWriter) (*gqs.Manifest, error) {
+	var last *uuid.UUID
+	for {
+		var models []jobModel
+		query := tx.NewSelect().
+			Model(&models).
+			OrderExpr("id ASC").
+			Limit(exportBatch)
+		if last != nil {
+			query.Where("id > ?", *last)
+		}
+		if err := query.Scan(ctx); err != nil {
+			return nil, wrap("export", uuid.Nil, err)
+		}
+		for i := range models {
+			if err := ew.Write(models[i].toJob()); err != nil {
+				return nil, err
+			}
+		}
+		if len(models) < exportBatch {
+			return ew.Manifest(), nil
+		}
+		last = &models[len(models)-1].Id
+	}
+}
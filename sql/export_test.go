@@ -0,0 +1,80 @@
+package sql_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestExport(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	msgs := make([]*message.Message, 1001)
+	for i := range msgs {
+		msgs[i] = message.NewMessage()
+		msgs[i].Payload = []byte("payload")
+	}
+	if _, err := pusher.PushBatch(ctx, msgs, 0, gqs.BatchAtomic); err != nil {
+		t.Fatal(err)
+	}
+	jobs, err := puller.Pull(ctx, 1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := puller.Complete(ctx, jobs[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	manifest, err := observer.Export(ctx, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Jobs != 1001 || manifest.Statuses[job.Pending] != 1000 || manifest.Statuses[job.Done] != 1 {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+
+	var seen int
+	read, err := gqs.ReadExport(bytes.NewReader(buf.Bytes()), func(jb *job.Job) error {
+		seen++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seen != 1001 {
+		t.Fatalf("expected 1001 jobs read, got %d", seen)
+	}
+	if err := manifest.Verify(read); err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := observer.Export(ctx, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manifest.Verify(again); err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := bytes.Replace(buf.Bytes(), []byte("cGF5bG9hZA=="), []byte("dGFtcGVyZWQ="), 1)
+	read, err = gqs.ReadExport(bytes.NewReader(tampered), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manifest.Verify(read); !errors.Is(err, gqs.ErrManifestMismatch) {
+		t.Fatalf("expected ErrManifestMismatch, got %v", err)
+	}
+}
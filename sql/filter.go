@@ -0,0 +1,110 @@
+package sql
+
+import (
+	"encoding/base64"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func metadataPath(name dialect.Name, key string) string {
+	if name == dialect.PG {
+		return key
+	}
+	return "$." + strconv.Quote(key)
+}
+
+func metadataExpr(name dialect.Name) string {
+	switch name {
+	case dialect.PG:
+		return "metadata->>? = ?"
+	case dialect.MySQL:
+		return "JSON_UNQUOTE(JSON_EXTRACT(metadata, ?)) = ?"
+	default:
+		return "CAST(json_extract(metadata, ?) AS TEXT) = ?"
+	}
+}
+
+func applyFilter(name dialect.Name, opts *gqs.ListOptions) func(bun.QueryBuilder) bun.QueryBuilder {
+	return func(q bun.QueryBuilder) bun.QueryBuilder {
+		if len(opts.Ids) != 0 {
+			q = q.Where("id IN (?)", bun.In(opts.Ids))
+		}
+		if len(opts.Statuses) != 0 {
+			q = q.Where("status IN (?)", bun.In(opts.Statuses))
+		}
+		if len(opts.Queues) != 0 {
+			q = q.Where("queue IN (?)", bun.In(opts.Queues))
+		}
+		if len(opts.Tenants) != 0 {
+			q = q.Where("tenant_id IN (?)", bun.In(opts.Tenants))
+		}
+		if len(opts.LockedBy) != 0 {
+			q = q.Where("locked_by IN (?)", bun.In(opts.LockedBy))
+		}
+		if opts.CreatedAfter != nil {
+			q = q.Where("created_at >= ?", *opts.CreatedAfter)
+		}
+		if opts.CreatedBefore != nil {
+			q = q.Where("created_at <= ?", *opts.CreatedBefore)
+		}
+		if opts.UpdatedAfter != nil {
+			q = q.Where("updated_at >= ?", *opts.UpdatedAfter)
+		}
+		if opts.UpdatedBefore != nil {
+			q = q.Where("updated_at <= ?", *opts.UpdatedBefore)
+		}
+		expr := metadataExpr(name)
+		for key, value := range opts.Metadata {
+			q = q.Where(expr, metadataPath(name, key), value)
+		}
+		return q
+	}
+}
+
+type cursor struct {
+	at time.Time
+	id uuid.UUID
+}
+
+func encodeCursor(at time.Time, id uuid.UUID) string {
+	raw := strconv.FormatInt(at.UnixNano(), 10) + "|" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, gqs.ErrBadCursor
+	}
+	stamp, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, gqs.ErrBadCursor
+	}
+	nanos, err := strconv.ParseInt(stamp, 10, 64)
+	if err != nil {
+		return nil, gqs.ErrBadCursor
+	}
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return nil, gqs.ErrBadCursor
+	}
+	return &cursor{at: time.Unix(0, nanos), id: parsed}, nil
+}
+
+func orderColumn(order gqs.Order) (string, bool) {
+	switch order {
+	case gqs.OrderCreatedDesc:
+		return "created_at", true
+	case gqs.OrderUpdatedAsc:
+		return "updated_at", false
+	case gqs.OrderUpdatedDesc:
+		return "updated_at", true
+	default:
+		return "created_at", false
+	}
+}
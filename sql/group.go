@@ -0,0 +1,66 @@
+package sql
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/uptrace/bun"
+	"slices"
+)
+
+// PushGroup inserts the members and the callback of group within a
+// single transaction (see gqs.GroupPusher). The callback is recorded as
+// depending on every member, so Puller skips it until all members are
+// terminal; its dependencies are satisfied by any terminal status, and
+// DependencyReaper does not kill it when members die.
+//
+// Duplicate ids are handled according to the ConflictPolicy; a rejected
+// duplicate rolls back the group.
+func (p *Pusher) PushGroup(ctx context.Context, group *gqs.Group) error {
+	gqs.PrepareGroup(group)
+	err := p.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		now := p.clock.now(ctx, tx)
+		members := make([]uuid.UUID, len(group.Members))
+		for i, msg := range group.Members {
+			members[i] = msg.Id
+			if err := p.insert(ctx, tx, fromMessage(msg, now, group.Delay), false); err != nil {
+				return err
+			}
+		}
+		if group.Callback == nil {
+			return nil
+		}
+		model := fromMessage(group.Callback, now, group.Delay)
+		model.DependsOn = slices.Concat(model.DependsOn, members)
+		model.onEnd = members
+		return p.insert(ctx, tx, model, false)
+	})
+	return wrap("push group", group.Id, err)
+}
+
+// Group returns the status of the group id, counting its members with
+// a single query grouped by status, served by the (group_id, status)
+// index.
+func (o *Observer) Group(ctx context.Context, id uuid.UUID) (*gqs.GroupStatus, error) {
+	var summaries []statusSummary
+	err := o.db.NewSelect().
+		Model((*jobModel)(nil)).
+		Column("status").
+		ColumnExpr("COUNT(*) AS count").
+		Where("group_id = ?", id).
+		Group("status").
+		Scan(ctx, &summaries)
+	if err != nil {
+		return nil, wrap("get group", id, err)
+	}
+	ret := &gqs.GroupStatus{
+		Id:     id,
+		Counts: make(map[job.Status]int64, len(summaries)),
+	}
+	for _, summary := range summaries {
+		ret.Counts[summary.Status] = summary.Count
+		ret.Total += summary.Count
+	}
+	return ret, nil
+}
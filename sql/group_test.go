@@ -0,0 +1,72 @@
+package sql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestPushGroup(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+	reaper := gsql.NewDependencyReaper(db)
+
+	group := &gqs.Group{
+		Members:  []*message.Message{message.NewMessage(), message.NewMessage()},
+		Callback: message.NewMessage(),
+	}
+	if err := pusher.PushGroup(ctx, group); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err := puller.Pull(ctx, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("expected only the 2 members, got %d jobs", len(jobs))
+	}
+	if err := puller.Complete(ctx, jobs[0]); err != nil {
+		t.Fatal(err)
+	}
+	status, err := observer.Group(ctx, group.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Total != 2 || status.Counts[job.Done] != 1 || status.Terminal() {
+		t.Fatalf("expected one of 2 members done, got %+v", status)
+	}
+
+	if err := puller.Kill(ctx, jobs[1]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reaper.Reap(ctx); err != nil {
+		t.Fatal(err)
+	}
+	jobs, err = puller.Pull(ctx, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].Id != group.Callback.Id {
+		t.Fatal("expected the callback once every member ended")
+	}
+	if id, _ := message.Get[string](&jobs[0].Message, gqs.MetaGroupId); id != group.Id.String() {
+		t.Fatalf("expected the group id in the callback metadata, got %q", id)
+	}
+	status, err = observer.Group(ctx, group.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Terminal() || status.Counts[job.Dead] != 1 {
+		t.Fatalf("expected a terminal group, got %+v", status)
+	}
+}
@@ -0,0 +1,168 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs/job"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"time"
+)
+
+var (
+	// ErrHistoryUnsupported is returned when job history is requested
+	// for a dialect other than PostgreSQL and SQLite.
+	ErrHistoryUnsupported = errors.New("job history is not supported by dialect")
+
+	// ErrHistoryDisabled is returned by Observer.History if the Observer
+	// was created without ObserverOptions.History.
+	ErrHistoryDisabled = errors.New("job history is disabled")
+)
+
+type eventModel struct {
+	bun.BaseModel `bun:"table:job_events"`
+
+	Id        int64      `bun:"id,pk,autoincrement"`
+	JobId     uuid.UUID  `bun:"job_id,type:uuid,notnull"`
+	At        time.Time  `bun:"at,notnull"`
+	OldStatus job.Status `bun:"old_status,notnull"`
+	NewStatus job.Status `bun:"new_status,notnull"`
+	Attempts  uint32     `bun:"attempts,notnull"`
+	Error     string     `bun:"error,notnull,default:''"`
+}
+
+func (em *eventModel) toEvent() *job.Event {
+	return &job.Event{
+		Time:     em.At,
+		From:     em.OldStatus,
+		To:       em.NewStatus,
+		Attempts: em.Attempts,
+		Error:    em.Error,
+	}
+}
+
+// failureError selects last_error for transitions caused by a failed
+// attempt, that is a Return or Kill of a Processing job. Other
+// transitions, such as Release undoing the attempt, carry no error.
+var failureError = fmt.Sprintf(
+	"CASE WHEN OLD.status = %d AND NEW.status IN (%d, %d, %d, %d) AND NEW.attempts = OLD.attempts "+
+		"THEN NEW.last_error ELSE '' END",
+	job.Processing, job.Pending, job.Scheduled, job.Dead, job.Cancelled,
+)
+
+// PostgreSQL history trigger, recording creation, status and attempt
+// changes and removing events of deleted jobs.
+var pgHistory = []string{
+	`CREATE OR REPLACE FUNCTION gqs_jobs_history() RETURNS trigger AS $$
+BEGIN
+	IF TG_OP = 'DELETE' THEN
+		DELETE FROM job_events WHERE job_id = OLD.id;
+	ELSIF TG_OP = 'INSERT' THEN
+		INSERT INTO job_events (job_id, at, old_status, new_status, attempts)
+		VALUES (NEW.id, NEW.created_at, 0, NEW.status, NEW.attempts);
+	ELSIF OLD.status <> NEW.status OR OLD.attempts <> NEW.attempts THEN
+		INSERT INTO job_events (job_id, at, old_status, new_status, attempts, error)
+		VALUES (NEW.id, NEW.updated_at, OLD.status, NEW.status, NEW.attempts,
+			` + failureError + `);
+	END IF;
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS trg_jobs_history ON jobs`,
+	`CREATE TRIGGER trg_jobs_history AFTER INSERT OR UPDATE OR DELETE ON jobs
+	FOR EACH ROW EXECUTE FUNCTION gqs_jobs_history()`,
+}
+
+// SQLite history triggers, equivalent to pgHistory.
+var sqliteHistory = []string{
+	`DROP TRIGGER IF EXISTS trg_jobs_history_insert`,
+	`CREATE TRIGGER trg_jobs_history_insert AFTER INSERT ON jobs BEGIN
+	INSERT INTO job_events (job_id, at, old_status, new_status, attempts)
+	VALUES (NEW.id, NEW.created_at, 0, NEW.status, NEW.attempts);
+END`,
+	`DROP TRIGGER IF EXISTS trg_jobs_history_update`,
+	`CREATE TRIGGER trg_jobs_history_update AFTER UPDATE OF status, attempts ON jobs
+	WHEN OLD.status <> NEW.status OR OLD.attempts <> NEW.attempts BEGIN
+	INSERT INTO job_events (job_id, at, old_status, new_status, attempts, error)
+	VALUES (NEW.id, NEW.updated_at, OLD.status, NEW.status, NEW.attempts,
+		` + failureError + `);
+END`,
+	`DROP TRIGGER IF EXISTS trg_jobs_history_delete`,
+	`CREATE TRIGGER trg_jobs_history_delete AFTER DELETE ON jobs BEGIN
+	DELETE FROM job_events WHERE job_id = OLD.id;
+END`,
+}
+
+func historyStatements(name dialect.Name) ([]string, error) {
+	switch name {
+	case dialect.PG:
+		return pgHistory, nil
+	case dialect.SQLite:
+		return sqliteHistory, nil
+	}
+	return nil, ErrHistoryUnsupported
+}
+
+func (opts *InitOptions) createHistory(ctx context.Context, db bun.IDB) error {
+	if !opts.History {
+		return nil
+	}
+	stmts, err := historyStatements(db.Dialect().Name())
+	if err != nil {
+		return err
+	}
+	_, err = db.NewCreateTable().
+		Model((*eventModel)(nil)).
+		IfNotExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = db.NewCreateIndex().
+		Model((*eventModel)(nil)).
+		Index("idx_job_events_job").
+		Column("job_id", "id").
+		IfNotExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range stmts {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// History returns the recorded events of the job identified by id,
+// oldest first.
+//
+// Events are recorded by database triggers installed by InitDB when
+// InitOptions.History is set, so every transition is captured, whether
+// made by a Puller, an Admin or directly in SQL. The time of an event
+// is the updated_at of the job after the transition.
+//
+// History returns ErrHistoryDisabled if ObserverOptions.History is not
+// set.
+func (o *Observer) History(ctx context.Context, id uuid.UUID) ([]*job.Event, error) {
+	if !o.history {
+		return nil, ErrHistoryDisabled
+	}
+	var models []eventModel
+	err := o.db.NewSelect().
+		Model(&models).
+		Where("job_id = ?", id).
+		OrderExpr("id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, wrap("history", id, err)
+	}
+	ret := make([]*job.Event, len(models))
+	for i := range models {
+		ret[i] = models[i].toEvent()
+	}
+	return ret, nil
+}
@@ -0,0 +1,94 @@
+package sql_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+)
+
+func TestHistory(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite", "file::memory:?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	db := bun.NewDB(sqlDB, sqlitedialect.New())
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := gsql.InitDBWithOptions(ctx, db, &gsql.InitOptions{History: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserverWithOptions(db, &gsql.ObserverOptions{History: true})
+
+	msg := message.NewMessage()
+	if err := pusher.Push(ctx, msg, 0); err != nil {
+		t.Fatal(err)
+	}
+	jobs, err := puller.Pull(ctx, 1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobs[0].LastError = "first"
+	if err := puller.Return(ctx, jobs[0], 0); err != nil {
+		t.Fatal(err)
+	}
+	jobs, err = puller.Pull(ctx, 1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobs[0].LastError = "second"
+	if err := puller.Kill(ctx, jobs[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := observer.History(ctx, msg.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []job.Event{
+		{From: job.Unknown, To: job.Pending, Attempts: 0},
+		{From: job.Pending, To: job.Processing, Attempts: 1},
+		{From: job.Processing, To: job.Pending, Attempts: 1, Error: "first"},
+		{From: job.Pending, To: job.Processing, Attempts: 2},
+		{From: job.Processing, To: job.Dead, Attempts: 2, Error: "second"},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d", len(expected), len(events))
+	}
+	for i, event := range events {
+		if event.From != expected[i].From || event.To != expected[i].To ||
+			event.Attempts != expected[i].Attempts || event.Error != expected[i].Error {
+			t.Fatalf("unexpected event %d: %+v", i, event)
+		}
+		if event.Time.IsZero() {
+			t.Fatalf("expected event %d to have a time", i)
+		}
+	}
+
+	if _, err := db.NewDelete().TableExpr("jobs").Where("id = ?", msg.Id).Exec(ctx); err != nil {
+		t.Fatal(err)
+	}
+	events, err = observer.History(ctx, msg.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected events to be deleted with the job, got %d", len(events))
+	}
+
+	if _, err := gsql.NewObserver(db).History(ctx, msg.Id); !errors.Is(err, gsql.ErrHistoryDisabled) {
+		t.Fatalf("expected ErrHistoryDisabled, got %v", err)
+	}
+}
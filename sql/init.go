@@ -4,21 +4,24 @@ import (
 	"context"
 	"errors"
 	"github.com/uptrace/bun"
+	"time"
 )
 
-func createTable(ctx context.Context, db bun.IDB) error {
-	_, err := db.NewCreateTable().
+func createRunIndex(ctx context.Context, db bun.IDB) error {
+	_, err := db.NewCreateIndex().
 		Model((*jobModel)(nil)).
+		Index("idx_jobs_status_next").
+		Column("status", "next_run_at").
 		IfNotExists().
 		Exec(ctx)
 	return err
 }
 
-func createRunIndex(ctx context.Context, db bun.IDB) error {
+func createPriorityIndex(ctx context.Context, db bun.IDB) error {
 	_, err := db.NewCreateIndex().
 		Model((*jobModel)(nil)).
-		Index("idx_jobs_status_next").
-		Column("status", "next_run_at").
+		Index("idx_jobs_status_priority_next").
+		Column("status", "priority", "next_run_at").
 		IfNotExists().
 		Exec(ctx)
 	return err
@@ -44,38 +47,201 @@ func createUpdatedIndex(ctx context.Context, db bun.IDB) error {
 	return err
 }
 
-func initDB(ctx context.Context, db *bun.DB) error {
+func createQueueIndex(ctx context.Context, db bun.IDB) error {
+	_, err := db.NewCreateIndex().
+		Model((*jobModel)(nil)).
+		Index("idx_jobs_queue_status").
+		Column("queue", "status", "created_at").
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+func createOwnerIndex(ctx context.Context, db bun.IDB) error {
+	_, err := db.NewCreateIndex().
+		Model((*jobModel)(nil)).
+		Index("idx_jobs_locked_by").
+		Column("locked_by", "status").
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+func createTenantIndex(ctx context.Context, db bun.IDB) error {
+	_, err := db.NewCreateIndex().
+		Model((*jobModel)(nil)).
+		Index("idx_jobs_tenant_status").
+		Column("tenant_id", "status", "next_run_at").
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+func createOrderingIndex(ctx context.Context, db bun.IDB) error {
+	_, err := db.NewCreateIndex().
+		Model((*jobModel)(nil)).
+		Index("idx_jobs_ordering_key").
+		Column("ordering_key", "status", "created_at").
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+func createGroupIndex(ctx context.Context, db bun.IDB) error {
+	_, err := db.NewCreateIndex().
+		Model((*jobModel)(nil)).
+		Index("idx_jobs_group_status").
+		Column("group_id", "status").
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+func createDeadIndex(ctx context.Context, db bun.IDB) error {
+	_, err := db.NewCreateIndex().
+		Model((*jobModel)(nil)).
+		Index("idx_jobs_queue_updated").
+		Column("queue", "status", "updated_at").
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+func createInstanceTable(ctx context.Context, db bun.IDB) error {
+	_, err := db.NewCreateTable().
+		Model((*instanceModel)(nil)).
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+func createRetentionTable(ctx context.Context, db bun.IDB) error {
+	_, err := db.NewCreateTable().
+		Model((*retentionModel)(nil)).
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+func createAlertTable(ctx context.Context, db bun.IDB) error {
+	_, err := db.NewCreateTable().
+		Model((*alertThresholdModel)(nil)).
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+// InitOptions defines optional schema features created by
+// InitDBWithOptions.
+//
+// Partitioning selects the layout of the jobs table. Partitioning
+// is supported by PostgreSQL only.
+//
+// Partitions is the number of hash partitions created for
+// PartitionByQueue.
+//
+// PartitionInterval is the width of a single range partition for
+// PartitionByCreatedAt. If zero, DefaultPartitionInterval is used.
+//
+// History creates the job_events table and the triggers recording
+// every job state transition into it (see Observer.History). Events
+// of deleted jobs are removed together with them. History is supported
+// by PostgreSQL and SQLite only.
+//
+// Outbox creates the job_outbox table and the triggers recording every
+// job state transition into it until it is published (see Outbox).
+// Outbox is supported by PostgreSQL and SQLite only.
+//
+// Archive creates the jobs_archive table terminal jobs are moved into
+// by Cleaner.Archive (see ArchiveObserver).
+type InitOptions struct {
+	Partitioning      Partitioning
+	Partitions        int
+	PartitionInterval time.Duration
+	History           bool
+	Outbox            bool
+	Archive           bool
+}
+
+type initStep func(ctx context.Context, db bun.IDB) error
+
+func initSteps(opts *InitOptions) []initStep {
+	return []initStep{
+		opts.createTable,
+		migrateTables,
+		createRunIndex,
+		createPriorityIndex,
+		createStatusIndex,
+		createUpdatedIndex,
+		createQueueIndex,
+		createOwnerIndex,
+		createOrderingIndex,
+		createTenantIndex,
+		createGroupIndex,
+		createDeadIndex,
+		createAlertTable,
+		createInstanceTable,
+		createRetentionTable,
+		createPausedQueueTable,
+		createDependencyTable,
+		createWorkflowTable,
+		opts.createPartitions,
+		createNotifyTrigger,
+		opts.createHistory,
+		opts.createOutbox,
+		opts.createArchive,
+	}
+}
+
+func initDB(ctx context.Context, db *bun.DB, opts *InitOptions) error {
+	if err := opts.validate(db); err != nil {
+		return err
+	}
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	if err := createTable(ctx, tx); err != nil {
-		return errors.Join(err, tx.Rollback())
-	}
-	if err := createRunIndex(ctx, tx); err != nil {
-		return errors.Join(err, tx.Rollback())
-	}
-	if err := createStatusIndex(ctx, tx); err != nil {
-		return errors.Join(err, tx.Rollback())
-	}
-	if err := createUpdatedIndex(ctx, tx); err != nil {
-		return errors.Join(err, tx.Rollback())
+	for _, step := range initSteps(opts) {
+		if err := step(ctx, tx); err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
 	}
 	return tx.Commit()
 }
 
 // InitDB initializes the database schema required by the SQL backend.
 //
-// It creates the jobs table and required indexes inside a single
-// transaction. If any step fails, the transaction is rolled back.
+// It creates the jobs table, the alert_thresholds, worker_instances,
+// retention_policies, paused_queues, job_dependencies and workflows
+// tables and required indexes inside a single
+// transaction. On PostgreSQL, it also installs the trigger announcing
+// inserted jobs on NotifyChannel. If any step fails, the
+// transaction is rolled back.
 //
 // InitDB is idempotent and may be safely called multiple times.
-// It does not drop or modify existing tables beyond creating
-// missing objects.
+// Columns missing from tables created by earlier versions are added
+// with ALTER TABLE ... ADD COLUMN; NOT NULL columns without a default
+// are filled from existing columns (scheduled_at from next_run_at).
+// Existing columns are never dropped or changed.
+//
+// Unlike tables and indexes, the triggers installed by InitDB (the
+// NotifyChannel trigger and the triggers of InitOptions.History and
+// InitOptions.Outbox) and their functions are dropped and recreated on
+// every call, so that they match the current version. Changes made to
+// them by hand are therefore lost.
 //
 // The caller is responsible for providing a properly configured *bun.DB.
 func InitDB(ctx context.Context, db *bun.DB) error {
-	return initDB(ctx, db)
+	return initDB(ctx, db, &InitOptions{})
+}
+
+// InitDBWithOptions behaves like InitDB, applying the provided options.
+//
+// Options affecting the table layout (such as partitioning) take effect
+// only when the jobs table is created; they are not applied to an
+// existing table.
+func InitDBWithOptions(ctx context.Context, db *bun.DB, opts *InitOptions) error {
+	return initDB(ctx, db, opts)
 }
 
 // MustInitDB behaves like InitDB but panics if initialization fails.
@@ -83,7 +249,7 @@ func InitDB(ctx context.Context, db *bun.DB) error {
 // This helper is intended for application bootstrap code where
 // failure to initialize schema is considered unrecoverable.
 func MustInitDB(ctx context.Context, db *bun.DB) {
-	if err := initDB(ctx, db); err != nil {
+	if err := initDB(ctx, db, &InitOptions{}); err != nil {
 		panic(err)
 	}
 }
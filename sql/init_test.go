@@ -0,0 +1,105 @@
+package sql_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+)
+
+func TestInitDBIdempotent(t *testing.T) {
+	db := newTestDB(t)
+	if err := gsql.InitDB(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInitDBPriorityIndex(t *testing.T) {
+	db := newTestDB(t)
+	var count int
+	err := db.NewSelect().
+		TableExpr("sqlite_master").
+		ColumnExpr("COUNT(*)").
+		Where("type = 'index' AND name = ?", "idx_jobs_status_priority_next").
+		Scan(context.Background(), &count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatal("expected the (status, priority, next_run_at) index")
+	}
+}
+
+func TestInitDBPartitionUnsupported(t *testing.T) {
+	db := newTestDB(t)
+	opts := &gsql.InitOptions{Partitioning: gsql.PartitionByCreatedAt}
+	err := gsql.InitDBWithOptions(context.Background(), db, opts)
+	if !errors.Is(err, gsql.ErrPartitionUnsupported) {
+		t.Fatalf("expected ErrPartitionUnsupported, got %v", err)
+	}
+}
+
+func TestInitDBMigratesColumns(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite", "file::memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	db := bun.NewDB(sqlDB, sqlitedialect.New())
+	ctx := context.Background()
+
+	// the jobs table of the first release
+	_, err = db.ExecContext(ctx, `CREATE TABLE jobs (
+		id uuid PRIMARY KEY,
+		created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+		updated_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+		status INTEGER NOT NULL DEFAULT 0,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		locked_until TIMESTAMP DEFAULT NULL,
+		next_run_at TIMESTAMP NOT NULL,
+		metadata jsonb,
+		payload blob)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := uuid.New()
+	next := time.Now().Add(-time.Minute).UTC().Truncate(time.Millisecond)
+	_, err = db.ExecContext(ctx, "INSERT INTO jobs (id, status, next_run_at) VALUES (?, ?, ?)",
+		old, job.Pending, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gsql.InitDB(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	if err := gsql.InitDB(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := gsql.NewObserver(db).Get(ctx, old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j == nil || !j.ScheduledAt.Equal(next) || j.Version != 0 || j.Queue != "" {
+		t.Fatalf("expected the existing job with filled columns, got %+v", j)
+	}
+	if err := gsql.NewPusher(db).Push(ctx, message.NewMessage(), 0); err != nil {
+		t.Fatal(err)
+	}
+	jobs, err := gsql.NewPuller(db).Pull(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("expected both jobs pulled, got %d", len(jobs))
+	}
+}
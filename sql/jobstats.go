@@ -0,0 +1,101 @@
+package sql
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/uptrace/bun"
+	"strconv"
+	"time"
+)
+
+type statusSummary struct {
+	Status   job.Status   `bun:"status"`
+	Count    int64        `bun:"count"`
+	Attempts int64        `bun:"attempts"`
+	Oldest   bun.NullTime `bun:"oldest"`
+}
+
+// Stats returns the current statistics of all jobs, computed with two
+// aggregate queries.
+//
+// Counts, attempts and the oldest Pending job are read with a single
+// query grouped by status. Throughput is counted over the Done and Dead
+// jobs updated within the longest of gqs.StatsWindows, which is served
+// by the (status, updated_at) index.
+func (o *Observer) Stats(ctx context.Context) (*gqs.JobStats, error) {
+	now := time.Now()
+	var summaries []statusSummary
+	err := o.db.NewSelect().
+		Model((*jobModel)(nil)).
+		Column("status").
+		ColumnExpr("COUNT(*) AS count").
+		ColumnExpr("SUM(attempts) AS attempts").
+		ColumnExpr("MIN(created_at) AS oldest").
+		Group("status").
+		Scan(ctx, &summaries)
+	if err != nil {
+		return nil, wrap("stats", uuid.Nil, err)
+	}
+	ret := &gqs.JobStats{
+		Counts: make(map[job.Status]int64, len(summaries)),
+	}
+	var terminal, attempts int64
+	for _, summary := range summaries {
+		ret.Counts[summary.Status] = summary.Count
+		switch summary.Status {
+		case job.Pending:
+			if !summary.Oldest.IsZero() {
+				ret.OldestPending = max(now.Sub(summary.Oldest.Time), 0)
+			}
+		case job.Done, job.Dead, job.Cancelled:
+			terminal += summary.Count
+			attempts += summary.Attempts
+		}
+	}
+	if terminal > 0 {
+		ret.AvgAttempts = float64(attempts) / float64(terminal)
+	}
+	ret.Throughput, err = o.throughput(ctx, now)
+	if err != nil {
+		return nil, wrap("stats", uuid.Nil, err)
+	}
+	return ret, nil
+}
+
+func (o *Observer) throughput(ctx context.Context, now time.Time) ([]gqs.Throughput, error) {
+	windows := gqs.StatsWindows
+	if len(windows) == 0 {
+		return nil, nil
+	}
+	query := o.db.NewSelect().Model((*jobModel)(nil))
+	longest := windows[0]
+	for i, window := range windows {
+		since := now.Add(-window)
+		query.ColumnExpr("COALESCE(SUM(CASE WHEN status = ? AND updated_at >= ? THEN 1 ELSE 0 END), 0) AS ?",
+			job.Done, since, bun.Ident(windowColumn("done", i))).
+			ColumnExpr("COALESCE(SUM(CASE WHEN status = ? AND updated_at >= ? THEN 1 ELSE 0 END), 0) AS ?",
+				job.Dead, since, bun.Ident(windowColumn("dead", i)))
+		longest = max(longest, window)
+	}
+	counts := make([]any, 2*len(windows))
+	ret := make([]gqs.Throughput, len(windows))
+	for i, window := range windows {
+		ret[i].Window = window
+		counts[2*i] = &ret[i].Done
+		counts[2*i+1] = &ret[i].Dead
+	}
+	err := query.
+		Where("status IN (?)", bun.In([]job.Status{job.Done, job.Dead})).
+		Where("updated_at >= ?", now.Add(-longest)).
+		Scan(ctx, counts...)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func windowColumn(prefix string, i int) string {
+	return prefix + "_" + strconv.Itoa(i)
+}
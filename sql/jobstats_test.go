@@ -0,0 +1,69 @@
+package sql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestObserverStats(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	if !gqs.Supports(observer, gqs.CapStats) {
+		t.Fatal("expected observer to support stats")
+	}
+
+	stats, err := observer.Stats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Counts) != 0 || stats.OldestPending != 0 || stats.AvgAttempts != 0 {
+		t.Fatalf("expected empty stats, got %+v", stats)
+	}
+
+	for i := 0; i < 4; i++ {
+		if err := pusher.Push(ctx, message.NewMessage(), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	jobs, _ := puller.Pull(ctx, 3, time.Minute)
+	_ = puller.Complete(ctx, jobs[0])
+	_ = puller.Complete(ctx, jobs[1])
+	_ = puller.Kill(ctx, jobs[2])
+	time.Sleep(10 * time.Millisecond)
+
+	stats, err = observer.Stats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Counts[job.Pending] != 1 || stats.Counts[job.Done] != 2 || stats.Counts[job.Dead] != 1 {
+		t.Fatalf("unexpected counts %v", stats.Counts)
+	}
+	if stats.OldestPending < 10*time.Millisecond {
+		t.Fatalf("expected the pending job age, got %v", stats.OldestPending)
+	}
+	if stats.AvgAttempts != 1 {
+		t.Fatalf("expected 1 attempt on average, got %v", stats.AvgAttempts)
+	}
+	if len(stats.Throughput) != len(gqs.StatsWindows) {
+		t.Fatalf("expected a throughput per window, got %+v", stats.Throughput)
+	}
+	for _, tp := range stats.Throughput {
+		if tp.Done != 2 || tp.Dead != 1 {
+			t.Fatalf("unexpected throughput %+v", tp)
+		}
+	}
+	if stats.Throughput[0].Rate() != 2/time.Minute.Seconds() {
+		t.Fatalf("unexpected rate %v", stats.Throughput[0].Rate())
+	}
+}
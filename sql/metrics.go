@@ -0,0 +1,69 @@
+package sql
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type queueState struct {
+	Queue      string       `bun:"queue"`
+	Ready      int64        `bun:"ready"`
+	Scheduled  int64        `bun:"scheduled"`
+	Processing int64        `bun:"processing"`
+	Oldest     bun.NullTime `bun:"oldest"`
+}
+
+// QueueMetrics returns backlog metrics of every queue with at least one
+// Pending, Scheduled or Processing job, measured with a single grouped
+// query. Ready and Scheduled are told apart by next_run_at, so due
+// Scheduled jobs not promoted yet are counted as ready.
+//
+// Expired jobs (see message.Message.TTL) are not counted. A Processing
+// job with an expired lease is counted as ready since its lease expired.
+func (o *Observer) QueueMetrics(ctx context.Context) ([]gqs.QueueMetrics, error) {
+	now := time.Now()
+	var states []queueState
+	err := o.db.NewSelect().
+		Model((*jobModel)(nil)).
+		Column("queue").
+		ColumnExpr("SUM(CASE WHEN status IN (?) AND next_run_at <= ? THEN 1 "+
+			"WHEN status = ? AND locked_until < ? THEN 1 ELSE 0 END) AS ready",
+			bun.In(waiting), now, job.Processing, now).
+		ColumnExpr("SUM(CASE WHEN status IN (?) AND next_run_at > ? THEN 1 ELSE 0 END) AS scheduled",
+			bun.In(waiting), now).
+		ColumnExpr("SUM(CASE WHEN status = ? AND locked_until >= ? THEN 1 ELSE 0 END) AS processing",
+			job.Processing, now).
+		ColumnExpr("MIN(CASE WHEN status IN (?) AND next_run_at <= ? THEN next_run_at "+
+			"WHEN status = ? AND locked_until < ? THEN locked_until END) AS oldest",
+			bun.In(waiting), now, job.Processing, now).
+		Where("status IN (?)", bun.In([]job.Status{job.Pending, job.Scheduled, job.Processing})).
+		WhereGroup("AND", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.
+				Where("expires_at IS NULL").
+				WhereOr("expires_at > ?", now)
+		}).
+		Group("queue").
+		Order("queue ASC").
+		Scan(ctx, &states)
+	if err != nil {
+		return nil, wrap("queue metrics", uuid.Nil, err)
+	}
+	ret := make([]gqs.QueueMetrics, len(states))
+	for i, state := range states {
+		ret[i] = gqs.QueueMetrics{
+			Queue:      state.Queue,
+			Ready:      state.Ready,
+			Scheduled:  state.Scheduled,
+			Processing: state.Processing,
+			Backlog:    state.Ready + state.Processing,
+		}
+		if !state.Oldest.IsZero() {
+			ret[i].OldestReady = max(now.Sub(state.Oldest.Time), 0)
+		}
+	}
+	return ret, nil
+}
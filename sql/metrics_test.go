@@ -0,0 +1,68 @@
+package sql_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestQueueMetrics(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPullerWithOptions(db, &gsql.PullerOptions{Queues: []string{"mail"}})
+	observer := gsql.NewObserver(db)
+
+	for i := 0; i < 3; i++ {
+		msg := message.NewMessage()
+		msg.Queue = "mail"
+		if err := pusher.Push(ctx, msg, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	later := message.NewMessage()
+	later.Queue = "mail"
+	if err := pusher.Push(ctx, later, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	other := message.NewMessage()
+	other.Queue = "sms"
+	if err := pusher.Push(ctx, other, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := puller.Pull(ctx, 1, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	metrics, err := observer.QueueMetrics(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics) != 2 || metrics[0].Queue != "mail" || metrics[1].Queue != "sms" {
+		t.Fatalf("unexpected metrics %+v", metrics)
+	}
+	mail := metrics[0]
+	if mail.Ready != 2 || mail.Scheduled != 1 || mail.Processing != 1 || mail.Backlog != 3 {
+		t.Fatalf("unexpected mail metrics %+v", mail)
+	}
+	if mail.OldestReady <= 0 {
+		t.Fatal("expected age of the oldest ready job")
+	}
+
+	rec := httptest.NewRecorder()
+	gqs.NewScalerHandler(observer).ServeHTTP(rec, httptest.NewRequest("GET", "/?queue=mail&queue=push", nil))
+	var report gqs.ScalerReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Backlog != 3 || len(report.Queues) != 2 || report.Queues[1].Backlog != 0 {
+		t.Fatalf("unexpected scaler report %+v", report)
+	}
+}
@@ -0,0 +1,154 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/schema"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// migratedModels lists the models whose tables InitDB extends with
+// missing columns.
+var migratedModels = []any{
+	(*jobModel)(nil),
+	(*archiveModel)(nil),
+	(*eventModel)(nil),
+	(*outboxModel)(nil),
+	(*instanceModel)(nil),
+	(*retentionModel)(nil),
+	(*alertThresholdModel)(nil),
+	(*pausedQueueModel)(nil),
+	(*dependencyModel)(nil),
+	(*workflowModel)(nil),
+}
+
+// migrationBackfill maps NOT NULL columns without a default to the
+// column their existing rows are filled from.
+var migrationBackfill = map[string]string{
+	"scheduled_at": "next_run_at",
+	"archived_at":  "updated_at",
+}
+
+// migrationEpoch is the constant default of added NOT NULL timestamp
+// columns, replaced by the backfill; SQLite rejects non-constant
+// defaults of added columns.
+const migrationEpoch = "'1970-01-01 00:00:00'"
+
+func columnsQuery(name dialect.Name) string {
+	switch name {
+	case dialect.SQLite:
+		return "SELECT name FROM pragma_table_info(?)"
+	case dialect.PG:
+		return "SELECT column_name FROM information_schema.columns " +
+			"WHERE table_schema = current_schema() AND table_name = ?"
+	case dialect.MySQL:
+		return "SELECT column_name FROM information_schema.columns " +
+			"WHERE table_schema = DATABASE() AND table_name = ?"
+	case dialect.MSSQL:
+		return "SELECT column_name FROM information_schema.columns " +
+			"WHERE table_schema = SCHEMA_NAME() AND table_name = ?"
+	default:
+		return "SELECT column_name FROM information_schema.columns WHERE table_name = ?"
+	}
+}
+
+// existingColumns returns the names of the columns of table, or an
+// empty set if the table does not exist.
+func existingColumns(ctx context.Context, db bun.IDB, table string) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, columnsQuery(db.Dialect().Name()), table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ret := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		ret[strings.ToLower(name)] = true
+	}
+	return ret, rows.Err()
+}
+
+// columnDefinition returns the definition of field as created by
+// CREATE TABLE, with a constant default for NOT NULL columns that
+// have none.
+func columnDefinition(db bun.IDB, field *schema.Field) (string, error) {
+	var b strings.Builder
+	b.WriteString(string(field.SQLName))
+	b.WriteByte(' ')
+	b.WriteString(field.CreateTableSQLType)
+	if n := db.Dialect().DefaultVarcharLen(); n > 0 && strings.EqualFold(field.CreateTableSQLType, "varchar") {
+		b.WriteString("(" + strconv.Itoa(n) + ")")
+	}
+	if field.NotNull {
+		b.WriteString(" NOT NULL")
+	}
+	switch {
+	case field.SQLDefault != "":
+		b.WriteString(" DEFAULT " + field.SQLDefault)
+	case field.NotNull:
+		if _, ok := migrationBackfill[field.Name]; !ok {
+			return "", fmt.Errorf("cannot add column %s without default", field.Name)
+		}
+		b.WriteString(" DEFAULT " + migrationEpoch)
+	}
+	return b.String(), nil
+}
+
+// migrateColumns adds the columns of model missing from its existing
+// table, filling NOT NULL columns without a default from the columns
+// listed in migrationBackfill. Tables that do not exist are skipped.
+func migrateColumns(ctx context.Context, db bun.IDB, model any) error {
+	table := db.Dialect().Tables().Get(reflect.TypeOf(model).Elem())
+	existing, err := existingColumns(ctx, db, table.Name)
+	if err != nil || len(existing) == 0 {
+		return err
+	}
+	for _, field := range table.Fields {
+		if existing[field.Name] {
+			continue
+		}
+		def, err := columnDefinition(db, field)
+		if err != nil {
+			return err
+		}
+		_, err = db.NewAddColumn().
+			Model(model).
+			ColumnExpr(def).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		source, ok := migrationBackfill[field.Name]
+		if !ok || field.SQLDefault != "" {
+			continue
+		}
+		_, err = db.NewUpdate().
+			Model(model).
+			Set("? = ?", field.SQLName, bun.Ident(source)).
+			Where("1 = 1").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateTables adds missing columns to the existing tables of every
+// model in migratedModels, so that tables created by earlier versions
+// match the current models.
+func migrateTables(ctx context.Context, db bun.IDB) error {
+	for _, model := range migratedModels {
+		if err := migrateColumns(ctx, db, model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
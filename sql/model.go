@@ -16,41 +16,127 @@ type jobModel struct {
 	CreatedAt time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
 	UpdatedAt time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
 
-	Status      job.Status `bun:"status,notnull,default:0"`
-	Attempts    uint32     `bun:"attempts,notnull,default:0"`
-	LockedUntil *time.Time `bun:"locked_until,nullzero,default:null"`
-	NextRunAt   time.Time  `bun:"next_run_at,notnull"`
+	Status          job.Status `bun:"status,notnull,default:0"`
+	Attempts        uint32     `bun:"attempts,notnull,default:0"`
+	LockedUntil     *time.Time `bun:"locked_until,nullzero,default:null"`
+	LockedBy        string     `bun:"locked_by,notnull,default:''"`
+	LockLosses      uint32     `bun:"lock_losses,notnull,default:0"`
+	CancelRequested bool       `bun:"cancel_requested,notnull,default:false"`
+	Version         uint64     `bun:"version,notnull,default:0"`
+	LastError       string     `bun:"last_error,notnull,default:''"`
+	NextRunAt       time.Time  `bun:"next_run_at,notnull"`
+	ScheduledAt     time.Time  `bun:"scheduled_at,notnull"`
+	ExpiresAt       *time.Time `bun:"expires_at,nullzero,default:null"`
+	Priority        int        `bun:"priority,notnull,default:0"`
+	Region          string     `bun:"region,notnull,default:''"`
+	OrderingKey     string     `bun:"ordering_key,notnull,default:''"`
 
-	Metadata map[string]any `bun:"metadata,type:jsonb"`
-	Payload  []byte         `bun:"payload,type:blob"`
+	MaxRetries  uint32        `bun:"max_retries,notnull,default:0"`
+	LockTimeout time.Duration `bun:"lock_timeout,notnull,default:0"`
+	Timeout     time.Duration `bun:"timeout,notnull,default:0"`
+	TTL         time.Duration `bun:"ttl,notnull,default:0"`
+	DependsOn   []uuid.UUID   `bun:"depends_on,type:jsonb"`
+	GroupId     uuid.UUID     `bun:"group_id,type:uuid,nullzero"`
+
+	RetainDone      time.Duration `bun:"retain_done,notnull,default:0"`
+	RetainDead      time.Duration `bun:"retain_dead,notnull,default:0"`
+	RetainCancelled time.Duration `bun:"retain_cancelled,notnull,default:0"`
+
+	// onEnd lists the dependencies satisfied by any terminal status
+	// rather than by Done only, such as the members of a group
+	onEnd []uuid.UUID `bun:"-"`
+
+	Queue         string         `bun:"queue,notnull,default:''"`
+	TenantId      string         `bun:"tenant_id,notnull,default:''"`
+	Type          string         `bun:"type,notnull,default:''"`
+	Metadata      map[string]any `bun:"metadata,type:jsonb"`
+	Payload       []byte         `bun:"payload,type:blob"`
+	SchemaVersion uint32         `bun:"schema_version,notnull,default:0"`
+
+	Result      []byte           `bun:"result,type:blob"`
+	Logs        []job.LogLine    `bun:"logs,type:jsonb"`
+	Diagnostics *job.Diagnostics `bun:"diagnostics,type:jsonb"`
 }
 
 func (jm *jobModel) toJob() *job.Job {
 	return &job.Job{
 		Message: message.Message{
-			Id:       jm.Id,
-			Metadata: jm.Metadata,
-			Payload:  jm.Payload,
+			Id:            jm.Id,
+			Queue:         jm.Queue,
+			TenantId:      jm.TenantId,
+			Type:          jm.Type,
+			Metadata:      jm.Metadata,
+			Payload:       jm.Payload,
+			SchemaVersion: jm.SchemaVersion,
+			Priority:      jm.Priority,
+			Region:        jm.Region,
+			OrderingKey:   jm.OrderingKey,
+			TTL:           jm.TTL,
+			DependsOn:     jm.DependsOn,
+			GroupId:       jm.GroupId,
+			MaxRetries:    jm.MaxRetries,
+			LockTimeout:   jm.LockTimeout,
+			Timeout:       jm.Timeout,
 		},
-		CreatedAt:   jm.CreatedAt,
-		UpdatedAt:   jm.UpdatedAt,
-		Status:      jm.Status,
-		Attempts:    jm.Attempts,
-		LockedUntil: jm.LockedUntil,
-		NextRunAt:   jm.NextRunAt,
+		CreatedAt:       jm.CreatedAt,
+		UpdatedAt:       jm.UpdatedAt,
+		Status:          jm.Status,
+		Attempts:        jm.Attempts,
+		LockedUntil:     jm.LockedUntil,
+		NextRunAt:       jm.NextRunAt,
+		ScheduledAt:     jm.ScheduledAt,
+		ExpiresAt:       jm.ExpiresAt,
+		LockedBy:        jm.LockedBy,
+		LockLosses:      jm.LockLosses,
+		CancelRequested: jm.CancelRequested,
+		Version:         jm.Version,
+		LastError:       jm.LastError,
+		RetainDone:      jm.RetainDone,
+		RetainDead:      jm.RetainDead,
+		RetainCancelled: jm.RetainCancelled,
+		Result:          jm.Result,
+		Logs:            jm.Logs,
+		Diagnostics:     jm.Diagnostics,
 	}
 }
 
-func fromMessage(msg *message.Message, delay time.Duration) *jobModel {
-	now := time.Now()
+func fromMessage(msg *message.Message, now time.Time, delay time.Duration) *jobModel {
+	return newJobModel(msg, now, now.Add(delay))
+}
+
+func fromMessageAt(msg *message.Message, now, at time.Time) *jobModel {
+	return newJobModel(msg, now, at)
+}
+
+func newJobModel(msg *message.Message, now, at time.Time) *jobModel {
+	var expiresAt *time.Time
+	if msg.TTL > 0 {
+		expiry := at.Add(msg.TTL)
+		expiresAt = &expiry
+	}
 	return &jobModel{
-		Id:          msg.Id,
-		Metadata:    msg.Metadata,
-		Payload:     msg.Payload,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-		Status:      job.Pending,
-		LockedUntil: nil,
-		NextRunAt:   now.Add(delay),
+		Id:            msg.Id,
+		Queue:         msg.Queue,
+		TenantId:      msg.TenantId,
+		Type:          msg.Type,
+		Metadata:      msg.Metadata,
+		Payload:       msg.Payload,
+		SchemaVersion: msg.SchemaVersion,
+		Priority:      msg.Priority,
+		Region:        msg.Region,
+		OrderingKey:   msg.OrderingKey,
+		TTL:           msg.TTL,
+		DependsOn:     msg.DependsOn,
+		GroupId:       msg.GroupId,
+		MaxRetries:    msg.MaxRetries,
+		LockTimeout:   msg.LockTimeout,
+		Timeout:       msg.Timeout,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Status:        waitStatus(now, at),
+		LockedUntil:   nil,
+		NextRunAt:     at,
+		ScheduledAt:   at,
+		ExpiresAt:     expiresAt,
 	}
 }
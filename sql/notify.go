@@ -0,0 +1,131 @@
+package sql
+
+import (
+	"context"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"time"
+)
+
+// NotifyChannel is the PostgreSQL notification channel on which
+// inserts into the jobs table are announced.
+//
+// InitDB installs a trigger issuing NOTIFY on this channel after every
+// statement inserting jobs, so every Pusher method announces new jobs,
+// and jobs pushed with PushTx are announced when the transaction
+// commits.
+const NotifyChannel = "gqs_jobs"
+
+// DefaultListenRetry is the delay before a Notifier re-subscribes
+// after its ListenFunc fails, used when NotifierOptions.Retry is zero.
+const DefaultListenRetry = time.Second
+
+// ListenFunc subscribes to a PostgreSQL notification channel using
+// a driver-specific API and calls notify for every notification
+// received on it, until ctx is done or the connection fails.
+//
+// LISTEN requires a dedicated connection, which database/sql does not
+// expose, so the listening itself is left to the driver. For example,
+// with pgdriver:
+//
+//	func(ctx context.Context, channel string, notify func()) error {
+//		ln := pgdriver.NewListener(db)
+//		defer ln.Close()
+//		if err := ln.Listen(ctx, channel); err != nil {
+//			return err
+//		}
+//		for {
+//			if _, _, err := ln.Receive(ctx); err != nil {
+//				return err
+//			}
+//			notify()
+//		}
+//	}
+type ListenFunc func(ctx context.Context, channel string, notify func()) error
+
+// NotifierOptions defines optional behavior of a Notifier.
+//
+// Retry is the delay before re-subscribing after the ListenFunc fails.
+// If zero, DefaultListenRetry is used.
+type NotifierOptions struct {
+	Retry time.Duration
+}
+
+// Notifier implements gqs.Notifier on top of PostgreSQL LISTEN/NOTIFY.
+//
+// Notifier listens on NotifyChannel and re-subscribes whenever the
+// connection fails, so a Worker using it keeps waking up on new jobs
+// after database restarts. Notifications missed while re-subscribing
+// are covered by the periodic pull of the Worker.
+type Notifier struct {
+	listen ListenFunc
+	retry  time.Duration
+}
+
+// NewNotifier creates a new Notifier using listen to receive
+// notifications.
+func NewNotifier(listen ListenFunc) *Notifier {
+	return NewNotifierWithOptions(listen, &NotifierOptions{})
+}
+
+// NewNotifierWithOptions creates a new Notifier using listen to receive
+// notifications and the provided options.
+func NewNotifierWithOptions(listen ListenFunc, opts *NotifierOptions) *Notifier {
+	retry := opts.Retry
+	if retry <= 0 {
+		retry = DefaultListenRetry
+	}
+	return &Notifier{
+		listen: listen,
+		retry:  retry,
+	}
+}
+
+// Listen implements gqs.Notifier.
+//
+// Listen never fails: subscription errors are retried in background
+// until ctx is done.
+func (n *Notifier) Listen(ctx context.Context) (<-chan struct{}, error) {
+	ret := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case ret <- struct{}{}:
+		default:
+		}
+	}
+	go func() {
+		defer close(ret)
+		for {
+			_ = n.listen(ctx, NotifyChannel, notify)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(n.retry):
+			}
+		}
+	}()
+	return ret, nil
+}
+
+func createNotifyTrigger(ctx context.Context, db bun.IDB) error {
+	if db.Dialect().Name() != dialect.PG {
+		return nil
+	}
+	stmts := []string{
+		`CREATE OR REPLACE FUNCTION gqs_notify_jobs() RETURNS trigger AS $$
+BEGIN
+	PERFORM pg_notify('` + NotifyChannel + `', '');
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS trg_jobs_notify ON jobs`,
+		`CREATE TRIGGER trg_jobs_notify AFTER INSERT ON jobs
+	FOR EACH STATEMENT EXECUTE FUNCTION gqs_notify_jobs()`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
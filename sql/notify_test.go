@@ -0,0 +1,47 @@
+package sql_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestNotifierResubscribes(t *testing.T) {
+	var calls atomic.Int32
+	listen := func(ctx context.Context, channel string, notify func()) error {
+		if channel != gsql.NotifyChannel {
+			t.Errorf("unexpected channel %s", channel)
+		}
+		if calls.Add(1) == 1 {
+			return errors.New("connection refused")
+		}
+		notify()
+		notify()
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	notifier := gsql.NewNotifierWithOptions(listen, &gsql.NotifierOptions{Retry: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := notifier.Listen(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatal("expected notification after resubscribing")
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected 2 subscriptions, got %d", calls.Load())
+	}
+
+	cancel()
+	for range ch {
+	}
+}
@@ -4,12 +4,17 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
 	"github.com/romanqed/gqs/job"
 	"github.com/uptrace/bun"
 )
 
-// Observer implements gqs.Observer using a SQL backend.
+// Observer implements gqs.Observer, gqs.QueryObserver,
+// gqs.InstanceObserver, gqs.Exporter, gqs.HistoryObserver,
+// gqs.Reporter, gqs.MetricsObserver, gqs.OverviewObserver,
+// gqs.StatsObserver and gqs.GroupObserver using a SQL backend.
 //
 // Observer provides read-only access to job state stored in the database.
 // It does not participate in visibility timeout handling or state
@@ -18,7 +23,30 @@ import (
 // Returned Job values represent authoritative snapshots of storage state
 // at the time of the query.
 type Observer struct {
-	db *bun.DB
+	db       *bun.DB
+	primary  *bun.DB
+	history  bool
+	notFound bool
+}
+
+// ObserverOptions defines optional behavior of an Observer.
+//
+// Primary enables read-your-writes for Get on deployments where the
+// Observer reads from a replica: if a job is missing on the replica,
+// Get repeats the lookup on Primary. This guarantees that a job pushed
+// through the primary is visible to Get immediately, regardless of
+// replication lag.
+//
+// History enables Observer.History. The schema must be initialized
+// with InitOptions.History.
+//
+// NotFoundError makes Get return an error wrapping gqs.ErrNotFound for
+// missing jobs instead of (nil, nil). It is disabled by default for
+// compatibility with existing callers.
+type ObserverOptions struct {
+	Primary       *bun.DB
+	History       bool
+	NotFoundError bool
 }
 
 // NewObserver creates a new SQL-backed Observer.
@@ -26,24 +54,48 @@ type Observer struct {
 // The provided *bun.DB must be properly configured and connected.
 // Schema initialization must be completed before using Observer.
 func NewObserver(db *bun.DB) *Observer {
+	return NewObserverWithOptions(db, &ObserverOptions{})
+}
+
+// NewObserverWithOptions creates a new SQL-backed Observer using
+// the provided options.
+//
+// The provided *bun.DB is used for all reads; it may be a replica.
+func NewObserverWithOptions(db *bun.DB, opts *ObserverOptions) *Observer {
 	return &Observer{
-		db: db,
+		db:       db,
+		primary:  opts.Primary,
+		history:  opts.History,
+		notFound: opts.NotFoundError,
 	}
 }
 
 // Get retrieves a job by its identifier.
 //
-// If no job with the given id exists, Get returns (nil, nil).
+// If no job with the given id exists, Get returns (nil, nil), or an
+// error wrapping gqs.ErrNotFound if ObserverOptions.NotFoundError is set.
 //
 // The returned Job is a snapshot of the current database state.
 // Modifying the returned value does not affect storage.
 //
 // Get performs a simple SELECT query and does not apply
 // any locking or transactional semantics beyond what the
-// underlying database provides.
+// underlying database provides. If a primary is configured,
+// a miss is retried on the primary.
 func (o *Observer) Get(ctx context.Context, id uuid.UUID) (*job.Job, error) {
+	ret, err := get(ctx, o.db, id)
+	if ret == nil && err == nil && o.primary != nil {
+		ret, err = get(ctx, o.primary, id)
+	}
+	if ret == nil && err == nil && o.notFound {
+		return nil, fmt.Errorf("%w: %s", gqs.ErrNotFound, id)
+	}
+	return ret, wrap("get", id, err)
+}
+
+func get(ctx context.Context, db *bun.DB, id uuid.UUID) (*job.Job, error) {
 	var ret jobModel
-	err := o.db.NewSelect().
+	err := db.NewSelect().
 		Model(&ret).
 		Where("id = ?", id).
 		Scan(ctx)
@@ -78,7 +130,121 @@ func (o *Observer) List(ctx context.Context, status job.Status, limit int) ([]*j
 		query.Limit(limit)
 	}
 	if err := query.Scan(ctx, &ret); err != nil {
-		return nil, err
+		return nil, wrap("list", uuid.Nil, err)
 	}
 	return ret, nil
 }
+
+func applyPage(query *bun.SelectQuery, opts *gqs.ListOptions) error {
+	column, desc := orderColumn(opts.Order)
+	direction, cmp := "ASC", ">"
+	if desc {
+		direction, cmp = "DESC", "<"
+	}
+	query.OrderExpr("? "+direction, bun.Ident(column)).
+		OrderExpr("id " + direction)
+	if opts.Limit > 0 {
+		query.Limit(opts.Limit)
+	}
+	if opts.Cursor == "" {
+		if opts.Offset > 0 {
+			query.Offset(opts.Offset)
+		}
+		return nil
+	}
+	cur, err := decodeCursor(opts.Cursor)
+	if err != nil {
+		return err
+	}
+	query.WhereGroup("AND", func(sq *bun.SelectQuery) *bun.SelectQuery {
+		return sq.
+			Where("? "+cmp+" ?", bun.Ident(column), cur.at).
+			WhereOr("? = ? AND id "+cmp+" ?", bun.Ident(column), cur.at, cur.id)
+	})
+	return nil
+}
+
+// Query returns a page of jobs matching opts.
+//
+// Pagination with Cursor uses keyset conditions on the ordering
+// timestamp and id, so its cost does not grow with the page number,
+// unlike Offset.
+//
+// Metadata filters are translated into dialect-specific JSON
+// extraction expressions (PostgreSQL, MySQL and SQLite are supported).
+func (o *Observer) Query(ctx context.Context, opts *gqs.ListOptions) (*gqs.Page, error) {
+	query := o.db.NewSelect().
+		Model((*jobModel)(nil)).
+		ApplyQueryBuilder(applyFilter(o.db.Dialect().Name(), opts))
+	if err := applyPage(query, opts); err != nil {
+		return nil, err
+	}
+	var jobs []*job.Job
+	if err := query.Scan(ctx, &jobs); err != nil {
+		return nil, wrap("query", uuid.Nil, err)
+	}
+	return newPage(jobs, opts), nil
+}
+
+func newPage(jobs []*job.Job, opts *gqs.ListOptions) *gqs.Page {
+	ret := &gqs.Page{Jobs: jobs}
+	if opts.Limit > 0 && len(jobs) == opts.Limit {
+		last := jobs[len(jobs)-1]
+		column, _ := orderColumn(opts.Order)
+		at := last.CreatedAt
+		if column == "updated_at" {
+			at = last.UpdatedAt
+		}
+		ret.Next = encodeCursor(at, last.Id)
+	}
+	return ret
+}
+
+// Count returns the number of jobs matching the filters of opts.
+func (o *Observer) Count(ctx context.Context, opts *gqs.ListOptions) (int64, error) {
+	count, err := o.db.NewSelect().
+		Model((*jobModel)(nil)).
+		ApplyQueryBuilder(applyFilter(o.db.Dialect().Name(), opts)).
+		Count(ctx)
+	return int64(count), wrap("count", uuid.Nil, err)
+}
+
+// Capabilities implements gqs.Capable.
+func (o *Observer) Capabilities() gqs.Capability {
+	ret := gqs.CapQuery | gqs.CapInstances | gqs.CapExport | gqs.CapReport |
+		gqs.CapMetrics | gqs.CapOverview | gqs.CapStats | gqs.CapGroups |
+		gqs.CapDeadLetters
+	if o.history {
+		ret |= gqs.CapHistory
+	}
+	return ret
+}
+
+// Report calls fn for every job matching opts, reading rows one by one
+// from a single query instead of loading pages into memory.
+//
+// The query holds a database connection until iteration completes, so
+// fn should not block for long.
+func (o *Observer) Report(ctx context.Context, opts *gqs.ListOptions, fn func(jb *job.Job) error) error {
+	query := o.db.NewSelect().
+		Model((*jobModel)(nil)).
+		ApplyQueryBuilder(applyFilter(o.db.Dialect().Name(), opts))
+	if err := applyPage(query, opts); err != nil {
+		return err
+	}
+	rows, err := query.Rows(ctx)
+	if err != nil {
+		return wrap("report", uuid.Nil, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var model jobModel
+		if err := o.db.ScanRow(ctx, rows, &model); err != nil {
+			return wrap("report", uuid.Nil, err)
+		}
+		if err := fn(model.toJob()); err != nil {
+			return err
+		}
+	}
+	return wrap("report", uuid.Nil, rows.Err())
+}
@@ -2,8 +2,12 @@ package sql_test
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
 	"github.com/romanqed/gqs/job"
 	"github.com/romanqed/gqs/message"
 	gsql "github.com/romanqed/gqs/sql"
@@ -17,6 +21,7 @@ func TestPusherAndObserver(t *testing.T) {
 	observer := gsql.NewObserver(db)
 
 	msg := &message.Message{
+		Type:     "test",
 		Metadata: map[string]any{"a": 1},
 		Payload:  []byte("data"),
 	}
@@ -35,4 +40,117 @@ func TestPusherAndObserver(t *testing.T) {
 	if j.Status != job.Pending {
 		t.Fatalf("expected Pending, got %v", j.Status)
 	}
+	if j.Type != "test" {
+		t.Fatalf("expected type test, got %q", j.Type)
+	}
+}
+
+func TestObserverQueryPagination(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	observer := gsql.NewObserver(db)
+
+	for i := 0; i < 5; i++ {
+		msg := message.NewMessage()
+		msg.Set("tenant", "a")
+		if i%2 == 0 {
+			msg.Set("tenant", "b")
+		}
+		if err := pusher.Push(ctx, msg, 0); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	opts := &gqs.ListOptions{
+		Statuses: []job.Status{job.Pending},
+		Order:    gqs.OrderCreatedDesc,
+		Limit:    2,
+	}
+
+	seen := make(map[uuid.UUID]struct{})
+	var last time.Time
+	for {
+		page, err := observer.Query(ctx, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, j := range page.Jobs {
+			if !last.IsZero() && j.CreatedAt.After(last) {
+				t.Fatal("expected descending order")
+			}
+			last = j.CreatedAt
+			seen[j.Id] = struct{}{}
+		}
+		if page.Next == "" {
+			break
+		}
+		opts.Cursor = page.Next
+	}
+	if len(seen) != 5 {
+		t.Fatalf("expected 5 distinct jobs, got %d", len(seen))
+	}
+
+	count, err := observer.Count(ctx, &gqs.ListOptions{Metadata: map[string]string{"tenant": "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Fatalf("expected 3 jobs of tenant b, got %d", count)
+	}
+}
+
+func TestObserverReadYourWrites(t *testing.T) {
+	primary := newTestDB(t)
+	replica := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(primary)
+	observer := gsql.NewObserverWithOptions(replica, &gsql.ObserverOptions{Primary: primary})
+
+	msg := message.NewMessage()
+	snapshot, err := pusher.PushSnapshot(ctx, msg, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Id != msg.Id || snapshot.Status != job.Pending {
+		t.Fatalf("unexpected snapshot %+v", snapshot)
+	}
+
+	j, err := observer.Get(ctx, msg.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j == nil {
+		t.Fatal("expected job to be read from primary")
+	}
+}
+
+func TestObserverNotFoundError(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	missing := uuid.New()
+
+	j, err := gsql.NewObserver(db).Get(ctx, missing)
+	if j != nil || err != nil {
+		t.Fatalf("expected (nil, nil) by default, got %v, %v", j, err)
+	}
+	if _, err := gqs.GetExisting(ctx, gsql.NewObserver(db), missing); !errors.Is(err, gqs.ErrNotFound) {
+		t.Fatalf("expected GetExisting to return ErrNotFound, got %v", err)
+	}
+
+	observer := gsql.NewObserverWithOptions(db, &gsql.ObserverOptions{NotFoundError: true})
+	if _, err := observer.Get(ctx, missing); !errors.Is(err, gqs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	msg := message.NewMessage()
+	_ = gsql.NewPusher(db).Push(ctx, msg, 0)
+	j, err = gqs.GetExisting(ctx, observer, msg.Id)
+	if err != nil || j == nil || j.Id != msg.Id {
+		t.Fatalf("expected existing job, got %v, %v", j, err)
+	}
 }
@@ -0,0 +1,165 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"time"
+)
+
+// ErrOutboxUnsupported is returned when the transition outbox is
+// requested for a dialect other than PostgreSQL and SQLite.
+var ErrOutboxUnsupported = errors.New("transition outbox is not supported by dialect")
+
+type outboxModel struct {
+	bun.BaseModel `bun:"table:job_outbox"`
+
+	Id        int64      `bun:"id,pk,autoincrement"`
+	JobId     uuid.UUID  `bun:"job_id,type:uuid,notnull"`
+	Queue     string     `bun:"queue,notnull,default:''"`
+	Type      string     `bun:"type,notnull,default:''"`
+	At        time.Time  `bun:"at,notnull"`
+	OldStatus job.Status `bun:"old_status,notnull"`
+	NewStatus job.Status `bun:"new_status,notnull"`
+	Attempts  uint32     `bun:"attempts,notnull"`
+	Error     string     `bun:"error,notnull,default:''"`
+}
+
+func (om *outboxModel) toTransition() gqs.Transition {
+	return gqs.Transition{
+		Seq:      om.Id,
+		JobId:    om.JobId,
+		Queue:    om.Queue,
+		Type:     om.Type,
+		Time:     om.At,
+		From:     om.OldStatus,
+		To:       om.NewStatus,
+		Attempts: om.Attempts,
+		Error:    om.Error,
+	}
+}
+
+// PostgreSQL outbox trigger, recording creation, status and attempt
+// changes. Unlike history, transitions outlive deleted jobs until they
+// are published.
+var pgOutbox = []string{
+	`CREATE OR REPLACE FUNCTION gqs_jobs_outbox() RETURNS trigger AS $$
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		INSERT INTO job_outbox (job_id, queue, type, at, old_status, new_status, attempts)
+		VALUES (NEW.id, NEW.queue, NEW.type, NEW.created_at, 0, NEW.status, NEW.attempts);
+	ELSIF OLD.status <> NEW.status OR OLD.attempts <> NEW.attempts THEN
+		INSERT INTO job_outbox (job_id, queue, type, at, old_status, new_status, attempts, error)
+		VALUES (NEW.id, NEW.queue, NEW.type, NEW.updated_at, OLD.status, NEW.status, NEW.attempts,
+			` + failureError + `);
+	END IF;
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS trg_jobs_outbox ON jobs`,
+	`CREATE TRIGGER trg_jobs_outbox AFTER INSERT OR UPDATE ON jobs
+	FOR EACH ROW EXECUTE FUNCTION gqs_jobs_outbox()`,
+}
+
+// SQLite outbox triggers, equivalent to pgOutbox.
+var sqliteOutbox = []string{
+	`DROP TRIGGER IF EXISTS trg_jobs_outbox_insert`,
+	`CREATE TRIGGER trg_jobs_outbox_insert AFTER INSERT ON jobs BEGIN
+	INSERT INTO job_outbox (job_id, queue, type, at, old_status, new_status, attempts)
+	VALUES (NEW.id, NEW.queue, NEW.type, NEW.created_at, 0, NEW.status, NEW.attempts);
+END`,
+	`DROP TRIGGER IF EXISTS trg_jobs_outbox_update`,
+	`CREATE TRIGGER trg_jobs_outbox_update AFTER UPDATE OF status, attempts ON jobs
+	WHEN OLD.status <> NEW.status OR OLD.attempts <> NEW.attempts BEGIN
+	INSERT INTO job_outbox (job_id, queue, type, at, old_status, new_status, attempts, error)
+	VALUES (NEW.id, NEW.queue, NEW.type, NEW.updated_at, OLD.status, NEW.status, NEW.attempts,
+		` + failureError + `);
+END`,
+}
+
+func outboxStatements(name dialect.Name) ([]string, error) {
+	switch name {
+	case dialect.PG:
+		return pgOutbox, nil
+	case dialect.SQLite:
+		return sqliteOutbox, nil
+	}
+	return nil, ErrOutboxUnsupported
+}
+
+func (opts *InitOptions) createOutbox(ctx context.Context, db bun.IDB) error {
+	if !opts.Outbox {
+		return nil
+	}
+	stmts, err := outboxStatements(db.Dialect().Name())
+	if err != nil {
+		return err
+	}
+	_, err = db.NewCreateTable().
+		Model((*outboxModel)(nil)).
+		IfNotExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range stmts {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Outbox implements gqs.Outbox on top of the job_outbox table.
+//
+// Transitions are recorded by database triggers installed by InitDB
+// when InitOptions.Outbox is set, within the transaction of the state
+// change itself, so every transition is captured, whether made by a
+// Puller, an Admin or directly in SQL, and none is lost on a crash.
+// The time of a transition is the updated_at of the job after it.
+type Outbox struct {
+	db *bun.DB
+}
+
+// NewOutbox creates a new SQL-backed Outbox.
+func NewOutbox(db *bun.DB) *Outbox {
+	return &Outbox{db: db}
+}
+
+// Fetch returns up to limit unpublished transitions, oldest first.
+func (o *Outbox) Fetch(ctx context.Context, limit int) ([]gqs.Transition, error) {
+	var models []outboxModel
+	err := o.db.NewSelect().
+		Model(&models).
+		OrderExpr("id ASC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, wrap("fetch transitions", uuid.Nil, err)
+	}
+	ret := make([]gqs.Transition, len(models))
+	for i := range models {
+		ret[i] = models[i].toTransition()
+	}
+	return ret, nil
+}
+
+// Ack deletes the given transitions from the outbox.
+func (o *Outbox) Ack(ctx context.Context, events []gqs.Transition) error {
+	if len(events) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(events))
+	for i, event := range events {
+		ids[i] = event.Seq
+	}
+	_, err := o.db.NewDelete().
+		Model((*outboxModel)(nil)).
+		Where("id IN (?)", bun.In(ids)).
+		Exec(ctx)
+	return wrap("ack transitions", uuid.Nil, err)
+}
@@ -0,0 +1,130 @@
+package sql_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+)
+
+func newOutboxDB(t *testing.T) *bun.DB {
+	t.Helper()
+	sqlDB, err := sql.Open("sqlite", "file::memory:?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	db := bun.NewDB(sqlDB, sqlitedialect.New())
+	if err := gsql.InitDBWithOptions(context.Background(), db, &gsql.InitOptions{Outbox: true}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestOutbox(t *testing.T) {
+	db := newOutboxDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	outbox := gsql.NewOutbox(db)
+
+	msg := message.NewMessage()
+	msg.Queue = "billing"
+	_ = pusher.Push(ctx, msg, 0)
+	jobs, _ := puller.Pull(ctx, 1, time.Minute)
+	if len(jobs) != 1 {
+		t.Fatal("expected job to be pulled")
+	}
+	jobs[0].LastError = "card declined"
+	_ = puller.Kill(ctx, jobs[0])
+
+	events, err := outbox.Fetch(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 transitions, got %+v", events)
+	}
+	want := []struct{ from, to job.Status }{
+		{job.Unknown, job.Pending},
+		{job.Pending, job.Processing},
+		{job.Processing, job.Dead},
+	}
+	for i, event := range events {
+		if event.JobId != msg.Id || event.Queue != "billing" || event.From != want[i].from || event.To != want[i].to {
+			t.Fatalf("unexpected transition %d: %+v", i, event)
+		}
+		if i > 0 && event.Seq <= events[i-1].Seq {
+			t.Fatal("expected increasing sequence numbers")
+		}
+	}
+	if events[2].Error != "card declined" {
+		t.Fatalf("expected failure error, got %q", events[2].Error)
+	}
+
+	if err := outbox.Ack(ctx, events[:2]); err != nil {
+		t.Fatal(err)
+	}
+	events, _ = outbox.Fetch(ctx, 10)
+	if len(events) != 1 || events[0].To != job.Dead {
+		t.Fatalf("expected only the unacknowledged transition, got %+v", events)
+	}
+}
+
+func TestRelayWorker(t *testing.T) {
+	db := newOutboxDB(t)
+	ctx := context.Background()
+
+	var mutex sync.Mutex
+	var received []gqs.Transition
+	fail := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if fail {
+			fail = false
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var batch []gqs.Transition
+		_ = json.NewDecoder(r.Body).Decode(&batch)
+		received = append(received, batch...)
+	}))
+	defer server.Close()
+
+	outbox := gsql.NewOutbox(db)
+	relay := gqs.NewRelayWorker(outbox, &gqs.WebhookSink{URL: server.URL}, &gqs.RelayConfig{
+		Interval:  20 * time.Millisecond,
+		BatchSize: 2,
+	}, slog.Default())
+
+	for i := 0; i < 3; i++ {
+		_ = gsql.NewPusher(db).Push(ctx, message.NewMessage(), 0)
+	}
+
+	_ = relay.Start(ctx)
+	time.Sleep(150 * time.Millisecond)
+	_ = relay.Stop(time.Second)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(received) != 3 {
+		t.Fatalf("expected every transition after a failed delivery, got %d", len(received))
+	}
+	if pending, _ := outbox.Fetch(ctx, 10); len(pending) != 0 {
+		t.Fatalf("expected published transitions to be acknowledged, got %d", len(pending))
+	}
+}
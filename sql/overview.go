@@ -0,0 +1,74 @@
+package sql
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type queueCounts struct {
+	Queue      string       `bun:"queue"`
+	Pending    int64        `bun:"pending"`
+	Scheduled  int64        `bun:"scheduled"`
+	Processing int64        `bun:"processing"`
+	Done       int64        `bun:"done"`
+	Dead       int64        `bun:"dead"`
+	Cancelled  int64        `bun:"cancelled"`
+	InFlight   int64        `bun:"in_flight"`
+	Oldest     bun.NullTime `bun:"oldest"`
+}
+
+// QueueOverview returns the overview of every queue with at least one
+// job, computed with a single grouped query, so that all counts come
+// from the same snapshot.
+func (o *Observer) QueueOverview(ctx context.Context) ([]gqs.QueueOverview, error) {
+	now := time.Now()
+	query := o.db.NewSelect().
+		Model((*jobModel)(nil)).
+		Column("queue")
+	for _, column := range []struct {
+		status job.Status
+		alias  string
+	}{
+		{job.Pending, "pending"},
+		{job.Scheduled, "scheduled"},
+		{job.Processing, "processing"},
+		{job.Done, "done"},
+		{job.Dead, "dead"},
+		{job.Cancelled, "cancelled"},
+	} {
+		query.ColumnExpr("SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS ?",
+			column.status, bun.Ident(column.alias))
+	}
+	var counts []queueCounts
+	err := query.
+		ColumnExpr("SUM(CASE WHEN status = ? AND locked_until >= ? THEN 1 ELSE 0 END) AS in_flight",
+			job.Processing, now).
+		ColumnExpr("MIN(CASE WHEN status = ? THEN created_at END) AS oldest", job.Pending).
+		Group("queue").
+		Order("queue ASC").
+		Scan(ctx, &counts)
+	if err != nil {
+		return nil, wrap("queue overview", uuid.Nil, err)
+	}
+	ret := make([]gqs.QueueOverview, len(counts))
+	for i, c := range counts {
+		ret[i] = gqs.QueueOverview{
+			Queue:      c.Queue,
+			Pending:    c.Pending,
+			Scheduled:  c.Scheduled,
+			Processing: c.Processing,
+			Done:       c.Done,
+			Dead:       c.Dead,
+			Cancelled:  c.Cancelled,
+			InFlight:   c.InFlight,
+		}
+		if !c.Oldest.IsZero() {
+			ret[i].OldestPending = max(now.Sub(c.Oldest.Time), 0)
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,62 @@
+package sql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestQueueOverview(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPullerWithOptions(db, &gsql.PullerOptions{Queues: []string{"mail"}})
+	observer := gsql.NewObserver(db)
+
+	if !gqs.Supports(observer, gqs.CapOverview) {
+		t.Fatal("expected observer to support overviews")
+	}
+
+	for i := 0; i < 4; i++ {
+		msg := message.NewMessage()
+		msg.Queue = "mail"
+		if err := pusher.Push(ctx, msg, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other := message.NewMessage()
+	other.Queue = "sms"
+	if err := pusher.Push(ctx, other, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err := puller.Pull(ctx, 3, time.Minute)
+	if err != nil || len(jobs) != 3 {
+		t.Fatalf("expected 3 pulled jobs, got %d, %v", len(jobs), err)
+	}
+	_ = puller.Complete(ctx, jobs[0])
+	_ = puller.Kill(ctx, jobs[1])
+
+	overview, err := observer.QueueOverview(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overview) != 2 || overview[0].Queue != "mail" || overview[1].Queue != "sms" {
+		t.Fatalf("unexpected overview %+v", overview)
+	}
+	mail := overview[0]
+	if mail.Pending != 1 || mail.Processing != 1 || mail.Done != 1 || mail.Dead != 1 || mail.InFlight != 1 {
+		t.Fatalf("unexpected mail overview %+v", mail)
+	}
+	if mail.OldestPending <= 0 {
+		t.Fatal("expected age of the oldest pending job")
+	}
+	if sms := overview[1]; sms.Pending != 1 || sms.Processing != 0 || sms.InFlight != 0 {
+		t.Fatalf("unexpected sms overview %+v", sms)
+	}
+}
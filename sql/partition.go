@@ -0,0 +1,253 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs/job"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultPartitionInterval is the range partition width used when
+	// InitOptions.PartitionInterval is zero.
+	DefaultPartitionInterval = 24 * time.Hour
+
+	rangePrefix     = "jobs_p"
+	rangeLayout     = "20060102T1504"
+	defaultPartName = "jobs_default"
+)
+
+var (
+	// ErrPartitionUnsupported is returned when partitioning is requested
+	// for a dialect that does not support declarative partitioning.
+	ErrPartitionUnsupported = errors.New("partitioning is not supported by dialect")
+
+	// ErrBadPartitions is returned when PartitionByQueue is requested
+	// with a non-positive number of partitions.
+	ErrBadPartitions = errors.New("bad number of partitions")
+)
+
+// Partitioning selects the layout of the jobs table.
+type Partitioning uint8
+
+const (
+	// PartitionNone creates a regular, non-partitioned jobs table.
+	PartitionNone Partitioning = iota
+
+	// PartitionByCreatedAt creates a jobs table partitioned by range
+	// of created_at. Partitions are created and dropped over time by
+	// PartitionMaintainer; a default partition catches rows outside
+	// of existing ranges.
+	//
+	// The primary key becomes (id, created_at).
+	PartitionByCreatedAt
+
+	// PartitionByQueue creates a jobs table hash-partitioned by queue
+	// into a fixed number of partitions created by InitDB.
+	//
+	// Pulls restricted to specific queues (PullerOptions.Queues) only
+	// scan the matching partitions.
+	//
+	// The primary key becomes (id, queue).
+	PartitionByQueue
+)
+
+func (opts *InitOptions) validate(db *bun.DB) error {
+	if opts.Partitioning == PartitionNone {
+		return nil
+	}
+	if db.Dialect().Name() != dialect.PG {
+		return ErrPartitionUnsupported
+	}
+	if opts.Partitioning == PartitionByQueue && opts.Partitions <= 0 {
+		return ErrBadPartitions
+	}
+	return nil
+}
+
+func (opts *InitOptions) interval() time.Duration {
+	if opts.PartitionInterval > 0 {
+		return opts.PartitionInterval
+	}
+	return DefaultPartitionInterval
+}
+
+func (opts *InitOptions) createTable(ctx context.Context, db bun.IDB) error {
+	query := db.NewCreateTable().
+		Model((*jobModel)(nil)).
+		IfNotExists()
+	switch opts.Partitioning {
+	case PartitionByCreatedAt:
+		// partition key must be a part of the primary key
+		query.ColumnExpr("PRIMARY KEY (id, created_at)").
+			PartitionBy("RANGE (created_at)")
+	case PartitionByQueue:
+		query.ColumnExpr("PRIMARY KEY (id, queue)").
+			PartitionBy("HASH (queue)")
+	}
+	_, err := query.Exec(ctx)
+	return err
+}
+
+func (opts *InitOptions) createPartitions(ctx context.Context, db bun.IDB) error {
+	switch opts.Partitioning {
+	case PartitionByCreatedAt:
+		if err := createDefaultPartition(ctx, db); err != nil {
+			return err
+		}
+		return createRangePartition(ctx, db, time.Now(), opts.interval())
+	case PartitionByQueue:
+		for i := 0; i < opts.Partitions; i++ {
+			if err := createHashPartition(ctx, db, opts.Partitions, i); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func createDefaultPartition(ctx context.Context, db bun.IDB) error {
+	_, err := db.NewRaw(
+		"CREATE TABLE IF NOT EXISTS ? PARTITION OF ? DEFAULT",
+		bun.Ident(defaultPartName), bun.Ident("jobs"),
+	).Exec(ctx)
+	return err
+}
+
+func createHashPartition(ctx context.Context, db bun.IDB, modulus, remainder int) error {
+	_, err := db.NewRaw(
+		"CREATE TABLE IF NOT EXISTS ? PARTITION OF ? FOR VALUES WITH (MODULUS ?, REMAINDER ?)",
+		bun.Ident(fmt.Sprintf("jobs_h%d", remainder)), bun.Ident("jobs"), modulus, remainder,
+	).Exec(ctx)
+	return err
+}
+
+func rangeStart(at time.Time, interval time.Duration) time.Time {
+	return at.UTC().Truncate(interval)
+}
+
+func createRangePartition(ctx context.Context, db bun.IDB, at time.Time, interval time.Duration) error {
+	from := rangeStart(at, interval)
+	to := from.Add(interval)
+	_, err := db.NewRaw(
+		"CREATE TABLE IF NOT EXISTS ? PARTITION OF ? FOR VALUES FROM (?) TO (?)",
+		bun.Ident(rangePrefix+from.Format(rangeLayout)), bun.Ident("jobs"), from, to,
+	).Exec(ctx)
+	return err
+}
+
+// PartitionConfig defines the behavior of a PartitionMaintainer.
+//
+// Interval must match the InitOptions.PartitionInterval used to
+// create the table. If zero, DefaultPartitionInterval is used.
+//
+// Premake is the number of future partitions kept created ahead
+// of time, so inserts never fall into the default partition.
+//
+// Retention is the minimum age of a partition's upper bound before
+// it may be dropped. Zero disables dropping.
+type PartitionConfig struct {
+	Interval  time.Duration
+	Premake   int
+	Retention time.Duration
+}
+
+// PartitionMaintainer maintains range partitions of a jobs table
+// created with PartitionByCreatedAt.
+//
+// PartitionMaintainer implements gqs.Maintainer and is intended to be
+// run periodically by gqs.MaintenanceWorker.
+//
+// Dropping a partition is the cheapest way to remove old rows: it does
+// not produce dead tuples and needs no vacuum. A partition is dropped
+// only if it is older than Retention and contains no Pending or
+// Processing jobs; terminal jobs stored in it are discarded.
+type PartitionMaintainer struct {
+	db        *bun.DB
+	interval  time.Duration
+	premake   int
+	retention time.Duration
+}
+
+// NewPartitionMaintainer creates a new PartitionMaintainer.
+//
+// The jobs table must be created by InitDBWithOptions using
+// PartitionByCreatedAt.
+func NewPartitionMaintainer(db *bun.DB, config *PartitionConfig) *PartitionMaintainer {
+	interval := config.Interval
+	if interval <= 0 {
+		interval = DefaultPartitionInterval
+	}
+	return &PartitionMaintainer{
+		db:        db,
+		interval:  interval,
+		premake:   config.Premake,
+		retention: config.Retention,
+	}
+}
+
+func (pm *PartitionMaintainer) partitions(ctx context.Context) ([]string, error) {
+	var ret []string
+	err := pm.db.NewRaw(
+		"SELECT c.relname FROM pg_inherits i "+
+			"JOIN pg_class c ON c.oid = i.inhrelid "+
+			"JOIN pg_class p ON p.oid = i.inhparent "+
+			"WHERE p.relname = ?", "jobs",
+	).Scan(ctx, &ret)
+	return ret, err
+}
+
+func (pm *PartitionMaintainer) hasActive(ctx context.Context, name string) (bool, error) {
+	return pm.db.NewSelect().
+		TableExpr("?", bun.Ident(name)).
+		Where("status IN (?, ?, ?)", job.Pending, job.Scheduled, job.Processing).
+		Exists(ctx)
+}
+
+func (pm *PartitionMaintainer) dropExpired(ctx context.Context, now time.Time) error {
+	names, err := pm.partitions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if !strings.HasPrefix(name, rangePrefix) {
+			continue
+		}
+		from, err := time.Parse(rangeLayout, strings.TrimPrefix(name, rangePrefix))
+		if err != nil || now.Sub(from.Add(pm.interval)) < pm.retention {
+			continue
+		}
+		active, err := pm.hasActive(ctx, name)
+		if err != nil {
+			return err
+		}
+		if active {
+			continue
+		}
+		if _, err := pm.db.NewDropTable().TableExpr("?", bun.Ident(name)).IfExists().Exec(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Maintain creates the current and Premake future partitions and drops
+// expired partitions without active jobs.
+func (pm *PartitionMaintainer) Maintain(ctx context.Context) error {
+	now := time.Now()
+	for i := 0; i <= pm.premake; i++ {
+		at := now.Add(time.Duration(i) * pm.interval)
+		if err := createRangePartition(ctx, pm.db, at, pm.interval); err != nil {
+			return wrap("maintain partitions", uuid.Nil, err)
+		}
+	}
+	if pm.retention <= 0 {
+		return nil
+	}
+	return wrap("maintain partitions", uuid.Nil, pm.dropExpired(ctx, now))
+}
@@ -0,0 +1,87 @@
+package sql
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type pausedQueueModel struct {
+	bun.BaseModel `bun:"table:paused_queues"`
+
+	Queue    string    `bun:"queue,pk"`
+	PausedAt time.Time `bun:"paused_at,notnull"`
+}
+
+func createPausedQueueTable(ctx context.Context, db bun.IDB) error {
+	_, err := db.NewCreateTable().
+		Model((*pausedQueueModel)(nil)).
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+// QueueController implements gqs.QueueController using a SQL backend.
+//
+// Paused queues are stored in the paused_queues table, created by
+// InitDB. Puller skips jobs of paused queues.
+type QueueController struct {
+	db *bun.DB
+}
+
+// NewQueueController creates a new SQL-backed QueueController.
+//
+// The provided *bun.DB must be properly configured and connected.
+// Schema initialization must be completed before using QueueController.
+func NewQueueController(db *bun.DB) *QueueController {
+	return &QueueController{
+		db: db,
+	}
+}
+
+// Pause inserts queue into paused_queues, keeping the original
+// paused_at of an already paused queue.
+func (qc *QueueController) Pause(ctx context.Context, queue string) error {
+	_, err := qc.db.NewInsert().
+		Model(&pausedQueueModel{Queue: queue, PausedAt: time.Now()}).
+		Ignore().
+		Exec(ctx)
+	return wrap("pause", uuid.Nil, err)
+}
+
+// Resume removes queue from paused_queues.
+func (qc *QueueController) Resume(ctx context.Context, queue string) error {
+	_, err := qc.db.NewDelete().
+		Model((*pausedQueueModel)(nil)).
+		Where("queue = ?", queue).
+		Exec(ctx)
+	return wrap("resume", uuid.Nil, err)
+}
+
+// Paused returns all paused queues ordered by name.
+func (qc *QueueController) Paused(ctx context.Context) ([]string, error) {
+	ret, err := pausedQueues(ctx, qc.db, nil)
+	return ret, wrap("list paused queues", uuid.Nil, err)
+}
+
+func pausedQueues(ctx context.Context, db bun.IDB, queues []string) ([]string, error) {
+	ret := []string{}
+	query := db.NewSelect().
+		Model((*pausedQueueModel)(nil)).
+		Column("queue").
+		Order("queue ASC")
+	if len(queues) != 0 {
+		query.Where("queue IN (?)", bun.In(queues))
+	}
+	if err := query.Scan(ctx, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func selectPaused(db bun.IDB) *bun.SelectQuery {
+	return db.NewSelect().
+		Model((*pausedQueueModel)(nil)).
+		Column("queue")
+}
@@ -0,0 +1,67 @@
+package sql_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestQueueController(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	controller := gsql.NewQueueController(db)
+
+	for _, queue := range []string{"mail", "sms"} {
+		msg := message.NewMessage()
+		msg.Queue = queue
+		if err := pusher.Push(ctx, msg, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := controller.Pause(ctx, "mail"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	paused, err := controller.Paused(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(paused, []string{"mail"}) {
+		t.Fatalf("unexpected paused queues %v", paused)
+	}
+	paused, err = gsql.NewPullerWithOptions(db, &gsql.PullerOptions{Queues: []string{"sms"}}).PausedQueues(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paused) != 0 {
+		t.Fatal("expected paused queue not consumed by puller to be omitted")
+	}
+
+	jobs, err := puller.Pull(ctx, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].Queue != "sms" {
+		t.Fatal("expected jobs of paused queue to be skipped")
+	}
+
+	if err := controller.Resume(ctx, "mail"); err != nil {
+		t.Fatal(err)
+	}
+	jobs, err = puller.Pull(ctx, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].Queue != "mail" {
+		t.Fatal("expected jobs of resumed queue to be pulled")
+	}
+}
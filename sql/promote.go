@@ -0,0 +1,69 @@
+package sql
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs/job"
+	"github.com/uptrace/bun"
+)
+
+// Promoter transitions due Scheduled jobs to Pending.
+//
+// Puller pulls due Scheduled jobs on its own, so Promoter is not
+// required for processing. It keeps the Pending and Scheduled counts
+// of observers accurate, so that the backlog ready to run can be told
+// apart from deferred jobs. Promoter implements gqs.Maintainer and is
+// intended to be run periodically by gqs.MaintenanceWorker.
+type Promoter struct {
+	db    *bun.DB
+	clock *Clock
+}
+
+// PromoterOptions defines optional behavior of a Promoter.
+//
+// Clock, if set, provides the time due jobs are determined by instead
+// of the local clock (see Clock).
+type PromoterOptions struct {
+	Clock *Clock
+}
+
+// NewPromoter creates a new SQL-backed Promoter.
+//
+// The provided *bun.DB must be properly configured and connected.
+// Schema initialization must be completed before using Promoter.
+func NewPromoter(db *bun.DB) *Promoter {
+	return NewPromoterWithOptions(db, &PromoterOptions{})
+}
+
+// NewPromoterWithOptions creates a new SQL-backed Promoter using
+// the provided options.
+func NewPromoterWithOptions(db *bun.DB, opts *PromoterOptions) *Promoter {
+	return &Promoter{
+		db:    db,
+		clock: opts.Clock,
+	}
+}
+
+// Promote sets status to Pending for every Scheduled job whose
+// next_run_at has passed, using a single UPDATE statement, and returns
+// the number of promoted jobs. updated_at is left untouched, as the
+// promotion is not a change of the job.
+func (p *Promoter) Promote(ctx context.Context) (int64, error) {
+	res, err := p.db.NewUpdate().
+		Model((*jobModel)(nil)).
+		Set("status = ?", job.Pending).
+		Set("version = version + 1").
+		Where("status = ?", job.Scheduled).
+		Where("next_run_at <= ?", p.clock.now(ctx, p.db)).
+		Exec(ctx)
+	if err != nil {
+		return 0, wrap("promote", uuid.Nil, err)
+	}
+	return getAffected(res), nil
+}
+
+// Maintain implements gqs.Maintainer by calling Promote.
+func (p *Promoter) Maintain(ctx context.Context) error {
+	_, err := p.Promote(ctx)
+	return err
+}
@@ -0,0 +1,87 @@
+package sql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestScheduledPromote(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+	promoter := gsql.NewPromoter(db)
+
+	ready := message.NewMessage()
+	soon := message.NewMessage()
+	later := message.NewMessage()
+	_ = pusher.Push(ctx, ready, 0)
+	_ = pusher.Push(ctx, soon, 50*time.Millisecond)
+	_ = pusher.Push(ctx, later, time.Hour)
+
+	for msg, status := range map[*message.Message]job.Status{
+		ready: job.Pending,
+		soon:  job.Scheduled,
+		later: job.Scheduled,
+	} {
+		j, _ := observer.Get(ctx, msg.Id)
+		if j.Status != status {
+			t.Fatalf("expected %v, got %v", status, j.Status)
+		}
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	count, err := promoter.Promote(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 promoted job, got %d", count)
+	}
+	j, _ := observer.Get(ctx, soon.Id)
+	if j.Status != job.Pending {
+		t.Fatalf("expected promoted job to be Pending, got %v", j.Status)
+	}
+
+	jobs, err := puller.Pull(ctx, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("expected 2 pulled jobs, got %d", len(jobs))
+	}
+}
+
+func TestPullDueScheduled(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 50*time.Millisecond)
+
+	jobs, _ := puller.Pull(ctx, 1, time.Second)
+	if len(jobs) != 0 {
+		t.Fatal("expected no job to be pulled before it is due")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	jobs, err := puller.Pull(ctx, 1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].Status != job.Processing {
+		t.Fatal("expected the due Scheduled job to be pulled without promotion")
+	}
+}
@@ -2,13 +2,135 @@ package sql
 
 import (
 	"context"
+	"github.com/google/uuid"
 	"github.com/romanqed/gqs"
 	"github.com/romanqed/gqs/job"
 	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"iter"
+	"slices"
+	"strings"
 	"time"
 )
 
-// Puller implements gqs.Puller using a SQL backend.
+// PullMode selects the strategy Puller uses to claim jobs.
+type PullMode uint8
+
+const (
+	// PullUpdate claims jobs with a single UPDATE ... WHERE id IN (subquery)
+	// statement. It works with every supported dialect and is the default.
+	PullUpdate PullMode = iota
+
+	// PullSkipLocked claims jobs inside a transaction using
+	// SELECT ... FOR UPDATE SKIP LOCKED followed by an UPDATE of the
+	// selected rows.
+	//
+	// Concurrent pullers skip rows locked by each other instead of
+	// contending for them, which greatly reduces row contention and
+	// serialization failures under many concurrent workers.
+	//
+	// This mode requires a dialect supporting SKIP LOCKED, such as
+	// PostgreSQL or MySQL 8+. It must not be used with SQLite.
+	PullSkipLocked
+)
+
+// ExpiredOrder selects how Puller orders jobs whose lease expired
+// against waiting jobs of equal priority.
+type ExpiredOrder uint8
+
+const (
+	// ExpiredByNextRun orders jobs whose lease expired by their
+	// next_run_at, like waiting jobs. As next_run_at is not changed by
+	// claiming, such jobs precede every job that became due after them.
+	// It is the default.
+	ExpiredByNextRun ExpiredOrder = iota
+
+	// ExpiredByExpiry orders jobs whose lease expired by their
+	// locked_until, as if they became due when their lease expired.
+	ExpiredByExpiry
+
+	// ExpiredLast orders jobs whose lease expired after every waiting
+	// job of equal priority.
+	ExpiredLast
+)
+
+// PullerOptions defines optional behavior of a Puller.
+//
+// Mode selects the claiming strategy used by Pull.
+//
+// Queues restricts Pull to jobs of the listed queues.
+// If empty, jobs of all queues are pulled.
+//
+// Region restricts Pull to jobs of the given region and jobs without
+// a region. If empty, jobs of all regions are pulled.
+//
+// RegionFailover makes jobs of other regions eligible for a Puller with
+// a Region once they have stayed unclaimed for RegionFailover past
+// their next_run_at, so that jobs of an unavailable region are still
+// processed. Zero disables failover.
+//
+// Filter, if set, restricts Pull to jobs matching it. Types are
+// matched on the type column; metadata filters are translated into
+// dialect-specific JSON extraction expressions, as by Observer.Query.
+//
+// SQLite, if set, enables tuning for SQLite (see SQLiteOptions). It is
+// ignored for other dialects.
+//
+// FairTenants makes Pull schedule tenants round-robin: a batch takes
+// the best eligible job of every tenant before the second best of any,
+// so that a tenant with a large backlog cannot starve the others.
+// Within a tenant, jobs are ordered by priority and next_run_at as
+// usual. Ranking reads all eligible jobs, so Pull gets costlier with
+// the backlog; it requires window functions (PostgreSQL, MySQL 8+ and
+// SQLite 3.25+). Jobs without a tenant form a tenant of their own.
+//
+// Instance is recorded in the locked_by column of pulled jobs. It must
+// match gqs.WorkerConfig.Instance of the worker using the Puller, so
+// that Registry.Reap can reassign jobs of the instance once it dies.
+// A Worker sets it automatically via WithInstance.
+//
+// ScanWindow, if positive, bounds the rows Pull examines on large
+// tables. Instead of sorting all eligible jobs by priority, Pull takes
+// the ScanWindow waiting jobs due first and the ScanWindow jobs whose
+// lease expired first, each with an index range scan, and orders only
+// those by priority. Priority is therefore honored within the window
+// only: a high-priority job behind ScanWindow older jobs waits until
+// they are claimed. A window of a few times the batch size keeps Pull
+// cheap regardless of the backlog. Zero scans all eligible jobs.
+//
+// ExpiredOrder selects how jobs whose lease expired, typically the jobs
+// of crashed workers, are ordered against waiting jobs of equal
+// priority. With the default ExpiredByNextRun, jobs crashing their
+// workers repeatedly are always claimed first and may keep fresh jobs
+// from running.
+//
+// RequeueExpired sets next_run_at of jobs claimed after their lease
+// expired to the claim time, so that they queue up behind the jobs
+// that became due meanwhile once they are retried.
+//
+// Clock, if set, provides the time leases and eligibility are decided
+// by instead of the local clock, so that workers on hosts with skewed
+// clocks agree on lease expiry (see Clock).
+type PullerOptions struct {
+	Mode           PullMode
+	Queues         []string
+	Region         string
+	RegionFailover time.Duration
+	Filter         *gqs.PullFilter
+	SQLite         *SQLiteOptions
+	FairTenants    bool
+	Instance       string
+	ScanWindow     int
+	ExpiredOrder   ExpiredOrder
+	RequeueExpired bool
+	Clock          *Clock
+}
+
+// Puller implements gqs.Puller and its optional extensions
+// (gqs.BatchLockExtender, gqs.BatchCompleter, gqs.StreamPuller,
+// gqs.Releaser, gqs.ResultCompleter, gqs.LogSaver, gqs.LockLossRecorder,
+// gqs.DiagnosticsSaver, gqs.ChainCompleter, gqs.PauseObserver,
+// gqs.FilterPuller, gqs.OwnerPuller) using a SQL backend.
 //
 // Puller performs atomic state transitions using UPDATE ... RETURNING
 // semantics to ensure safe concurrent access across multiple workers.
@@ -22,17 +144,277 @@ import (
 // Puller enforces visibility timeout semantics using the locked_until
 // column.
 type Puller struct {
-	db *bun.DB
+	db       *bun.DB
+	mode     PullMode
+	queues   []string
+	region   string
+	failover time.Duration
+	instance string
+	filter   *gqs.PullFilter
+	fair     bool
+	window   int
+	expired  ExpiredOrder
+	requeue  bool
+	clock    *Clock
+	sqlite   *sqliteTuning
 }
 
-// NewPuller creates a new SQL-backed Puller.
+// NewPuller creates a new SQL-backed Puller with default options.
 //
 // The provided *bun.DB must be properly configured and connected.
 // Schema initialization must be completed before using Puller.
 func NewPuller(db *bun.DB) *Puller {
+	return NewPullerWithOptions(db, &PullerOptions{})
+}
+
+// NewPullerWithOptions creates a new SQL-backed Puller using
+// the provided options.
+//
+// The provided *bun.DB must be properly configured and connected.
+// Schema initialization must be completed before using Puller.
+func NewPullerWithOptions(db *bun.DB, opts *PullerOptions) *Puller {
 	return &Puller{
-		db: db,
+		db:       db,
+		mode:     opts.Mode,
+		queues:   opts.Queues,
+		region:   opts.Region,
+		failover: opts.RegionFailover,
+		instance: opts.Instance,
+		filter:   opts.Filter,
+		fair:     opts.FairTenants,
+		window:   opts.ScanWindow,
+		expired:  opts.ExpiredOrder,
+		requeue:  opts.RequeueExpired,
+		clock:    opts.Clock,
+		sqlite:   newSQLiteTuning(db, opts.SQLite),
+	}
+}
+
+// WithFilter returns a copy of the Puller handing out only jobs
+// matching filter, replacing PullerOptions.Filter. The copy shares
+// the database and the SQLite tuning state with p.
+func (p *Puller) WithFilter(filter *gqs.PullFilter) gqs.Puller {
+	ret := *p
+	ret.filter = filter
+	return &ret
+}
+
+// WithInstance returns a copy of the Puller recording id as the owner
+// of pulled jobs, replacing PullerOptions.Instance. The copy shares
+// the database and the SQLite tuning state with p.
+func (p *Puller) WithInstance(id string) gqs.Puller {
+	ret := *p
+	ret.instance = id
+	return &ret
+}
+
+func (p *Puller) applyFilter(query *bun.SelectQuery) {
+	if p.filter == nil {
+		return
+	}
+	if len(p.filter.Types) != 0 {
+		query.Where("type IN (?)", bun.In(p.filter.Types))
+	}
+	if len(p.filter.Tenants) != 0 {
+		query.Where("tenant_id IN (?)", bun.In(p.filter.Tenants))
+	}
+	if p.filter.Priority != nil {
+		query.Where("priority BETWEEN ? AND ?", p.filter.Priority.Min, p.filter.Priority.Max)
+	}
+	name := query.Dialect().Name()
+	expr := metadataExpr(name)
+	for key, value := range p.filter.Metadata {
+		query.Where(expr, metadataPath(name, key), value)
+	}
+}
+
+// selectCandidates selects jobs passing every eligibility condition
+// except the status one.
+func (p *Puller) selectCandidates(db bun.IDB, now time.Time) *bun.SelectQuery {
+	query := db.NewSelect().
+		Model((*jobModel)(nil)).
+		Column("id").
+		Where("next_run_at <= ?", now).
+		WhereGroup("AND", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.
+				Where("expires_at IS NULL").
+				WhereOr("expires_at > ?", now)
+		}).
+		Where("queue NOT IN (?)", selectPaused(db)).
+		Where("NOT EXISTS (?)", selectBlocking(db)).
+		WhereGroup("AND", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.
+				Where("?TableAlias.ordering_key = ''").
+				WhereOr("NOT EXISTS (?)", p.selectGroupHead(db, now))
+		})
+	if len(p.queues) != 0 {
+		query.Where("queue IN (?)", bun.In(p.queues))
+	}
+	p.applyFilter(query)
+	if p.region != "" {
+		query.WhereGroup("AND", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			sq.Where("region IN ('', ?)", p.region)
+			if p.failover > 0 {
+				sq.WhereOr("next_run_at <= ?", now.Add(-p.failover))
+			}
+			return sq
+		})
+	}
+	return query
+}
+
+// scanWindow restricts query to the first ScanWindow waiting jobs in
+// next_run_at order and the first ScanWindow jobs with an expired lease
+// in locked_until order. Both scans follow an index on status and the
+// scanned column and stop at the limit, so their cost does not grow
+// with the backlog. The derived tables let MySQL accept the limits
+// inside IN subqueries.
+func (p *Puller) scanWindow(db bun.IDB, query *bun.SelectQuery, now time.Time) {
+	waitingScan := p.selectCandidates(db, now).
+		Where("status IN (?)", bun.In(waiting)).
+		Order("next_run_at ASC").
+		Limit(p.window)
+	expiredScan := p.selectCandidates(db, now).
+		Where("status = ? AND locked_until < ?", job.Processing, now).
+		Order("locked_until ASC").
+		Limit(p.window)
+	query.WhereGroup("AND", func(sq *bun.SelectQuery) *bun.SelectQuery {
+		return sq.
+			Where("id IN (SELECT id FROM (?) AS waiting_scan)", waitingScan).
+			WhereOr("id IN (SELECT id FROM (?) AS expired_scan)", expiredScan)
+	})
+}
+
+func (p *Puller) selectEligible(db bun.IDB, now time.Time, batch int) *bun.SelectQuery {
+	var query *bun.SelectQuery
+	if p.window > 0 {
+		query = db.NewSelect().
+			Model((*jobModel)(nil)).
+			Column("id")
+		p.scanWindow(db, query, now)
+	} else {
+		query = p.selectCandidates(db, now).
+			WhereGroup("AND", func(sq *bun.SelectQuery) *bun.SelectQuery {
+				return sq.
+					Where("status IN (?)", bun.In(waiting)).
+					WhereOr("status = ? AND locked_until < ?", job.Processing, now)
+			})
+	}
+	order, args := p.order()
+	if !p.fair {
+		return query.
+			OrderExpr(order, args...).
+			Limit(batch)
+	}
+	// rank jobs within their tenant, then take the best job of every
+	// tenant before the second best of any
+	query.ColumnExpr("ROW_NUMBER() OVER (PARTITION BY tenant_id "+
+		"ORDER BY "+order+") AS tenant_rank", args...).
+		Column("priority", "next_run_at", "status", "locked_until")
+	return db.NewSelect().
+		TableExpr("(?) AS fair", query).
+		Column("id").
+		OrderExpr("tenant_rank ASC, "+order, args...).
+		Limit(batch)
+}
+
+// order returns the ordering of eligible jobs according to the
+// configured ExpiredOrder.
+func (p *Puller) order() (string, []any) {
+	switch p.expired {
+	case ExpiredByExpiry:
+		return "priority DESC, CASE WHEN status = ? THEN locked_until ELSE next_run_at END ASC",
+			[]any{job.Processing}
+	case ExpiredLast:
+		return "priority DESC, CASE WHEN status = ? THEN 1 ELSE 0 END ASC, next_run_at ASC",
+			[]any{job.Processing}
+	default:
+		return "priority DESC, next_run_at ASC", nil
+	}
+}
+
+// selectGroupHead selects unexpired non-terminal jobs preceding the
+// outer job in its ordering group, so that only the oldest job of
+// each group is eligible and jobs of a group never run concurrently.
+func (p *Puller) selectGroupHead(db bun.IDB, now time.Time) *bun.SelectQuery {
+	return db.NewSelect().
+		TableExpr("? AS og", bun.Ident("jobs")).
+		ColumnExpr("1").
+		Where("og.ordering_key = ?TableAlias.ordering_key").
+		Where("og.status IN (?)", bun.In([]job.Status{job.Pending, job.Scheduled, job.Processing})).
+		WhereGroup("AND", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.
+				Where("og.expires_at IS NULL").
+				WhereOr("og.expires_at > ?", now)
+		}).
+		WhereGroup("AND", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.
+				Where("og.created_at < ?TableAlias.created_at").
+				WhereOr("og.created_at = ?TableAlias.created_at AND og.id < ?TableAlias.id")
+		})
+}
+
+func (p *Puller) claim(db bun.IDB, now time.Time, lock time.Duration) *bun.UpdateQuery {
+	query := db.NewUpdate().
+		Model((*jobModel)(nil))
+	if p.requeue {
+		// MySQL assigns in order, so status must still hold the old
+		// value here
+		query.Set("next_run_at = CASE WHEN status = ? THEN ? ELSE next_run_at END", job.Processing, now)
+	}
+	// jobs whose cancellation was requested from a worker that lost
+	// them are cancelled instead of run again
+	return query.
+		Set("status = CASE WHEN cancel_requested = ? THEN ? ELSE ? END", true, job.Cancelled, job.Processing).
+		Set("attempts = CASE WHEN cancel_requested = ? THEN attempts ELSE attempts + 1 END", true).
+		Set("last_error = CASE WHEN cancel_requested = ? THEN ? ELSE last_error END", true, gqs.ErrCancelRequested.Error()).
+		Set("locked_until = CASE WHEN cancel_requested = ? THEN NULL ELSE ? END", true, now.Add(lock)).
+		Set("locked_by = ?", p.instance).
+		Set("version = version + 1").
+		Set("updated_at = ?", now).
+		Returning("*")
+}
+
+func (p *Puller) pullUpdate(ctx context.Context, db bun.IDB, batch int, lock time.Duration) ([]*job.Job, error) {
+	now := p.clock.now(ctx, db)
+	subQuery := p.selectEligible(db, now, batch)
+	var jobs []*job.Job
+	err := p.claim(db, now, lock).
+		Where("id IN (?)", subQuery).
+		Scan(ctx, &jobs)
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (p *Puller) pullSkipLocked(ctx context.Context, batch int, lock time.Duration) ([]*job.Job, error) {
+	var jobs []*job.Job
+	err := p.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		now := p.clock.now(ctx, tx)
+		query := p.selectEligible(tx, now, batch)
+		if p.fair {
+			// row locks cannot be taken through window functions
+			query = tx.NewSelect().
+				Model((*jobModel)(nil)).
+				Column("id").
+				Where("id IN (?)", query)
+		}
+		var ids []uuid.UUID
+		err := query.
+			For("UPDATE SKIP LOCKED").
+			Scan(ctx, &ids)
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		return p.claim(tx, now, lock).
+			Where("id IN (?)", bun.In(ids)).
+			Scan(ctx, &jobs)
+	})
+	if err != nil {
+		return nil, err
 	}
+	return jobs, nil
 }
 
 // Pull selects up to batch eligible jobs and transitions them
@@ -40,98 +422,306 @@ func NewPuller(db *bun.DB) *Puller {
 //
 // A job is eligible if:
 //
+//   - queue is one of the configured queues (if any)
+//   - queue is not paused (see QueueController)
+//   - the job matches the configured filter (if any), including
+//     its tenants
+//   - region is empty or the configured region (if any), unless the
+//     job has waited for longer than the region failover
 //   - next_run_at <= now
-//   - status = Pending
+//   - expires_at is NULL or > now
+//   - ordering_key is empty, or no older unexpired Pending,
+//     Scheduled or Processing job with the same ordering_key exists
+//   - every job listed in job_dependencies for the job is Done or
+//     missing
+//   - status = Pending OR status = Scheduled
 //     OR
 //   - status = Processing AND locked_until < now
 //
+// Jobs with higher priority are selected first; jobs of equal
+// priority are selected in next_run_at order, with jobs whose lease
+// expired ordered according to ExpiredOrder. With FairTenants, jobs
+// are first ranked within their tenant and selected round-robin
+// across tenants.
+//
 // Eligible jobs are transitioned to Processing,
 // attempts are incremented,
 // locked_until is set to now + lock,
+// locked_by is set to the configured instance,
+// next_run_at is set to now for jobs whose lease expired, if
+// RequeueExpired is set,
+// version is incremented,
 // updated_at is refreshed.
 //
-// Pull returns the updated job snapshots.
+// Eligible jobs with cancel_requested set, left by a worker whose
+// lease expired, are transitioned to Cancelled instead, with the text
+// of gqs.ErrCancelRequested as last_error, and are not returned.
+//
+// Pull returns the updated job snapshots, with WaitTime set to the
+// time between max(created_at, next_run_at) and the claim.
 //
-// Pull relies on a single UPDATE ... WHERE id IN (subquery)
-// statement with RETURNING to avoid race conditions between
-// selection and state transition.
+// In PullUpdate mode, Pull relies on a single UPDATE ... WHERE id IN
+// (subquery) statement with RETURNING to avoid race conditions between
+// selection and state transition. In PullSkipLocked mode, the selected
+// rows are locked with FOR UPDATE SKIP LOCKED and updated within the
+// same transaction.
+//
+// With SQLite tuning enabled (see SQLiteOptions), Pull uses the
+// PullUpdate statement inside a BEGIN IMMEDIATE transaction regardless
+// of Mode.
 func (p *Puller) Pull(ctx context.Context, batch int, lock time.Duration) ([]*job.Job, error) {
-	now := time.Now()
-	lockUntil := now.Add(lock)
-	subQuery := p.db.NewSelect().
-		Model((*jobModel)(nil)).
-		Column("id").
-		Where("next_run_at <= ?", now).
-		WhereGroup("AND", func(sq *bun.SelectQuery) *bun.SelectQuery {
-			return sq.
-				Where("status = ?", job.Pending).
-				WhereOr("status = ? AND locked_until < ?", job.Processing, now)
-		}).
-		Order("next_run_at ASC").
-		Limit(batch)
-	var jobs []*job.Job
-	err := p.db.NewUpdate().
-		Model((*jobModel)(nil)).
-		Set("status = ?", job.Processing).
-		Set("attempts = attempts + 1").
-		Set("locked_until = ?", lockUntil).
-		Set("updated_at = ?", now).
-		Where("id IN (?)", subQuery).
-		Returning("*").
-		Scan(ctx, &jobs)
+	jobs, err := p.pull(ctx, batch, lock)
 	if err != nil {
-		return nil, err
+		return nil, wrap("pull", uuid.Nil, err)
+	}
+	for _, jb := range jobs {
+		eligible := jb.CreatedAt
+		if jb.NextRunAt.After(eligible) {
+			eligible = jb.NextRunAt
+		}
+		// updated_at holds the claim time
+		jb.WaitTime = max(jb.UpdatedAt.Sub(eligible), 0)
 	}
 	return jobs, nil
 }
 
-// ExtendLock extends the visibility timeout of a Processing job.
+func (p *Puller) pull(ctx context.Context, batch int, lock time.Duration) ([]*job.Job, error) {
+	for {
+		jobs, err := p.claimBatch(ctx, batch, lock)
+		if err != nil {
+			return nil, err
+		}
+		ret := slices.DeleteFunc(jobs, func(jb *job.Job) bool {
+			return jb.Status == job.Cancelled
+		})
+		// an empty result must mean that no job is eligible
+		if len(ret) != 0 || len(jobs) == 0 {
+			return ret, nil
+		}
+	}
+}
+
+func (p *Puller) claimBatch(ctx context.Context, batch int, lock time.Duration) ([]*job.Job, error) {
+	if p.sqlite != nil {
+		return tuned(ctx, p, func(ctx context.Context) ([]*job.Job, error) {
+			return p.pullImmediate(ctx, batch, lock)
+		})
+	}
+	if p.mode == PullSkipLocked {
+		return p.pullSkipLocked(ctx, batch, lock)
+	}
+	return p.pullUpdate(ctx, p.db, batch, lock)
+}
+
+// PullStream returns an iterator claiming eligible jobs one at a time.
 //
-// The job must currently be in Processing state.
-// If no rows are affected, ErrLockLost is returned.
+// Each step performs Pull with a batch of one, so jobs are claimed with
+// the same eligibility rules and ordering as Pull. The iteration ends
+// once no eligible job is left.
+func (p *Puller) PullStream(ctx context.Context, lock time.Duration) iter.Seq2[*job.Job, error] {
+	return func(yield func(*job.Job, error) bool) {
+		for ctx.Err() == nil {
+			jobs, err := p.Pull(ctx, 1, lock)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if len(jobs) == 0 || !yield(jobs[0], nil) {
+				return
+			}
+		}
+	}
+}
+
+// ExtendLock extends the visibility timeout of a Processing job.
 //
-// ExtendLock updates locked_until and updated_at.
+// The job must currently be in Processing state and still have the
+// version of jb, so only the worker holding the latest claim can
+// extend it. If no rows are affected, ErrLockLost is returned, or
+// ErrCancelRequested if the job was canceled with Admin.Cancel.
 //
-// This method does not guarantee exclusive ownership;
-// it only ensures the row was still Processing at update time.
+// ExtendLock updates locked_until and updated_at and increments
+// version.
 func (p *Puller) ExtendLock(ctx context.Context, jb *job.Job, lock time.Duration) error {
-	now := time.Now()
+	return wrap("extend lock", jb.Id, p.write(ctx, func(ctx context.Context) error {
+		return p.extendLock(ctx, jb, lock)
+	}))
+}
+
+func (p *Puller) extendLock(ctx context.Context, jb *job.Job, lock time.Duration) error {
+	now := p.clock.now(ctx, p.db)
 	newLock := now.Add(lock)
 	res, err := p.db.NewUpdate().
 		Model((*jobModel)(nil)).
 		Set("locked_until = ?", newLock).
+		Set("version = version + 1").
 		Set("updated_at = ?", now).
 		Where("id = ?", jb.Id).
 		Where("status = ?", job.Processing).
+		Where("version = ?", jb.Version).
+		Where("cancel_requested = ?", false).
 		Exec(ctx)
 	if err != nil {
 		return err
 	}
 	if !isAffected(res) {
+		canceled, err := p.cancelRequested(ctx, []*job.Job{jb})
+		if err != nil {
+			return err
+		}
+		if len(canceled) > 0 {
+			jb.CancelRequested = true
+			return gqs.ErrCancelRequested
+		}
 		return gqs.ErrLockLost
 	}
 	jb.UpdatedAt = now
 	jb.LockedUntil = &newLock
 	jb.Status = job.Processing
+	jb.Version++
 	return nil
 }
 
-// Complete transitions a Processing job to Done state.
-//
-// The job must currently be in Processing state.
-// If the update affects no rows, ErrCompleteFailed is returned.
+// cancelRequested returns the ids of those of jobs that are still
+// held by their snapshots and whose cancellation was requested. The
+// cancellation request does not change the version of a Processing
+// job, so that it is delivered to the worker holding it.
+func (p *Puller) cancelRequested(ctx context.Context, jobs []*job.Job) ([]uuid.UUID, error) {
+	var ret []uuid.UUID
+	err := p.db.NewSelect().
+		Model((*jobModel)(nil)).
+		Column("id").
+		Where("id IN (?)", bun.In(jobIds(jobs))).
+		Where("status = ?", job.Processing).
+		Where("cancel_requested = ?", true).
+		ApplyQueryBuilder(matchVersions(jobs)).
+		Scan(ctx, &ret)
+	return ret, err
+}
+
+func jobIds(jobs []*job.Job) []uuid.UUID {
+	ret := make([]uuid.UUID, len(jobs))
+	for i, jb := range jobs {
+		ret[i] = jb.Id
+	}
+	return ret
+}
+
+// matchVersions restricts a query to the rows whose version equals the
+// version of the snapshot in jobs with the same id.
+func matchVersions(jobs []*job.Job) func(bun.QueryBuilder) bun.QueryBuilder {
+	var expr strings.Builder
+	args := make([]any, 0, 2*len(jobs))
+	expr.WriteString("version = CASE id")
+	for _, jb := range jobs {
+		expr.WriteString(" WHEN ? THEN ?")
+		args = append(args, jb.Id, jb.Version)
+	}
+	expr.WriteString(" END")
+	return func(qb bun.QueryBuilder) bun.QueryBuilder {
+		return qb.Where(expr.String(), args...)
+	}
+}
+
+// ExtendLockBatch extends the visibility timeout of several Processing
+// jobs using a single UPDATE ... WHERE id IN statement.
 //
-// Complete clears locked_until and updates updated_at.
-func (p *Puller) Complete(ctx context.Context, jb *job.Job) error {
-	now := time.Now()
-	res, err := p.db.NewUpdate().
+// Jobs that are no longer Processing at the version of their snapshot
+// are reported with ErrLockLost,
+// and canceled jobs with ErrCancelRequested.
+// Snapshots of extended jobs are updated in place, as in ExtendLock.
+func (p *Puller) ExtendLockBatch(ctx context.Context, jobs []*job.Job, lock time.Duration) ([]error, error) {
+	errs, err := tuned(ctx, p, func(ctx context.Context) ([]error, error) {
+		return p.extendLockBatch(ctx, jobs, lock)
+	})
+	return errs, wrap("extend lock", uuid.Nil, err)
+}
+
+func (p *Puller) extendLockBatch(ctx context.Context, jobs []*job.Job, lock time.Duration) ([]error, error) {
+	now := p.clock.now(ctx, p.db)
+	newLock := now.Add(lock)
+	query := p.db.NewUpdate().
+		Model((*jobModel)(nil)).
+		Set("locked_until = ?", newLock).
+		Set("updated_at = ?", now).
+		Where("cancel_requested = ?", false)
+	ret, err := updateBatch(ctx, query, jobs, gqs.ErrLockLost)
+	if err != nil {
+		return nil, err
+	}
+	var lost []*job.Job
+	for i, jb := range jobs {
+		if ret[i] != nil {
+			lost = append(lost, jb)
+		}
+	}
+	if len(lost) > 0 {
+		canceled, err := p.cancelRequested(ctx, lost)
+		if err != nil {
+			return nil, err
+		}
+		for i, jb := range jobs {
+			if slices.Contains(canceled, jb.Id) {
+				jb.CancelRequested = true
+				ret[i] = gqs.ErrCancelRequested
+			}
+		}
+	}
+	for i, jb := range jobs {
+		if ret[i] == nil {
+			jb.UpdatedAt = now
+			jb.LockedUntil = &newLock
+			jb.Status = job.Processing
+		}
+	}
+	return ret, nil
+}
+
+// updateBatch applies query to those of jobs that are Processing at
+// the version of their snapshots and reports lost for the others. The
+// versions of updated snapshots are advanced.
+func updateBatch(ctx context.Context, query *bun.UpdateQuery, jobs []*job.Job, lost error) ([]error, error) {
+	var updated []uuid.UUID
+	err := query.
+		Set("version = version + 1").
+		Where("id IN (?)", bun.In(jobIds(jobs))).
+		Where("status = ?", job.Processing).
+		ApplyQueryBuilder(matchVersions(jobs)).
+		Returning("id").
+		Scan(ctx, &updated)
+	if err != nil {
+		return nil, err
+	}
+	set := make(map[uuid.UUID]struct{}, len(updated))
+	for _, id := range updated {
+		set[id] = struct{}{}
+	}
+	ret := make([]error, len(jobs))
+	for i, jb := range jobs {
+		if _, ok := set[jb.Id]; !ok {
+			ret[i] = lost
+			continue
+		}
+		jb.Version++
+	}
+	return ret, nil
+}
+
+func (p *Puller) complete(ctx context.Context, db bun.IDB, jb *job.Job, result []byte, withResult bool) error {
+	now := p.clock.now(ctx, db)
+	query := db.NewUpdate().
 		Model((*jobModel)(nil)).
 		Set("status = ?", job.Done).
 		Set("locked_until = NULL").
+		Set("version = version + 1").
 		Set("updated_at = ?", now).
 		Where("id = ?", jb.Id).
 		Where("status = ?", job.Processing).
-		Exec(ctx)
+		Where("version = ?", jb.Version)
+	if withResult {
+		query.Set("result = ?", result)
+	}
+	res, err := query.Exec(ctx)
 	if err != nil {
 		return err
 	}
@@ -141,30 +731,101 @@ func (p *Puller) Complete(ctx context.Context, jb *job.Job) error {
 	jb.Status = job.Done
 	jb.LockedUntil = nil
 	jb.UpdatedAt = now
+	jb.Version++
+	if withResult {
+		jb.Result = result
+	}
 	return nil
 }
 
-// Return reschedules a Processing job back to Pending state.
+// Complete transitions a Processing job to Done state.
+//
+// The job must currently be in Processing state at the version of jb.
+// If the update affects no rows, ErrCompleteFailed is returned.
+//
+// Complete clears locked_until and updates updated_at.
+func (p *Puller) Complete(ctx context.Context, jb *job.Job) error {
+	return wrap("complete", jb.Id, p.write(ctx, func(ctx context.Context) error {
+		return p.complete(ctx, p.db, jb, nil, false)
+	}))
+}
+
+// CompleteWithResult behaves like Complete and additionally stores
+// result in the result column of the job.
+func (p *Puller) CompleteWithResult(ctx context.Context, jb *job.Job, result []byte) error {
+	return wrap("complete", jb.Id, p.write(ctx, func(ctx context.Context) error {
+		return p.complete(ctx, p.db, jb, result, true)
+	}))
+}
+
+// CompleteAndPush behaves like Complete and additionally inserts the
+// messages of next, within a single transaction. If jb.Result is not
+// nil, it is stored in the result column of the job.
+//
+// If the job is no longer Processing, ErrCompleteFailed is returned
+// and no message is inserted.
+func (p *Puller) CompleteAndPush(ctx context.Context, jb *job.Job, next []gqs.Continuation) error {
+	return wrap("complete", jb.Id, p.write(ctx, func(ctx context.Context) error {
+		return p.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			if err := p.complete(ctx, tx, jb, jb.Result, jb.Result != nil); err != nil {
+				return err
+			}
+			if len(next) == 0 {
+				return nil
+			}
+			now := p.clock.now(ctx, tx)
+			models := make([]*jobModel, len(next))
+			for i, cont := range next {
+				models[i] = fromMessage(cont.Message, now, cont.Delay)
+			}
+			_, err := tx.NewInsert().
+				Model(&models).
+				Exec(ctx)
+			if err != nil {
+				return err
+			}
+			return insertDependencies(ctx, tx, models, false)
+		})
+	}))
+}
+
+// Return reschedules a Processing job back to Pending state, or to
+// Scheduled if backoff is positive.
 //
 // next_run_at is set to now + backoff.
+// priority is set to the job's current Priority.
+// last_error is set to the job's current LastError.
 // locked_until is cleared.
+// version is incremented.
 // updated_at is refreshed.
 //
-// If the update affects no rows, ErrJobLost is returned.
+// If the job is no longer Processing at the version of jb,
+// ErrJobLost is returned.
 //
 // Return is typically used after handler failure when
 // retry attempts to remain.
 func (p *Puller) Return(ctx context.Context, jb *job.Job, backoff time.Duration) error {
-	now := time.Now()
+	return wrap("return", jb.Id, p.write(ctx, func(ctx context.Context) error {
+		return p.returnJob(ctx, jb, backoff)
+	}))
+}
+
+func (p *Puller) returnJob(ctx context.Context, jb *job.Job, backoff time.Duration) error {
+	now := p.clock.now(ctx, p.db)
 	nextRun := now.Add(backoff)
+	status := waitStatus(now, nextRun)
 	res, err := p.db.NewUpdate().
 		Model((*jobModel)(nil)).
-		Set("status = ?", job.Pending).
+		Set("status = ?", status).
 		Set("next_run_at = ?", nextRun).
+		Set("priority = ?", jb.Priority).
+		Set("last_error = ?", jb.LastError).
 		Set("locked_until = NULL").
+		Set("version = version + 1").
 		Set("updated_at = ?", now).
 		Where("id = ?", jb.Id).
 		Where("status = ?", job.Processing).
+		Where("version = ?", jb.Version).
 		Exec(ctx)
 	if err != nil {
 		return err
@@ -172,16 +833,292 @@ func (p *Puller) Return(ctx context.Context, jb *job.Job, backoff time.Duration)
 	if !isAffected(res) {
 		return gqs.ErrJobLost
 	}
-	jb.Status = job.Pending
+	jb.Status = status
 	jb.NextRunAt = nextRun
 	jb.LockedUntil = nil
 	jb.UpdatedAt = now
+	jb.Version++
+	return nil
+}
+
+// CompleteBatch transitions several Processing jobs to Done state using
+// a single UPDATE ... WHERE id IN statement, as Complete does for one.
+//
+// Jobs that are no longer Processing at the version of their snapshot
+// are reported with ErrCompleteFailed.
+func (p *Puller) CompleteBatch(ctx context.Context, jobs []*job.Job) ([]error, error) {
+	errs, err := tuned(ctx, p, func(ctx context.Context) ([]error, error) {
+		return p.completeBatch(ctx, jobs)
+	})
+	return errs, wrap("complete", uuid.Nil, err)
+}
+
+func (p *Puller) completeBatch(ctx context.Context, jobs []*job.Job) ([]error, error) {
+	now := p.clock.now(ctx, p.db)
+	query := p.db.NewUpdate().
+		Model((*jobModel)(nil)).
+		Set("status = ?", job.Done).
+		Set("locked_until = NULL").
+		Set("updated_at = ?", now)
+	ret, err := updateBatch(ctx, query, jobs, gqs.ErrCompleteFailed)
+	if err != nil {
+		return nil, err
+	}
+	for i, jb := range jobs {
+		if ret[i] == nil {
+			jb.Status = job.Done
+			jb.LockedUntil = nil
+			jb.UpdatedAt = now
+		}
+	}
+	return ret, nil
+}
+
+// ReturnBatch reschedules several Processing jobs back to Pending or
+// Scheduled state using a single UPDATE ... WHERE id IN statement, as
+// Return does for one. Per-job status, next_run_at, priority and
+// last_error are set with CASE expressions.
+//
+// Jobs that are no longer Processing at the version of their snapshot
+// are reported with ErrJobLost.
+func (p *Puller) ReturnBatch(ctx context.Context, jobs []*job.Job, backoffs []time.Duration) ([]error, error) {
+	errs, err := tuned(ctx, p, func(ctx context.Context) ([]error, error) {
+		return p.returnBatch(ctx, jobs, backoffs)
+	})
+	return errs, wrap("return", uuid.Nil, err)
+}
+
+func (p *Puller) returnBatch(ctx context.Context, jobs []*job.Job, backoffs []time.Duration) ([]error, error) {
+	now := p.clock.now(ctx, p.db)
+	// PostgreSQL types bare literals of a CASE as text
+	stamp := "?"
+	if p.db.Dialect().Name() == dialect.PG {
+		stamp = "CAST(? AS TIMESTAMPTZ)"
+	}
+	nextRuns := make([]time.Time, len(jobs))
+	var statusExpr, nextExpr, prioExpr, errExpr strings.Builder
+	var statusArgs, nextArgs, prioArgs, errArgs []any
+	statusExpr.WriteString("status = CASE id")
+	nextExpr.WriteString("next_run_at = CASE id")
+	prioExpr.WriteString("priority = CASE id")
+	errExpr.WriteString("last_error = CASE id")
+	for i, jb := range jobs {
+		nextRuns[i] = now.Add(backoffs[i])
+		statusExpr.WriteString(" WHEN ? THEN ?")
+		statusArgs = append(statusArgs, jb.Id, waitStatus(now, nextRuns[i]))
+		nextExpr.WriteString(" WHEN ? THEN " + stamp)
+		nextArgs = append(nextArgs, jb.Id, nextRuns[i])
+		prioExpr.WriteString(" WHEN ? THEN ?")
+		prioArgs = append(prioArgs, jb.Id, jb.Priority)
+		errExpr.WriteString(" WHEN ? THEN ?")
+		errArgs = append(errArgs, jb.Id, jb.LastError)
+	}
+	statusExpr.WriteString(" END")
+	nextExpr.WriteString(" END")
+	prioExpr.WriteString(" END")
+	errExpr.WriteString(" END")
+	query := p.db.NewUpdate().
+		Model((*jobModel)(nil)).
+		Set(statusExpr.String(), statusArgs...).
+		Set(nextExpr.String(), nextArgs...).
+		Set(prioExpr.String(), prioArgs...).
+		Set(errExpr.String(), errArgs...).
+		Set("locked_until = NULL").
+		Set("updated_at = ?", now)
+	ret, err := updateBatch(ctx, query, jobs, gqs.ErrJobLost)
+	if err != nil {
+		return nil, err
+	}
+	for i, jb := range jobs {
+		if ret[i] == nil {
+			jb.Status = waitStatus(now, nextRuns[i])
+			jb.NextRunAt = nextRuns[i]
+			jb.LockedUntil = nil
+			jb.UpdatedAt = now
+		}
+	}
+	return ret, nil
+}
+
+// Release reschedules a Processing job back to Pending state
+// without counting the current attempt.
+//
+// next_run_at is set to now.
+// attempts is decremented.
+// locked_until is cleared.
+// version is incremented.
+// updated_at is refreshed.
+//
+// If the job is no longer Processing at the version of jb,
+// ErrJobLost is returned.
+func (p *Puller) Release(ctx context.Context, jb *job.Job) error {
+	return wrap("release", jb.Id, p.write(ctx, func(ctx context.Context) error {
+		return p.release(ctx, jb)
+	}))
+}
+
+func (p *Puller) release(ctx context.Context, jb *job.Job) error {
+	now := p.clock.now(ctx, p.db)
+	res, err := p.db.NewUpdate().
+		Model((*jobModel)(nil)).
+		Set("status = ?", job.Pending).
+		Set("next_run_at = ?", now).
+		Set("attempts = attempts - 1").
+		Set("locked_until = NULL").
+		Set("version = version + 1").
+		Set("updated_at = ?", now).
+		Where("id = ?", jb.Id).
+		Where("status = ?", job.Processing).
+		Where("version = ?", jb.Version).
+		Where("attempts > 0").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if !isAffected(res) {
+		return gqs.ErrJobLost
+	}
+	jb.Status = job.Pending
+	jb.NextRunAt = now
+	jb.Attempts--
+	jb.LockedUntil = nil
+	jb.UpdatedAt = now
+	jb.Version++
 	return nil
 }
 
-// Kill transitions a job to Dead state.
+// SaveLogs replaces the stored log lines of a Processing job with
+// jb.Logs.
 //
-// The job must be in Pending or Processing state.
+// updated_at is not modified, version is incremented.
+//
+// If the job is no longer Processing at the version of jb,
+// ErrJobLost is returned.
+func (p *Puller) SaveLogs(ctx context.Context, jb *job.Job) error {
+	return wrap("save logs", jb.Id, p.write(ctx, func(ctx context.Context) error {
+		return p.saveLogs(ctx, jb)
+	}))
+}
+
+func (p *Puller) saveLogs(ctx context.Context, jb *job.Job) error {
+	res, err := p.db.NewUpdate().
+		Model(&jobModel{Logs: jb.Logs}).
+		Column("logs", "version").
+		Value("version", "version + 1").
+		Where("id = ?", jb.Id).
+		Where("status = ?", job.Processing).
+		Where("version = ?", jb.Version).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if !isAffected(res) {
+		return gqs.ErrJobLost
+	}
+	jb.Version++
+	return nil
+}
+
+// SaveDiagnostics replaces the stored diagnostics of a Processing job
+// with jb.Diagnostics.
+//
+// updated_at is not modified, version is incremented.
+//
+// If the job is no longer Processing at the version of jb,
+// ErrJobLost is returned.
+func (p *Puller) SaveDiagnostics(ctx context.Context, jb *job.Job) error {
+	return wrap("save diagnostics", jb.Id, p.write(ctx, func(ctx context.Context) error {
+		return p.saveDiagnostics(ctx, jb)
+	}))
+}
+
+func (p *Puller) saveDiagnostics(ctx context.Context, jb *job.Job) error {
+	res, err := p.db.NewUpdate().
+		Model(&jobModel{Diagnostics: jb.Diagnostics}).
+		Column("diagnostics", "version").
+		Value("version", "version + 1").
+		Where("id = ?", jb.Id).
+		Where("status = ?", job.Processing).
+		Where("version = ?", jb.Version).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if !isAffected(res) {
+		return gqs.ErrJobLost
+	}
+	jb.Version++
+	return nil
+}
+
+// RecordLockLoss increments lock_losses of the job regardless of its
+// status. If penalty is positive and the job is waiting to be pulled,
+// next_run_at is moved to at least now + penalty * 2^(lock_losses-1)
+// and the job becomes Scheduled.
+//
+// The read and both updates are performed within one transaction.
+// The version of a Processing job is left unchanged, as it is held by
+// another worker that pulled the job again.
+//
+// If the job does not exist, ErrJobLost is returned.
+func (p *Puller) RecordLockLoss(ctx context.Context, jb *job.Job, penalty time.Duration) error {
+	return wrap("record lock loss", jb.Id, p.write(ctx, func(ctx context.Context) error {
+		return p.recordLockLoss(ctx, jb, penalty)
+	}))
+}
+
+func (p *Puller) recordLockLoss(ctx context.Context, jb *job.Job, penalty time.Duration) error {
+	return p.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		res, err := tx.NewUpdate().
+			Model((*jobModel)(nil)).
+			Set("lock_losses = lock_losses + 1").
+			// the job may have been pulled again by another worker,
+			// whose snapshot must stay valid
+			Set("version = CASE WHEN status = ? THEN version ELSE version + 1 END", job.Processing).
+			Where("id = ?", jb.Id).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if !isAffected(res) {
+			return gqs.ErrJobLost
+		}
+		model := &jobModel{}
+		err = tx.NewSelect().
+			Model(model).
+			Column("status", "lock_losses", "next_run_at").
+			Where("id = ?", jb.Id).
+			Scan(ctx)
+		if err != nil {
+			return err
+		}
+		jb.LockLosses = model.LockLosses
+		if penalty <= 0 || !model.Status.Waiting() {
+			return nil
+		}
+		delay := penalty << min(model.LockLosses-1, 16)
+		next := p.clock.now(ctx, tx).Add(delay)
+		if !next.After(model.NextRunAt) {
+			return nil
+		}
+		_, err = tx.NewUpdate().
+			Model((*jobModel)(nil)).
+			Set("next_run_at = ?", next).
+			Set("status = ?", job.Scheduled).
+			Set("version = version + 1").
+			Where("id = ?", jb.Id).
+			Where("status IN (?)", bun.In(waiting)).
+			Exec(ctx)
+		return err
+	})
+}
+
+// Kill transitions a job to Dead state, or to Cancelled if the job
+// has CancelRequested set.
+//
+// The job must be in Pending, Scheduled or Processing state at the
+// version of jb.
+// last_error is set to the job's current LastError.
 // locked_until is cleared.
 // updated_at is refreshed.
 //
@@ -189,14 +1126,27 @@ func (p *Puller) Return(ctx context.Context, jb *job.Job, backoff time.Duration)
 //
 // Kill is typically used when retry limits are exceeded.
 func (p *Puller) Kill(ctx context.Context, jb *job.Job) error {
-	now := time.Now()
+	return wrap("kill", jb.Id, p.write(ctx, func(ctx context.Context) error {
+		return p.kill(ctx, jb)
+	}))
+}
+
+func (p *Puller) kill(ctx context.Context, jb *job.Job) error {
+	now := p.clock.now(ctx, p.db)
+	status := job.Dead
+	if jb.CancelRequested {
+		status = job.Cancelled
+	}
 	res, err := p.db.NewUpdate().
 		Model((*jobModel)(nil)).
-		Set("status = ?", job.Dead).
+		Set("status = ?", status).
+		Set("last_error = ?", jb.LastError).
 		Set("locked_until = NULL").
+		Set("version = version + 1").
 		Set("updated_at = ?", now).
 		Where("id = ?", jb.Id).
-		Where("status IN (?, ?)", job.Pending, job.Processing).
+		Where("status IN (?, ?, ?)", job.Pending, job.Scheduled, job.Processing).
+		Where("version = ?", jb.Version).
 		Exec(ctx)
 	if err != nil {
 		return err
@@ -204,8 +1154,29 @@ func (p *Puller) Kill(ctx context.Context, jb *job.Job) error {
 	if !isAffected(res) {
 		return gqs.ErrJobLost
 	}
-	jb.Status = job.Dead
+	jb.Status = status
 	jb.LockedUntil = nil
 	jb.UpdatedAt = now
+	jb.Version++
 	return nil
 }
+
+// PausedQueues returns the paused queues among the configured queues,
+// or all paused queues if the Puller is not restricted to queues.
+func (p *Puller) PausedQueues(ctx context.Context) ([]string, error) {
+	ret, err := pausedQueues(ctx, p.db, p.queues)
+	return ret, wrap("list paused queues", uuid.Nil, err)
+}
+
+// Queues implements gqs.QueueLister.
+func (p *Puller) Queues() []string {
+	return p.queues
+}
+
+// Capabilities implements gqs.Capable.
+func (p *Puller) Capabilities() gqs.Capability {
+	return gqs.CapBatchExtend | gqs.CapBatchComplete | gqs.CapStream |
+		gqs.CapRelease | gqs.CapResult | gqs.CapLogs | gqs.CapLockLoss |
+		gqs.CapDiagnostics | gqs.CapChain | gqs.CapPause | gqs.CapFilter |
+		gqs.CapOwner
+}
@@ -2,9 +2,12 @@ package sql_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
 	"github.com/romanqed/gqs/job"
 	"github.com/romanqed/gqs/message"
 	gsql "github.com/romanqed/gqs/sql"
@@ -67,8 +70,8 @@ func TestPullAndReturn(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if j.Status != job.Pending {
-		t.Fatalf("expected Pending, got %v", j.Status)
+	if j.Status != job.Scheduled {
+		t.Fatalf("expected Scheduled, got %v", j.Status)
 	}
 }
 
@@ -150,3 +153,658 @@ func TestLeaseExpiration(t *testing.T) {
 		t.Fatal("expected job to be re-acquired after lease expiration")
 	}
 }
+
+func TestStaleSnapshot(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+
+	_ = pusher.Push(ctx, message.NewMessage(), 0)
+
+	jobs, _ := puller.Pull(ctx, 1, time.Millisecond*50)
+	if len(jobs) != 1 {
+		t.Fatal("expected job to be pulled")
+	}
+	stale := jobs[0]
+
+	time.Sleep(time.Millisecond * 80)
+
+	jobs, _ = puller.Pull(ctx, 1, time.Second)
+	if len(jobs) != 1 {
+		t.Fatal("expected job to be re-acquired after lease expiration")
+	}
+	current := jobs[0]
+	if current.Version <= stale.Version {
+		t.Fatalf("expected version to grow, got %d after %d", current.Version, stale.Version)
+	}
+
+	if err := puller.ExtendLock(ctx, stale, time.Second); !errors.Is(err, gqs.ErrLockLost) {
+		t.Fatalf("expected ErrLockLost, got %v", err)
+	}
+	if err := puller.Complete(ctx, stale); !errors.Is(err, gqs.ErrCompleteFailed) {
+		t.Fatalf("expected ErrCompleteFailed, got %v", err)
+	}
+	if err := puller.Return(ctx, stale, 0); !errors.Is(err, gqs.ErrJobLost) {
+		t.Fatalf("expected ErrJobLost, got %v", err)
+	}
+	if err := puller.Kill(ctx, stale); !errors.Is(err, gqs.ErrJobLost) {
+		t.Fatalf("expected ErrJobLost, got %v", err)
+	}
+	errs, err := puller.CompleteBatch(ctx, []*job.Job{stale})
+	if err != nil || !errors.Is(errs[0], gqs.ErrCompleteFailed) {
+		t.Fatalf("expected ErrCompleteFailed, got %v, %v", errs, err)
+	}
+
+	if err := puller.ExtendLock(ctx, current, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := puller.Complete(ctx, current); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPullPriority(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+
+	low := message.NewMessage()
+	high := message.NewMessage()
+	high.Priority = 10
+
+	if err := pusher.Push(ctx, low, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := pusher.Push(ctx, high, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err := puller.Pull(ctx, 1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("expected 1 job, got %d", len(jobs))
+	}
+	if jobs[0].Id != high.Id {
+		t.Fatal("expected high priority job to be pulled first")
+	}
+}
+
+func TestPullScanWindow(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPullerWithOptions(db, &gsql.PullerOptions{ScanWindow: 2})
+
+	var ids []uuid.UUID
+	for i := range 3 {
+		msg := message.NewMessage()
+		// the last job has the highest priority but is outside the window
+		msg.Priority = i * 10
+		if err := pusher.Push(ctx, msg, 0); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, msg.Id)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	jobs, err := puller.Pull(ctx, 1, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].Id != ids[1] {
+		t.Fatal("expected the highest priority job within the window")
+	}
+
+	jobs, err = puller.Pull(ctx, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 2 || jobs[0].Id == ids[1] || jobs[1].Id == ids[1] {
+		t.Fatalf("expected the window to move on, got %d jobs", len(jobs))
+	}
+
+	time.Sleep(80 * time.Millisecond)
+	jobs, err = puller.Pull(ctx, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].Id != ids[1] {
+		t.Fatal("expected the expired job to be pulled again")
+	}
+}
+
+func TestPullExpiredOrder(t *testing.T) {
+	cases := []struct {
+		name  string
+		opts  gsql.PullerOptions
+		first bool
+	}{
+		{"next run", gsql.PullerOptions{}, true},
+		{"expiry", gsql.PullerOptions{ExpiredOrder: gsql.ExpiredByExpiry}, false},
+		{"last", gsql.PullerOptions{ExpiredOrder: gsql.ExpiredLast}, false},
+		{"fair expiry", gsql.PullerOptions{ExpiredOrder: gsql.ExpiredByExpiry, FairTenants: true}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			db := newTestDB(t)
+			ctx := context.Background()
+
+			pusher := gsql.NewPusher(db)
+			opts := c.opts
+			opts.RequeueExpired = true
+			puller := gsql.NewPullerWithOptions(db, &opts)
+
+			crashed := message.NewMessage()
+			if err := pusher.Push(ctx, crashed, 0); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := puller.Pull(ctx, 1, 30*time.Millisecond); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(5 * time.Millisecond)
+			fresh := message.NewMessage()
+			if err := pusher.Push(ctx, fresh, 0); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(40 * time.Millisecond)
+
+			jobs, err := puller.Pull(ctx, 1, time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := fresh.Id
+			if c.first {
+				expected = crashed.Id
+			}
+			if len(jobs) != 1 || jobs[0].Id != expected {
+				t.Fatal("unexpected order of the expired job")
+			}
+
+			jobs, err = puller.Pull(ctx, 1, time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(jobs) != 1 {
+				t.Fatal("expected the other job to be pulled")
+			}
+			if jb := jobs[0]; jb.Id == crashed.Id && !jb.NextRunAt.After(jb.CreatedAt.Add(30*time.Millisecond)) {
+				t.Fatal("expected the expired job to be requeued")
+			}
+		})
+	}
+}
+
+func TestExtendLockBatch(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+
+	for i := 0; i < 2; i++ {
+		if err := pusher.Push(ctx, message.NewMessage(), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	jobs, err := puller.Pull(ctx, 2, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := puller.Complete(ctx, jobs[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	old := jobs[0].LockedUntil
+	errs, err := puller.ExtendLockBatch(ctx, jobs, time.Second*2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs[0] != nil {
+		t.Fatalf("expected lock to be extended, got %v", errs[0])
+	}
+	if !jobs[0].LockedUntil.After(*old) {
+		t.Fatal("lock was not extended")
+	}
+	if !errors.Is(errs[1], gqs.ErrLockLost) {
+		t.Fatalf("expected ErrLockLost, got %v", errs[1])
+	}
+}
+
+func TestPullStream(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	for i := 0; i < 3; i++ {
+		_ = pusher.Push(ctx, message.NewMessage(), 0)
+	}
+
+	count := 0
+	for jb, err := range puller.PullStream(ctx, time.Minute) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if jb.Status != job.Processing {
+			t.Fatalf("expected Processing, got %v", jb.Status)
+		}
+		count++
+		if count == 2 {
+			break
+		}
+	}
+
+	processing, _ := observer.Count(ctx, &gqs.ListOptions{Statuses: []job.Status{job.Processing}})
+	if processing != 2 {
+		t.Fatalf("expected only consumed jobs to be claimed, got %d", processing)
+	}
+
+	for _, err := range puller.PullStream(ctx, time.Minute) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		count++
+	}
+	if count != 3 {
+		t.Fatalf("expected 3 jobs, got %d", count)
+	}
+}
+
+func TestRecordLockLoss(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+
+	jobs, _ := puller.Pull(ctx, 1, time.Minute)
+	jb := jobs[0]
+	if err := puller.Return(ctx, jb, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		if err := puller.RecordLockLoss(ctx, jb, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		if jb.LockLosses != uint32(i) {
+			t.Fatalf("expected %d lock losses, got %d", i, jb.LockLosses)
+		}
+	}
+
+	j, _ := observer.Get(ctx, msg.Id)
+	if j.LockLosses != 2 {
+		t.Fatalf("expected stored lock losses, got %d", j.LockLosses)
+	}
+	// the second loss doubles the penalty
+	if j.NextRunAt.Before(time.Now().Add(time.Minute + 30*time.Second)) {
+		t.Fatalf("expected exponential penalty, got next run at %v", j.NextRunAt)
+	}
+
+	missing := &job.Job{Message: *message.NewMessage()}
+	if err := puller.RecordLockLoss(ctx, missing, 0); !errors.Is(err, gqs.ErrJobLost) {
+		t.Fatalf("expected ErrJobLost, got %v", err)
+	}
+}
+
+func TestRecordLockLossKeepsNewOwner(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+
+	_ = pusher.Push(ctx, message.NewMessage(), 0)
+
+	jobs, _ := puller.Pull(ctx, 1, time.Millisecond)
+	lost := jobs[0]
+	time.Sleep(10 * time.Millisecond)
+	jobs, err := puller.Pull(ctx, 1, time.Minute)
+	if err != nil || len(jobs) != 1 {
+		t.Fatalf("expected the expired job pulled again, got %d (%v)", len(jobs), err)
+	}
+	owner := jobs[0]
+
+	if err := puller.ExtendLock(ctx, lost, time.Minute); !errors.Is(err, gqs.ErrLockLost) {
+		t.Fatalf("expected ErrLockLost, got %v", err)
+	}
+	if err := puller.RecordLockLoss(ctx, lost, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := puller.ExtendLock(ctx, owner, time.Minute); err != nil {
+		t.Fatalf("expected the new owner to keep its lease, got %v", err)
+	}
+	if err := puller.Complete(ctx, owner); err != nil {
+		t.Fatalf("expected the new owner to complete, got %v", err)
+	}
+}
+
+func TestCompleteAndReturnBatch(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	for i := 0; i < 4; i++ {
+		_ = pusher.Push(ctx, message.NewMessage(), 0)
+	}
+	jobs, _ := puller.Pull(ctx, 4, time.Minute)
+	if len(jobs) != 4 {
+		t.Fatalf("expected 4 jobs, got %d", len(jobs))
+	}
+
+	errs, err := puller.CompleteBatch(ctx, jobs[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	jobs[2].Priority = 7
+	backoffs := []time.Duration{time.Hour, 0, time.Minute}
+	errs, err = puller.ReturnBatch(ctx, []*job.Job{jobs[2], jobs[0], jobs[3]}, backoffs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs[0] != nil || errs[2] != nil {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	if !errors.Is(errs[1], gqs.ErrJobLost) {
+		t.Fatalf("expected ErrJobLost for a Done job, got %v", errs[1])
+	}
+
+	j, _ := observer.Get(ctx, jobs[2].Id)
+	if j.Status != job.Scheduled || j.Priority != 7 {
+		t.Fatalf("unexpected returned job %+v", j)
+	}
+	if j.NextRunAt.Before(time.Now().Add(59 * time.Minute)) {
+		t.Fatalf("expected per-job backoff, got %v", j.NextRunAt)
+	}
+	j, _ = observer.Get(ctx, jobs[3].Id)
+	if j.NextRunAt.After(time.Now().Add(2 * time.Minute)) {
+		t.Fatalf("expected per-job backoff, got %v", j.NextRunAt)
+	}
+	j, _ = observer.Get(ctx, jobs[1].Id)
+	if j.Status != job.Done {
+		t.Fatalf("expected Done, got %v", j.Status)
+	}
+}
+
+func TestPullRegion(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	eu := gsql.NewPullerWithOptions(db, &gsql.PullerOptions{Region: "eu"})
+	us := gsql.NewPullerWithOptions(db, &gsql.PullerOptions{
+		Region:         "us",
+		RegionFailover: 200 * time.Millisecond,
+	})
+
+	msg := message.NewMessage()
+	msg.Region = "eu"
+	if err := pusher.Push(ctx, msg, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err := us.Pull(ctx, 1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 0 {
+		t.Fatal("expected job of another region to be skipped")
+	}
+
+	time.Sleep(300 * time.Millisecond)
+
+	jobs, err = us.Pull(ctx, 1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].Region != "eu" {
+		t.Fatal("expected job to fail over to another region")
+	}
+
+	anywhere := message.NewMessage()
+	if err := pusher.Push(ctx, anywhere, 0); err != nil {
+		t.Fatal(err)
+	}
+	jobs, err = eu.Pull(ctx, 1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].Id != anywhere.Id {
+		t.Fatal("expected job without region to be pulled")
+	}
+}
+
+func TestPullerCompleteAndPush(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	msg := message.NewMessage()
+	if err := pusher.Push(ctx, msg, 0); err != nil {
+		t.Fatal(err)
+	}
+	jobs, err := puller.Pull(ctx, 1, time.Second)
+	if err != nil || len(jobs) != 1 {
+		t.Fatal("expected pulled job")
+	}
+	jb := jobs[0]
+
+	first := message.NewMessage()
+	if err := puller.CompleteAndPush(ctx, jb, []gqs.Continuation{{Message: first}}); err != nil {
+		t.Fatal(err)
+	}
+	j, err := observer.Get(ctx, first.Id)
+	if err != nil || j == nil {
+		t.Fatal("expected follow-up to be inserted")
+	}
+
+	second := message.NewMessage()
+	err = puller.CompleteAndPush(ctx, jb, []gqs.Continuation{{Message: second}})
+	if !errors.Is(err, gqs.ErrCompleteFailed) {
+		t.Fatalf("expected ErrCompleteFailed, got %v", err)
+	}
+	j, err = observer.Get(ctx, second.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j != nil {
+		t.Fatal("expected follow-up of failed completion not to be inserted")
+	}
+}
+
+func TestPullOrderingKey(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+
+	var group []*message.Message
+	for i := 0; i < 3; i++ {
+		msg := message.NewMessage()
+		msg.OrderingKey = "account"
+		if err := pusher.Push(ctx, msg, 0); err != nil {
+			t.Fatal(err)
+		}
+		group = append(group, msg)
+		time.Sleep(time.Millisecond)
+	}
+	free := message.NewMessage()
+	if err := pusher.Push(ctx, free, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err := puller.Pull(ctx, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[uuid.UUID]*job.Job)
+	for _, j := range jobs {
+		ids[j.Id] = j
+	}
+	if len(jobs) != 2 || ids[group[0].Id] == nil || ids[free.Id] == nil {
+		t.Fatalf("expected group head and unordered job, got %d jobs", len(jobs))
+	}
+
+	jobs, err = puller.Pull(ctx, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 0 {
+		t.Fatal("expected group to be blocked while its head is processing")
+	}
+
+	if err := puller.Complete(ctx, ids[group[0].Id]); err != nil {
+		t.Fatal(err)
+	}
+	jobs, err = puller.Pull(ctx, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].Id != group[1].Id || jobs[0].OrderingKey != "account" {
+		t.Fatal("expected next job of the group after completion")
+	}
+}
+
+func TestPullFilter(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+
+	for _, kind := range []string{"invoice", "receipt"} {
+		for _, tenant := range []string{"a", "b"} {
+			msg := message.NewMessage()
+			msg.Type = kind
+			msg.Set("tenant", tenant)
+			if err := pusher.Push(ctx, msg, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	puller := gsql.NewPullerWithOptions(db, &gsql.PullerOptions{
+		Filter: &gqs.PullFilter{Types: []string{"invoice"}},
+	})
+	jobs, err := puller.Pull(ctx, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 2 || jobs[0].Type != "invoice" || jobs[1].Type != "invoice" {
+		t.Fatal("expected only jobs of the filtered type")
+	}
+
+	filtered := puller.WithFilter(&gqs.PullFilter{Metadata: map[string]string{"tenant": "b"}})
+	jobs, err = filtered.Pull(ctx, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].Type != "receipt" || jobs[0].Metadata["tenant"] != "b" {
+		t.Fatal("expected only the remaining job of the filtered tenant")
+	}
+
+	urgent := message.NewMessage()
+	urgent.Priority = 5
+	if err := pusher.Push(ctx, urgent, 0); err != nil {
+		t.Fatal(err)
+	}
+	filtered = puller.WithFilter(&gqs.PullFilter{Priority: &gqs.PriorityRange{Min: 1, Max: 10}})
+	jobs, err = filtered.Pull(ctx, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].Id != urgent.Id {
+		t.Fatal("expected only the job within the priority range")
+	}
+}
+
+func TestPullWaitTime(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+
+	msg := message.NewMessage()
+	if err := pusher.Push(ctx, msg, 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(70 * time.Millisecond)
+
+	jobs, err := puller.Pull(ctx, 1, time.Second)
+	if err != nil || len(jobs) != 1 {
+		t.Fatal("expected pulled job")
+	}
+	if wait := jobs[0].WaitTime; wait < 40*time.Millisecond || wait > time.Second {
+		t.Fatalf("expected wait time measured from next_run_at, got %v", wait)
+	}
+}
+
+func TestPullFairTenants(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+
+	// the noisy tenant pushes first and has the most jobs
+	for _, tenant := range []string{"noisy", "noisy", "noisy", "noisy", "a", "b"} {
+		msg := message.NewMessage()
+		msg.TenantId = tenant
+		if err := pusher.Push(ctx, msg, 0); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	puller := gsql.NewPullerWithOptions(db, &gsql.PullerOptions{FairTenants: true})
+	jobs, err := puller.Pull(ctx, 3, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tenants := map[string]int{}
+	for _, jb := range jobs {
+		tenants[jb.TenantId]++
+	}
+	if len(jobs) != 3 || tenants["noisy"] != 1 || tenants["a"] != 1 || tenants["b"] != 1 {
+		t.Fatalf("expected one job of every tenant, got %v", tenants)
+	}
+
+	filtered := puller.WithFilter(&gqs.PullFilter{Tenants: []string{"a", "b"}})
+	jobs, err = filtered.Pull(ctx, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 0 {
+		t.Fatalf("expected no jobs of the filtered tenants left, got %d", len(jobs))
+	}
+	jobs, _ = puller.Pull(ctx, 10, time.Second)
+	if len(jobs) != 3 {
+		t.Fatalf("expected the remaining jobs of the noisy tenant, got %d", len(jobs))
+	}
+}
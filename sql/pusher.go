@@ -2,19 +2,95 @@ package sql
 
 import (
 	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
 	"github.com/romanqed/gqs/message"
 	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/dialect/feature"
+	"reflect"
 	"time"
 )
 
-// Pusher implements gqs.Pusher using a SQL backend.
+var (
+	// ErrUpsertUnsupported is returned when ConflictUpsert is used with
+	// a dialect that does not support INSERT ... ON CONFLICT, or with a
+	// partitioned jobs table.
+	ErrUpsertUnsupported = errors.New("upsert is not supported by dialect")
+)
+
+// ConflictPolicy defines how a Pusher handles a message whose id is
+// already used by a stored job.
+type ConflictPolicy uint8
+
+const (
+	// ConflictError rejects the message with gqs.ErrDuplicateID.
+	ConflictError ConflictPolicy = iota
+
+	// ConflictIgnore keeps the stored job and reports success, which
+	// makes retried pushes idempotent.
+	ConflictIgnore
+
+	// ConflictUpsert replaces the stored job with a new Pending job
+	// built from the message, unless the stored job is Processing, in
+	// which case gqs.ErrDuplicateID is returned. The creation time of
+	// the stored job is kept and its version is incremented, so that
+	// snapshots of the replaced job cannot apply transitions.
+	//
+	// ConflictUpsert requires INSERT ... ON CONFLICT support
+	// (PostgreSQL and SQLite) and a unique id, so it cannot be used
+	// with a partitioned jobs table: such Pushers (see
+	// PusherOptions.Partitioned) return ErrUpsertUnsupported.
+	ConflictUpsert
+)
+
+// Pusher implements gqs.Pusher, gqs.BatchPusher, gqs.SnapshotPusher,
+// gqs.SchedulePusher and gqs.GroupPusher using a SQL backend.
 //
 // Pusher inserts new jobs into storage in the Pending state.
-// It does not perform any deduplication or idempotency checks.
-// The caller is responsible for ensuring that message identifiers
-// are unique if required.
+// Messages whose id is already used by a stored job are handled
+// according to the configured ConflictPolicy; by default they are
+// rejected with gqs.ErrDuplicateID.
 type Pusher struct {
-	db *bun.DB
+	db          *bun.DB
+	conflict    ConflictPolicy
+	clock       *Clock
+	retention   gqs.RetentionConfig
+	queues      map[string]gqs.RetentionConfig
+	partitioned bool
+}
+
+// PusherOptions defines optional behavior of a Pusher.
+//
+// OnConflict defines how messages with an already used id are
+// handled. The zero value is ConflictError. Only conflicts of the id are
+// subject to it; other constraint violations fail the push.
+//
+// Clock, if set, provides the time jobs are created and scheduled at
+// instead of the local clock (see Clock). It should be shared with the
+// Pullers consuming the jobs.
+//
+// Retention is recorded on every pushed job as its own retention (see
+// gqs.RetentionConfig); QueueRetention replaces it for the jobs of the
+// listed queues. Zero values leave jobs to the policy of
+// gqs.CleanWorker.
+//
+// Partitioned must be set if the jobs table was created partitioned
+// (see InitOptions.Partitioning). The primary key of a partitioned
+// table includes the partition key, so it does not reject a duplicate
+// id pushed with another creation time or queue; the Pusher then looks
+// the id up before inserting, serialized by a transaction-scoped
+// advisory lock of the id on PostgreSQL.
+type PusherOptions struct {
+	OnConflict     ConflictPolicy
+	Clock          *Clock
+	Retention      gqs.RetentionConfig
+	QueueRetention map[string]gqs.RetentionConfig
+	Partitioned    bool
 }
 
 // NewPusher creates a new SQL-backed Pusher.
@@ -22,8 +98,19 @@ type Pusher struct {
 // The provided *bun.DB must be properly configured and connected.
 // Schema initialization must be completed before pushing jobs.
 func NewPusher(db *bun.DB) *Pusher {
+	return NewPusherWithOptions(db, &PusherOptions{})
+}
+
+// NewPusherWithOptions creates a new SQL-backed Pusher using the
+// provided options.
+func NewPusherWithOptions(db *bun.DB, opts *PusherOptions) *Pusher {
 	return &Pusher{
-		db: db,
+		db:          db,
+		conflict:    opts.OnConflict,
+		clock:       opts.Clock,
+		retention:   opts.Retention,
+		queues:      opts.QueueRetention,
+		partitioned: opts.Partitioned,
 	}
 }
 
@@ -37,9 +124,242 @@ func NewPusher(db *bun.DB) *Pusher {
 //
 // Push respects the provided context for cancellation.
 func (p *Pusher) Push(ctx context.Context, msg *message.Message, delay time.Duration) error {
-	model := fromMessage(msg, delay)
-	_, err := p.db.NewInsert().
-		Model(model).
-		Exec(ctx)
+	return wrap("push", msg.Id, p.insert(ctx, p.db, fromMessage(msg, p.clock.now(ctx, p.db), delay), false))
+}
+
+// PushAt inserts a new message scheduled for execution at time at.
+//
+// The provided time is stored as the initial NextRunAt timestamp and
+// as the ScheduledAt timestamp of the job.
+func (p *Pusher) PushAt(ctx context.Context, msg *message.Message, at time.Time) error {
+	return wrap("push", msg.Id, p.insert(ctx, p.db, fromMessageAt(msg, p.clock.now(ctx, p.db), at), false))
+}
+
+// PushTx inserts a new message as part of the provided transaction.
+//
+// The job becomes visible to pullers only when tx is committed, and
+// disappears if tx is rolled back. This allows enqueueing a job
+// atomically with the application's own writes (outbox pattern)
+// without a separate outbox table.
+//
+// tx must belong to the same database the Pusher was created for.
+func (p *Pusher) PushTx(ctx context.Context, tx bun.Tx, msg *message.Message, delay time.Duration) error {
+	return wrap("push", msg.Id, p.insert(ctx, tx, fromMessage(msg, p.clock.now(ctx, tx), delay), false))
+}
+
+// PushSnapshot inserts a new message and returns the stored job,
+// read back from the primary by the INSERT ... RETURNING statement.
+//
+// If the message is ignored under ConflictIgnore, the stored job is
+// read back instead.
+func (p *Pusher) PushSnapshot(ctx context.Context, msg *message.Message, delay time.Duration) (*job.Job, error) {
+	model := fromMessage(msg, p.clock.now(ctx, p.db), delay)
+	err := p.insert(ctx, p.db, model, true)
+	if errors.Is(err, errIgnored) {
+		jb, err := get(ctx, p.db, msg.Id)
+		return jb, wrap("get", msg.Id, err)
+	}
+	if err != nil {
+		return nil, wrap("push", msg.Id, err)
+	}
+	return model.toJob(), nil
+}
+
+// errIgnored reports an insert skipped under ConflictIgnore to callers
+// that need to tell it apart from a successful insert.
+var errIgnored = errors.New("insert ignored")
+
+func upsertColumns(db bun.IDB) []string {
+	table := db.Dialect().Tables().Get(reflect.TypeFor[jobModel]())
+	ret := make([]string, 0, len(table.Fields))
+	for _, field := range table.Fields {
+		if field.Name == "id" || field.Name == "created_at" || field.Name == "version" {
+			continue
+		}
+		ret = append(ret, field.Name)
+	}
+	return ret
+}
+
+func (p *Pusher) insert(ctx context.Context, db bun.IDB, model *jobModel, returning bool) error {
+	retention, ok := p.queues[model.Queue]
+	if !ok {
+		retention = p.retention
+	}
+	model.RetainDone = retention.Done
+	model.RetainDead = retention.Dead
+	model.RetainCancelled = retention.Cancelled
+	var err error
+	if len(model.DependsOn) == 0 && p.conflict != ConflictUpsert && !p.partitioned {
+		err = p.insertJob(ctx, db, model, returning)
+	} else {
+		// the job must not become eligible before its dependencies are
+		// recorded, an upserted job must not keep the old ones, and the
+		// id must be looked up and inserted atomically
+		err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			if err := p.insertJob(ctx, tx, model, returning); err != nil {
+				return err
+			}
+			return insertDependencies(ctx, tx, []*jobModel{model}, p.conflict == ConflictUpsert)
+		})
+	}
+	if errors.Is(err, errIgnored) && !returning {
+		return nil
+	}
 	return err
 }
+
+// insertJob inserts the job of model, returning errIgnored if it is
+// skipped under ConflictIgnore.
+func (p *Pusher) insertJob(ctx context.Context, db bun.IDB, model *jobModel, returning bool) error {
+	if p.partitioned {
+		if err := p.checkDuplicate(ctx, db, model.Id); err != nil {
+			return err
+		}
+	}
+	query := db.NewInsert().
+		Model(model)
+	if returning {
+		query.Returning("*")
+	}
+	switch p.conflict {
+	case ConflictUpsert:
+		if !db.Dialect().Features().Has(feature.InsertOnConflict) {
+			return ErrUpsertUnsupported
+		}
+		query.On("CONFLICT (id) DO UPDATE")
+		for _, column := range upsertColumns(db) {
+			query.Set("? = EXCLUDED.?", bun.Ident(column), bun.Ident(column))
+		}
+		// snapshots of the replaced job must stay stale
+		query.Set("version = ?TableAlias.version + 1")
+		query.Where("?TableAlias.status != ?", job.Processing)
+	default:
+		// only conflicts of the id are ignored, other violations fail
+		switch features := db.Dialect().Features(); {
+		case features.Has(feature.InsertOnConflict):
+			query.On("CONFLICT (id) DO NOTHING")
+		case features.Has(feature.InsertOnDuplicateKey):
+			query.On("DUPLICATE KEY UPDATE").Set("id = id")
+		default:
+			query.Ignore()
+		}
+	}
+	res, err := query.Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if isAffected(res) {
+		return nil
+	}
+	if p.conflict == ConflictIgnore {
+		return errIgnored
+	}
+	return fmt.Errorf("%w: %s", gqs.ErrDuplicateID, model.Id)
+}
+
+// checkDuplicate reports an existing job with the id according to the
+// ConflictPolicy. It must be called within the transaction inserting
+// the job, which the advisory lock is held by until it ends.
+func (p *Pusher) checkDuplicate(ctx context.Context, db bun.IDB, id uuid.UUID) error {
+	if p.conflict == ConflictUpsert {
+		return ErrUpsertUnsupported
+	}
+	if db.Dialect().Name() == dialect.PG {
+		key := int64(binary.BigEndian.Uint64(id[:8]))
+		if _, err := db.ExecContext(ctx, "SELECT pg_advisory_xact_lock(?)", key); err != nil {
+			return err
+		}
+	}
+	exists, err := db.NewSelect().
+		Model((*jobModel)(nil)).
+		Where("id = ?", id).
+		Exists(ctx)
+	if err != nil || !exists {
+		return err
+	}
+	if p.conflict == ConflictIgnore {
+		return errIgnored
+	}
+	return fmt.Errorf("%w: %s", gqs.ErrDuplicateID, id)
+}
+
+func (p *Pusher) pushAtomic(ctx context.Context, msgs []*message.Message, delay time.Duration, ret []gqs.PushResult) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		for i := range ret {
+			ret[i].Err = err
+		}
+		return err
+	}
+	for i, msg := range msgs {
+		err := p.insert(ctx, tx, fromMessage(msg, p.clock.now(ctx, tx), delay), false)
+		if err == nil {
+			continue
+		}
+		for j := range ret {
+			ret[j].Err = gqs.ErrBatchAborted
+		}
+		ret[i].Err = err
+		return errors.Join(err, tx.Rollback())
+	}
+	if err := tx.Commit(); err != nil {
+		for i := range ret {
+			ret[i].Err = err
+		}
+		return err
+	}
+	return nil
+}
+
+func (p *Pusher) pushEach(ctx context.Context, msgs []*message.Message, delay time.Duration, ret []gqs.PushResult) error {
+	for i, msg := range msgs {
+		if err := ctx.Err(); err != nil {
+			for j := i; j < len(ret); j++ {
+				ret[j].Err = err
+			}
+			return err
+		}
+		ret[i].Err = p.Push(ctx, msg, delay)
+	}
+	return nil
+}
+
+// PushBatch inserts msgs into storage, reporting the outcome per message.
+//
+// In gqs.BatchAtomic mode all rows are inserted inside a single
+// transaction. The first failing insert rolls back the whole batch:
+// its result carries the insert error, all other results carry
+// gqs.ErrBatchAborted, and the insert error is returned.
+//
+// In gqs.BatchContinueOnError mode every message is inserted
+// independently. Failed inserts are reported only through results.
+// If ctx is canceled midway, the remaining messages are reported
+// with the context error, which is also returned.
+//
+// Duplicate ids are handled according to the ConflictPolicy; in
+// gqs.BatchAtomic mode a rejected duplicate aborts the batch.
+func (p *Pusher) PushBatch(ctx context.Context, msgs []*message.Message, delay time.Duration, mode gqs.BatchMode) ([]gqs.PushResult, error) {
+	ret := make([]gqs.PushResult, len(msgs))
+	for i, msg := range msgs {
+		ret[i].Id = msg.Id
+	}
+	if len(msgs) == 0 {
+		return ret, nil
+	}
+	var err error
+	if mode == gqs.BatchContinueOnError {
+		err = p.pushEach(ctx, msgs, delay, ret)
+	} else {
+		err = p.pushAtomic(ctx, msgs, delay, ret)
+	}
+	for i := range ret {
+		ret[i].Err = wrap("push", ret[i].Id, ret[i].Err)
+	}
+	return ret, wrap("push", uuid.Nil, err)
+}
+
+// Capabilities implements gqs.Capable.
+func (p *Pusher) Capabilities() gqs.Capability {
+	return gqs.CapBatchPush | gqs.CapSnapshotPush | gqs.CapSchedulePush | gqs.CapGroupPush
+}
@@ -0,0 +1,311 @@
+package sql_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestPushBatchAtomic(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	observer := gsql.NewObserver(db)
+
+	existing := message.NewMessage()
+	if err := pusher.Push(ctx, existing, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	fresh := message.NewMessage()
+	msgs := []*message.Message{fresh, existing}
+
+	res, err := pusher.PushBatch(ctx, msgs, 0, gqs.BatchAtomic)
+	if err == nil {
+		t.Fatal("expected batch error")
+	}
+	if len(res) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(res))
+	}
+	if !errors.Is(res[0].Err, gqs.ErrBatchAborted) {
+		t.Fatalf("expected ErrBatchAborted, got %v", res[0].Err)
+	}
+	if res[1].Err == nil || errors.Is(res[1].Err, gqs.ErrBatchAborted) {
+		t.Fatalf("expected insert error, got %v", res[1].Err)
+	}
+
+	j, err := observer.Get(ctx, fresh.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j != nil {
+		t.Fatal("expected batch to be rolled back")
+	}
+}
+
+func TestPushBatchContinueOnError(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	observer := gsql.NewObserver(db)
+
+	existing := message.NewMessage()
+	if err := pusher.Push(ctx, existing, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	fresh := message.NewMessage()
+	msgs := []*message.Message{existing, fresh}
+
+	res, err := pusher.PushBatch(ctx, msgs, 0, gqs.BatchContinueOnError)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res[0].Err == nil {
+		t.Fatal("expected duplicate message to fail")
+	}
+	if res[1].Err != nil {
+		t.Fatalf("expected fresh message to succeed, got %v", res[1].Err)
+	}
+
+	j, err := observer.Get(ctx, fresh.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j == nil {
+		t.Fatal("job not found")
+	}
+}
+
+func TestPushTx(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	observer := gsql.NewObserver(db)
+
+	rolledBack := message.NewMessage()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pusher.PushTx(ctx, tx, rolledBack, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	committed := message.NewMessage()
+	tx, err = db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pusher.PushTx(ctx, tx, committed, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := observer.Get(ctx, rolledBack.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j != nil {
+		t.Fatal("expected rolled back job to be absent")
+	}
+
+	j, err = observer.Get(ctx, committed.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j == nil {
+		t.Fatal("expected committed job to be present")
+	}
+}
+
+func TestPushAt(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	at := time.Now().Add(200 * time.Millisecond).Truncate(time.Millisecond)
+	msg := message.NewMessage()
+	if err := pusher.PushAt(ctx, msg, at); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := observer.Get(ctx, msg.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !j.ScheduledAt.Equal(at) || !j.NextRunAt.Equal(at) {
+		t.Fatalf("expected job scheduled at %v, got %v", at, j.ScheduledAt)
+	}
+
+	jobs, err := puller.Pull(ctx, 1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 0 {
+		t.Fatal("expected job not to be pulled before its schedule")
+	}
+
+	time.Sleep(300 * time.Millisecond)
+
+	jobs, err = puller.Pull(ctx, 1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("expected 1 job, got %d", len(jobs))
+	}
+	if err := puller.Return(ctx, jobs[0], time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err = observer.Get(ctx, msg.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !j.ScheduledAt.Equal(at) {
+		t.Fatal("expected scheduled time to survive retries")
+	}
+}
+
+func TestPusherConflict(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	observer := gsql.NewObserver(db)
+	puller := gsql.NewPuller(db)
+
+	msg := message.NewMessage()
+	msg.Type = "first"
+	if err := gsql.NewPusher(db).Push(ctx, msg, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := gsql.NewPusher(db).Push(ctx, msg, 0); !errors.Is(err, gqs.ErrDuplicateID) {
+		t.Fatalf("expected ErrDuplicateID, got %v", err)
+	}
+
+	ignoring := gsql.NewPusherWithOptions(db, &gsql.PusherOptions{OnConflict: gsql.ConflictIgnore})
+	dup := *msg
+	dup.Type = "second"
+	if err := ignoring.Push(ctx, &dup, 0); err != nil {
+		t.Fatal(err)
+	}
+	j, err := ignoring.PushSnapshot(ctx, &dup, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.Type != "first" {
+		t.Fatalf("expected stored job to be kept, got type %q", j.Type)
+	}
+
+	upserting := gsql.NewPusherWithOptions(db, &gsql.PusherOptions{OnConflict: gsql.ConflictUpsert})
+	if err := upserting.Push(ctx, &dup, 0); err != nil {
+		t.Fatal(err)
+	}
+	j, err = observer.Get(ctx, msg.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.Type != "second" || j.Status != job.Pending {
+		t.Fatalf("expected stored job to be replaced, got type %q", j.Type)
+	}
+
+	jobs, err := puller.Pull(ctx, 1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := upserting.Push(ctx, msg, 0); !errors.Is(err, gqs.ErrDuplicateID) {
+		t.Fatalf("expected ErrDuplicateID for processing job, got %v", err)
+	}
+
+	// a snapshot of the replaced job must not apply to its successor
+	stale := *jobs[0]
+	if err := puller.Return(ctx, jobs[0], 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := upserting.Push(ctx, msg, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := puller.Pull(ctx, 1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := puller.Complete(ctx, &stale); !errors.Is(err, gqs.ErrCompleteFailed) {
+		t.Fatalf("expected ErrCompleteFailed for stale snapshot, got %v", err)
+	}
+}
+
+func TestPusherPartitioned(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusherWithOptions(db, &gsql.PusherOptions{Partitioned: true})
+	msg := message.NewMessage()
+	if err := pusher.Push(ctx, msg, 0); err != nil {
+		t.Fatal(err)
+	}
+	// a later push would land in another partition
+	if err := pusher.Push(ctx, msg, time.Hour); !errors.Is(err, gqs.ErrDuplicateID) {
+		t.Fatalf("expected ErrDuplicateID, got %v", err)
+	}
+
+	ignoring := gsql.NewPusherWithOptions(db, &gsql.PusherOptions{
+		OnConflict:  gsql.ConflictIgnore,
+		Partitioned: true,
+	})
+	if err := ignoring.Push(ctx, msg, 0); err != nil {
+		t.Fatal(err)
+	}
+	j, err := ignoring.PushSnapshot(ctx, msg, 0)
+	if err != nil || j.Id != msg.Id {
+		t.Fatalf("expected the stored job, got %v", err)
+	}
+
+	upserting := gsql.NewPusherWithOptions(db, &gsql.PusherOptions{
+		OnConflict:  gsql.ConflictUpsert,
+		Partitioned: true,
+	})
+	if err := upserting.Push(ctx, msg, 0); !errors.Is(err, gsql.ErrUpsertUnsupported) {
+		t.Fatalf("expected ErrUpsertUnsupported, got %v", err)
+	}
+}
+
+func TestPusherConflictOtherConstraint(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	if _, err := db.ExecContext(ctx, "CREATE UNIQUE INDEX test_jobs_type ON jobs (type)"); err != nil {
+		t.Fatal(err)
+	}
+	for _, policy := range []gsql.ConflictPolicy{gsql.ConflictError, gsql.ConflictIgnore} {
+		pusher := gsql.NewPusherWithOptions(db, &gsql.PusherOptions{OnConflict: policy})
+		first := message.NewMessage()
+		first.Type = first.Id.String()
+		if err := pusher.Push(ctx, first, 0); err != nil {
+			t.Fatal(err)
+		}
+		// a violation of another constraint is not a duplicate id
+		second := message.NewMessage()
+		second.Type = first.Type
+		err := pusher.Push(ctx, second, 0)
+		if err == nil || errors.Is(err, gqs.ErrDuplicateID) {
+			t.Fatalf("expected the constraint violation reported, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package sql
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type instanceModel struct {
+	bun.BaseModel `bun:"table:worker_instances"`
+
+	Id          string    `bun:"id,pk"`
+	Host        string    `bun:"host,notnull,default:''"`
+	StartedAt   time.Time `bun:"started_at,notnull"`
+	HeartbeatAt time.Time `bun:"heartbeat_at,notnull"`
+	InFlight    int64     `bun:"in_flight,notnull,default:0"`
+}
+
+func (im *instanceModel) toInstance() *gqs.Instance {
+	return &gqs.Instance{
+		Id:          im.Id,
+		Host:        im.Host,
+		StartedAt:   im.StartedAt,
+		HeartbeatAt: im.HeartbeatAt,
+		InFlight:    im.InFlight,
+	}
+}
+
+// Registry implements gqs.Registry and gqs.Reaper using a SQL backend.
+//
+// Instances are stored in the worker_instances table, created by InitDB.
+// Job ownership is taken from the locked_by column, which Puller fills
+// in when PullerOptions.Instance is set.
+type Registry struct {
+	db    *bun.DB
+	clock *Clock
+}
+
+// RegistryOptions defines optional behavior of a Registry.
+//
+// Clock, if set, provides the time written to heartbeat_at and to
+// reassigned jobs instead of the local clock (see Clock). The deadline
+// passed to Reap, given in local time, is then shifted by the offset
+// of the Clock.
+type RegistryOptions struct {
+	Clock *Clock
+}
+
+// NewRegistry creates a new SQL-backed Registry.
+//
+// The provided *bun.DB must be properly configured and connected.
+// Schema initialization must be completed before using Registry.
+func NewRegistry(db *bun.DB) *Registry {
+	return NewRegistryWithOptions(db, &RegistryOptions{})
+}
+
+// NewRegistryWithOptions creates a new SQL-backed Registry using the
+// provided options.
+func NewRegistryWithOptions(db *bun.DB, opts *RegistryOptions) *Registry {
+	return &Registry{
+		db:    db,
+		clock: opts.Clock,
+	}
+}
+
+// Heartbeat inserts the instance or refreshes heartbeat_at and
+// in_flight of an existing instance with the same id.
+func (r *Registry) Heartbeat(ctx context.Context, instance *gqs.Instance) error {
+	model := &instanceModel{
+		Id:          instance.Id,
+		Host:        instance.Host,
+		StartedAt:   instance.StartedAt,
+		HeartbeatAt: r.clock.now(ctx, r.db),
+		InFlight:    instance.InFlight,
+	}
+	_, err := r.db.NewInsert().
+		Model(model).
+		On("CONFLICT (id) DO UPDATE").
+		Set("heartbeat_at = EXCLUDED.heartbeat_at").
+		Set("in_flight = EXCLUDED.in_flight").
+		Exec(ctx)
+	if err != nil {
+		return wrap("heartbeat", uuid.Nil, err)
+	}
+	instance.HeartbeatAt = model.HeartbeatAt
+	return nil
+}
+
+// Deregister removes the instance with the given id.
+func (r *Registry) Deregister(ctx context.Context, id string) error {
+	_, err := r.db.NewDelete().
+		Model((*instanceModel)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+	return wrap("deregister", uuid.Nil, err)
+}
+
+// Reap returns Processing jobs of instances whose heartbeat_at is older
+// than deadline to Pending and removes those instances, all within
+// a single transaction.
+//
+// Reassigned jobs keep their attempts counter: the interrupted attempt
+// is counted. next_run_at is set to now, locked_until and locked_by
+// are cleared.
+func (r *Registry) Reap(ctx context.Context, deadline time.Time) (int64, error) {
+	var count int64
+	now := r.clock.now(ctx, r.db)
+	if r.clock != nil {
+		deadline = deadline.Add(r.clock.Offset())
+	}
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var ids []string
+		err := tx.NewSelect().
+			Model((*instanceModel)(nil)).
+			Column("id").
+			Where("heartbeat_at < ?", deadline).
+			Scan(ctx, &ids)
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		res, err := tx.NewUpdate().
+			Model((*jobModel)(nil)).
+			Set("status = ?", job.Pending).
+			Set("next_run_at = ?", now).
+			Set("locked_until = NULL").
+			Set("locked_by = ''").
+			Set("version = version + 1").
+			Set("updated_at = ?", now).
+			Where("status = ?", job.Processing).
+			Where("locked_by IN (?)", bun.In(ids)).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		count, err = res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		_, err = tx.NewDelete().
+			Model((*instanceModel)(nil)).
+			Where("id IN (?)", bun.In(ids)).
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return 0, wrap("reap", uuid.Nil, err)
+	}
+	return count, nil
+}
+
+// Instances returns all registered worker instances ordered by id.
+//
+// Observer implements gqs.InstanceObserver with this method.
+func (o *Observer) Instances(ctx context.Context) ([]*gqs.Instance, error) {
+	var models []*instanceModel
+	err := o.db.NewSelect().
+		Model(&models).
+		Order("id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, wrap("instances", uuid.Nil, err)
+	}
+	ret := make([]*gqs.Instance, len(models))
+	for i, model := range models {
+		ret[i] = model.toInstance()
+	}
+	return ret, nil
+}
@@ -0,0 +1,68 @@
+package sql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestRegistryReap(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	registry := gsql.NewRegistry(db)
+	observer := gsql.NewObserver(db)
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPullerWithOptions(db, &gsql.PullerOptions{Instance: "dead"})
+
+	for _, id := range []string{"dead", "alive"} {
+		instance := &gqs.Instance{Id: id, StartedAt: time.Now(), InFlight: 1}
+		if err := registry.Heartbeat(ctx, instance); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	instances, err := observer.Instances(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(instances) != 2 || instances[0].Id != "alive" || instances[1].InFlight != 1 {
+		t.Fatalf("unexpected instances %+v", instances)
+	}
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+	jobs, _ := puller.Pull(ctx, 1, time.Hour)
+	if len(jobs) != 1 || jobs[0].LockedBy != "dead" {
+		t.Fatalf("expected job locked by instance, got %+v", jobs)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	deadline := time.Now()
+	if err := registry.Heartbeat(ctx, &gqs.Instance{Id: "alive"}); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := registry.Reap(ctx, deadline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 reassigned job, got %d", count)
+	}
+
+	j, _ := observer.Get(ctx, msg.Id)
+	if j.Status != job.Pending || j.LockedUntil != nil || j.LockedBy != "" {
+		t.Fatalf("expected job to be reassigned, got %+v", j)
+	}
+
+	instances, _ = observer.Instances(ctx)
+	if len(instances) != 1 || instances[0].Id != "alive" {
+		t.Fatalf("expected dead instance to be removed, got %+v", instances)
+	}
+}
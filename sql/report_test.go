@@ -0,0 +1,74 @@
+package sql_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+type pagingObserver struct {
+	*gsql.Observer
+}
+
+func (po *pagingObserver) Capabilities() gqs.Capability {
+	return gqs.CapQuery
+}
+
+func TestWriteReport(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	observer := gsql.NewObserver(db)
+
+	for i := 0; i < 5; i++ {
+		msg := message.NewMessage()
+		msg.Type = "report"
+		if i%2 == 0 {
+			msg.Queue = "other"
+		}
+		if err := pusher.Push(ctx, msg, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := &gqs.ListOptions{Statuses: []job.Status{job.Pending}, Queues: []string{"other"}}
+	for _, obs := range []gqs.QueryObserver{observer, &pagingObserver{observer}} {
+		var buf bytes.Buffer
+		count, err := gqs.WriteReport(ctx, obs, opts, gqs.NewCSVWriter(&buf, gqs.ColumnId, gqs.ColumnQueue, gqs.ColumnStatus))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 3 {
+			t.Fatalf("expected 3 reported jobs, got %d", count)
+		}
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 4 || strings.Join(records[0], ",") != "id,queue,status" {
+			t.Fatalf("unexpected csv report %v", records)
+		}
+		for _, record := range records[1:] {
+			if record[1] != "other" || record[2] != job.Pending.String() {
+				t.Fatalf("unexpected csv row %v", record)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	count, err := gqs.WriteReport(ctx, observer, &gqs.ListOptions{Limit: 2}, gqs.NewNDJSONWriter(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 || strings.Count(buf.String(), "\n") != 2 {
+		t.Fatalf("expected 2 ndjson lines, got %d", count)
+	}
+}
@@ -0,0 +1,134 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"github.com/romanqed/gqs/job"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBusyRetries is the number of retries of a write failing
+	// with SQLITE_BUSY, used when SQLiteOptions.BusyRetries is zero.
+	DefaultBusyRetries = 5
+
+	// DefaultBusyBackoff is the base delay between retries of a write
+	// failing with SQLITE_BUSY, used when SQLiteOptions.BusyBackoff
+	// is zero.
+	DefaultBusyBackoff = 10 * time.Millisecond
+)
+
+// SQLiteOptions enables tuning of a Puller for SQLite, which allows
+// a single writer at a time and fails concurrent writers with
+// "database is locked" (SQLITE_BUSY) errors.
+//
+// With tuning enabled, the Puller:
+//
+//   - claims jobs inside a BEGIN IMMEDIATE transaction, acquiring the
+//     write lock upfront instead of upgrading a read lock, which SQLite
+//     cannot do without failing under contention
+//   - serializes its writes through an internal mutex, so concurrent
+//     handlers of one process do not contend for the database lock
+//   - retries writes failing with SQLITE_BUSY up to BusyRetries times,
+//     waiting BusyBackoff * 2^retry with jitter in between
+//
+// Zero values of BusyRetries and BusyBackoff use DefaultBusyRetries
+// and DefaultBusyBackoff.
+type SQLiteOptions struct {
+	BusyRetries int
+	BusyBackoff time.Duration
+}
+
+type sqliteTuning struct {
+	mu      sync.Mutex
+	retries int
+	backoff time.Duration
+}
+
+func newSQLiteTuning(db *bun.DB, opts *SQLiteOptions) *sqliteTuning {
+	if opts == nil || db.Dialect().Name() != dialect.SQLite {
+		return nil
+	}
+	retries := opts.BusyRetries
+	if retries <= 0 {
+		retries = DefaultBusyRetries
+	}
+	backoff := opts.BusyBackoff
+	if backoff <= 0 {
+		backoff = DefaultBusyBackoff
+	}
+	return &sqliteTuning{
+		retries: retries,
+		backoff: backoff,
+	}
+}
+
+// isBusy matches the error text, as SQLite drivers report SQLITE_BUSY
+// with different error types.
+func isBusy(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "database is locked") || strings.Contains(msg, "SQLITE_BUSY")
+}
+
+func (st *sqliteTuning) jitter(retry int) time.Duration {
+	delay := st.backoff << min(retry, 16)
+	return delay/2 + rand.N(delay)
+}
+
+// tuned runs fn under the write mutex of p, retrying it while it fails
+// with SQLITE_BUSY. The mutex is released while waiting between retries.
+func tuned[T any](ctx context.Context, p *Puller, fn func(context.Context) (T, error)) (T, error) {
+	st := p.sqlite
+	if st == nil {
+		return fn(ctx)
+	}
+	for retry := 0; ; retry++ {
+		st.mu.Lock()
+		ret, err := fn(ctx)
+		st.mu.Unlock()
+		if !isBusy(err) || retry >= st.retries {
+			return ret, err
+		}
+		select {
+		case <-ctx.Done():
+			return ret, errors.Join(err, ctx.Err())
+		case <-time.After(st.jitter(retry)):
+		}
+	}
+}
+
+func (p *Puller) write(ctx context.Context, fn func(context.Context) error) error {
+	_, err := tuned(ctx, p, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+func (p *Puller) pullImmediate(ctx context.Context, batch int, lock time.Duration) ([]*job.Job, error) {
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return nil, err
+	}
+	jobs, err := p.pullUpdate(ctx, &conn, batch, lock)
+	if err != nil {
+		_, rbErr := conn.ExecContext(context.WithoutCancel(ctx), "ROLLBACK")
+		return nil, errors.Join(err, rbErr)
+	}
+	if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+		_, rbErr := conn.ExecContext(context.WithoutCancel(ctx), "ROLLBACK")
+		return nil, errors.Join(err, rbErr)
+	}
+	return jobs, nil
+}
@@ -0,0 +1,147 @@
+// Package sqlite provides an embedded, single-binary setup of gqs
+// backed by an SQLite database file.
+//
+// OpenDefault replaces the boilerplate of opening the database with
+// recommended pragmas, initializing the schema and wiring the SQL
+// backend:
+//
+//	store, err := sqlite.OpenDefault("jobs.db")
+//	if err != nil {
+//		return err
+//	}
+//	defer store.Close()
+//
+//	worker := store.NewWorker(handler, nil, nil)
+//	err = gqs.Run(ctx, worker, store.NewCleanWorker(nil, nil))
+//
+// The database uses the pure Go modernc.org/sqlite driver, so no cgo
+// is required.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	gsql "github.com/romanqed/gqs/sql"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"log/slog"
+	"net/url"
+	"runtime"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// DefaultRetention is the age after which NewCleanWorker deletes
+// Done jobs by default.
+const DefaultRetention = 7 * 24 * time.Hour
+
+// pragmas recommended for a queue database: WAL lets readers proceed
+// during writes, busy_timeout absorbs short lock waits and
+// synchronous=NORMAL is durable enough in WAL mode.
+var pragmas = []string{
+	"journal_mode(WAL)",
+	"busy_timeout(5000)",
+	"synchronous(NORMAL)",
+}
+
+// Store is a ready-to-use gqs backend stored in an SQLite database.
+//
+// All components share DB, which is limited to a single connection,
+// as SQLite allows only one writer at a time.
+type Store struct {
+	DB       *bun.DB
+	Pusher   *gsql.Pusher
+	Puller   *gsql.Puller
+	Observer *gsql.Observer
+	Cleaner  *gsql.Cleaner
+	Admin    *gsql.Admin
+}
+
+// OpenDefault opens or creates the SQLite database at path with the
+// recommended pragmas, initializes the schema with gsql.InitDB and
+// returns a Store using it.
+func OpenDefault(path string) (*Store, error) {
+	query := url.Values{"_pragma": pragmas}
+	sqlDB, err := sql.Open("sqlite", "file:"+path+"?"+query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+	db := bun.NewDB(sqlDB, sqlitedialect.New())
+	if err := gsql.InitDB(context.Background(), db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &Store{
+		DB:       db,
+		Pusher:   gsql.NewPusher(db),
+		Puller:   gsql.NewPuller(db),
+		Observer: gsql.NewObserver(db),
+		Cleaner:  gsql.NewCleaner(db),
+		Admin:    gsql.NewAdmin(db),
+	}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.DB.Close()
+}
+
+// DefaultWorkerConfig returns the WorkerConfig used by NewWorker when
+// no config is given: one handler per CPU, a one second pull interval,
+// a 30 second lease and up to 5 retries with exponential backoff
+// between one second and five minutes.
+func DefaultWorkerConfig() *gqs.WorkerConfig {
+	n := runtime.NumCPU()
+	return &gqs.WorkerConfig{
+		Concurrency:  n,
+		Queue:        n,
+		BatchSize:    n,
+		PullInterval: time.Second,
+		LockTimeout:  30 * time.Second,
+		Backoff: gqs.BackoffConfig{
+			MaxRetries:          5,
+			InitialInterval:     time.Second,
+			MaxInterval:         5 * time.Minute,
+			Multiplier:          2,
+			RandomizationFactor: 0.2,
+		},
+	}
+}
+
+func orDefault(log gqs.Logger) gqs.Logger {
+	if log == nil {
+		return slog.Default()
+	}
+	return log
+}
+
+// NewWorker creates a Worker consuming the store.
+//
+// If config is nil, DefaultWorkerConfig is used. If log is nil,
+// slog.Default is used.
+func (s *Store) NewWorker(handler gqs.MessageHandler, config *gqs.WorkerConfig, log gqs.Logger) *gqs.Worker {
+	if config == nil {
+		config = DefaultWorkerConfig()
+	}
+	return gqs.NewWorker(s.Puller, handler, config, orDefault(log))
+}
+
+// NewCleanWorker creates a CleanWorker removing old jobs of the store.
+//
+// If config is nil, Done jobs older than DefaultRetention are deleted
+// every hour. If log is nil, slog.Default is used.
+func (s *Store) NewCleanWorker(config *gqs.CleanConfig, log gqs.Logger) *gqs.CleanWorker {
+	if config == nil {
+		config = &gqs.CleanConfig{
+			Status:   job.Done,
+			Interval: time.Hour,
+			Before:   true,
+			Delta:    DefaultRetention,
+		}
+	}
+	return gqs.NewCleanWorker(s.Cleaner, config, orDefault(log))
+}
@@ -0,0 +1,60 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	"github.com/romanqed/gqs/sql/sqlite"
+)
+
+func TestOpenDefault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.db")
+	store, err := sqlite.OpenDefault(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		return nil
+	}
+	config := sqlite.DefaultWorkerConfig()
+	config.PullInterval = 20 * time.Millisecond
+	worker := store.NewWorker(handler, config, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := worker.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := message.NewMessage()
+	if err := store.Pusher.Push(ctx, msg, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	j, err := store.Observer.Get(ctx, msg.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.Status != job.Done {
+		t.Fatalf("expected Done, got %v", j.Status)
+	}
+
+	if err := worker.Stop(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := sqlite.OpenDefault(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+}
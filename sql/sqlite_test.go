@@ -0,0 +1,89 @@
+package sql_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+)
+
+func TestPullSQLiteTuning(t *testing.T) {
+	// a file database without busy_timeout, so that concurrent
+	// connections fail fast with SQLITE_BUSY
+	path := filepath.Join(t.TempDir(), "jobs.db")
+	sqlDB, err := sql.Open("sqlite", "file:"+path+"?_pragma=journal_mode(WAL)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := bun.NewDB(sqlDB, sqlitedialect.New())
+	defer db.Close()
+	ctx := context.Background()
+	if err := gsql.InitDB(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+
+	pusher := gsql.NewPusher(db)
+	msgs := make([]*message.Message, 200)
+	for i := range msgs {
+		msgs[i] = message.NewMessage()
+	}
+	if _, err := pusher.PushBatch(ctx, msgs, 0, gqs.BatchAtomic); err != nil {
+		t.Fatal(err)
+	}
+
+	puller := gsql.NewPullerWithOptions(db, &gsql.PullerOptions{
+		SQLite: &gsql.SQLiteOptions{BusyRetries: 20, BusyBackoff: time.Millisecond},
+	})
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	seen := make(map[uuid.UUID]bool)
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				jobs, err := puller.Pull(ctx, 5, time.Minute)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if len(jobs) == 0 {
+					return
+				}
+				for _, jb := range jobs {
+					mu.Lock()
+					if seen[jb.Id] {
+						mu.Unlock()
+						t.Errorf("job %s pulled twice", jb.Id)
+						return
+					}
+					seen[jb.Id] = true
+					mu.Unlock()
+					if err := puller.Complete(ctx, jb); err != nil {
+						errs <- err
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+	if len(seen) != len(msgs) {
+		t.Fatalf("expected %d jobs, got %d", len(msgs), len(seen))
+	}
+}
@@ -0,0 +1,121 @@
+package sql
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs/job"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+// TableStats describes the size and bloat of the jobs table, for
+// capacity management without DBA-only queries.
+//
+// Rows holds the number of jobs per status.
+//
+// TableSize and IndexSize are the on-disk sizes in bytes of the table
+// data and of all its indexes, summed over partitions on PostgreSQL.
+//
+// DeadRows is the number of dead tuples awaiting vacuum on PostgreSQL,
+// as estimated by the statistics collector.
+//
+// FreeSize is the number of bytes allocated but unused: the free pages
+// of the whole database file on SQLite and the free space of the table
+// on MySQL.
+//
+// Values that the dialect cannot report are -1.
+type TableStats struct {
+	Rows      map[job.Status]int64
+	TableSize int64
+	IndexSize int64
+	DeadRows  int64
+	FreeSize  int64
+}
+
+type statusCount struct {
+	Status job.Status `bun:"status"`
+	Count  int64      `bun:"count"`
+}
+
+// TableStats returns the statistics of the jobs table.
+//
+// Row counts are exact and require a scan of the status index; the
+// remaining values are read from dialect-specific catalogs: pg_class
+// statistics on PostgreSQL, the dbstat virtual table and pragmas on
+// SQLite, and information_schema on MySQL. Catalog values may lag
+// behind recent writes.
+func (a *Admin) TableStats(ctx context.Context) (*TableStats, error) {
+	var counts []statusCount
+	err := a.db.NewSelect().
+		Model((*jobModel)(nil)).
+		Column("status").
+		ColumnExpr("COUNT(*) AS count").
+		Group("status").
+		Scan(ctx, &counts)
+	if err != nil {
+		return nil, wrap("table stats", uuid.Nil, err)
+	}
+	ret := &TableStats{
+		Rows:      make(map[job.Status]int64, len(counts)),
+		TableSize: -1,
+		IndexSize: -1,
+		DeadRows:  -1,
+		FreeSize:  -1,
+	}
+	for _, count := range counts {
+		ret.Rows[count.Status] = count.Count
+	}
+	switch a.db.Dialect().Name() {
+	case dialect.PG:
+		err = pgTableStats(ctx, a.db, ret)
+	case dialect.SQLite:
+		err = sqliteTableStats(ctx, a.db, ret)
+	case dialect.MySQL:
+		err = mysqlTableStats(ctx, a.db, ret)
+	}
+	if err != nil {
+		return nil, wrap("table stats", uuid.Nil, err)
+	}
+	return ret, nil
+}
+
+func pgTableStats(ctx context.Context, db bun.IDB, stats *TableStats) error {
+	// pg_partition_tree also lists a non-partitioned table itself
+	return db.NewRaw(
+		"SELECT COALESCE(SUM(pg_table_size(t.relid)), 0), "+
+			"COALESCE(SUM(pg_indexes_size(t.relid)), 0), "+
+			"COALESCE(SUM(s.n_dead_tup), 0) "+
+			"FROM pg_partition_tree(?::regclass) t "+
+			"LEFT JOIN pg_stat_user_tables s ON s.relid = t.relid",
+		"jobs",
+	).Scan(ctx, &stats.TableSize, &stats.IndexSize, &stats.DeadRows)
+}
+
+func sqliteTableStats(ctx context.Context, db bun.IDB, stats *TableStats) error {
+	err := db.NewRaw(
+		"SELECT freelist_count * page_size FROM pragma_freelist_count, pragma_page_size",
+	).Scan(ctx, &stats.FreeSize)
+	if err != nil {
+		return err
+	}
+	// dbstat is an optional compile-time feature of SQLite
+	var tableSize, indexSize int64
+	err = db.NewRaw(
+		"SELECT COALESCE(SUM(CASE WHEN name = ? THEN pgsize END), 0), "+
+			"COALESCE(SUM(CASE WHEN name != ? THEN pgsize END), 0) "+
+			"FROM dbstat WHERE name = ? OR name IN (SELECT name FROM pragma_index_list(?))",
+		"jobs", "jobs", "jobs", "jobs",
+	).Scan(ctx, &tableSize, &indexSize)
+	if err == nil {
+		stats.TableSize, stats.IndexSize = tableSize, indexSize
+	}
+	return nil
+}
+
+func mysqlTableStats(ctx context.Context, db bun.IDB, stats *TableStats) error {
+	return db.NewRaw(
+		"SELECT data_length, index_length, data_free FROM information_schema.tables "+
+			"WHERE table_schema = DATABASE() AND table_name = ?",
+		"jobs",
+	).Scan(ctx, &stats.TableSize, &stats.IndexSize, &stats.FreeSize)
+}
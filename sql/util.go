@@ -1,6 +1,21 @@
 package sql
 
-import "database/sql"
+import (
+	"database/sql"
+	"github.com/romanqed/gqs/job"
+	"time"
+)
+
+// waiting lists the statuses of jobs waiting to be pulled.
+var waiting = []job.Status{job.Pending, job.Scheduled}
+
+// waitStatus returns the status of a job waiting to run at at.
+func waitStatus(now, at time.Time) job.Status {
+	if at.After(now) {
+		return job.Scheduled
+	}
+	return job.Pending
+}
 
 func isAffected(res sql.Result) bool {
 	rows, err := res.RowsAffected()
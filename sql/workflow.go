@@ -0,0 +1,156 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/workflow"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type workflowModel struct {
+	bun.BaseModel `bun:"table:workflows"`
+
+	Id        uuid.UUID       `bun:"id,pk,type:uuid"`
+	Workflow  string          `bun:"workflow,notnull"`
+	Status    workflow.Status `bun:"status,notnull"`
+	Step      int             `bun:"step,notnull,default:0"`
+	Data      []byte          `bun:"data,type:blob"`
+	Error     string          `bun:"error,notnull,default:''"`
+	Version   uint64          `bun:"version,notnull,default:0"`
+	CreatedAt time.Time       `bun:"created_at,notnull"`
+	UpdatedAt time.Time       `bun:"updated_at,notnull"`
+}
+
+func (wm *workflowModel) toState() *workflow.State {
+	return &workflow.State{
+		Id:        wm.Id,
+		Workflow:  wm.Workflow,
+		Status:    wm.Status,
+		Step:      wm.Step,
+		Data:      wm.Data,
+		Error:     wm.Error,
+		Version:   wm.Version,
+		CreatedAt: wm.CreatedAt,
+		UpdatedAt: wm.UpdatedAt,
+	}
+}
+
+func createWorkflowTable(ctx context.Context, db bun.IDB) error {
+	_, err := db.NewCreateTable().
+		Model((*workflowModel)(nil)).
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+// WorkflowStore implements workflow.Store using a SQL backend.
+//
+// States are stored in the workflows table, created by InitDB.
+type WorkflowStore struct {
+	db    *bun.DB
+	clock *Clock
+}
+
+// WorkflowStoreOptions defines optional behavior of a WorkflowStore.
+//
+// Clock, if set, provides the time written to created_at and
+// updated_at instead of the local clock (see Clock).
+type WorkflowStoreOptions struct {
+	Clock *Clock
+}
+
+// NewWorkflowStore creates a new SQL-backed WorkflowStore.
+//
+// The provided *bun.DB must be properly configured and connected.
+// Schema initialization must be completed before using WorkflowStore.
+func NewWorkflowStore(db *bun.DB) *WorkflowStore {
+	return NewWorkflowStoreWithOptions(db, &WorkflowStoreOptions{})
+}
+
+// NewWorkflowStoreWithOptions creates a new SQL-backed WorkflowStore
+// using the provided options.
+func NewWorkflowStoreWithOptions(db *bun.DB, opts *WorkflowStoreOptions) *WorkflowStore {
+	return &WorkflowStore{
+		db:    db,
+		clock: opts.Clock,
+	}
+}
+
+// Create inserts st. An existing state with the same id is reported
+// with gqs.ErrDuplicateID.
+func (ws *WorkflowStore) Create(ctx context.Context, st *workflow.State) error {
+	now := ws.clock.now(ctx, ws.db)
+	model := &workflowModel{
+		Id:        st.Id,
+		Workflow:  st.Workflow,
+		Status:    st.Status,
+		Step:      st.Step,
+		Data:      st.Data,
+		Error:     st.Error,
+		Version:   st.Version,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	res, err := ws.db.NewInsert().
+		Model(model).
+		Ignore().
+		Exec(ctx)
+	if err != nil {
+		return wrap("create workflow", st.Id, err)
+	}
+	if !isAffected(res) {
+		return fmt.Errorf("%w: %s", gqs.ErrDuplicateID, st.Id)
+	}
+	st.CreatedAt = now
+	st.UpdatedAt = now
+	return nil
+}
+
+// Get returns the state of the workflow id, or an error wrapping
+// gqs.ErrNotFound if there is none.
+func (ws *WorkflowStore) Get(ctx context.Context, id uuid.UUID) (*workflow.State, error) {
+	var ret workflowModel
+	err := ws.db.NewSelect().
+		Model(&ret).
+		Where("id = ?", id).
+		Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: workflow %s", gqs.ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, wrap("get workflow", id, err)
+	}
+	return ret.toState(), nil
+}
+
+// Update sets status, step, data and error of the workflow, if its
+// version equals st.Version. version is incremented and updated_at is
+// refreshed. Otherwise, workflow.ErrConflict is returned.
+func (ws *WorkflowStore) Update(ctx context.Context, st *workflow.State) error {
+	now := ws.clock.now(ctx, ws.db)
+	res, err := ws.db.NewUpdate().
+		Model((*workflowModel)(nil)).
+		Set("status = ?", st.Status).
+		Set("step = ?", st.Step).
+		Set("data = ?", st.Data).
+		Set("error = ?", st.Error).
+		Set("version = version + 1").
+		Set("updated_at = ?", now).
+		Where("id = ?", st.Id).
+		Where("version = ?", st.Version).
+		Exec(ctx)
+	if err != nil {
+		return wrap("update workflow", st.Id, err)
+	}
+	if !isAffected(res) {
+		return workflow.ErrConflict
+	}
+	st.Version++
+	st.UpdatedAt = now
+	return nil
+}
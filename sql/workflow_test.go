@@ -0,0 +1,50 @@
+package sql_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	gsql "github.com/romanqed/gqs/sql"
+	"github.com/romanqed/gqs/workflow"
+)
+
+func TestWorkflowStore(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	store := gsql.NewWorkflowStore(db)
+
+	st := &workflow.State{Id: uuid.New(), Workflow: "order", Status: workflow.Running, Data: []byte("x")}
+	if err := store.Create(ctx, st); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Create(ctx, st); !errors.Is(err, gqs.ErrDuplicateID) {
+		t.Fatalf("expected ErrDuplicateID, got %v", err)
+	}
+
+	stale, err := store.Get(ctx, st.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.Step = 1
+	st.Data = []byte("xy")
+	if err := store.Update(ctx, st); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Update(ctx, stale); !errors.Is(err, workflow.ErrConflict) {
+		t.Fatalf("expected ErrConflict for a stale state, got %v", err)
+	}
+
+	got, err := store.Get(ctx, st.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Step != 1 || string(got.Data) != "xy" || got.Version != 1 {
+		t.Fatalf("unexpected stored state %+v", got)
+	}
+	if _, err := store.Get(ctx, uuid.New()); !errors.Is(err, gqs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package gqs
+
+import (
+	"github.com/romanqed/gqs/job"
+	"sync/atomic"
+	"time"
+)
+
+// WorkerStats holds counters of a Worker since it was created.
+//
+// Pulled is the number of jobs pulled. TotalWait is the sum and
+// MaxWait the maximum of their queueing delays (see job.Job.WaitTime),
+// a direct measure of whether the worker keeps up with its queues.
+//
+// Completed, Retried and Killed count the attempts that ended with the
+// job marked Done, rescheduled for a retry and marked Dead.
+type WorkerStats struct {
+	Pulled    int64
+	TotalWait time.Duration
+	MaxWait   time.Duration
+	Completed int64
+	Retried   int64
+	Killed    int64
+}
+
+// AvgWait returns the mean queueing delay of pulled jobs, or zero if
+// no job was pulled.
+func (ws WorkerStats) AvgWait() time.Duration {
+	if ws.Pulled == 0 {
+		return 0
+	}
+	return ws.TotalWait / time.Duration(ws.Pulled)
+}
+
+type workerStats struct {
+	pulled    atomic.Int64
+	totalWait atomic.Int64
+	maxWait   atomic.Int64
+	completed atomic.Int64
+	retried   atomic.Int64
+	killed    atomic.Int64
+}
+
+func (ws *workerStats) record(jb *job.Job) {
+	wait := int64(jb.WaitTime)
+	ws.pulled.Add(1)
+	ws.totalWait.Add(wait)
+	for {
+		cur := ws.maxWait.Load()
+		if wait <= cur || ws.maxWait.CompareAndSwap(cur, wait) {
+			return
+		}
+	}
+}
+
+// Stats returns the current counters of the Worker.
+func (w *Worker) Stats() WorkerStats {
+	return WorkerStats{
+		Pulled:    w.stats.pulled.Load(),
+		TotalWait: time.Duration(w.stats.totalWait.Load()),
+		MaxWait:   time.Duration(w.stats.maxWait.Load()),
+		Completed: w.stats.completed.Load(),
+		Retried:   w.stats.retried.Load(),
+		Killed:    w.stats.killed.Load(),
+	}
+}
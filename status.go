@@ -0,0 +1,125 @@
+package gqs
+
+import (
+	"sync"
+	"time"
+)
+
+// WorkerState is the lifecycle state of a Worker.
+type WorkerState uint8
+
+const (
+	// WorkerStopped indicates a worker that is not started or was
+	// stopped.
+	WorkerStopped WorkerState = iota
+
+	// WorkerRunning indicates a started worker pulling jobs.
+	WorkerRunning
+
+	// WorkerDraining indicates a started worker that stopped pulling
+	// and finishes already pulled jobs (see Worker.Drain).
+	WorkerDraining
+
+	// WorkerPaused indicates a started worker whose pulling is
+	// suspended (see Worker.Pause).
+	WorkerPaused
+)
+
+// String returns the name of the state.
+func (ws WorkerState) String() string {
+	switch ws {
+	case WorkerStopped:
+		return "stopped"
+	case WorkerRunning:
+		return "running"
+	case WorkerDraining:
+		return "draining"
+	case WorkerPaused:
+		return "paused"
+	default:
+		return "unknown"
+	}
+}
+
+// WorkerStatus is a snapshot of the state of a Worker, for health
+// checks and diagnostics of the hosting application.
+//
+// InFlight is the number of jobs whose handler is running. Queued is
+// the number of pulled jobs waiting for a free handler.
+//
+// LastPull is the time of the most recent pull, zero if the worker has
+// not pulled yet. LastPullError is the error of the most recent pull,
+// nil if it succeeded.
+//
+// Stats holds the counters of the worker (see Worker.Stats).
+type WorkerStatus struct {
+	State         WorkerState
+	InFlight      int64
+	Queued        int64
+	LastPull      time.Time
+	LastPullError error
+	Stats         WorkerStats
+}
+
+type pullStatus struct {
+	mu   sync.Mutex
+	last time.Time
+	err  error
+}
+
+func (ps *pullStatus) record(err error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.last = time.Now()
+	ps.err = err
+}
+
+func (ps *pullStatus) load() (time.Time, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.last, ps.err
+}
+
+func (w *Worker) state() WorkerState {
+	if !w.running() {
+		return WorkerStopped
+	}
+	if w.draining.Load() {
+		return WorkerDraining
+	}
+	if w.pause.paused() {
+		return WorkerPaused
+	}
+	return WorkerRunning
+}
+
+// Status returns a snapshot of the current state of the Worker.
+func (w *Worker) Status() WorkerStatus {
+	pending := w.pool.Pending()
+	if w.reserved != nil {
+		pending += w.reserved.Pending()
+	}
+	inFlight := w.inFlight.Load()
+	last, err := w.pulls.load()
+	return WorkerStatus{
+		State:         w.state(),
+		InFlight:      inFlight,
+		Queued:        max(int64(pending)-inFlight, 0),
+		LastPull:      last,
+		LastPullError: err,
+		Stats:         w.Stats(),
+	}
+}
+
+// Healthy reports whether the Worker is ready to process jobs: it is
+// running, neither draining nor paused, and its most recent pull, if any, succeeded.
+//
+// Healthy is intended for readiness probes: a worker failing to reach
+// its storage reports itself unhealthy until a pull succeeds again.
+func (w *Worker) Healthy() bool {
+	if w.state() != WorkerRunning {
+		return false
+	}
+	_, err := w.pulls.load()
+	return err == nil
+}
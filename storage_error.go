@@ -0,0 +1,81 @@
+package gqs
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+)
+
+// StorageErrorKind classifies the cause of a StorageError.
+type StorageErrorKind uint8
+
+const (
+	// StorageUnknown denotes errors the storage could not classify.
+	StorageUnknown StorageErrorKind = iota
+
+	// StorageTransient denotes errors caused by the current state of
+	// the storage rather than by the operation, such as lost
+	// connections, timeouts, deadlocks, serialization failures or lock
+	// contention. Retrying the operation may succeed.
+	StorageTransient
+
+	// StorageConstraint denotes violations of storage constraints, such
+	// as unique or not-null constraints. Retrying the same operation
+	// fails again.
+	StorageConstraint
+)
+
+// String returns the name of the kind.
+func (k StorageErrorKind) String() string {
+	switch k {
+	case StorageTransient:
+		return "transient"
+	case StorageConstraint:
+		return "constraint"
+	default:
+		return "unknown"
+	}
+}
+
+// StorageError reports a failure of the storage while performing an
+// operation, wrapping the error of the underlying driver.
+//
+// Op names the failed operation, such as "pull" or "complete". Id is
+// the job the operation was performed on, or uuid.Nil for operations
+// on several jobs. Kind classifies Err, so that callers can retry
+// transient failures of storage calls.
+//
+// Errors reporting the state of jobs, such as ErrLockLost or
+// ErrDuplicateID, and context errors are not storage failures and are
+// returned as is.
+type StorageError struct {
+	Op   string
+	Id   uuid.UUID
+	Kind StorageErrorKind
+	Err  error
+}
+
+// Error implements error.
+func (e *StorageError) Error() string {
+	if e.Id == uuid.Nil {
+		return fmt.Sprintf("storage %s failed (%s): %v", e.Op, e.Kind, e.Err)
+	}
+	return fmt.Sprintf("storage %s of %s failed (%s): %v", e.Op, e.Id, e.Kind, e.Err)
+}
+
+// Unwrap returns the error of the underlying driver.
+func (e *StorageError) Unwrap() error {
+	return e.Err
+}
+
+// Temporary reports whether retrying the operation may succeed.
+func (e *StorageError) Temporary() bool {
+	return e.Kind == StorageTransient
+}
+
+// IsTransient reports whether err is or wraps a StorageError of kind
+// StorageTransient.
+func IsTransient(err error) bool {
+	var se *StorageError
+	return errors.As(err, &se) && se.Temporary()
+}
@@ -0,0 +1,127 @@
+package gqs
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	"time"
+)
+
+// ErrTenantMismatch is returned by TenantPusher for messages already
+// assigned to another tenant.
+var ErrTenantMismatch = errors.New("message belongs to another tenant")
+
+// TenantPusher is a Pusher assigning every message to a single tenant
+// before delegating to the underlying Pusher (see
+// message.Message.TenantId).
+//
+// TenantPusher is intended to be handed to code acting on behalf of a
+// tenant, such as a request handler of a SaaS application, so that it
+// cannot enqueue messages of other tenants.
+type TenantPusher struct {
+	pusher Pusher
+	tenant string
+}
+
+// NewTenantPusher creates a TenantPusher of tenant delegating to pusher.
+func NewTenantPusher(pusher Pusher, tenant string) *TenantPusher {
+	return &TenantPusher{
+		pusher: pusher,
+		tenant: tenant,
+	}
+}
+
+func (tp *TenantPusher) assign(msg *message.Message) error {
+	if msg.TenantId != "" && msg.TenantId != tp.tenant {
+		return ErrTenantMismatch
+	}
+	msg.TenantId = tp.tenant
+	return nil
+}
+
+// Push assigns msg to the tenant and enqueues it. It returns
+// ErrTenantMismatch if msg is assigned to another tenant.
+func (tp *TenantPusher) Push(ctx context.Context, msg *message.Message, delay time.Duration) error {
+	if err := tp.assign(msg); err != nil {
+		return err
+	}
+	return tp.pusher.Push(ctx, msg, delay)
+}
+
+// PushAt assigns msg to the tenant and enqueues it to run at at. If the
+// underlying Pusher does not implement SchedulePusher, the time
+// remaining until at is used as the push delay.
+func (tp *TenantPusher) PushAt(ctx context.Context, msg *message.Message, at time.Time) error {
+	if err := tp.assign(msg); err != nil {
+		return err
+	}
+	if scheduler, ok := feature[SchedulePusher](tp.pusher, CapSchedulePush); ok {
+		return scheduler.PushAt(ctx, msg, at)
+	}
+	return tp.pusher.Push(ctx, msg, max(time.Until(at), 0))
+}
+
+// TenantObserver is a QueryObserver restricted to the jobs of a single
+// tenant, delegating to the underlying QueryObserver.
+//
+// Jobs of other tenants are invisible: Get reports them as missing,
+// and List, Query and Count only consider jobs of the tenant.
+type TenantObserver struct {
+	observer QueryObserver
+	tenant   string
+}
+
+// NewTenantObserver creates a TenantObserver of tenant delegating to
+// observer.
+func NewTenantObserver(observer QueryObserver, tenant string) *TenantObserver {
+	return &TenantObserver{
+		observer: observer,
+		tenant:   tenant,
+	}
+}
+
+func (to *TenantObserver) scope(opts *ListOptions) *ListOptions {
+	var ret ListOptions
+	if opts != nil {
+		ret = *opts
+	}
+	ret.Tenants = []string{to.tenant}
+	return &ret
+}
+
+// Get retrieves a job of the tenant by its identifier. A job of another
+// tenant is reported as missing, that is (nil, nil).
+func (to *TenantObserver) Get(ctx context.Context, id uuid.UUID) (*job.Job, error) {
+	ret, err := to.observer.Get(ctx, id)
+	if err != nil || ret == nil || ret.TenantId != to.tenant {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// List returns up to limit jobs of the tenant filtered by status.
+func (to *TenantObserver) List(ctx context.Context, status job.Status, limit int) ([]*job.Job, error) {
+	opts := &ListOptions{Limit: limit}
+	if status != 0 {
+		opts.Statuses = []job.Status{status}
+	}
+	page, err := to.Query(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return page.Jobs, nil
+}
+
+// Query returns a page of jobs of the tenant matching opts. The Tenants
+// filter of opts is replaced with the tenant.
+func (to *TenantObserver) Query(ctx context.Context, opts *ListOptions) (*Page, error) {
+	return to.observer.Query(ctx, to.scope(opts))
+}
+
+// Count returns the number of jobs of the tenant matching the filters
+// of opts. The Tenants filter of opts is replaced with the tenant.
+func (to *TenantObserver) Count(ctx context.Context, opts *ListOptions) (int64, error) {
+	return to.observer.Count(ctx, to.scope(opts))
+}
@@ -0,0 +1,57 @@
+package gqs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+)
+
+func TestTenantScope(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	pusher := gsql.NewPusher(db)
+	observer := gsql.NewObserver(db)
+
+	acme := gqs.NewTenantPusher(pusher, "acme")
+	other := gqs.NewTenantPusher(pusher, "other")
+
+	own := message.NewMessage()
+	if err := acme.Push(ctx, own, 0); err != nil {
+		t.Fatal(err)
+	}
+	if own.TenantId != "acme" {
+		t.Fatalf("expected the message to be assigned to the tenant, got %q", own.TenantId)
+	}
+	foreign := message.NewMessage()
+	if err := other.Push(ctx, foreign, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := acme.Push(ctx, foreign, 0); !errors.Is(err, gqs.ErrTenantMismatch) {
+		t.Fatalf("expected ErrTenantMismatch, got %v", err)
+	}
+
+	scoped := gqs.NewTenantObserver(observer, "acme")
+	if j, err := scoped.Get(ctx, own.Id); err != nil || j == nil || j.TenantId != "acme" {
+		t.Fatalf("expected the own job, got %v, %v", j, err)
+	}
+	if j, err := scoped.Get(ctx, foreign.Id); err != nil || j != nil {
+		t.Fatalf("expected the foreign job to be invisible, got %v, %v", j, err)
+	}
+	jobs, err := scoped.List(ctx, job.Pending, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].Id != own.Id {
+		t.Fatalf("expected only the own job, got %d jobs", len(jobs))
+	}
+	count, _ := scoped.Count(ctx, &gqs.ListOptions{Tenants: []string{"other"}})
+	if count != 1 {
+		t.Fatalf("expected the tenant filter to be enforced, got %d", count)
+	}
+}
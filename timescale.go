@@ -0,0 +1,47 @@
+package gqs
+
+import (
+	"context"
+	"github.com/romanqed/gqs/message"
+	"time"
+)
+
+func scaleDelay(delay time.Duration, scale float64) time.Duration {
+	if scale <= 0 || scale == 1 {
+		return delay
+	}
+	return time.Duration(float64(delay) * scale)
+}
+
+// ScaledPusher is a Pusher multiplying every push delay by a constant
+// factor before delegating to the underlying Pusher.
+//
+// ScaledPusher is intended for integration tests of schedules: together
+// with WorkerConfig.TimeScale it compresses time, so that a one minute
+// delay with a scale of 1.0/6000 elapses in 10ms. It should not be used
+// in production.
+type ScaledPusher struct {
+	pusher Pusher
+	scale  float64
+}
+
+// NewScaledPusher creates a ScaledPusher delegating to pusher.
+//
+// A scale of zero, one or below zero leaves delays unchanged.
+func NewScaledPusher(pusher Pusher, scale float64) *ScaledPusher {
+	return &ScaledPusher{
+		pusher: pusher,
+		scale:  scale,
+	}
+}
+
+// Push enqueues msg with delay multiplied by the scale.
+func (sp *ScaledPusher) Push(ctx context.Context, msg *message.Message, delay time.Duration) error {
+	return sp.pusher.Push(ctx, msg, scaleDelay(delay, sp.scale))
+}
+
+// PushAt enqueues msg with the time remaining until at multiplied by
+// the scale, so absolute schedules are compressed relative to now.
+func (sp *ScaledPusher) PushAt(ctx context.Context, msg *message.Message, at time.Time) error {
+	return sp.Push(ctx, msg, max(time.Until(at), 0))
+}
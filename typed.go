@@ -0,0 +1,50 @@
+package gqs
+
+import (
+	"context"
+	"fmt"
+	"github.com/romanqed/gqs/codec"
+	"github.com/romanqed/gqs/message"
+	"time"
+)
+
+var (
+	// ErrDecode indicates that a message payload could not be decoded
+	// by TypedHandler.
+	//
+	// ErrDecode wraps ErrKill: a payload that cannot be decoded will not
+	// become valid on retry, so the job is killed immediately.
+	ErrDecode = fmt.Errorf("%w: cannot decode payload", ErrKill)
+)
+
+// PushTyped encodes value with c into msg.Payload and pushes msg.
+//
+// Other message fields (Queue, Type, Metadata and so on) are pushed
+// as set by the caller. If encoding fails, nothing is pushed.
+func PushTyped[T any](ctx context.Context, pusher Pusher, c codec.Codec, msg *message.Message, value T, delay time.Duration) error {
+	payload, err := c.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("cannot encode payload: %w", err)
+	}
+	msg.Payload = payload
+	return pusher.Push(ctx, msg, delay)
+}
+
+// TypedHandlerFunc is a handler receiving the decoded payload along
+// with the message.
+type TypedHandlerFunc[T any] func(ctx context.Context, msg *message.Message, value T) error
+
+// TypedHandler wraps fn into a MessageHandler decoding the payload
+// with c before invoking fn.
+//
+// If the payload cannot be decoded, fn is not invoked and an error
+// wrapping ErrDecode is returned.
+func TypedHandler[T any](c codec.Codec, fn TypedHandlerFunc[T]) MessageHandler {
+	return func(ctx context.Context, msg *message.Message) error {
+		var value T
+		if err := c.Unmarshal(msg.Payload, &value); err != nil {
+			return fmt.Errorf("%w: %w", ErrDecode, err)
+		}
+		return fn(ctx, msg, value)
+	}
+}
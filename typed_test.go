@@ -0,0 +1,46 @@
+package gqs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/codec"
+	"github.com/romanqed/gqs/message"
+)
+
+type order struct {
+	Id    int
+	Total float64
+}
+
+func TestTypedHandler(t *testing.T) {
+	ctx := context.Background()
+	capture := &capturePusher{}
+
+	msg := message.NewMessage()
+	if err := gqs.PushTyped(ctx, capture, codec.JSON, msg, order{Id: 1, Total: 9.5}, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	var got order
+	handler := gqs.TypedHandler(codec.JSON, func(ctx context.Context, msg *message.Message, value order) error {
+		got = value
+		return nil
+	})
+	if err := handler(ctx, &capture.msg); err != nil {
+		t.Fatal(err)
+	}
+	if got.Id != 1 || got.Total != 9.5 {
+		t.Fatalf("unexpected value %+v", got)
+	}
+
+	bad := message.NewMessage()
+	bad.Payload = []byte("not json")
+	err := handler(ctx, bad)
+	if !errors.Is(err, gqs.ErrDecode) || !errors.Is(err, gqs.ErrKill) {
+		t.Fatalf("expected ErrDecode wrapping ErrKill, got %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+// Package ui serves an embeddable web dashboard for gqs.
+//
+// The dashboard is a single page showing job counts by status, an
+// overview of every queue if the Observer implements
+// gqs.OverviewObserver, and a job browser filtered by status,
+// defaulting to dead jobs, with buttons to requeue terminal jobs. It is backed by an Observer
+// and an optional Admin; without an Admin the dashboard is read-only.
+//
+// NewHandler returns an http.Handler that can be mounted on any
+// http.ServeMux under a prefix, for example:
+//
+//	mux.Handle("/queue/", http.StripPrefix("/queue", ui.NewHandler(&ui.Config{
+//		Observer: observer,
+//		Admin:    admin,
+//	})))
+//
+// The page uses relative URLs, so it must be served with a trailing
+// slash ("/queue/"). Its JSON API is served under "api/" and consists
+// of the routes of the httpapi package plus "GET api/stats" (see
+// Stats). Job counts and the paginated job browser require an Observer
+// implementing gqs.QueryObserver.
+//
+// The handler performs no authentication; it should be mounted behind
+// the access control of the hosting application.
+package ui
@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/httpapi"
+	"github.com/romanqed/gqs/job"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var static embed.FS
+
+// statuses lists the statuses reported by the stats endpoint.
+var statuses = []job.Status{job.Pending, job.Scheduled, job.Processing, job.Done, job.Dead, job.Cancelled}
+
+// Config defines the backends and appearance of the dashboard.
+//
+// Observer is required. Admin is optional; without it, requeueing is
+// disabled. Title replaces the default page title "gqs" if set.
+type Config struct {
+	Observer gqs.Observer
+	Admin    gqs.Admin
+	Title    string
+}
+
+// Stats is the response body of "GET api/stats".
+//
+// Counts maps status names to the number of jobs; it is nil if the
+// observer implements neither gqs.OverviewObserver nor
+// gqs.QueryObserver. Queues holds per-queue overviews if the observer
+// implements gqs.OverviewObserver. ReadOnly reports whether requeueing
+// is disabled.
+type Stats struct {
+	Title    string              `json:"title"`
+	Counts   map[string]int64    `json:"counts"`
+	Queues   []gqs.QueueOverview `json:"queues,omitempty"`
+	ReadOnly bool                `json:"read_only"`
+}
+
+type dashboard struct {
+	observer gqs.Observer
+	admin    gqs.Admin
+	title    string
+}
+
+// NewHandler returns an HTTP handler serving the dashboard page and
+// its API.
+func NewHandler(cfg *Config) http.Handler {
+	d := &dashboard{
+		observer: cfg.Observer,
+		admin:    cfg.Admin,
+		title:    cfg.Title,
+	}
+	if d.title == "" {
+		d.title = "gqs"
+	}
+	api := httpapi.NewHandler(&httpapi.Config{
+		Observer: cfg.Observer,
+		Admin:    cfg.Admin,
+	})
+	root, _ := fs.Sub(static, "static")
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/stats", d.stats)
+	mux.Handle("GET /api/jobs", http.StripPrefix("/api", api))
+	mux.Handle("GET /api/jobs/{id}", http.StripPrefix("/api", api))
+	mux.Handle("POST /api/jobs/{id}/requeue", http.StripPrefix("/api", api))
+	mux.Handle("GET /", http.FileServerFS(root))
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func (d *dashboard) counts(ctx context.Context, stats *Stats) error {
+	if obs, ok := d.observer.(gqs.OverviewObserver); ok && gqs.Supports(d.observer, gqs.CapOverview) {
+		queues, err := obs.QueueOverview(ctx)
+		if err != nil {
+			return err
+		}
+		stats.Queues = queues
+		stats.Counts = make(map[string]int64, len(statuses))
+		for _, status := range statuses {
+			stats.Counts[status.String()] = 0
+		}
+		for _, q := range queues {
+			stats.Counts[job.Pending.String()] += q.Pending
+			stats.Counts[job.Scheduled.String()] += q.Scheduled
+			stats.Counts[job.Processing.String()] += q.Processing
+			stats.Counts[job.Done.String()] += q.Done
+			stats.Counts[job.Dead.String()] += q.Dead
+			stats.Counts[job.Cancelled.String()] += q.Cancelled
+		}
+		return nil
+	}
+	obs, ok := d.observer.(gqs.QueryObserver)
+	if !ok || !gqs.Supports(d.observer, gqs.CapQuery) {
+		return nil
+	}
+	stats.Counts = make(map[string]int64, len(statuses))
+	for _, status := range statuses {
+		count, err := obs.Count(ctx, &gqs.ListOptions{Statuses: []job.Status{status}})
+		if err != nil {
+			return err
+		}
+		stats.Counts[status.String()] = count
+	}
+	return nil
+}
+
+func (d *dashboard) stats(w http.ResponseWriter, r *http.Request) {
+	ret := &Stats{
+		Title:    d.title,
+		ReadOnly: d.admin == nil,
+	}
+	if err := d.counts(r.Context(), ret); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, ret)
+}
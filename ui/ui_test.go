@@ -0,0 +1,75 @@
+package ui_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/romanqed/gqs/message"
+	gsql "github.com/romanqed/gqs/sql"
+	"github.com/romanqed/gqs/ui"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+
+	_ "modernc.org/sqlite"
+)
+
+func newTestDB(t *testing.T) *bun.DB {
+	t.Helper()
+	sqlDB, err := sql.Open("sqlite", "file::memory:?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1) // important for sqlite
+	db := bun.NewDB(sqlDB, sqlitedialect.New())
+	if err := gsql.InitDB(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestDashboard(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	_ = gsql.NewPusher(db).Push(ctx, message.NewMessage(), 0)
+
+	mux := http.NewServeMux()
+	mux.Handle("/queue/", http.StripPrefix("/queue", ui.NewHandler(&ui.Config{
+		Observer: gsql.NewObserver(db),
+		Title:    "orders",
+	})))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/queue/", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "api/stats") {
+		t.Fatalf("expected dashboard page, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/queue/api/stats", nil))
+	var stats ui.Stats
+	if err := json.NewDecoder(rec.Body).Decode(&stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Title != "orders" || !stats.ReadOnly || stats.Counts["Pending"] != 1 || stats.Counts["Dead"] != 0 ||
+		len(stats.Queues) != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/queue/api/jobs?status=Pending", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"jobs"`) {
+		t.Fatalf("expected job list, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/queue/api/jobs/"+message.NewMessage().Id.String()+"/requeue", nil))
+	if rec.Code != http.StatusNotImplemented {
+		t.Fatalf("expected read-only dashboard to reject requeue, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,55 @@
+package gqs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// WebhookSink is a TransitionSink posting batches of transitions to an
+// HTTP endpoint as a JSON array.
+//
+// URL is the endpoint. Client is used to send requests; if nil,
+// http.DefaultClient is used. Header is added to every request, for
+// example to authenticate.
+//
+// A response with a status code other than 2xx fails the batch, which
+// is then delivered again.
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+	Header http.Header
+}
+
+// Publish posts events to the endpoint.
+func (ws *WebhookSink) Publish(ctx context.Context, events []Transition) error {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ws.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, values := range ws.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := ws.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -3,14 +3,39 @@ package gqs
 import (
 	"context"
 	"errors"
+	"fmt"
+	"github.com/google/uuid"
 	"github.com/romanqed/gqs/job"
 	"github.com/romanqed/gqs/message"
-	"log/slog"
+	"maps"
+	"math/rand/v2"
+	"os"
+	"sync/atomic"
 	"time"
 
 	"github.com/romanqed/gqs/internal"
 )
 
+// DefaultHeartbeatInterval is the heartbeat interval used when
+// WorkerConfig.HeartbeatInterval is zero.
+const DefaultHeartbeatInterval = 10 * time.Second
+
+// DefaultLockLossWarn is the number of lease losses of a single job
+// after which Worker logs further losses as errors, used when
+// WorkerConfig.LockLossWarn is zero.
+const DefaultLockLossWarn = 3
+
+// DefaultStorageBackoff is the backoff between retries of job
+// transitions failing with transient storage errors, used when
+// WorkerConfig.StorageBackoff is zero.
+var DefaultStorageBackoff = BackoffConfig{
+	MaxRetries:          3,
+	InitialInterval:     50 * time.Millisecond,
+	MaxInterval:         time.Second,
+	Multiplier:          2,
+	RandomizationFactor: 0.2,
+}
+
 var (
 	// ErrKill indicates that the job must be permanently transitioned
 	// to Dead state without applying retry or backoff logic.
@@ -21,6 +46,22 @@ var (
 	// ErrKill is intended for unrecoverable business errors,
 	// such as validation failures or permanently invalid payloads.
 	ErrKill = errors.New("kill job")
+
+	// ErrHandlerTimeout indicates that a handler exceeded its maximum
+	// wall-clock duration (see WorkerConfig.HandlerTimeout).
+	//
+	// It is both the cancellation cause of the handler context and the
+	// error the job fails with; the job then follows the retry path.
+	ErrHandlerTimeout = errors.New("handler timeout")
+
+	// ErrShutdown is the cancellation cause of a handler context
+	// canceled because the worker is shutting down.
+	//
+	// Handlers can tell shutdown apart from lease loss by inspecting
+	// context.Cause: it returns ErrShutdown on shutdown and ErrLockLost
+	// (or the error returned by the lock extension) when the job lease
+	// could not be extended.
+	ErrShutdown = errors.New("worker shutdown")
 )
 
 // MessageHandler defines the user-provided function that processes
@@ -31,6 +72,10 @@ var (
 //   - the worker is shutting down
 //   - the job lease is lost
 //
+// The reason is available via context.Cause: ErrShutdown for shutdown,
+// ErrLockLost (or another lock extension error) for lease loss, and
+// ErrCancelRequested if the job was canceled with Canceler.Cancel.
+//
 // The handler must be idempotent. gqs provides at-least-once delivery
 // semantics, and a message may be executed more than once if a worker
 // crashes or fails to complete it before the visibility timeout expires.
@@ -44,13 +89,48 @@ var (
 //	    The job is permanently marked as Dead.
 //	    Retry and backoff logic are skipped.
 //
+//	context.Canceled (or an error wrapping it) during shutdown
+//	    The job is treated according to WorkerConfig.OnCancel.
+//
+//	*RetryAfterError (see RetryAfter and RetryAt)
+//	    The job is retried after the requested delay, bypassing
+//	    the retry policy. Retry limits still apply.
+//
 //	any other non-nil error
-//	    The job is retried according to BackoffConfig.
+//	    The job is retried according to the RetryPolicy.
 //	    If retry limits are exceeded, the job is transitioned to Dead.
 type MessageHandler func(ctx context.Context, msg *message.Message) error
 
+// ContextDecorator derives the context passed to the handler of jb,
+// typically attaching request-scoped dependencies, such as database
+// handles or loggers carrying job fields, with context.WithValue.
+//
+// The returned context must be derived from ctx, so that lease loss,
+// handler timeouts and shutdown still cancel the handler.
+type ContextDecorator func(ctx context.Context, jb *job.Job) context.Context
+
 type errChan chan error
 
+// CancelPolicy defines how Worker treats a job whose handler returns
+// context.Canceled (or an error wrapping it) because the worker is
+// shutting down.
+type CancelPolicy uint8
+
+const (
+	// CancelRetry applies the normal failure path: the attempt is
+	// consumed and the job is rescheduled with backoff or killed.
+	CancelRetry CancelPolicy = iota
+
+	// CancelReturn returns the job to Pending with zero backoff.
+	// The attempt is still counted.
+	CancelReturn
+
+	// CancelRelease returns the job to Pending with zero backoff
+	// without counting the attempt. It requires the Puller to implement
+	// Releaser; otherwise Worker falls back to CancelReturn.
+	CancelRelease
+)
+
 // WorkerConfig defines runtime behavior of a Worker.
 //
 // Concurrency specifies the number of concurrent message handlers.
@@ -63,16 +143,229 @@ type errChan chan error
 // PullInterval defines how often the worker polls storage for new jobs.
 //
 // LockTimeout defines the visibility timeout (lease duration) assigned
-// to each pulled job.
+// to each pulled job. Jobs with their own LockTimeout are extended
+// using it instead, starting with the first lease extension.
+//
+// HandlerTimeout limits the wall-clock duration of a single handler
+// invocation, regardless of lease extensions. Once exceeded, the handler
+// context is canceled with ErrHandlerTimeout and the job fails with it
+// immediately, even if the handler does not return. Jobs with their own
+// Timeout use it instead. Zero means no limit.
+//
+// Backoff defines the retry policy applied when a handler returns an error,
+// including optional priority demotion of rescheduled jobs.
+//
+// RetryPolicy, if set, replaces Backoff in deciding whether and when a
+// failed job is retried, for example to skip retries of validation
+// errors (see RetryPolicy). Priority demotion still follows Backoff.
+//
+// Classify and ClassPolicies refine the retry policy per kind of error:
+// a failed job whose error is classified into a class listed in
+// ClassPolicies is killed or retried according to that policy. Errors
+// of other classes, or all errors when Classify is nil, use RetryPolicy
+// or Backoff.
+//
+// ExtendInterval defines how often the lease of an in-flight job is
+// extended. If zero, half of the lock timeout of the job is used. The
+// next extension is scheduled from the lease expiry reported by the
+// Puller (job.Job.LockedUntil) rather than from the time of the last
+// extension: it happens no later than halfway through the remaining
+// lease, so that a late extension, caused for example by a long pause
+// of the process, is followed by an earlier one.
+//
+// ExtendJitter, if positive, shortens each wait before an extension by
+// a random duration up to it, so that jobs pulled together do not
+// extend their leases at the same time.
+//
+// ExtendBatchWindow enables coalescing of lease extensions. Extension
+// requests issued by concurrent handlers within this window are merged
+// into a single BatchLockExtender.ExtendLockBatch call. It has effect
+// only if the Puller implements BatchLockExtender; zero disables
+// coalescing.
+//
+// CompleteBatchWindow enables coalescing of completions and retries in
+// the same way: jobs finishing within this window are completed with
+// a single BatchCompleter.CompleteBatch call, and failed jobs are
+// rescheduled with a single BatchCompleter.ReturnBatch call. Jobs
+// completed with a result (see SetResult) or with follow-ups (see
+// PushNext) are not coalesced. It has effect only if the Puller
+// implements BatchCompleter; zero disables coalescing.
+//
+// OnCancel defines how jobs interrupted by shutdown are treated.
+//
+// ReservedConcurrency specifies the number of additional handler slots
+// reserved exclusively for high-priority jobs, that is jobs whose
+// Priority is at least ReservedPriority. High-priority jobs use reserved
+// slots first and fall back to regular ones. When reserved slots are
+// enabled, the worker never blocks pulling on a saturated regular pool:
+// regular jobs that cannot be buffered are immediately given back to
+// storage, so high-priority jobs keep flowing. Zero disables reserved
+// slots.
+//
+// MaxLogLines limits the number of handler log lines kept per job
+// (see SaveLog). If zero, DefaultMaxLogLines is used.
+//
+// OnLeaseLost, if set, is called when the lease of an in-flight job
+// is lost, that is when extending its lock fails with ErrLockLost.
+// It is called after the handler context has been canceled and must
+// not block.
+//
+// If the Puller implements LockLossRecorder, every lease loss is
+// recorded on the job. LockLossPenalty is passed to it as the base of
+// an exponential delay postponing the next delivery of a job that keeps
+// losing its lease. Once a job has lost its lease LockLossWarn times
+// (default DefaultLockLossWarn), every further loss is logged as an
+// error, as chronic lease loss usually indicates a too short
+// LockTimeout.
+//
+// RateLimit, if set, throttles handler invocations (see RateLimitConfig).
+//
+// Stream makes the worker claim jobs one at a time via StreamPuller,
+// if the Puller supports it, instead of pulling batches of BatchSize.
+// A job is claimed only once the previous one has been accepted by the
+// internal queue; with Queue set to zero, jobs are claimed only when
+// a handler is free, so leases never tick while jobs wait in a buffer.
+//
+// AdaptivePull, if set, adjusts the pull interval to the observed load
+// (see AdaptivePullConfig). It has no effect with Stream.
+//
+// Dispatch controls how pulled batches feed the handlers when BatchSize
+// exceeds Concurrency (see DispatchMode). AdaptiveBatch, if set, sizes
+// batches by the observed handler duration (see AdaptiveBatchConfig).
+// Both have no effect with Stream.
 //
-// Backoff defines the retry policy applied when a handler returns an error.
+// Events, if set, receives lifecycle events of handled jobs (see
+// EventListener).
+//
+// Filter, if set, restricts the worker to jobs matching it (see
+// PullFilter). It requires a Puller implementing FilterPuller; other
+// Pullers ignore it with a warning.
+//
+// PriorityBands, if set, replaces the priority-first order of pulls
+// with weighted fair shares: every pulled batch is split among the
+// bands by their weights, and the share a band cannot fill is passed
+// to the others, highest first. For example, bands of weights 6, 3 and
+// 1 hand out six high, three medium and one low priority jobs per ten
+// pulled while all have a backlog, so low-priority jobs keep making
+// progress. Within a band, jobs are pulled by priority as usual. Every
+// band is pulled with its own filter, so PriorityBands requires a
+// Puller implementing FilterPuller; other Pullers ignore it with a
+// warning. It has no effect with Stream.
+//
+// Notifier, if set, wakes the worker up to pull as soon as new jobs
+// are announced, instead of waiting for the next PullInterval, which
+// then only bounds the latency when notifications are lost.
+//
+// Diagnostics, if set, captures extended diagnostics for a sampled
+// fraction of failed attempts and persists them with the job, if the
+// Puller implements DiagnosticsSaver (see DiagnosticsConfig). Lease
+// losses and shutdown interruptions are not sampled.
+//
+// TimeScale, if positive, multiplies every delay the worker reschedules
+// jobs with, that is retry backoffs and lock loss penalties. It is
+// intended for integration tests, where it compresses realistic
+// schedules (see ScaledPusher): with a scale of 1.0/6000, a one minute
+// backoff elapses in 10ms. Leases, handler timeouts and the pull
+// interval are not scaled. Zero disables scaling.
+//
+// Registry, if set, makes the worker heartbeat into it every
+// HeartbeatInterval (DefaultHeartbeatInterval if zero) under the
+// Instance id, reporting the number of in-flight jobs, and deregister
+// on Stop. If Instance is empty, a random id is generated. If the
+// Puller implements OwnerPuller, the worker passes it the Instance id,
+// so that pulled jobs record the worker as their owner; this lets a
+// Reaper reassign jobs of a dead worker and operators find the owner
+// of a stuck job.
+//
+// DecorateContext, if set, derives the handler context of every job
+// (see ContextDecorator). The derived context is passed to middlewares
+// as well as to the handler.
+//
+// Blobs, if set, resolves payloads offloaded by OffloadPusher before
+// the handler is invoked, and deletes the blob of every job once it is
+// Done. Blobs of jobs ending otherwise are kept, so that they can be
+// requeued.
+//
+// Migrations, if set, upgrades the payload of every job to its latest
+// schema version before middlewares added with Use and the handler see
+// it (see Migrations). Payloads resolved through Blobs are migrated
+// after they are loaded.
+//
+// StorageBackoff defines the retries of completions, returns, releases
+// and kills failing with transient storage errors (see IsTransient),
+// such as a reset connection. If its InitialInterval is zero,
+// DefaultStorageBackoff is used. Retries stop once the lease of the
+// job would expire before the next one, as the transition cannot
+// succeed afterwards. A transition applied by the storage before the
+// failure was reported fails on retry as if the job was lost.
 type WorkerConfig struct {
-	Concurrency  int
-	Queue        int
-	BatchSize    int
-	PullInterval time.Duration
-	LockTimeout  time.Duration
-	Backoff      BackoffConfig
+	Concurrency         int
+	Queue               int
+	BatchSize           int
+	PullInterval        time.Duration
+	LockTimeout         time.Duration
+	HandlerTimeout      time.Duration
+	Backoff             BackoffConfig
+	RetryPolicy         RetryPolicy
+	Classify            Classifier
+	ClassPolicies       map[ErrorClass]ClassPolicy
+	ExtendInterval      time.Duration
+	ExtendJitter        time.Duration
+	ExtendBatchWindow   time.Duration
+	CompleteBatchWindow time.Duration
+	OnCancel            CancelPolicy
+
+	ReservedConcurrency int
+	ReservedPriority    int
+
+	MaxLogLines     int
+	OnLeaseLost     func(job *job.Job)
+	LockLossPenalty time.Duration
+	LockLossWarn    uint32
+
+	RateLimit     *RateLimitConfig
+	Stream        bool
+	Filter        *PullFilter
+	PriorityBands []PriorityBand
+	AdaptivePull  *AdaptivePullConfig
+	Dispatch      DispatchMode
+	AdaptiveBatch *AdaptiveBatchConfig
+	Events        EventListener
+	TimeScale     float64
+	Notifier      Notifier
+
+	Diagnostics *DiagnosticsConfig
+
+	Registry          Registry
+	Instance          string
+	HeartbeatInterval time.Duration
+
+	DecorateContext ContextDecorator
+	Blobs           BlobStore
+	Migrations      *Migrations
+	StorageBackoff  BackoffConfig
+}
+
+// RateLimitConfig defines a token bucket limiting the rate at which
+// Worker invokes its handler.
+//
+// Rate is the sustained number of jobs per second. Burst is the number
+// of jobs that may start at once after a quiet period; values below
+// one are treated as one.
+//
+// Key, if set, names a metadata field: jobs with different values of
+// the field are limited independently, each with its own bucket of
+// Rate and Burst. Jobs without the field share a single bucket. Buckets
+// that refilled to Burst are dropped, so memory grows with the number
+// of values seen recently rather than with all values ever seen.
+//
+// A job waiting for a token holds a handler slot and its lease, which
+// is extended while it waits. The token is taken before the handler is
+// invoked, so the wait does not count against the handler timeout.
+type RateLimitConfig struct {
+	Rate  float64
+	Burst int
+	Key   string
 }
 
 // Worker coordinates pulling, dispatching, retrying and completing jobs.
@@ -96,16 +389,62 @@ type WorkerConfig struct {
 //   - Stop waits until all in-flight handlers finish or the timeout expires.
 type Worker struct {
 	lcBase
-	puller    Puller
-	pullTask  internal.TimerTask
-	pool      *internal.WorkerPool[*job.Job]
-	log       *slog.Logger
-	handler   MessageHandler
-	batchSize int
-	interval  time.Duration
-	lock      time.Duration
-	halfLock  time.Duration
-	backoff   backoffCounter
+	puller       Puller
+	bands        *priorityBands
+	stream       StreamPuller
+	pauses       PauseObserver
+	paused       map[string]bool
+	limiter      *internal.RateLimiter
+	limitKey     string
+	pullTask     internal.TimerTask
+	pool         *internal.WorkerPool[*job.Job]
+	reserved     *internal.WorkerPool[*job.Job]
+	extender     *internal.Coalescer[*job.Job]
+	completer    *internal.Coalescer[*job.Job]
+	returner     *internal.Coalescer[returnRequest]
+	log          Logger
+	handler      MessageHandler
+	chain        MessageHandler
+	mws          []Middleware
+	concurrency  int
+	reservedN    int
+	batchSize    int
+	interval     time.Duration
+	lock         time.Duration
+	extendEvery  time.Duration
+	extendJitter time.Duration
+	timeout      time.Duration
+	retry        retryPolicy
+	storeRetry   BackoffConfig
+	classify     Classifier
+	policies     map[ErrorClass]classPolicy
+	onCancel     CancelPolicy
+	highPrio     int
+	maxLogs      int
+	onLost       func(job *job.Job)
+	decorate     ContextDecorator
+	blobs        BlobStore
+	migrations   *Migrations
+	lossPenalty  time.Duration
+	lossWarn     uint32
+	scale        float64
+	diag         *DiagnosticsConfig
+	notifier     Notifier
+	stopListen   context.CancelFunc
+	listenDone   internal.DoneChan
+	registry     Registry
+	instance     Instance
+	beatTask     internal.TimerTask
+	beat         time.Duration
+	inFlight     atomic.Int64
+	stats        workerStats
+	pulls        pullStatus
+	draining     atomic.Bool
+	pause        pauseGate
+	adaptive     *adaptivePull
+	dispatchMode DispatchMode
+	sizer        *batchSizer
+	events       EventListener
 }
 
 // NewWorker creates a new Worker instance.
@@ -114,34 +453,331 @@ type Worker struct {
 //
 // The provided Puller implementation defines storage semantics.
 // The provided MessageHandler defines user processing logic.
-func NewWorker(puller Puller, handler MessageHandler, config *WorkerConfig, log *slog.Logger) *Worker {
+func NewWorker(puller Puller, handler MessageHandler, config *WorkerConfig, log Logger) *Worker {
+	log = loggerOf(log)
+	if config.Filter != nil {
+		if filtered, ok := feature[FilterPuller](puller, CapFilter); ok {
+			puller = filtered.WithFilter(config.Filter)
+		} else {
+			log.Warn("pull filter ignored, puller does not support filters")
+		}
+	}
+	var extender *internal.Coalescer[*job.Job]
+	if Supports(puller, CapBatchExtend) && config.ExtendBatchWindow > 0 {
+		extender = internal.NewCoalescer[*job.Job](config.ExtendBatchWindow, config.Concurrency+config.ReservedConcurrency)
+	}
+	var completer *internal.Coalescer[*job.Job]
+	var returner *internal.Coalescer[returnRequest]
+	if Supports(puller, CapBatchComplete) && config.CompleteBatchWindow > 0 {
+		limit := config.Concurrency + config.ReservedConcurrency
+		completer = internal.NewCoalescer[*job.Job](config.CompleteBatchWindow, limit)
+		returner = internal.NewCoalescer[returnRequest](config.CompleteBatchWindow, limit)
+	}
+	instance := config.Instance
+	if instance == "" {
+		instance = uuid.NewString()
+	}
+	if owned, ok := feature[OwnerPuller](puller, CapOwner); ok {
+		puller = owned.WithInstance(instance)
+	}
+	var bands *priorityBands
+	if len(config.PriorityBands) != 0 {
+		if filtered, ok := feature[FilterPuller](puller, CapFilter); ok {
+			bands = newPriorityBands(filtered, config.Filter, config.PriorityBands)
+		} else {
+			log.Warn("priority bands ignored, puller does not support filters")
+		}
+	}
+	host, _ := os.Hostname()
+	beat := config.HeartbeatInterval
+	if beat <= 0 {
+		beat = DefaultHeartbeatInterval
+	}
+	lossWarn := config.LockLossWarn
+	if lossWarn == 0 {
+		lossWarn = DefaultLockLossWarn
+	}
+	maxLogs := config.MaxLogLines
+	if maxLogs <= 0 {
+		maxLogs = DefaultMaxLogLines
+	}
+	storageBackoff := config.StorageBackoff
+	if storageBackoff.InitialInterval <= 0 {
+		storageBackoff = DefaultStorageBackoff
+	}
+	var limiter *internal.RateLimiter
+	var limitKey string
+	if config.RateLimit != nil && config.RateLimit.Rate > 0 {
+		limiter = internal.NewRateLimiter(config.RateLimit.Rate, config.RateLimit.Burst)
+		limitKey = config.RateLimit.Key
+	}
+	var stream StreamPuller
+	if config.Stream {
+		stream, _ = feature[StreamPuller](puller, CapStream)
+	}
+	pauses, _ := feature[PauseObserver](puller, CapPause)
+	var reserved *internal.WorkerPool[*job.Job]
+	if config.ReservedConcurrency > 0 {
+		reserved = internal.NewWorkerPool[*job.Job](config.ReservedConcurrency, config.Queue, log)
+	}
 	return &Worker{
-		puller:    puller,
-		pool:      internal.NewWorkerPool[*job.Job](config.Concurrency, config.Queue, log),
-		log:       log,
-		handler:   handler,
-		batchSize: config.BatchSize,
-		interval:  config.PullInterval,
-		lock:      config.LockTimeout,
-		halfLock:  config.LockTimeout / 2,
-		backoff:   backoffCounter{config.Backoff},
+		puller:       puller,
+		bands:        bands,
+		stream:       stream,
+		pauses:       pauses,
+		paused:       make(map[string]bool),
+		limiter:      limiter,
+		limitKey:     limitKey,
+		pool:         internal.NewWorkerPool[*job.Job](config.Concurrency, config.Queue, log),
+		reserved:     reserved,
+		extender:     extender,
+		completer:    completer,
+		returner:     returner,
+		log:          log,
+		handler:      handler,
+		concurrency:  config.Concurrency,
+		reservedN:    config.ReservedConcurrency,
+		batchSize:    config.BatchSize,
+		interval:     config.PullInterval,
+		lock:         config.LockTimeout,
+		extendEvery:  config.ExtendInterval,
+		extendJitter: config.ExtendJitter,
+		timeout:      config.HandlerTimeout,
+		retry:        newRetryPolicy(config.RetryPolicy, config.Backoff),
+		storeRetry:   storageBackoff,
+		classify:     config.Classify,
+		policies:     newClassPolicies(config.ClassPolicies),
+		onCancel:     config.OnCancel,
+		highPrio:     config.ReservedPriority,
+		maxLogs:      maxLogs,
+		onLost:       config.OnLeaseLost,
+		decorate:     config.DecorateContext,
+		blobs:        config.Blobs,
+		migrations:   config.Migrations,
+		events:       listenerOf(config.Events),
+		adaptive:     newAdaptivePull(config.AdaptivePull, config.PullInterval),
+		dispatchMode: config.Dispatch,
+		sizer:        newBatchSizer(config.AdaptiveBatch, config.Concurrency, config.BatchSize, config.PullInterval),
+		lossPenalty:  scaleDelay(config.LockLossPenalty, config.TimeScale),
+		lossWarn:     lossWarn,
+		scale:        config.TimeScale,
+		diag:         config.Diagnostics,
+		notifier:     config.Notifier,
+		registry:     config.Registry,
+		instance:     Instance{Id: instance, Host: host},
+		beat:         beat,
+	}
+}
+
+func (w *Worker) pullStream(ctx context.Context) {
+	for entry, err := range w.stream.PullStream(ctx, w.lock) {
+		w.pulls.record(err)
+		if err != nil {
+			w.log.Error("pull failed", "err", err)
+			return
+		}
+		if !w.dispatch(ctx, entry) {
+			w.log.Debug("job push interrupted via shutdown", "id", entry.Id)
+			return
+		}
+		if w.pause.paused() {
+			return
+		}
 	}
 }
 
 func (w *Worker) pull(ctx context.Context) {
-	jobs, err := w.puller.Pull(ctx, w.batchSize, w.lock)
+	if w.pause.paused() {
+		return
+	}
+	if w.stream != nil {
+		w.pullStream(ctx)
+		return
+	}
+	batch := w.batchSize
+	if w.sizer != nil {
+		batch = w.sizer.size()
+	}
+	if w.dispatchMode == DispatchFair {
+		batch = min(batch, w.freeSlots())
+		if batch <= 0 {
+			return // every handler is busy, yield until one is free
+		}
+	}
+	var jobs []*job.Job
+	var err error
+	if w.bands != nil {
+		jobs, err = w.bands.pull(ctx, batch, w.lock)
+	} else {
+		jobs, err = w.puller.Pull(ctx, batch, w.lock)
+	}
+	w.pulls.record(err)
 	if err != nil {
 		w.log.Error("pull failed", "err", err)
 		return
 	}
-	for _, entry := range jobs {
-		if !w.pool.Push(entry) {
+	if len(jobs) < batch {
+		w.checkPaused(ctx)
+	}
+	if w.adaptive != nil {
+		w.pullTask.SetInterval(w.adaptive.next(len(jobs), batch))
+	}
+	if w.dispatchMode == DispatchFair {
+		jobs = interleave(jobs)
+	}
+	for i, entry := range jobs {
+		if !w.dispatch(ctx, entry) {
 			w.log.Debug("job push interrupted via shutdown", "id", entry.Id)
-			return // pool closed, stop handle any jobs, LockUntil fix possible pull-hold
+			// pool closed, give back the rest of the batch
+			for _, jb := range jobs[i:] {
+				w.giveBack(ctx, jb, true)
+			}
+			return
+		}
+	}
+}
+
+func (w *Worker) freeSlots() int {
+	ret := w.pool.Free()
+	if w.reserved != nil {
+		ret += w.reserved.Free()
+	}
+	return ret
+}
+
+func (w *Worker) dispatch(ctx context.Context, jb *job.Job) bool {
+	w.stats.record(jb)
+	w.events.OnPulled(jb)
+	if w.reserved == nil {
+		return w.pool.Push(jb)
+	}
+	if jb.Priority >= w.highPrio {
+		if w.reserved.TryPush(jb) || w.pool.TryPush(jb) {
+			return true
+		}
+		return w.reserved.Push(jb)
+	}
+	if w.pool.TryPush(jb) {
+		return true
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+	// regular pool is saturated, do not block high-priority jobs behind it
+	w.giveBack(ctx, jb, true)
+	return true
+}
+
+// spread turns the outcome of a batch operation into per-item errors.
+func spread(errs []error, err error, n int) []error {
+	if err == nil {
+		return errs
+	}
+	errs = make([]error, n)
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
+
+func (w *Worker) extendBatch(ctx context.Context, jobs []*job.Job) []error {
+	errs, err := w.puller.(BatchLockExtender).ExtendLockBatch(ctx, jobs, w.lock)
+	return spread(errs, err, len(jobs))
+}
+
+func (w *Worker) completeBatch(ctx context.Context, jobs []*job.Job) []error {
+	errs, err := w.puller.(BatchCompleter).CompleteBatch(ctx, jobs)
+	return spread(errs, err, len(jobs))
+}
+
+type returnRequest struct {
+	job     *job.Job
+	backoff time.Duration
+}
+
+func (w *Worker) returnBatch(ctx context.Context, reqs []returnRequest) []error {
+	jobs := make([]*job.Job, len(reqs))
+	backoffs := make([]time.Duration, len(reqs))
+	for i, req := range reqs {
+		jobs[i] = req.job
+		backoffs[i] = req.backoff
+	}
+	errs, err := w.puller.(BatchCompleter).ReturnBatch(ctx, jobs, backoffs)
+	return spread(errs, err, len(reqs))
+}
+
+func (w *Worker) doReturn(ctx context.Context, jb *job.Job, backoff time.Duration) error {
+	backoff = scaleDelay(backoff, w.scale)
+	return w.transition(ctx, jb, func() error {
+		if w.returner != nil {
+			return w.returner.Submit(ctx, returnRequest{job: jb, backoff: backoff})
+		}
+		return w.puller.Return(ctx, jb, backoff)
+	})
+}
+
+// transition calls fn performing a transition of jb, retrying it with
+// the storage backoff while it fails with a transient storage error
+// and the lease of jb outlasts the next retry.
+func (w *Worker) transition(ctx context.Context, jb *job.Job, fn func() error) error {
+	for attempt := uint32(1); ; attempt++ {
+		err := fn()
+		if err == nil || !IsTransient(err) {
+			return err
+		}
+		delay, ok := w.storeRetry.next(attempt, 0)
+		if !ok || jb.LockedUntil != nil && time.Now().Add(delay).After(*jb.LockedUntil) {
+			return err
+		}
+		w.log.Warn("retrying job transition", "id", jb.Id, "retry", attempt, "err", err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
 		}
 	}
 }
 
+func (w *Worker) jobLock(jb *job.Job) time.Duration {
+	if jb.LockTimeout > 0 {
+		return jb.LockTimeout
+	}
+	return w.lock
+}
+
+func (w *Worker) extendLock(ctx context.Context, jb *job.Job) error {
+	lock := w.jobLock(jb)
+	// coalesced extensions share the worker-wide lock timeout
+	if w.extender != nil && lock == w.lock {
+		return w.extender.Submit(ctx, jb)
+	}
+	return w.puller.ExtendLock(ctx, jb, lock)
+}
+
+// nextExtension returns the delay before the next lease extension of
+// jb. It is bounded by half of the lease remaining according to
+// jb.LockedUntil, which covers the initial lease granted by Pull with
+// the worker-wide timeout as well as extensions that ran late.
+func (w *Worker) nextExtension(jb *job.Job) time.Duration {
+	ret := w.extendEvery
+	if ret <= 0 {
+		ret = w.jobLock(jb) / 2
+	}
+	// an expired lease, or one the Puller does not report, leaves the
+	// interval unbounded, so that extending does not spin
+	if jb.LockedUntil != nil {
+		if remaining := time.Until(*jb.LockedUntil); remaining > 0 {
+			ret = min(ret, remaining/2)
+		}
+	}
+	if w.extendJitter > 0 {
+		ret -= rand.N(min(w.extendJitter, ret) + 1)
+	}
+	return ret
+}
+
 func do(handler MessageHandler, ctx context.Context, msg *message.Message) errChan {
 	ret := make(errChan, 1)
 	go func() {
@@ -150,54 +786,279 @@ func do(handler MessageHandler, ctx context.Context, msg *message.Message) errCh
 	return ret
 }
 
-func (w *Worker) handleOrExtend(ctx context.Context, jb *job.Job) error {
-	wrapped, cancel := context.WithCancel(ctx)
-	defer cancel()
-	errCh := do(w.handler, wrapped, &jb.Message)
-	timer := time.NewTimer(w.halfLock)
+func (w *Worker) handleOrExtend(ctx context.Context, jb *job.Job, at *attempt) error {
+	// the handler context is detached from the worker context, so that
+	// shutdown can be reported with its own cancellation cause
+	wrapped, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+	defer cancel(nil)
+	stop := context.AfterFunc(ctx, func() {
+		cancel(ErrShutdown)
+	})
+	defer stop()
+	msg := &at.job.Message
+	timer := time.NewTimer(w.nextExtension(jb))
 	defer timer.Stop()
+	if w.limiter != nil {
+		// the token is awaited before the attempt starts, so that the
+		// wait does not count against the handler timeout
+		waitCh := make(errChan, 1)
+		go func() {
+			waitCh <- w.limiter.Wait(wrapped, w.limitKeyOf(msg))
+		}()
+		if err := w.extendUntil(ctx, jb, timer, cancel, waitCh, nil); err != nil {
+			return err
+		}
+		at.started = time.Now()
+	}
+	handlerCtx := wrapped
+	if w.decorate != nil {
+		handlerCtx = w.decorate(wrapped, jb)
+	}
+	errCh := do(w.chain, handlerCtx, msg)
+	var deadline <-chan time.Time
+	if timeout := w.handlerTimeout(jb); timeout > 0 {
+		limit := time.NewTimer(timeout)
+		defer limit.Stop()
+		deadline = limit.C
+	}
+	return w.extendUntil(ctx, jb, timer, cancel, errCh, deadline)
+}
+
+// extendUntil extends the lease of jb with timer until done yields
+// a result or deadline passes, canceling the handler context with the
+// cause of the failure.
+func (w *Worker) extendUntil(ctx context.Context, jb *job.Job, timer *time.Timer, cancel context.CancelCauseFunc, done errChan, deadline <-chan time.Time) error {
 	for {
 		select {
 		case <-timer.C:
-			if err := w.puller.ExtendLock(ctx, jb, w.lock); err != nil {
-				cancel()
+			if err := w.extendLock(ctx, jb); err != nil {
+				cancel(err)
+				if errors.Is(err, ErrLockLost) {
+					w.leaseLost(ctx, jb)
+				}
 				return err
 			}
-			timer.Reset(w.halfLock)
-		case err := <-errCh:
+			timer.Reset(w.nextExtension(jb))
+		case <-deadline:
+			// the handler may ignore cancellation, do not wait for it
+			cancel(ErrHandlerTimeout)
+			return ErrHandlerTimeout
+		case err := <-done:
 			return err
 		}
 	}
 }
 
+func (w *Worker) handlerTimeout(jb *job.Job) time.Duration {
+	if jb.Timeout > 0 {
+		return jb.Timeout
+	}
+	return w.timeout
+}
+
+func (w *Worker) policyOf(err error) (bool, retryPolicy) {
+	if w.classify == nil {
+		return false, w.retry
+	}
+	policy, ok := w.policies[w.classify(err)]
+	if !ok {
+		return false, w.retry
+	}
+	return policy.kill, policy.retry
+}
+
+func (w *Worker) leaseLost(ctx context.Context, jb *job.Job) {
+	if recorder, ok := feature[LockLossRecorder](w.puller, CapLockLoss); ok {
+		if err := recorder.RecordLockLoss(ctx, jb, w.lossPenalty); err != nil {
+			w.log.Error("cannot record lock loss", "id", jb.Id, "err", err)
+		} else if jb.LockLosses >= w.lossWarn {
+			w.log.Error("job repeatedly loses its lock, lock timeout may be too short",
+				"id", jb.Id, "losses", jb.LockLosses, "lock", w.jobLock(jb))
+		}
+	}
+	w.events.OnLeaseLost(jb)
+	if w.onLost != nil {
+		w.onLost(jb)
+	}
+}
+
+func (w *Worker) isShutdownCancel(ctx context.Context, err error) bool {
+	return w.onCancel != CancelRetry && ctx.Err() != nil && errors.Is(err, context.Canceled)
+}
+
+func (w *Worker) giveBack(ctx context.Context, jb *job.Job, release bool) {
+	// the worker context may be already canceled, so the transition
+	// is performed on a detached context bounded by the lock timeout
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), w.lock)
+	defer cancel()
+	if releaser, ok := feature[Releaser](w.puller, CapRelease); ok && release {
+		err := w.transition(ctx, jb, func() error {
+			return releaser.Release(ctx, jb)
+		})
+		if err != nil {
+			w.log.Error("cannot release job", "id", jb.Id, "err", err)
+		}
+		return
+	}
+	err := w.transition(ctx, jb, func() error {
+		return w.puller.Return(ctx, jb, 0)
+	})
+	if err != nil {
+		w.log.Error("cannot return job", "id", jb.Id, "err", err)
+	}
+}
+
+func (w *Worker) saveLogs(ctx context.Context, jb *job.Job, at *attempt) {
+	lines := at.takeLogs()
+	if len(lines) == 0 {
+		return
+	}
+	saver, ok := feature[LogSaver](w.puller, CapLogs)
+	if !ok {
+		w.log.Warn("job logs discarded, puller does not support logs", "id", jb.Id)
+		return
+	}
+	logs := append(jb.Logs, lines...)
+	if len(logs) > w.maxLogs {
+		logs = logs[len(logs)-w.maxLogs:]
+	}
+	jb.Logs = logs
+	if err := saver.SaveLogs(ctx, jb); err != nil {
+		w.log.Error("cannot save job logs", "id", jb.Id, "err", err)
+	}
+}
+
+func (w *Worker) complete(ctx context.Context, jb *job.Job, at *attempt) error {
+	if next := at.getNext(); len(next) != 0 {
+		if completer, ok := feature[ChainCompleter](w.puller, CapChain); ok {
+			if result, ok := at.getResult(); ok {
+				jb.Result = result
+			}
+			return completer.CompleteAndPush(ctx, jb, next)
+		}
+		w.log.Error("job follow-ups discarded, puller does not support chaining", "id", jb.Id, "count", len(next))
+	}
+	result, ok := at.getResult()
+	if !ok {
+		if w.completer != nil {
+			return w.completer.Submit(ctx, jb)
+		}
+		return w.puller.Complete(ctx, jb)
+	}
+	if completer, ok := feature[ResultCompleter](w.puller, CapResult); ok {
+		return completer.CompleteWithResult(ctx, jb, result)
+	}
+	w.log.Warn("job result discarded, puller does not support results", "id", jb.Id)
+	return w.puller.Complete(ctx, jb)
+}
+
 func (w *Worker) handle(ctx context.Context, jb *job.Job) {
-	err := w.handleOrExtend(ctx, jb)
+	w.inFlight.Add(1)
+	defer w.inFlight.Add(-1)
+	// lease extensions and transitions update jb while the handler may
+	// still run, so handlers see a copy taken before dispatch
+	snapshot := *jb
+	snapshot.Metadata = maps.Clone(jb.Metadata)
+	at := &attempt{
+		job:       &snapshot,
+		number:    jb.Attempts,
+		started:   time.Now(),
+		scheduled: jb.NextRunAt,
+		lastError: jb.LastError,
+	}
+	err := w.handleOrExtend(withAttempt(ctx, at), jb, at)
+	started := at.started
+	took := time.Since(started)
+	if w.sizer != nil {
+		w.sizer.observe(took)
+	}
+	w.saveLogs(ctx, jb, at)
 	if err == nil {
-		if err := w.puller.Complete(ctx, jb); err != nil {
+		err := w.transition(ctx, jb, func() error {
+			return w.complete(ctx, jb, at)
+		})
+		if err != nil {
 			w.log.Error("cannot complete job", "id", jb.Id, "err", err)
+			return
 		}
+		w.stats.completed.Add(1)
+		w.deleteBlob(ctx, jb)
+		w.events.OnCompleted(jb, took)
 		return
 	}
 	if errors.Is(err, ErrLockLost) {
 		w.log.Warn("job lock lost", "id", jb.Id, "err", err)
 		return
 	}
-	if errors.Is(err, ErrKill) {
-		if err := w.puller.Kill(ctx, jb); err != nil {
-			w.log.Error("cannot kill job", "id", jb.Id, "err", err)
-		}
+	if w.isShutdownCancel(ctx, err) {
+		w.giveBack(ctx, jb, w.onCancel == CancelRelease)
+		return
+	}
+	jb.LastError = err.Error()
+	w.diagnose(ctx, jb, err, started.Sub(jb.UpdatedAt), took)
+	if errors.Is(err, ErrKill) || errors.Is(err, ErrCancelRequested) {
+		w.kill(ctx, jb, err)
 		return
 	}
-	backoff, ok := w.backoff.next(jb.Attempts)
+	var retryAfter *RetryAfterError
+	if errors.As(err, &retryAfter) {
+		w.retryAfter(ctx, jb, err, retryAfter.delay(time.Now()))
+		return
+	}
+	kill, policy := w.policyOf(err)
+	if kill {
+		w.kill(ctx, jb, err)
+		return
+	}
+	backoff, ok := policy.next(jb, err)
 	if !ok {
-		if err := w.puller.Kill(ctx, jb); err != nil {
-			w.log.Error("cannot kill job", "id", jb.Id, "err", err)
-		}
+		w.kill(ctx, jb, err)
+		return
+	}
+	jb.Priority = policy.backoff.demote(jb.Priority)
+	if err := w.doReturn(ctx, jb, backoff); err != nil {
+		w.log.Error("cannot return job", "id", jb.Id, "err", err)
 		return
 	}
-	if err := w.puller.Return(ctx, jb, backoff); err != nil {
+	w.stats.retried.Add(1)
+	w.events.OnRetried(jb, err, backoff)
+}
+
+func (w *Worker) retryAfter(ctx context.Context, jb *job.Job, cause error, delay time.Duration) {
+	if w.retry.exceeded(jb) {
+		w.kill(ctx, jb, cause)
+		return
+	}
+	jb.Priority = w.retry.backoff.demote(jb.Priority)
+	if err := w.doReturn(ctx, jb, delay); err != nil {
 		w.log.Error("cannot return job", "id", jb.Id, "err", err)
+		return
 	}
+	w.stats.retried.Add(1)
+	w.events.OnRetried(jb, cause, delay)
+}
+
+func (w *Worker) kill(ctx context.Context, jb *job.Job, cause error) {
+	err := w.transition(ctx, jb, func() error {
+		return w.puller.Kill(ctx, jb)
+	})
+	if err != nil {
+		w.log.Error("cannot kill job", "id", jb.Id, "err", err)
+		return
+	}
+	w.stats.killed.Add(1)
+	w.events.OnKilled(jb, cause)
+}
+
+// Use appends middlewares to the worker handler chain.
+//
+// Middlewares are applied in the order they are added: the first one
+// becomes the outermost wrapper of the MessageHandler.
+//
+// Use must be called before Start. Middlewares added to a running
+// worker take effect on the next Start.
+func (w *Worker) Use(mws ...Middleware) {
+	w.mws = append(w.mws, mws...)
 }
 
 // Start begins background pulling and processing of jobs.
@@ -211,15 +1072,166 @@ func (w *Worker) Start(ctx context.Context) error {
 	if err := w.tryStart(); err != nil {
 		return err
 	}
+	w.draining.Store(false)
+	w.chain = Chain(w.handler, w.mws...)
+	if w.migrations != nil {
+		w.chain = w.migrations.Middleware()(w.chain)
+	}
+	if w.blobs != nil {
+		w.chain = ResolvePayloads(w.blobs)(w.chain)
+	}
+	w.chain = w.hold(w.chain)
+	if w.extender != nil {
+		w.extender.Start(ctx, w.extendBatch)
+	}
+	if w.completer != nil {
+		w.completer.Start(ctx, w.completeBatch)
+		w.returner.Start(ctx, w.returnBatch)
+	}
 	w.pool.Start(ctx, w.handle)
+	if w.reserved != nil {
+		w.reserved.Start(ctx, w.handle)
+	}
+	if w.registry != nil {
+		w.instance.StartedAt = time.Now()
+		w.beatTask.Start(ctx, w.heartbeat, w.beat)
+	}
 	w.pullTask.Start(ctx, w.pull, w.interval)
+	if w.notifier != nil {
+		w.listen(ctx)
+	}
 	return nil
 }
 
+// limitKeyOf returns the rate limiter bucket of msg.
+func (w *Worker) limitKeyOf(msg *message.Message) string {
+	if w.limitKey == "" {
+		return ""
+	}
+	if value, ok := msg.Metadata[w.limitKey]; ok {
+		return fmt.Sprint(value)
+	}
+	return ""
+}
+
+func (w *Worker) deleteBlob(ctx context.Context, jb *job.Job) {
+	if w.blobs == nil {
+		return
+	}
+	key, ok := message.Get[string](&jb.Message, MetaPayloadRef)
+	if !ok {
+		return
+	}
+	if err := w.blobs.Delete(ctx, key); err != nil {
+		w.log.Warn("cannot delete payload blob", "id", jb.Id, "key", key, "err", err)
+	}
+}
+
+func (w *Worker) heartbeat(ctx context.Context) {
+	instance := w.instance
+	instance.InFlight = w.inFlight.Load()
+	if err := w.registry.Heartbeat(ctx, &instance); err != nil {
+		w.log.Error("heartbeat failed", "instance", instance.Id, "err", err)
+	}
+}
+
+func (w *Worker) deregister(done internal.DoneChan) internal.DoneChan {
+	ret := make(internal.DoneChan)
+	go func() {
+		defer close(ret)
+		<-done
+		ctx, cancel := context.WithTimeout(context.Background(), w.lock)
+		defer cancel()
+		if err := w.registry.Deregister(ctx, w.instance.Id); err != nil {
+			w.log.Error("cannot deregister instance", "instance", w.instance.Id, "err", err)
+		}
+	}()
+	return ret
+}
+
+// InstanceId returns the id the worker reports to its Registry.
+func (w *Worker) InstanceId() string {
+	return w.instance.Id
+}
+
+// giveBackBuffered gives back the jobs left in the internal queues
+// once pulling and the pools stopped, so that other workers can pull
+// them without waiting for their leases to expire.
+func (w *Worker) giveBackBuffered(done internal.DoneChan) internal.DoneChan {
+	ret := make(internal.DoneChan)
+	go func() {
+		defer close(ret)
+		<-done
+		jobs := w.pool.Abandoned()
+		if w.reserved != nil {
+			jobs = append(jobs, w.reserved.Abandoned()...)
+		}
+		for _, jb := range jobs {
+			w.log.Debug("buffered job given back via shutdown", "id", jb.Id)
+			w.giveBack(context.Background(), jb, true)
+		}
+	}()
+	return ret
+}
+
 func (w *Worker) doStop() internal.DoneChan {
-	first := w.pullTask.Stop()
-	second := w.pool.Stop()
-	return internal.Combine(first, second)
+	pools := []internal.DoneChan{w.pullTask.Stop(), w.pool.Stop()}
+	if w.reserved != nil {
+		pools = append(pools, w.reserved.Stop())
+	}
+	chans := []internal.DoneChan{w.giveBackBuffered(internal.Combine(pools...))}
+	if w.notifier != nil {
+		w.stopListen()
+		chans = append(chans, w.listenDone)
+	}
+	if w.extender != nil {
+		chans = append(chans, w.extender.Stop())
+	}
+	if w.completer != nil {
+		chans = append(chans, w.completer.Stop(), w.returner.Stop())
+	}
+	if w.registry == nil {
+		return internal.Combine(chans...)
+	}
+	chans = append(chans, w.beatTask.Stop())
+	return w.deregister(internal.Combine(chans...))
+}
+
+func wait(ctx context.Context, done internal.DoneChan) error {
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Drain stops pulling new jobs and blocks until every job already
+// pulled into the internal queue has been handled, or ctx is done.
+//
+// Unlike Stop, Drain does not cancel handler contexts, so buffered jobs
+// are finished instead of being abandoned back to storage. The worker
+// remains started: Stop must still be called afterwards to release
+// its resources. Pulling is not resumed until the worker is restarted.
+//
+// Drain returns ErrNotRunning if the worker is not started, and
+// ctx.Err() if ctx is done before draining completes.
+//
+// Worker implements Drainer, so RunWithConfig drains it before
+// stopping when RunConfig.DrainTimeout is set.
+func (w *Worker) Drain(ctx context.Context) error {
+	if !w.running() {
+		return ErrNotRunning
+	}
+	w.draining.Store(true)
+	if err := wait(ctx, w.pullTask.Stop()); err != nil {
+		return err
+	}
+	chans := []internal.DoneChan{w.pool.Drain()}
+	if w.reserved != nil {
+		chans = append(chans, w.reserved.Drain())
+	}
+	return wait(ctx, internal.Combine(chans...))
 }
 
 // Stop initiates graceful shutdown of the worker.
@@ -229,6 +1241,10 @@ func (w *Worker) doStop() internal.DoneChan {
 //  1. Stops periodic pulling of new jobs.
 //  2. Cancels the internal worker pool.
 //  3. Waits for all in-flight handlers to complete.
+//  4. Gives back jobs pulled but not started yet, releasing them if
+//     the Puller implements Releaser and returning them with zero
+//     backoff otherwise, so that they are immediately eligible for
+//     other workers.
 //
 // If shutdown does not complete within the specified timeout,
 // ErrStopTimeout is returned. In this case, background goroutines
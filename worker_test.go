@@ -4,12 +4,17 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 
 	"log/slog"
+	"slices"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/romanqed/gqs"
 	"github.com/romanqed/gqs/job"
 	"github.com/romanqed/gqs/message"
@@ -35,6 +40,26 @@ func newTestDB(t *testing.T) *bun.DB {
 	return db
 }
 
+// waitStatus polls the job identified by id until it reaches one of
+// statuses and returns it, failing the test after a few seconds.
+func waitStatus(t *testing.T, observer *gsql.Observer, id uuid.UUID, statuses ...job.Status) *job.Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		jb, err := observer.Get(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if jb != nil && slices.Contains(statuses, jb.Status) {
+			return jb
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected job %s to reach %v, got %+v", id, statuses, jb)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
 func TestWorkerProcessesJob(t *testing.T) {
 	db := newTestDB(t)
 
@@ -186,3 +211,2501 @@ func TestWorkerKillShortcut(t *testing.T) {
 
 	_ = worker.Stop(time.Second)
 }
+
+func TestWorkerRetryDemotesPriority(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		return errors.New("always fail")
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    1,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  200 * time.Millisecond,
+		Backoff: gqs.BackoffConfig{
+			InitialInterval: time.Minute,
+			MaxInterval:     time.Minute,
+			Multiplier:      1,
+			PriorityStep:    5,
+			MinPriority:     -10,
+		},
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_ = worker.Start(ctx)
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+
+	time.Sleep(200 * time.Millisecond)
+
+	j, _ := observer.Get(ctx, msg.Id)
+	if j.Status != job.Scheduled {
+		t.Fatalf("expected Scheduled, got %v", j.Status)
+	}
+	if j.Priority != -5 {
+		t.Fatalf("expected priority -5, got %d", j.Priority)
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+func TestWorkerBatchedExtendLock(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(250 * time.Millisecond):
+			return nil
+		}
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:       4,
+		Queue:             10,
+		BatchSize:         4,
+		PullInterval:      20 * time.Millisecond,
+		LockTimeout:       100 * time.Millisecond,
+		ExtendBatchWindow: 10 * time.Millisecond,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msgs := make([]*message.Message, 4)
+	for i := range msgs {
+		msgs[i] = message.NewMessage()
+		_ = pusher.Push(ctx, msgs[i], 0)
+	}
+
+	_ = worker.Start(ctx)
+
+	time.Sleep(500 * time.Millisecond)
+
+	for _, msg := range msgs {
+		j, _ := observer.Get(ctx, msg.Id)
+		if j.Status != job.Done {
+			t.Fatalf("expected Done, got %v", j.Status)
+		}
+		if j.Attempts != 1 {
+			t.Fatalf("expected single attempt, got %d", j.Attempts)
+		}
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+type extendCountingPuller struct {
+	gqs.Puller
+	extensions atomic.Int32
+}
+
+func (ep *extendCountingPuller) ExtendLock(ctx context.Context, jb *job.Job, lock time.Duration) error {
+	ep.extensions.Add(1)
+	return ep.Puller.ExtendLock(ctx, jb, lock)
+}
+
+func TestWorkerExtendInterval(t *testing.T) {
+	cases := []struct {
+		name     string
+		lock     time.Duration
+		interval time.Duration
+		check    func(extensions int32) bool
+	}{
+		// extensions follow the interval rather than the long lease
+		{"interval", 10 * time.Second, 40 * time.Millisecond, func(n int32) bool { return n >= 4 }},
+		// the remaining lease bounds a long interval
+		{"lease", 100 * time.Millisecond, 10 * time.Second, func(n int32) bool { return n >= 2 }},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			db := newTestDB(t)
+
+			pusher := gsql.NewPusher(db)
+			observer := gsql.NewObserver(db)
+			puller := &extendCountingPuller{Puller: gsql.NewPuller(db)}
+
+			handler := func(ctx context.Context, msg *message.Message) error {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(300 * time.Millisecond):
+					return nil
+				}
+			}
+
+			cfg := &gqs.WorkerConfig{
+				Concurrency:    1,
+				Queue:          10,
+				BatchSize:      1,
+				PullInterval:   20 * time.Millisecond,
+				LockTimeout:    c.lock,
+				ExtendInterval: c.interval,
+				ExtendJitter:   5 * time.Millisecond,
+			}
+
+			worker := gqs.NewWorker(puller, handler, cfg, slog.Default())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			msg := message.NewMessage()
+			_ = pusher.Push(ctx, msg, 0)
+
+			_ = worker.Start(ctx)
+
+			time.Sleep(500 * time.Millisecond)
+
+			j, _ := observer.Get(ctx, msg.Id)
+			if j.Status != job.Done || j.Attempts != 1 {
+				t.Fatalf("expected Done after single attempt, got %v after %d", j.Status, j.Attempts)
+			}
+			if n := puller.extensions.Load(); !c.check(n) {
+				t.Fatalf("unexpected number of extensions: %d", n)
+			}
+
+			_ = worker.Stop(time.Second)
+		})
+	}
+}
+
+func TestWorkerReleaseOnShutdown(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	started := make(chan struct{}, 1)
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    1,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  time.Second,
+		Backoff: gqs.BackoffConfig{
+			InitialInterval: time.Minute,
+			MaxInterval:     time.Minute,
+			Multiplier:      1,
+		},
+		OnCancel: gqs.CancelRelease,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx := context.Background()
+
+	_ = worker.Start(ctx)
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("handler not called")
+	}
+
+	if err := worker.Stop(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	j, _ := observer.Get(ctx, msg.Id)
+	if j.Status != job.Pending {
+		t.Fatalf("expected Pending, got %v", j.Status)
+	}
+	if j.Attempts != 0 {
+		t.Fatalf("expected attempt to be released, got %d", j.Attempts)
+	}
+	if j.NextRunAt.After(time.Now()) {
+		t.Fatal("expected job to be immediately eligible")
+	}
+}
+
+func TestWorkerGiveBackBufferedOnStop(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	started := make(chan struct{}, 1)
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    5,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  time.Minute,
+		OnCancel:     gqs.CancelReturn,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, slog.Default())
+
+	ctx := context.Background()
+
+	msgs := make([]*message.Message, 5)
+	for i := range msgs {
+		msgs[i] = message.NewMessage()
+		_ = pusher.Push(ctx, msgs[i], 0)
+	}
+
+	_ = worker.Start(ctx)
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("handler not called")
+	}
+
+	if err := worker.Stop(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// one job was interrupted, the others were never started
+	var released int
+	for _, msg := range msgs {
+		j, _ := observer.Get(ctx, msg.Id)
+		if j.Status != job.Pending {
+			t.Fatalf("expected Pending, got %v", j.Status)
+		}
+		if j.Attempts == 0 {
+			released++
+		}
+	}
+	if released != 4 {
+		t.Fatalf("expected 4 buffered jobs to be released, got %d", released)
+	}
+}
+
+func TestWorkerMiddleware(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	order := make(chan string, 2)
+
+	trace := func(name string) gqs.Middleware {
+		return func(next gqs.MessageHandler) gqs.MessageHandler {
+			return func(ctx context.Context, msg *message.Message) error {
+				order <- name
+				return next(ctx, msg)
+			}
+		}
+	}
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		panic("boom")
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    1,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  200 * time.Millisecond,
+		Backoff: gqs.BackoffConfig{
+			InitialInterval: time.Minute,
+			MaxInterval:     time.Minute,
+			Multiplier:      1,
+		},
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+	worker.Use(trace("outer"), trace("inner"), gqs.Recover())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_ = worker.Start(ctx)
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+
+	time.Sleep(200 * time.Millisecond)
+
+	if first := <-order; first != "outer" {
+		t.Fatalf("expected outer middleware first, got %s", first)
+	}
+
+	j, _ := observer.Get(ctx, msg.Id)
+	if j.Status != job.Scheduled {
+		t.Fatalf("expected panic to be retried, got %v", j.Status)
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+func TestWorkerStoresResult(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		if !gqs.SetResult(ctx, []byte("result")) {
+			return errors.New("result not supported")
+		}
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    1,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  200 * time.Millisecond,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_ = worker.Start(ctx)
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+
+	time.Sleep(200 * time.Millisecond)
+
+	j, _ := observer.Get(ctx, msg.Id)
+	if j.Status != job.Done {
+		t.Fatalf("expected Done, got %v", j.Status)
+	}
+	if string(j.Result) != "result" {
+		t.Fatalf("expected stored result, got %q", j.Result)
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+func TestWorkerSavesLogs(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	var calls atomic.Int32
+	handler := func(ctx context.Context, msg *message.Message) error {
+		n := calls.Add(1)
+		gqs.SaveLog(ctx, "first", "call", n)
+		gqs.SaveLogf(ctx, "second %d", n)
+		if n == 1 {
+			return errors.New("fail")
+		}
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    1,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  200 * time.Millisecond,
+		Backoff:      gqs.BackoffConfig{MaxRetries: 5, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, Multiplier: 1},
+		MaxLogLines:  3,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_ = worker.Start(ctx)
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+
+	time.Sleep(300 * time.Millisecond)
+
+	j, _ := observer.Get(ctx, msg.Id)
+	if j.Status != job.Done {
+		t.Fatalf("expected Done, got %v", j.Status)
+	}
+	if len(j.Logs) != 3 {
+		t.Fatalf("expected 3 log lines, got %d", len(j.Logs))
+	}
+	if j.Logs[0].Message != "second 1" || j.Logs[0].Attempt != 1 {
+		t.Fatalf("unexpected first line %+v", j.Logs[0])
+	}
+	if j.Logs[1].Message != "first" || j.Logs[1].Attempt != 2 {
+		t.Fatalf("unexpected second line %+v", j.Logs[1])
+	}
+	if j.Logs[2].Message != "second 2" {
+		t.Fatalf("unexpected third line %+v", j.Logs[2])
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+func TestWorkerLeaseLostCause(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+
+	logger := slog.Default()
+
+	started := make(chan *message.Message, 1)
+	cause := make(chan error, 1)
+	lost := make(chan *job.Job, 1)
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		started <- msg
+		<-ctx.Done()
+		cause <- context.Cause(ctx)
+		return ctx.Err()
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    1,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  100 * time.Millisecond,
+		OnLeaseLost: func(jb *job.Job) {
+			lost <- jb
+		},
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_ = worker.Start(ctx)
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+
+	<-started
+	// another actor takes the job away from the worker
+	current, err := gsql.NewObserver(db).Get(ctx, msg.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := puller.Kill(ctx, current); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-cause:
+		if !errors.Is(err, gqs.ErrLockLost) {
+			t.Fatalf("expected ErrLockLost cause, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler context was not canceled")
+	}
+	select {
+	case jb := <-lost:
+		if jb.Id != msg.Id {
+			t.Fatalf("unexpected job %v", jb.Id)
+		}
+		if jb.LockLosses != 1 {
+			t.Fatalf("expected lock loss to be recorded, got %d", jb.LockLosses)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnLeaseLost was not called")
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+func TestWorkerShutdownCause(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+
+	logger := slog.Default()
+
+	started := make(chan struct{}, 1)
+	cause := make(chan error, 1)
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		cause <- context.Cause(ctx)
+		return ctx.Err()
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    1,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  time.Second,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_ = worker.Start(ctx)
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+
+	<-started
+	_ = worker.Stop(time.Second)
+
+	if err := <-cause; !errors.Is(err, gqs.ErrShutdown) {
+		t.Fatalf("expected ErrShutdown cause, got %v", err)
+	}
+}
+
+func TestWorkerReservedConcurrency(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+
+	logger := slog.Default()
+
+	release := make(chan struct{})
+	highDone := make(chan struct{}, 1)
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		if msg.Priority > 0 {
+			highDone <- struct{}{}
+			return nil
+		}
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:         1,
+		Queue:               1,
+		BatchSize:           4,
+		PullInterval:        20 * time.Millisecond,
+		LockTimeout:         time.Second,
+		ReservedConcurrency: 1,
+		ReservedPriority:    10,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer close(release)
+
+	for i := 0; i < 4; i++ {
+		_ = pusher.Push(ctx, message.NewMessage(), 0)
+	}
+
+	_ = worker.Start(ctx)
+
+	time.Sleep(100 * time.Millisecond)
+
+	high := message.NewMessage()
+	high.Priority = 10
+	_ = pusher.Push(ctx, high, 0)
+
+	select {
+	case <-highDone:
+	case <-time.After(time.Second):
+		t.Fatal("high priority job was blocked by saturated pool")
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+func TestWorkerPerMessageMaxRetries(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		return errors.New("always fail")
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    1,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  200 * time.Millisecond,
+		Backoff: gqs.BackoffConfig{
+			InitialInterval: 10 * time.Millisecond,
+			MaxInterval:     10 * time.Millisecond,
+			Multiplier:      1,
+		},
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_ = worker.Start(ctx)
+
+	msg := message.NewMessage()
+	msg.MaxRetries = 1
+	msg.LockTimeout = time.Second
+	_ = pusher.Push(ctx, msg, 0)
+
+	time.Sleep(300 * time.Millisecond)
+
+	j, _ := observer.Get(ctx, msg.Id)
+	if j.Status != job.Dead {
+		t.Fatalf("expected Dead, got %v", j.Status)
+	}
+	if j.Attempts != 2 {
+		t.Fatalf("expected 2 attempts, got %d", j.Attempts)
+	}
+	if j.LockTimeout != time.Second {
+		t.Fatalf("expected persisted lock timeout, got %v", j.LockTimeout)
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+func TestWorkerDrain(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	started := make(chan struct{}, 10)
+	handler := func(ctx context.Context, msg *message.Message) error {
+		started <- struct{}{}
+		select {
+		case <-time.After(50 * time.Millisecond):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    5,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  time.Second,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := worker.Drain(ctx); !errors.Is(err, gqs.ErrNotRunning) {
+		t.Fatalf("expected ErrNotRunning, got %v", err)
+	}
+
+	var msgs []*message.Message
+	for i := 0; i < 3; i++ {
+		msg := message.NewMessage()
+		_ = pusher.Push(ctx, msg, 0)
+		msgs = append(msgs, msg)
+	}
+
+	_ = worker.Start(ctx)
+	<-started
+
+	if err := worker.Drain(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range msgs {
+		j, _ := observer.Get(ctx, msg.Id)
+		if j.Status != job.Done {
+			t.Fatalf("expected buffered job to be Done, got %v", j.Status)
+		}
+	}
+
+	late := message.NewMessage()
+	_ = pusher.Push(ctx, late, 0)
+	time.Sleep(100 * time.Millisecond)
+
+	j, _ := observer.Get(ctx, late.Id)
+	if j.Status != job.Pending {
+		t.Fatalf("expected drained worker not to pull, got %v", j.Status)
+	}
+
+	if err := worker.Stop(time.Second); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWorkerHeartbeat(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPullerWithOptions(db, &gsql.PullerOptions{Instance: "w1"})
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	release := make(chan struct{})
+	handler := func(ctx context.Context, msg *message.Message) error {
+		<-release
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:       1,
+		Queue:             10,
+		BatchSize:         1,
+		PullInterval:      20 * time.Millisecond,
+		LockTimeout:       time.Second,
+		Registry:          gsql.NewRegistry(db),
+		Instance:          "w1",
+		HeartbeatInterval: 20 * time.Millisecond,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_ = worker.Start(ctx)
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+
+	time.Sleep(100 * time.Millisecond)
+
+	instances, err := observer.Instances(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(instances) != 1 || instances[0].Id != "w1" || instances[0].InFlight != 1 {
+		t.Fatalf("unexpected instances %+v", instances)
+	}
+	j, _ := observer.Get(ctx, msg.Id)
+	if j.LockedBy != "w1" {
+		t.Fatalf("expected job locked by w1, got %q", j.LockedBy)
+	}
+
+	close(release)
+	if err := worker.Stop(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	instances, _ = observer.Instances(ctx)
+	if len(instances) != 0 {
+		t.Fatalf("expected instance to deregister, got %+v", instances)
+	}
+}
+
+func TestWorkerStream(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	release := make(chan struct{})
+	handler := func(ctx context.Context, msg *message.Message) error {
+		<-release
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        0,
+		BatchSize:    10,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  time.Second,
+		Stream:       true,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for i := 0; i < 3; i++ {
+		_ = pusher.Push(ctx, message.NewMessage(), 0)
+	}
+
+	_ = worker.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	processing, _ := observer.Count(ctx, &gqs.ListOptions{Statuses: []job.Status{job.Processing}})
+	// one job is handled, the next one is claimed and waits for a free handler
+	if processing > 2 {
+		t.Fatalf("expected claim-as-you-consume, got %d processing jobs", processing)
+	}
+
+	close(release)
+	time.Sleep(200 * time.Millisecond)
+
+	done, _ := observer.Count(ctx, &gqs.ListOptions{Statuses: []job.Status{job.Done}})
+	if done != 3 {
+		t.Fatalf("expected 3 Done jobs, got %d", done)
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+func TestWorkerRateLimit(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+
+	logger := slog.Default()
+
+	var started atomic.Int32
+	handler := func(ctx context.Context, msg *message.Message) error {
+		started.Add(1)
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  5,
+		Queue:        10,
+		BatchSize:    10,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  time.Second,
+		RateLimit:    &gqs.RateLimitConfig{Rate: 5, Burst: 1, Key: "tenant"},
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for i := 0; i < 4; i++ {
+		msg := message.NewMessage()
+		msg.Set("tenant", "a")
+		_ = pusher.Push(ctx, msg, 0)
+	}
+	other := message.NewMessage()
+	other.Set("tenant", "b")
+	_ = pusher.Push(ctx, other, 0)
+
+	_ = worker.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	// tenant a has consumed its single burst token, tenant b its own
+	if n := started.Load(); n != 2 {
+		t.Fatalf("expected 2 handled jobs, got %d", n)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	if n := started.Load(); n != 3 && n != 4 {
+		t.Fatalf("expected throttled progress, got %d handled jobs", n)
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+func TestWorkerRateLimitTimeout(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		return nil
+	}
+
+	// the third job waits for its token longer than the handler timeout
+	cfg := &gqs.WorkerConfig{
+		Concurrency:    3,
+		Queue:          10,
+		BatchSize:      10,
+		PullInterval:   20 * time.Millisecond,
+		LockTimeout:    time.Second,
+		HandlerTimeout: 100 * time.Millisecond,
+		RateLimit:      &gqs.RateLimitConfig{Rate: 5, Burst: 1},
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var ids []uuid.UUID
+	for i := 0; i < 3; i++ {
+		msg := message.NewMessage()
+		ids = append(ids, msg.Id)
+		_ = pusher.Push(ctx, msg, 0)
+	}
+
+	_ = worker.Start(ctx)
+	defer worker.Stop(time.Second)
+
+	for _, id := range ids {
+		jb := waitStatus(t, observer, id, job.Done, job.Scheduled, job.Dead)
+		if jb.Status != job.Done || jb.LastError != "" {
+			t.Fatalf("expected throttled job done on the first attempt, got %v (%s)", jb.Status, jb.LastError)
+		}
+	}
+}
+
+func TestWorkerClassPolicies(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	errValidation := errors.New("validation")
+	errNetwork := errors.New("network")
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		if msg.Type == "invalid" {
+			return errValidation
+		}
+		return errNetwork
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  2,
+		Queue:        10,
+		BatchSize:    2,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  time.Second,
+		Backoff:      gqs.BackoffConfig{MaxRetries: 10, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, Multiplier: 1},
+		Classify: func(err error) gqs.ErrorClass {
+			if errors.Is(err, errValidation) {
+				return "validation"
+			}
+			return "network"
+		},
+		ClassPolicies: map[gqs.ErrorClass]gqs.ClassPolicy{
+			"validation": {Kill: true},
+			"network":    {Backoff: gqs.BackoffConfig{MaxRetries: 1, InitialInterval: time.Hour, MaxInterval: time.Hour, Multiplier: 1}},
+		},
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	invalid := message.NewMessage()
+	invalid.Type = "invalid"
+	_ = pusher.Push(ctx, invalid, 0)
+	flaky := message.NewMessage()
+	_ = pusher.Push(ctx, flaky, 0)
+
+	_ = worker.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	j, _ := observer.Get(ctx, invalid.Id)
+	if j.Status != job.Dead || j.Attempts != 1 {
+		t.Fatalf("expected validation error to kill the job, got %v after %d attempts", j.Status, j.Attempts)
+	}
+	j, _ = observer.Get(ctx, flaky.Id)
+	if j.Status != job.Scheduled || j.NextRunAt.Before(time.Now().Add(time.Minute)) {
+		t.Fatalf("expected network error to use the long backoff, got %v at %v", j.Status, j.NextRunAt)
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+type statusPolicy struct {
+	errRateLimited error
+}
+
+func (p statusPolicy) NextDelay(attempt uint32, err error) (time.Duration, bool) {
+	if errors.Is(err, p.errRateLimited) {
+		return time.Hour, true
+	}
+	return 0, false
+}
+
+func TestWorkerRetryPolicy(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	errRateLimited := errors.New("429 too many requests")
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		if msg.Type == "invalid" {
+			return errors.New("validation")
+		}
+		return errRateLimited
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  2,
+		Queue:        10,
+		BatchSize:    2,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  time.Second,
+		Backoff:      gqs.BackoffConfig{PriorityStep: 1, MinPriority: -10},
+		RetryPolicy:  statusPolicy{errRateLimited: errRateLimited},
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	invalid := message.NewMessage()
+	invalid.Type = "invalid"
+	_ = pusher.Push(ctx, invalid, 0)
+	limited := message.NewMessage()
+	_ = pusher.Push(ctx, limited, 0)
+
+	_ = worker.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	j, _ := observer.Get(ctx, invalid.Id)
+	if j.Status != job.Dead || j.Attempts != 1 {
+		t.Fatalf("expected the policy to kill the job, got %v after %d attempts", j.Status, j.Attempts)
+	}
+	j, _ = observer.Get(ctx, limited.Id)
+	if j.Status != job.Scheduled || j.NextRunAt.Before(time.Now().Add(time.Minute)) {
+		t.Fatalf("expected the policy delay, got %v at %v", j.Status, j.NextRunAt)
+	}
+	if j.Priority != -1 {
+		t.Fatalf("expected Backoff to demote the job, got priority %d", j.Priority)
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+func TestWorkerRetryAfter(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	errUpstream := errors.New("upstream unavailable")
+	at := time.Now().Add(2 * time.Hour)
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		switch msg.Type {
+		case "after":
+			return gqs.RetryAfter(time.Hour).WithCause(errUpstream)
+		case "at":
+			return fmt.Errorf("wrapped: %w", gqs.RetryAt(at))
+		default:
+			return gqs.RetryAfter(0)
+		}
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  3,
+		Queue:        10,
+		BatchSize:    3,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  time.Second,
+		Backoff:      gqs.BackoffConfig{MaxRetries: 5, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, Multiplier: 1},
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	after := message.NewMessage()
+	after.Type = "after"
+	_ = pusher.Push(ctx, after, 0)
+	retryAt := message.NewMessage()
+	retryAt.Type = "at"
+	_ = pusher.Push(ctx, retryAt, 0)
+	limited := message.NewMessage()
+	limited.MaxRetries = 1
+	_ = pusher.Push(ctx, limited, 0)
+
+	_ = worker.Start(ctx)
+	// the limited job is rescheduled immediately, so only its end counts
+	waitStatus(t, observer, limited.Id, job.Dead, job.Done)
+	waitStatus(t, observer, after.Id, job.Scheduled, job.Dead, job.Done)
+	waitStatus(t, observer, retryAt.Id, job.Scheduled, job.Dead, job.Done)
+	_ = worker.Stop(time.Second)
+
+	j, _ := observer.Get(ctx, after.Id)
+	if j.Status != job.Scheduled || j.NextRunAt.Before(time.Now().Add(50*time.Minute)) {
+		t.Fatalf("expected the requested delay, got %v at %v", j.Status, j.NextRunAt)
+	}
+	if !strings.Contains(j.LastError, errUpstream.Error()) {
+		t.Fatalf("expected the cause to be recorded, got %q", j.LastError)
+	}
+	j, _ = observer.Get(ctx, retryAt.Id)
+	if j.Status != job.Scheduled || j.NextRunAt.Sub(at).Abs() > time.Second {
+		t.Fatalf("expected the requested time %v, got %v at %v", at, j.Status, j.NextRunAt)
+	}
+	j, _ = observer.Get(ctx, limited.Id)
+	if j.Status != job.Dead || j.Attempts != 2 {
+		t.Fatalf("expected the retry limit to apply, got %v after %d attempts", j.Status, j.Attempts)
+	}
+}
+
+func TestRetryAfterError(t *testing.T) {
+	cause := errors.New("cause")
+	err := fmt.Errorf("call: %w", gqs.RetryAfter(time.Second).WithCause(cause))
+	if !errors.Is(err, gqs.ErrRetryAfter) || !errors.Is(err, cause) {
+		t.Fatalf("expected the error to match ErrRetryAfter and its cause: %v", err)
+	}
+	var retryAfter *gqs.RetryAfterError
+	if !errors.As(err, &retryAfter) || retryAfter.Delay != time.Second {
+		t.Fatalf("expected a RetryAfterError, got %v", err)
+	}
+}
+
+func TestWorkerCompleteBatch(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		if msg.Type == "fail" {
+			return errors.New("fail")
+		}
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:         4,
+		Queue:               10,
+		BatchSize:           10,
+		PullInterval:        20 * time.Millisecond,
+		LockTimeout:         time.Second,
+		Backoff:             gqs.BackoffConfig{MaxRetries: 5, InitialInterval: time.Hour, MaxInterval: time.Hour, Multiplier: 1},
+		CompleteBatchWindow: 10 * time.Millisecond,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for i := 0; i < 6; i++ {
+		msg := message.NewMessage()
+		if i%2 == 0 {
+			msg.Type = "fail"
+		}
+		_ = pusher.Push(ctx, msg, 0)
+	}
+
+	_ = worker.Start(ctx)
+	time.Sleep(200 * time.Millisecond)
+
+	done, _ := observer.Count(ctx, &gqs.ListOptions{Statuses: []job.Status{job.Done}})
+	pending, _ := observer.Count(ctx, &gqs.ListOptions{Statuses: []job.Status{job.Scheduled}})
+	if done != 3 || pending != 3 {
+		t.Fatalf("expected 3 Done and 3 Scheduled jobs, got %d and %d", done, pending)
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+func TestWorkerHandlerTimeout(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	cause := make(chan error, 1)
+	handler := func(ctx context.Context, msg *message.Message) error {
+		<-ctx.Done()
+		cause <- context.Cause(ctx)
+		// a stuck handler ignoring cancellation
+		time.Sleep(300 * time.Millisecond)
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:    1,
+		Queue:          10,
+		BatchSize:      1,
+		PullInterval:   20 * time.Millisecond,
+		LockTimeout:    time.Second,
+		HandlerTimeout: 100 * time.Millisecond,
+		Backoff:        gqs.BackoffConfig{MaxRetries: 5, InitialInterval: time.Hour, MaxInterval: time.Hour, Multiplier: 1},
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_ = worker.Start(ctx)
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+
+	select {
+	case err := <-cause:
+		if !errors.Is(err, gqs.ErrHandlerTimeout) {
+			t.Fatalf("expected ErrHandlerTimeout cause, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler was not canceled")
+	}
+
+	j := waitStatus(t, observer, msg.Id, job.Scheduled, job.Done, job.Dead)
+	if j.Status != job.Scheduled || j.Attempts != 1 {
+		t.Fatalf("expected timed out job to be retried, got %v after %d attempts", j.Status, j.Attempts)
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+func TestWorkerTimeScale(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gqs.NewScaledPusher(gsql.NewPusher(db), 1.0/6000)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	var calls atomic.Int32
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		if calls.Add(1) < 2 {
+			return errors.New("fail once")
+		}
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    1,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  200 * time.Millisecond,
+		Backoff: gqs.BackoffConfig{
+			MaxRetries:      3,
+			InitialInterval: time.Minute,
+			MaxInterval:     time.Minute,
+			Multiplier:      1,
+		},
+		TimeScale: 1.0 / 6000,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_ = worker.Start(ctx)
+
+	msg := message.NewMessage()
+	if err := pusher.Push(ctx, msg, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+
+	j, _ := observer.Get(ctx, msg.Id)
+	if j.Status != job.Done {
+		t.Fatalf("expected Done after scaled delay and backoff, got %v", j.Status)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls.Load())
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+func TestWorkerDiagnostics(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		time.Sleep(10 * time.Millisecond)
+		return gqs.ErrKill
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    1,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  200 * time.Millisecond,
+		Diagnostics: &gqs.DiagnosticsConfig{
+			Rate: 1,
+			Capture: func(ctx context.Context, jb *job.Job, err error) map[string]any {
+				return map[string]any{"build": "test"}
+			},
+		},
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_ = worker.Start(ctx)
+
+	msg := message.NewMessage()
+	msg.Payload = []byte("payload")
+	_ = pusher.Push(ctx, msg, 0)
+
+	time.Sleep(300 * time.Millisecond)
+
+	j, _ := observer.Get(ctx, msg.Id)
+	if j.Status != job.Dead {
+		t.Fatalf("expected Dead, got %v", j.Status)
+	}
+	diag := j.Diagnostics
+	if diag == nil {
+		t.Fatal("expected diagnostics to be saved")
+	}
+	if diag.Error != gqs.ErrKill.Error() || diag.Attempt != 1 || string(diag.Payload) != "payload" {
+		t.Fatalf("unexpected diagnostics %+v", diag)
+	}
+	if diag.Duration < 10*time.Millisecond || diag.Stack == "" || diag.Attrs["build"] != "test" {
+		t.Fatalf("unexpected diagnostics %+v", diag)
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+type chanNotifier chan struct{}
+
+func (n chanNotifier) Listen(ctx context.Context) (<-chan struct{}, error) {
+	ret := make(chan struct{})
+	go func() {
+		defer close(ret)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-n:
+				ret <- struct{}{}
+			}
+		}
+	}()
+	return ret, nil
+}
+
+func TestWorkerNotifier(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		return nil
+	}
+
+	notifier := make(chanNotifier)
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    1,
+		PullInterval: time.Hour,
+		LockTimeout:  200 * time.Millisecond,
+		Notifier:     notifier,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_ = worker.Start(ctx)
+
+	time.Sleep(50 * time.Millisecond)
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+	notifier <- struct{}{}
+
+	time.Sleep(100 * time.Millisecond)
+
+	j, _ := observer.Get(ctx, msg.Id)
+	if j.Status != job.Done {
+		t.Fatalf("expected Done after notification, got %v", j.Status)
+	}
+
+	if err := worker.Stop(time.Second); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWorkerStoresLastError(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	var calls atomic.Int32
+	handler := func(ctx context.Context, msg *message.Message) error {
+		return fmt.Errorf("attempt %d", calls.Add(1))
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    1,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  200 * time.Millisecond,
+		Backoff:      gqs.BackoffConfig{MaxRetries: 2, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, Multiplier: 1},
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_ = worker.Start(ctx)
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+
+	time.Sleep(300 * time.Millisecond)
+
+	j, _ := observer.Get(ctx, msg.Id)
+	if j.Status != job.Dead {
+		t.Fatalf("expected Dead, got %v", j.Status)
+	}
+	if j.LastError != "attempt 3" {
+		t.Fatalf("expected last error of the final attempt, got %q", j.LastError)
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+func TestWorkerPushNext(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	next := message.NewMessage()
+	next.Type = "second"
+
+	var handled atomic.Int32
+	handler := func(ctx context.Context, msg *message.Message) error {
+		if msg.Type == "second" {
+			handled.Add(1)
+			return nil
+		}
+		gqs.SetResult(ctx, []byte("result"))
+		if !gqs.PushNext(ctx, next, 0) {
+			return errors.New("chaining not supported")
+		}
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    1,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  200 * time.Millisecond,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_ = worker.Start(ctx)
+
+	msg := message.NewMessage()
+	msg.Type = "first"
+	_ = pusher.Push(ctx, msg, 0)
+
+	time.Sleep(300 * time.Millisecond)
+
+	j, _ := observer.Get(ctx, msg.Id)
+	if j.Status != job.Done || string(j.Result) != "result" {
+		t.Fatalf("expected Done with result, got %v %q", j.Status, j.Result)
+	}
+	j, _ = observer.Get(ctx, next.Id)
+	if j == nil || j.Status != job.Done {
+		t.Fatal("expected follow-up to be pushed and processed")
+	}
+	if handled.Load() != 1 {
+		t.Fatalf("expected follow-up to be handled once, got %d", handled.Load())
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+func TestWorkerPreviousError(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	infos := make(chan gqs.AttemptInfo, 2)
+	handler := func(ctx context.Context, msg *message.Message) error {
+		info, ok := gqs.Attempt(ctx)
+		if !ok {
+			return errors.New("attempt info not available")
+		}
+		infos <- info
+		if prev, ok := gqs.PreviousError(ctx); !ok || prev != "endpoint down" {
+			return errors.New("endpoint down")
+		}
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    1,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  200 * time.Millisecond,
+		Backoff: gqs.BackoffConfig{
+			MaxRetries:      3,
+			InitialInterval: 10 * time.Millisecond,
+			MaxInterval:     100 * time.Millisecond,
+			Multiplier:      1,
+		},
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_ = worker.Start(ctx)
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+
+	time.Sleep(300 * time.Millisecond)
+
+	j, _ := observer.Get(ctx, msg.Id)
+	if j.Status != job.Done || j.Attempts != 2 {
+		t.Fatalf("expected Done after one retry, got %v after %d attempts", j.Status, j.Attempts)
+	}
+
+	first, second := <-infos, <-infos
+	if first.Number != 1 || first.PreviousError != "" {
+		t.Fatalf("unexpected first attempt %+v", first)
+	}
+	if second.Number != 2 || second.PreviousError != "endpoint down" {
+		t.Fatalf("unexpected second attempt %+v", second)
+	}
+	if second.Started.Before(second.Scheduled) {
+		t.Fatal("expected attempt to start after it was scheduled")
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+func TestWorkerFilter(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		if msg.Type != "wanted" {
+			return gqs.ErrKill
+		}
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    10,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  200 * time.Millisecond,
+		Filter:       &gqs.PullFilter{Types: []string{"wanted"}},
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_ = worker.Start(ctx)
+
+	wanted := message.NewMessage()
+	wanted.Type = "wanted"
+	other := message.NewMessage()
+	other.Type = "other"
+	_ = pusher.Push(ctx, wanted, 0)
+	_ = pusher.Push(ctx, other, 0)
+
+	time.Sleep(200 * time.Millisecond)
+
+	j, _ := observer.Get(ctx, wanted.Id)
+	if j.Status != job.Done {
+		t.Fatalf("expected matching job Done, got %v", j.Status)
+	}
+	j, _ = observer.Get(ctx, other.Id)
+	if j.Status != job.Pending {
+		t.Fatalf("expected filtered out job Pending, got %v", j.Status)
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+func TestWorkerStats(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+
+	logger := slog.Default()
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    10,
+		PullInterval: 100 * time.Millisecond,
+		LockTimeout:  200 * time.Millisecond,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for i := 0; i < 2; i++ {
+		_ = pusher.Push(ctx, message.NewMessage(), 0)
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	_ = worker.Start(ctx)
+
+	time.Sleep(200 * time.Millisecond)
+
+	stats := worker.Stats()
+	if stats.Pulled != 2 {
+		t.Fatalf("expected 2 pulled jobs, got %d", stats.Pulled)
+	}
+	if stats.MaxWait < 30*time.Millisecond || stats.AvgWait() > stats.MaxWait {
+		t.Fatalf("unexpected wait times %+v", stats)
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+func TestWorkerStatus(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+
+	logger := slog.Default()
+
+	release := make(chan struct{})
+	handler := func(ctx context.Context, msg *message.Message) error {
+		if msg.Type == "fail" {
+			return errors.New("fail")
+		}
+		<-release
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    10,
+		PullInterval: 50 * time.Millisecond,
+		LockTimeout:  time.Second,
+		Backoff: gqs.BackoffConfig{
+			MaxRetries:      5,
+			InitialInterval: time.Hour,
+			MaxInterval:     time.Hour,
+			Multiplier:      1,
+		},
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+	if worker.Status().State != gqs.WorkerStopped || worker.Healthy() {
+		t.Fatal("expected stopped and unhealthy worker before start")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for i := 0; i < 2; i++ {
+		_ = pusher.Push(ctx, message.NewMessage(), 0)
+	}
+
+	_ = worker.Start(ctx)
+
+	time.Sleep(150 * time.Millisecond)
+
+	status := worker.Status()
+	if status.State != gqs.WorkerRunning || !worker.Healthy() {
+		t.Fatalf("expected running and healthy worker, got %v", status.State)
+	}
+	if status.InFlight != 1 || status.Queued != 1 {
+		t.Fatalf("expected 1 in-flight and 1 queued job, got %d and %d", status.InFlight, status.Queued)
+	}
+	if status.LastPull.IsZero() || status.LastPullError != nil {
+		t.Fatalf("unexpected last pull %v: %v", status.LastPull, status.LastPullError)
+	}
+
+	failed := message.NewMessage()
+	failed.Type = "fail"
+	_ = pusher.Push(ctx, failed, 0)
+	close(release)
+
+	time.Sleep(200 * time.Millisecond)
+
+	stats := worker.Status().Stats
+	if stats.Completed != 2 || stats.Retried != 1 || stats.Killed != 0 {
+		t.Fatalf("unexpected counters %+v", stats)
+	}
+
+	_ = worker.Stop(time.Second)
+
+	if worker.Status().State != gqs.WorkerStopped || worker.Healthy() {
+		t.Fatal("expected stopped and unhealthy worker after stop")
+	}
+}
+
+func TestWorkerAdaptivePull(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    1,
+		PullInterval: 200 * time.Millisecond,
+		LockTimeout:  time.Second,
+		AdaptivePull: &gqs.AdaptivePullConfig{
+			MinInterval: 10 * time.Millisecond,
+			MaxInterval: time.Second,
+		},
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for i := 0; i < 5; i++ {
+		_ = pusher.Push(ctx, message.NewMessage(), 0)
+	}
+
+	_ = worker.Start(ctx)
+
+	// with a fixed interval, five single-job batches take 800ms
+	time.Sleep(400 * time.Millisecond)
+
+	count, _ := observer.Count(ctx, &gqs.ListOptions{Statuses: []job.Status{job.Done}})
+	if count != 5 {
+		t.Fatalf("expected full batches to shorten the interval, got %d done jobs", count)
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+func TestWorkerDispatchFair(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        0,
+		BatchSize:    10,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  time.Second,
+		Dispatch:     gqs.DispatchFair,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for i := 0; i < 5; i++ {
+		_ = pusher.Push(ctx, message.NewMessage(), 0)
+	}
+
+	_ = worker.Start(ctx)
+
+	time.Sleep(50 * time.Millisecond)
+
+	// a sequential worker would have leased the whole batch
+	processing, _ := observer.Count(ctx, &gqs.ListOptions{Statuses: []job.Status{job.Processing}})
+	if processing != 1 {
+		t.Fatalf("expected only the running job to be leased, got %d", processing)
+	}
+
+	_ = worker.Stop(time.Second)
+}
+
+type batchRecorder struct {
+	gqs.Puller
+	mutex   sync.Mutex
+	batches []int
+}
+
+func (br *batchRecorder) Pull(ctx context.Context, batch int, lock time.Duration) ([]*job.Job, error) {
+	br.mutex.Lock()
+	br.batches = append(br.batches, batch)
+	br.mutex.Unlock()
+	return br.Puller.Pull(ctx, batch, lock)
+}
+
+func TestWorkerAdaptiveBatch(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := &batchRecorder{Puller: gsql.NewPuller(db)}
+
+	logger := slog.Default()
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		time.Sleep(40 * time.Millisecond)
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    10,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  time.Second,
+		AdaptiveBatch: &gqs.AdaptiveBatchConfig{
+			Target: 20 * time.Millisecond,
+		},
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_ = worker.Start(ctx)
+	_ = pusher.Push(ctx, message.NewMessage(), 0)
+
+	time.Sleep(200 * time.Millisecond)
+	_ = worker.Stop(time.Second)
+
+	puller.mutex.Lock()
+	defer puller.mutex.Unlock()
+	if len(puller.batches) < 2 || puller.batches[0] != 10 {
+		t.Fatalf("expected full batches before the first sample, got %v", puller.batches)
+	}
+	if last := puller.batches[len(puller.batches)-1]; last != 1 {
+		t.Fatalf("expected slow handlers to shrink batches, got %v", puller.batches)
+	}
+}
+
+func TestWorkerOwner(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	release := make(chan struct{})
+	handler := func(ctx context.Context, msg *message.Message) error {
+		<-release
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    1,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  time.Second,
+		Instance:     "worker-a",
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+
+	_ = worker.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	page, err := observer.Query(ctx, &gqs.ListOptions{
+		Statuses: []job.Status{job.Processing},
+		LockedBy: []string{"worker-a"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Jobs) != 1 || page.Jobs[0].Id != msg.Id || page.Jobs[0].LockedBy != "worker-a" {
+		t.Fatalf("expected the job to be owned by the worker, got %+v", page.Jobs)
+	}
+	count, _ := observer.Count(ctx, &gqs.ListOptions{LockedBy: []string{"worker-b"}})
+	if count != 0 {
+		t.Fatalf("expected no jobs of another worker, got %d", count)
+	}
+
+	close(release)
+	_ = worker.Stop(time.Second)
+}
+
+type decoratorKey struct{}
+
+func TestWorkerDecorateContext(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+
+	logger := slog.Default()
+
+	got := make(chan string, 1)
+	handler := func(ctx context.Context, msg *message.Message) error {
+		value, _ := ctx.Value(decoratorKey{}).(string)
+		got <- value
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    10,
+		PullInterval: 50 * time.Millisecond,
+		LockTimeout:  200 * time.Millisecond,
+		DecorateContext: func(ctx context.Context, jb *job.Job) context.Context {
+			return context.WithValue(ctx, decoratorKey{}, jb.Id.String())
+		},
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+
+	_ = worker.Start(ctx)
+	defer worker.Stop(time.Second)
+
+	select {
+	case value := <-got:
+		if value != msg.Id.String() {
+			t.Fatalf("expected decorated value %s, got %q", msg.Id, value)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler was not called")
+	}
+}
+
+func TestJobWorker(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	var mu sync.Mutex
+	var attempts []uint32
+	handler := func(ctx context.Context, jb *job.Job) error {
+		mu.Lock()
+		attempts = append(attempts, jb.Attempts)
+		mu.Unlock()
+		if jb.Attempts < 2 {
+			return errors.New("fail")
+		}
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    10,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  200 * time.Millisecond,
+		Backoff: gqs.BackoffConfig{
+			MaxRetries:      3,
+			InitialInterval: 10 * time.Millisecond,
+			MaxInterval:     10 * time.Millisecond,
+			Multiplier:      1,
+		},
+	}
+
+	worker := gqs.NewJobWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+
+	_ = worker.Start(ctx)
+
+	time.Sleep(200 * time.Millisecond)
+
+	_ = worker.Stop(time.Second)
+
+	jb, _ := observer.Get(ctx, msg.Id)
+	if jb == nil || jb.Status != job.Done {
+		t.Fatalf("expected done job, got %+v", jb)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(attempts) != 2 || attempts[0] != 1 || attempts[1] != 2 {
+		t.Fatalf("unexpected attempts %v", attempts)
+	}
+}
+
+func TestJobWorkerSnapshot(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	logger := slog.Default()
+
+	changed := make(chan string, 1)
+	handler := func(ctx context.Context, jb *job.Job) error {
+		version, lockedUntil := jb.Version, jb.LockedUntil
+		// the lease is extended several times meanwhile
+		deadline := time.Now().Add(150 * time.Millisecond)
+		for time.Now().Before(deadline) {
+			if jb.Version != version || jb.LockedUntil != lockedUntil {
+				changed <- "lease"
+				return nil
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		// changes of the handler do not reach the worker
+		jb.Version = 0
+		jb.Priority = 99
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:    1,
+		Queue:          10,
+		BatchSize:      10,
+		PullInterval:   20 * time.Millisecond,
+		LockTimeout:    time.Second,
+		ExtendInterval: 20 * time.Millisecond,
+	}
+
+	worker := gqs.NewJobWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+
+	_ = worker.Start(ctx)
+	defer worker.Stop(time.Second)
+
+	jb := waitStatus(t, observer, msg.Id, job.Done)
+	if jb.Priority != 0 {
+		t.Fatalf("expected the priority of the handler copy discarded, got %d", jb.Priority)
+	}
+	select {
+	case what := <-changed:
+		t.Fatalf("expected the job of the handler unchanged, %s changed", what)
+	default:
+	}
+}
+
+func TestWorkerCancel(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+	admin := gsql.NewAdmin(db)
+
+	logger := slog.Default()
+
+	started := make(chan struct{})
+	cause := make(chan error, 1)
+	handler := func(ctx context.Context, msg *message.Message) error {
+		close(started)
+		<-ctx.Done()
+		cause <- context.Cause(ctx)
+		return ctx.Err()
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    10,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  100 * time.Millisecond,
+		Backoff: gqs.BackoffConfig{
+			MaxRetries:      3,
+			InitialInterval: 10 * time.Millisecond,
+			MaxInterval:     10 * time.Millisecond,
+			Multiplier:      1,
+		},
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+
+	_ = worker.Start(ctx)
+	defer worker.Stop(time.Second)
+
+	<-started
+	if err := admin.Cancel(ctx, msg.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-cause:
+		if !errors.Is(err, gqs.ErrCancelRequested) {
+			t.Fatalf("expected ErrCancelRequested cause, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler was not canceled")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	jb, _ := observer.Get(ctx, msg.Id)
+	if jb == nil || jb.Status != job.Cancelled {
+		t.Fatalf("expected cancelled job, got %+v", jb)
+	}
+}
+
+type recordingLogger struct {
+	gqs.NopLogger
+	errors chan string
+}
+
+func (rl *recordingLogger) Error(msg string, args ...any) {
+	select {
+	case rl.errors <- msg:
+	default:
+	}
+}
+
+func TestWorkerLogger(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+
+	handled := make(chan struct{}, 1)
+	handler := func(ctx context.Context, msg *message.Message) error {
+		handled <- struct{}{}
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    1,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  200 * time.Millisecond,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// a nil logger discards messages
+	var nilLogger *slog.Logger
+	worker := gqs.NewWorker(puller, handler, cfg, nilLogger)
+	_ = worker.Start(ctx)
+	_ = pusher.Push(ctx, message.NewMessage(), 0)
+	select {
+	case <-handled:
+	case <-time.After(time.Second):
+		t.Fatal("expected handler to be called with a nil logger")
+	}
+	_ = worker.Stop(time.Second)
+
+	logger := &recordingLogger{errors: make(chan string, 1)}
+	worker = gqs.NewWorker(puller, handler, cfg, logger)
+	_ = db.Close()
+	_ = worker.Start(ctx)
+	defer worker.Stop(time.Second)
+	select {
+	case msg := <-logger.errors:
+		if msg != "pull failed" {
+			t.Fatalf("expected pull failure to be logged, got %q", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected an error to be logged")
+	}
+}
+
+type flakyPuller struct {
+	gqs.Puller
+	failures atomic.Int32
+}
+
+func (fp *flakyPuller) Complete(ctx context.Context, jb *job.Job) error {
+	if fp.failures.Add(-1) >= 0 {
+		return &gqs.StorageError{Op: "complete", Id: jb.Id, Kind: gqs.StorageTransient, Err: errors.New("connection reset")}
+	}
+	return fp.Puller.Complete(ctx, jb)
+}
+
+type completionListener struct {
+	gqs.NopListener
+	completed chan uuid.UUID
+}
+
+func (cl *completionListener) OnCompleted(jb *job.Job, _ time.Duration) {
+	cl.completed <- jb.Id
+}
+
+func TestWorkerStorageRetry(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	observer := gsql.NewObserver(db)
+	puller := &flakyPuller{Puller: gsql.NewPuller(db)}
+	puller.failures.Store(2)
+	listener := &completionListener{completed: make(chan uuid.UUID, 1)}
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    1,
+		PullInterval: 20 * time.Millisecond,
+		LockTimeout:  time.Second,
+		StorageBackoff: gqs.BackoffConfig{
+			MaxRetries:      3,
+			InitialInterval: 5 * time.Millisecond,
+			MaxInterval:     10 * time.Millisecond,
+			Multiplier:      2,
+		},
+		Events: listener,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, slog.Default())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msg := message.NewMessage()
+	_ = pusher.Push(ctx, msg, 0)
+	_ = worker.Start(ctx)
+	select {
+	case <-listener.completed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job was not completed")
+	}
+	_ = worker.Stop(time.Second)
+
+	jb, err := observer.Get(ctx, msg.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jb.Status != job.Done || jb.Attempts != 1 {
+		t.Fatalf("expected job to be completed by retries, got %s after %d attempts", jb.Status, jb.Attempts)
+	}
+	if puller.failures.Load() != -1 {
+		t.Fatalf("expected 3 complete calls, got %d", 2-puller.failures.Load())
+	}
+}
+
+func TestWorkerPause(t *testing.T) {
+	db := newTestDB(t)
+
+	pusher := gsql.NewPusher(db)
+	puller := gsql.NewPuller(db)
+	observer := gsql.NewObserver(db)
+
+	handled := make(chan string, 10)
+	blocked := make(chan struct{})
+
+	handler := func(ctx context.Context, msg *message.Message) error {
+		if msg.Type == "blocking" {
+			<-blocked
+		}
+		handled <- msg.Type
+		return nil
+	}
+
+	cfg := &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    2,
+		PullInterval: 10 * time.Millisecond,
+		LockTimeout:  time.Second,
+	}
+
+	worker := gqs.NewWorker(puller, handler, cfg, slog.Default())
+
+	ctx := context.Background()
+
+	worker.Pause()
+	_ = worker.Start(ctx)
+	defer worker.Stop(time.Second)
+
+	if !worker.Paused() || worker.Status().State != gqs.WorkerPaused {
+		t.Fatal("expected the worker to be paused")
+	}
+
+	first := message.NewMessage()
+	first.Type = "blocking"
+	_ = pusher.Push(ctx, first, 0)
+	time.Sleep(5 * time.Millisecond)
+	second := message.NewMessage()
+	second.Type = "held"
+	_ = pusher.Push(ctx, second, 0)
+
+	time.Sleep(50 * time.Millisecond)
+	j, _ := observer.Get(ctx, first.Id)
+	if j.Status != job.Pending {
+		t.Fatalf("expected the job not to be pulled, got %v", j.Status)
+	}
+
+	worker.Resume()
+	time.Sleep(50 * time.Millisecond)
+	j, _ = observer.Get(ctx, second.Id)
+	if j.Status != job.Processing {
+		t.Fatalf("expected the second job to be buffered, got %v", j.Status)
+	}
+
+	// the buffered job is held once the first one ends
+	worker.PauseDispatch()
+	close(blocked)
+	if typ := <-handled; typ != "blocking" {
+		t.Fatalf("expected the blocking job to be handled, got %s", typ)
+	}
+	select {
+	case <-handled:
+		t.Fatal("expected the buffered job to be held")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	worker.Resume()
+	select {
+	case <-handled:
+	case <-time.After(time.Second):
+		t.Fatal("expected the held job to be handled after resume")
+	}
+}
@@ -0,0 +1,36 @@
+// Package workflow runs multi-step workflows (sagas) on top of gqs.
+//
+// A workflow is a named sequence of steps (see Definition). Every step
+// is executed as a job of its own: once a step succeeds, the job of the
+// next step is pushed, so steps survive worker crashes and are
+// distributed across workers like any other job. Workflow state, such
+// as the current step and the data steps share, is persisted in a
+// Store between steps.
+//
+// # Execution
+//
+// An Engine holds the registered definitions. Engine.Start creates the
+// state of a new workflow instance and pushes its first step;
+// Engine.Handler returns the gqs.MessageHandler executing steps, to be
+// run by a gqs.Worker consuming the queue of the Engine.
+//
+// Every step has its own retry policy (see Step.Retry). A failed step
+// is retried by the worker until its retries are exhausted or it
+// returns an error wrapping gqs.ErrKill.
+//
+// # Compensation
+//
+// Once a step fails for good, the workflow compensates: the Compensate
+// handlers of the steps completed so far run in reverse order, each as
+// a job of its own with the retry policy of its step, and the workflow
+// ends Compensated. If a compensation fails for good, the workflow ends
+// Failed and requires manual intervention.
+//
+// # Delivery
+//
+// Steps and compensations follow the at-least-once semantics of gqs:
+// a step may run again if its worker crashes before the state is
+// saved, so handlers must be idempotent. Jobs of steps have ids derived
+// from the workflow id, so a step is never pushed twice; the Pusher
+// must report duplicates with gqs.ErrDuplicateID or ignore them.
+package workflow
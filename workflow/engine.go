@@ -0,0 +1,262 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"github.com/romanqed/gqs/job"
+	"github.com/romanqed/gqs/message"
+	"math"
+	"slices"
+)
+
+// MessageType is the message type of jobs of workflow steps.
+const MessageType = "gqs.workflow"
+
+// stepMessage is the payload of the job of a step.
+type stepMessage struct {
+	Workflow   uuid.UUID `json:"workflow"`
+	Step       int       `json:"step"`
+	Compensate bool      `json:"compensate,omitempty"`
+}
+
+// EngineOptions defines optional behavior of an Engine.
+//
+// Queue is the queue jobs of steps are pushed to. The empty string
+// denotes the default queue.
+type EngineOptions struct {
+	Queue string
+}
+
+// Engine starts workflows and executes their steps.
+//
+// Definitions must be registered before the handler of the Engine
+// starts handling jobs; Register must not be called concurrently with
+// other methods.
+type Engine struct {
+	pusher gqs.Pusher
+	store  Store
+	queue  string
+	defs   map[string]*Definition
+}
+
+// NewEngine creates an Engine pushing the jobs of steps with pusher
+// and persisting workflow states in store.
+func NewEngine(pusher gqs.Pusher, store Store) *Engine {
+	return NewEngineWithOptions(pusher, store, &EngineOptions{})
+}
+
+// NewEngineWithOptions creates an Engine using the provided options.
+func NewEngineWithOptions(pusher gqs.Pusher, store Store, opts *EngineOptions) *Engine {
+	return &Engine{
+		pusher: pusher,
+		store:  store,
+		queue:  opts.Queue,
+		defs:   make(map[string]*Definition),
+	}
+}
+
+// Register registers def under def.Name. It returns ErrDuplicateWorkflow
+// if the name is already registered and ErrNoSteps if def has no steps.
+func (e *Engine) Register(def *Definition) error {
+	if _, ok := e.defs[def.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateWorkflow, def.Name)
+	}
+	if len(def.Steps) == 0 {
+		return fmt.Errorf("%w: %s", ErrNoSteps, def.Name)
+	}
+	e.defs[def.Name] = def
+	return nil
+}
+
+// Start creates a Running instance of the workflow name with the
+// initial data and pushes its first step. It returns the created
+// state.
+//
+// If the first step cannot be pushed, the instance is marked Failed
+// and the error is returned.
+func (e *Engine) Start(ctx context.Context, name string, data []byte) (*State, error) {
+	if _, ok := e.defs[name]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownWorkflow, name)
+	}
+	st := &State{
+		Id:       uuid.New(),
+		Workflow: name,
+		Status:   Running,
+		Data:     data,
+	}
+	if err := e.store.Create(ctx, st); err != nil {
+		return nil, err
+	}
+	if err := e.schedule(ctx, st); err != nil {
+		st.Status = Failed
+		st.Error = err.Error()
+		return nil, errors.Join(err, e.store.Update(context.WithoutCancel(ctx), st))
+	}
+	return st, nil
+}
+
+// Get returns the state of the workflow id.
+func (e *Engine) Get(ctx context.Context, id uuid.UUID) (*State, error) {
+	return e.store.Get(ctx, id)
+}
+
+// retryOf returns the retry policy of step.
+func retryOf(step *Step) gqs.BackoffConfig {
+	if step.Retry.InitialInterval <= 0 {
+		return DefaultStepRetry
+	}
+	return step.Retry
+}
+
+// schedule pushes the job continuing st, if st has not ended. The job
+// id is derived from the workflow id, the step and the phase, so that
+// scheduling the same continuation twice pushes a single job.
+func (e *Engine) schedule(ctx context.Context, st *State) error {
+	if st.Status != Running && st.Status != Compensating {
+		return nil
+	}
+	def, ok := e.defs[st.Workflow]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownWorkflow, st.Workflow)
+	}
+	sm := stepMessage{
+		Workflow:   st.Id,
+		Step:       st.Step,
+		Compensate: st.Status == Compensating,
+	}
+	payload, err := json.Marshal(sm)
+	if err != nil {
+		return err
+	}
+	phase := "run"
+	if sm.Compensate {
+		phase = "compensate"
+	}
+	// the worker must not kill the job before the engine gives up on
+	// it, as the engine compensates first
+	retry := retryOf(&def.Steps[st.Step])
+	maxRetries := uint32(math.MaxUint32)
+	if retry.MaxRetries > 0 && retry.MaxRetries < math.MaxUint32 {
+		maxRetries = retry.MaxRetries + 1
+	}
+	msg := &message.Message{
+		Id:         uuid.NewSHA1(st.Id, fmt.Appendf(nil, "%s/%d", phase, st.Step)),
+		Queue:      e.queue,
+		Type:       MessageType,
+		Payload:    payload,
+		MaxRetries: maxRetries,
+	}
+	msg.Set("workflow", st.Workflow)
+	msg.Set("step", def.Steps[st.Step].Name)
+	err = e.pusher.Push(ctx, msg, 0)
+	if errors.Is(err, gqs.ErrDuplicateID) {
+		return nil
+	}
+	return err
+}
+
+// Handler returns the handler executing the jobs of steps. It must be
+// run by a Worker consuming the queue of the Engine.
+func (e *Engine) Handler() gqs.MessageHandler {
+	return gqs.HandleJob(e.handle)
+}
+
+func (e *Engine) handle(ctx context.Context, jb *job.Job) error {
+	var sm stepMessage
+	if err := json.Unmarshal(jb.Payload, &sm); err != nil {
+		return fmt.Errorf("%w: %w", gqs.ErrDecode, err)
+	}
+	st, err := e.store.Get(ctx, sm.Workflow)
+	if errors.Is(err, gqs.ErrNotFound) {
+		return fmt.Errorf("%w: %w", gqs.ErrKill, err)
+	}
+	if err != nil {
+		return err
+	}
+	def, ok := e.defs[st.Workflow]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownWorkflow, st.Workflow)
+	}
+	status := Running
+	if sm.Compensate {
+		status = Compensating
+	}
+	if st.Status != status || st.Step != sm.Step {
+		// a redelivered job of a step whose outcome is already saved;
+		// its continuation may not have been pushed yet
+		return e.schedule(ctx, st)
+	}
+	step := &def.Steps[st.Step]
+	fn := step.Run
+	if sm.Compensate {
+		fn = step.Compensate
+	}
+	run := *st
+	run.Data = slices.Clone(st.Data)
+	if fn != nil {
+		err = fn(ctx, &run)
+	}
+	if err != nil {
+		return e.fail(ctx, jb, st, step, err)
+	}
+	st.Data = run.Data
+	e.advance(st, len(def.Steps))
+	if err := e.store.Update(ctx, st); err != nil {
+		return err
+	}
+	return e.schedule(ctx, st)
+}
+
+// advance moves st past its current step.
+func (e *Engine) advance(st *State, steps int) {
+	if st.Status == Compensating {
+		st.Step--
+		if st.Step < 0 {
+			st.Step = 0
+			st.Status = Compensated
+		}
+		return
+	}
+	st.Step++
+	if st.Step == steps {
+		st.Step = steps - 1
+		st.Status = Completed
+	}
+}
+
+// fail handles the failure of the current step of st: the step is
+// retried until its retries are exhausted, then the workflow starts
+// compensating, or ends Failed if a compensation failed.
+func (e *Engine) fail(ctx context.Context, jb *job.Job, st *State, step *Step, cause error) error {
+	if !errors.Is(cause, gqs.ErrKill) {
+		if delay, ok := retryOf(step).NextDelay(jb.Attempts, cause); ok {
+			return gqs.RetryAfter(delay).WithCause(cause)
+		}
+	}
+	if st.Status == Compensating {
+		st.Status = Failed
+		st.Error = errors.Join(errors.New(st.Error), cause).Error()
+	} else {
+		st.Status = Compensating
+		st.Error = cause.Error()
+		st.Step--
+		if st.Step < 0 {
+			st.Step = 0
+			st.Status = Compensated
+		}
+	}
+	if err := e.store.Update(ctx, st); err != nil {
+		return errors.Join(cause, err)
+	}
+	if err := e.schedule(ctx, st); err != nil {
+		return errors.Join(cause, err)
+	}
+	if errors.Is(cause, gqs.ErrKill) {
+		return cause
+	}
+	return fmt.Errorf("%w: %w", gqs.ErrKill, cause)
+}
@@ -0,0 +1,178 @@
+package workflow_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/romanqed/gqs"
+	gsql "github.com/romanqed/gqs/sql"
+	"github.com/romanqed/gqs/workflow"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	_ "modernc.org/sqlite"
+)
+
+func newTestDB(t *testing.T) *bun.DB {
+	t.Helper()
+	sqlDB, err := sql.Open("sqlite", "file::memory:?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1) // important for sqlite
+	db := bun.NewDB(sqlDB, sqlitedialect.New())
+	if err := gsql.InitDB(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// newTestEngine creates an Engine over an SQLite database and starts
+// a worker executing its steps.
+func newTestEngine(t *testing.T, defs ...*workflow.Definition) *workflow.Engine {
+	t.Helper()
+	db := newTestDB(t)
+	engine := workflow.NewEngine(gsql.NewPusher(db), gsql.NewWorkflowStore(db))
+	for _, def := range defs {
+		if err := engine.Register(def); err != nil {
+			t.Fatal(err)
+		}
+	}
+	worker := gqs.NewWorker(gsql.NewPuller(db), engine.Handler(), &gqs.WorkerConfig{
+		Concurrency:  1,
+		Queue:        10,
+		BatchSize:    1,
+		PullInterval: 5 * time.Millisecond,
+		LockTimeout:  time.Second,
+	}, nil)
+	if err := worker.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = worker.Stop(time.Second) })
+	return engine
+}
+
+func waitFor(t *testing.T, engine *workflow.Engine, st *workflow.State) *workflow.State {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		ret, err := engine.Get(context.Background(), st.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ret.Status.Terminal() {
+			return ret
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("workflow did not end")
+	return nil
+}
+
+type journal struct {
+	mutex   sync.Mutex
+	entries []string
+}
+
+func (j *journal) step(name string, fail error) workflow.Step {
+	record := func(entry string, err error, data bool) workflow.StepFunc {
+		return func(ctx context.Context, st *workflow.State) error {
+			j.mutex.Lock()
+			defer j.mutex.Unlock()
+			j.entries = append(j.entries, entry)
+			if err != nil {
+				return err
+			}
+			if data {
+				st.Data = append(st.Data, entry...)
+			}
+			return nil
+		}
+	}
+	return workflow.Step{
+		Name:       name,
+		Run:        record(name, fail, true),
+		Compensate: record("undo "+name, nil, false),
+		Retry:      gqs.BackoffConfig{MaxRetries: 1, InitialInterval: time.Millisecond},
+	}
+}
+
+func (j *journal) get() []string {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	return slices.Clone(j.entries)
+}
+
+func TestEngineCompletes(t *testing.T) {
+	var j journal
+	engine := newTestEngine(t, &workflow.Definition{
+		Name:  "order",
+		Steps: []workflow.Step{j.step("reserve", nil), j.step("charge", nil), j.step("ship", nil)},
+	})
+	st, err := engine.Start(context.Background(), "order", []byte(">"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	st = waitFor(t, engine, st)
+	if st.Status != workflow.Completed {
+		t.Fatalf("expected Completed, got %v (%s)", st.Status, st.Error)
+	}
+	if string(st.Data) != ">reservechargeship" {
+		t.Fatalf("expected data of every step, got %q", st.Data)
+	}
+	if !slices.Equal(j.get(), []string{"reserve", "charge", "ship"}) {
+		t.Fatalf("expected steps in order, got %v", j.get())
+	}
+}
+
+func TestEngineCompensates(t *testing.T) {
+	var j journal
+	declined := errors.New("card declined")
+	engine := newTestEngine(t, &workflow.Definition{
+		Name:  "order",
+		Steps: []workflow.Step{j.step("reserve", nil), j.step("charge", declined), j.step("ship", nil)},
+	})
+	st, err := engine.Start(context.Background(), "order", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st = waitFor(t, engine, st)
+	if st.Status != workflow.Compensated {
+		t.Fatalf("expected Compensated, got %v", st.Status)
+	}
+	if st.Error != declined.Error() {
+		t.Fatalf("expected the error of the failed step, got %q", st.Error)
+	}
+	// the failing step is retried once, then the completed step is undone
+	expected := []string{"reserve", "charge", "charge", "undo reserve"}
+	if !slices.Equal(j.get(), expected) {
+		t.Fatalf("expected %v, got %v", expected, j.get())
+	}
+	if string(st.Data) != "reserve" {
+		t.Fatalf("expected data of the completed step only, got %q", st.Data)
+	}
+}
+
+func TestEngineKillSkipsRetries(t *testing.T) {
+	var j journal
+	engine := newTestEngine(t, &workflow.Definition{
+		Name:  "single",
+		Steps: []workflow.Step{j.step("validate", fmt.Errorf("%w: invalid", gqs.ErrKill))},
+	})
+	st, err := engine.Start(context.Background(), "single", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st = waitFor(t, engine, st)
+	if st.Status != workflow.Compensated || !slices.Equal(j.get(), []string{"validate"}) {
+		t.Fatalf("expected immediate compensation, got %v after %v", st.Status, j.get())
+	}
+	if _, err := engine.Start(context.Background(), "missing", nil); !errors.Is(err, workflow.ErrUnknownWorkflow) {
+		t.Fatalf("expected ErrUnknownWorkflow, got %v", err)
+	}
+}
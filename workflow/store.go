@@ -0,0 +1,73 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store keeping states in memory.
+//
+// States are lost when the process exits, so MemoryStore suits tests
+// and workflows whose workers run in the process starting them.
+type MemoryStore struct {
+	mutex  sync.Mutex
+	states map[uuid.UUID]State
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		states: make(map[uuid.UUID]State),
+	}
+}
+
+func copyState(st *State) State {
+	ret := *st
+	ret.Data = slices.Clone(st.Data)
+	return ret
+}
+
+// Create implements Store.
+func (ms *MemoryStore) Create(_ context.Context, st *State) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	if _, ok := ms.states[st.Id]; ok {
+		return fmt.Errorf("%w: %s", gqs.ErrDuplicateID, st.Id)
+	}
+	now := time.Now()
+	st.CreatedAt = now
+	st.UpdatedAt = now
+	ms.states[st.Id] = copyState(st)
+	return nil
+}
+
+// Get implements Store.
+func (ms *MemoryStore) Get(_ context.Context, id uuid.UUID) (*State, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	st, ok := ms.states[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: workflow %s", gqs.ErrNotFound, id)
+	}
+	ret := copyState(&st)
+	return &ret, nil
+}
+
+// Update implements Store.
+func (ms *MemoryStore) Update(_ context.Context, st *State) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	stored, ok := ms.states[st.Id]
+	if !ok || stored.Version != st.Version {
+		return ErrConflict
+	}
+	st.Version++
+	st.UpdatedAt = time.Now()
+	ms.states[st.Id] = copyState(st)
+	return nil
+}
@@ -0,0 +1,149 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/romanqed/gqs"
+	"time"
+)
+
+var (
+	// ErrConflict is returned by Store.Update if the stored state was
+	// modified since it was read.
+	ErrConflict = errors.New("workflow state conflict")
+
+	// ErrUnknownWorkflow is returned for workflows without a registered
+	// Definition.
+	ErrUnknownWorkflow = errors.New("unknown workflow")
+
+	// ErrDuplicateWorkflow is returned by Engine.Register for names
+	// already registered.
+	ErrDuplicateWorkflow = errors.New("duplicate workflow")
+
+	// ErrNoSteps is returned by Engine.Register for definitions without
+	// steps.
+	ErrNoSteps = errors.New("workflow has no steps")
+)
+
+// Status is the state of a workflow instance.
+type Status uint8
+
+const (
+	// Running denotes a workflow executing its steps.
+	Running Status = iota + 1
+
+	// Completed denotes a workflow whose steps all succeeded.
+	Completed
+
+	// Compensating denotes a workflow undoing its completed steps after
+	// a step failed.
+	Compensating
+
+	// Compensated denotes a workflow whose completed steps were all
+	// compensated.
+	Compensated
+
+	// Failed denotes a workflow whose compensation failed.
+	Failed
+)
+
+// String returns the name of the status.
+func (s Status) String() string {
+	switch s {
+	case Running:
+		return "Running"
+	case Completed:
+		return "Completed"
+	case Compensating:
+		return "Compensating"
+	case Compensated:
+		return "Compensated"
+	case Failed:
+		return "Failed"
+	default:
+		return "Unknown"
+	}
+}
+
+// Terminal reports whether the workflow has ended.
+func (s Status) Terminal() bool {
+	return s == Completed || s == Compensated || s == Failed
+}
+
+// State is the persisted state of a workflow instance.
+//
+// Step is the index of the step being executed while Running, or of
+// the step being compensated while Compensating. Data is shared by all
+// steps: a step may modify it, and the modification is persisted once
+// the step succeeds. Error is the error of the step that made the
+// workflow compensate, followed by the error of the failed compensation,
+// if any.
+//
+// Version is incremented by every update and is used by Store to
+// detect concurrent modifications.
+type State struct {
+	Id        uuid.UUID
+	Workflow  string
+	Status    Status
+	Step      int
+	Data      []byte
+	Error     string
+	Version   uint64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// StepFunc executes or compensates a step of the workflow st.
+//
+// st is a copy of the stored state; changes of st.Data made by a
+// successful StepFunc are persisted. Returning an error wrapping
+// gqs.ErrKill fails the step without further retries.
+type StepFunc func(ctx context.Context, st *State) error
+
+// Step is a named step of a workflow.
+//
+// Run executes the step. Compensate, if set, undoes the effects of a
+// successful Run once a later step fails; steps without Compensate are
+// skipped during compensation.
+//
+// Retry defines the retries of Run and of Compensate. If its
+// InitialInterval is zero, DefaultStepRetry is used. A zero MaxRetries
+// retries forever.
+type Step struct {
+	Name       string
+	Run        StepFunc
+	Compensate StepFunc
+	Retry      gqs.BackoffConfig
+}
+
+// DefaultStepRetry is the retry policy of steps without their own.
+var DefaultStepRetry = gqs.BackoffConfig{
+	MaxRetries:          3,
+	InitialInterval:     time.Second,
+	MaxInterval:         time.Minute,
+	Multiplier:          2,
+	RandomizationFactor: 0.2,
+}
+
+// Definition is a named sequence of steps.
+type Definition struct {
+	Name  string
+	Steps []Step
+}
+
+// Store persists workflow states.
+type Store interface {
+
+	// Create stores the new state st.
+	Create(ctx context.Context, st *State) error
+
+	// Get returns the state of the workflow id, or an error wrapping
+	// gqs.ErrNotFound if there is none.
+	Get(ctx context.Context, id uuid.UUID) (*State, error)
+
+	// Update replaces the stored state with st if its version equals
+	// st.Version, and increments st.Version. Otherwise it returns
+	// ErrConflict.
+	Update(ctx context.Context, st *State) error
+}